@@ -4,6 +4,7 @@ import (
 	"log"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/nextlag/keeper/pkg/cleanenv"
 )
@@ -17,6 +18,8 @@ type (
 		Log          *Log          `yaml:"logger"`
 		SQLite       *SQLite       `yaml:"sqlite"`
 		FilesStorage *FilesStorage `yaml:"files_storage"`
+		Crypto       *Crypto       `yaml:"crypto"`
+		Session      *Session      `yaml:"session"`
 	}
 
 	// App contains application-specific settings.
@@ -42,7 +45,20 @@ type (
 	// FilesStorage contains file storage-related settings.
 	FilesStorage struct {
 		ServerLocation string `yaml:"server_location"`
-		ClientLocation string `yaml:"client_location"`
+		ClientLocation string `yaml:"client_location"` // Encrypted files added locally until they are uploaded.
+	}
+
+	// Crypto contains Argon2id key derivation settings for the vault key.
+	Crypto struct {
+		KDFTime    uint32 `yaml:"kdf_time" env:"KDF_TIME"`       // Number of passes over the memory.
+		KDFMemory  uint32 `yaml:"kdf_memory" env:"KDF_MEMORY"`   // Memory size in KiB.
+		KDFThreads uint8  `yaml:"kdf_threads" env:"KDF_THREADS"` // Degree of parallelism.
+	}
+
+	// Session contains settings of the unlocked vault session.
+	Session struct {
+		Path        string        `yaml:"path" env:"SESSION_PATH"`                 // Session file, the user runtime directory if empty.
+		IdleTimeout time.Duration `yaml:"idle_timeout" env:"SESSION_IDLE_TIMEOUT"` // Session expires after this period of inactivity, checked by the next command.
 	}
 )
 
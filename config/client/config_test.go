@@ -3,6 +3,7 @@ package client
 import (
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -30,6 +31,14 @@ func TestLoadConfig(t *testing.T) {
 					ServerLocation: "data",
 					ClientLocation: "tmp",
 				},
+				Crypto: &Crypto{
+					KDFTime:    3,
+					KDFMemory:  65536,
+					KDFThreads: 4,
+				},
+				Session: &Session{
+					IdleTimeout: 15 * time.Minute,
+				},
 			},
 		},
 	}
@@ -50,6 +59,11 @@ func TestLoadConfig(t *testing.T) {
 				require.Equal(t, tt.expectedConfig.SQLite.DSN, cfg.SQLite.DSN)
 				require.Equal(t, tt.expectedConfig.FilesStorage.ServerLocation, cfg.FilesStorage.ServerLocation)
 				require.Equal(t, tt.expectedConfig.FilesStorage.ClientLocation, cfg.FilesStorage.ClientLocation)
+				require.Equal(t, tt.expectedConfig.Crypto.KDFTime, cfg.Crypto.KDFTime)
+				require.Equal(t, tt.expectedConfig.Crypto.KDFMemory, cfg.Crypto.KDFMemory)
+				require.Equal(t, tt.expectedConfig.Crypto.KDFThreads, cfg.Crypto.KDFThreads)
+				require.Equal(t, tt.expectedConfig.Session.Path, cfg.Session.Path)
+				require.Equal(t, tt.expectedConfig.Session.IdleTimeout, cfg.Session.IdleTimeout)
 			}
 		})
 	}
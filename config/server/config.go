@@ -26,6 +26,7 @@ type (
 		PG           *PG           `yaml:"postgres"`
 		Cache        *Cache        `yaml:"cache"`
 		FilesStorage *FilesStorage `yaml:"files_storage"`
+		Sync         *Sync         `yaml:"sync"`
 	}
 
 	// Network contains network-related settings.
@@ -48,11 +49,32 @@ type (
 		AccessTokenPrivateKey  string        `yaml:"access_token_private_key" env:"ACCESS_TOKEN_PRIVATE_KEY"`
 		AccessTokenPublicKey   string        `yaml:"access_token_public_key" env:"ACCESS_TOKEN_PUBLIC_KEY"`
 		RefreshTokenPrivateKey string        `yaml:"refresh_token_private_key" env:"REFRESH_TOKEN_PRIVATE_KEY"`
-		RefreshTokenPublicKey  string        `yaml:"refresh_token_public_key" env:"ACCESS_TOKEN_PUBLIC_KEY"`
+		RefreshTokenPublicKey  string        `yaml:"refresh_token_public_key" env:"REFRESH_TOKEN_PUBLIC_KEY"`
 		AccessTokenExpiresIn   time.Duration `yaml:"access_token_expired_in" env:"ACCESS_TOKEN_EXPIRED_IN"`
 		RefreshTokenExpiresIn  time.Duration `yaml:"refresh_token_expired_in" env:"REFRESH_TOKEN_EXPIRED_IN"`
 		AccessTokenMaxAge      int           `yaml:"access_token_maxage" env:"ACCESS_TOKEN_MAXAGE"`
 		RefreshTokenMaxAge     int           `yaml:"refresh_token_maxage" env:"ACCESS_TOKEN_MAXAGE"`
+
+		LoginIPLimit      int           `yaml:"login_ip_limit" env:"LOGIN_IP_LIMIT"`           // Authentication requests allowed per IP address within LoginIPWindow, 0 disables the limit.
+		LoginIPWindow     time.Duration `yaml:"login_ip_window" env:"LOGIN_IP_WINDOW"`         // Window of the per IP limit.
+		LoginFreeFailures int           `yaml:"login_free_failures" env:"LOGIN_FREE_FAILURES"` // Failed sign ins of an account before the backoff starts.
+		LoginBackoff      time.Duration `yaml:"login_backoff" env:"LOGIN_BACKOFF"`             // First backoff delay, doubled by every further failure, 0 disables the backoff.
+		LoginMaxFailures  int           `yaml:"login_max_failures" env:"LOGIN_MAX_FAILURES"`   // Failed sign ins that lock the account out, 0 disables the lockout.
+		LoginLockout      time.Duration `yaml:"login_lockout" env:"LOGIN_LOCKOUT"`             // Duration of the lockout, also the longest backoff.
+
+		PasswordTime    uint32 `yaml:"password_time" env:"PASSWORD_TIME"`       // Argon2id passes over the memory of password hashes.
+		PasswordMemory  uint32 `yaml:"password_memory" env:"PASSWORD_MEMORY"`   // Argon2id memory size of password hashes in KiB.
+		PasswordThreads uint8  `yaml:"password_threads" env:"PASSWORD_THREADS"` // Argon2id parallelism of password hashes.
+
+		// Public keys rotated out of the key pairs above, tokens signed with them stay valid until they retire.
+		AccessTokenPreviousKeys  []PreviousKey `yaml:"access_token_previous_keys"`
+		RefreshTokenPreviousKeys []PreviousKey `yaml:"refresh_token_previous_keys"`
+	}
+
+	// PreviousKey is a rotated out public key tokens are still accepted with until RetiresAt.
+	PreviousKey struct {
+		PublicKey string    `yaml:"public_key"` // Base64 encoded PEM RSA public key.
+		RetiresAt time.Time `yaml:"retires_at"` // Time tokens of the key stop being accepted, never if zero.
 	}
 
 	// PG contains PostgreSQL-related settings.
@@ -71,6 +93,13 @@ type (
 	FilesStorage struct {
 		Location string `yaml:"location" env:"FILES_LOCATION"`
 	}
+
+	// Sync contains settings of the changes feed and of the purge job keeping its tables small.
+	Sync struct {
+		TombstoneRetention   time.Duration `yaml:"tombstone_retention" env:"TOMBSTONE_RETENTION"`     // Deleted items are sent as tombstones this long before they are purged, 0 keeps them.
+		IdempotencyRetention time.Duration `yaml:"idempotency_retention" env:"IDEMPOTENCY_RETENTION"` // Responses to requests with an idempotency key are replayed this long, 0 keeps them.
+		PurgeInterval        time.Duration `yaml:"purge_interval" env:"PURGE_INTERVAL"`               // Interval of the purge job, 0 disables the job.
+	}
 )
 
 var (
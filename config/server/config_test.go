@@ -34,6 +34,17 @@ func TestLoadConfig(t *testing.T) {
 					RefreshTokenExpiresIn: 6000 * time.Minute,
 					AccessTokenMaxAge:     600,
 					RefreshTokenMaxAge:    6000,
+
+					LoginIPLimit:      30,
+					LoginIPWindow:     time.Minute,
+					LoginFreeFailures: 3,
+					LoginBackoff:      time.Second,
+					LoginMaxFailures:  10,
+					LoginLockout:      15 * time.Minute,
+
+					PasswordTime:    3,
+					PasswordMemory:  65536,
+					PasswordThreads: 4,
 				},
 				PG: &config.PG{
 					PoolMax: 2,
@@ -45,6 +56,11 @@ func TestLoadConfig(t *testing.T) {
 				FilesStorage: &config.FilesStorage{
 					Location: "data",
 				},
+				Sync: &config.Sync{
+					TombstoneRetention:   720 * time.Hour,
+					IdempotencyRetention: 168 * time.Hour,
+					PurgeInterval:        time.Hour,
+				},
 			},
 		},
 	}
@@ -72,10 +88,22 @@ func TestLoadConfig(t *testing.T) {
 			require.Equal(t, tt.expectedConfig.Security.RefreshTokenExpiresIn, cfg.Security.RefreshTokenExpiresIn)
 			require.Equal(t, tt.expectedConfig.Security.AccessTokenMaxAge, cfg.Security.AccessTokenMaxAge)
 			require.Equal(t, tt.expectedConfig.Security.RefreshTokenMaxAge, cfg.Security.RefreshTokenMaxAge)
+			require.Equal(t, tt.expectedConfig.Security.LoginIPLimit, cfg.Security.LoginIPLimit)
+			require.Equal(t, tt.expectedConfig.Security.LoginIPWindow, cfg.Security.LoginIPWindow)
+			require.Equal(t, tt.expectedConfig.Security.LoginFreeFailures, cfg.Security.LoginFreeFailures)
+			require.Equal(t, tt.expectedConfig.Security.LoginBackoff, cfg.Security.LoginBackoff)
+			require.Equal(t, tt.expectedConfig.Security.LoginMaxFailures, cfg.Security.LoginMaxFailures)
+			require.Equal(t, tt.expectedConfig.Security.LoginLockout, cfg.Security.LoginLockout)
+			require.Equal(t, tt.expectedConfig.Security.PasswordTime, cfg.Security.PasswordTime)
+			require.Equal(t, tt.expectedConfig.Security.PasswordMemory, cfg.Security.PasswordMemory)
+			require.Equal(t, tt.expectedConfig.Security.PasswordThreads, cfg.Security.PasswordThreads)
 			require.Equal(t, tt.expectedConfig.PG.PoolMax, cfg.PG.PoolMax)
 			require.Equal(t, tt.expectedConfig.Cache.DefaultExpiration, cfg.Cache.DefaultExpiration)
 			require.Equal(t, tt.expectedConfig.Cache.CleanupInterval, cfg.Cache.CleanupInterval)
 			require.Equal(t, tt.expectedConfig.FilesStorage.Location, cfg.FilesStorage.Location)
+			require.Equal(t, tt.expectedConfig.Sync.TombstoneRetention, cfg.Sync.TombstoneRetention)
+			require.Equal(t, tt.expectedConfig.Sync.IdempotencyRetention, cfg.Sync.IdempotencyRetention)
+			require.Equal(t, tt.expectedConfig.Sync.PurgeInterval, cfg.Sync.PurgeInterval)
 		})
 	}
 }
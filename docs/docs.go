@@ -34,12 +34,12 @@ const docTemplate = `{
                 "summary": "Sign in a user",
                 "parameters": [
                     {
-                        "description": "Login credentials",
+                        "description": "Login credentials and device",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/v1.loginPayload"
+                            "$ref": "#/definitions/v1.signInPayload"
                         }
                     }
                 ],
@@ -50,12 +50,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/entity.JWT"
                         }
                     },
+                    "202": {
+                        "description": "TOTP challenge to be completed at /auth/login/totp",
+                        "schema": {
+                            "$ref": "#/definitions/entity.JWT"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/v1.response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -65,9 +89,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/logout": {
+        "/auth/login/totp": {
             "post": {
-                "description": "Clear JWT tokens and user session",
+                "description": "Check the TOTP code of a sign in challenge and generate JWT tokens",
                 "consumes": [
                     "application/json"
                 ],
@@ -77,10 +101,39 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Log out the user",
+                "summary": "Complete a sign in with a TOTP code",
+                "parameters": [
+                    {
+                        "description": "Email, challenge and TOTP code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.totpSignInPayload"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.JWT"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/v1.response"
                         }
@@ -94,9 +147,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/refresh": {
-            "post": {
-                "description": "Refresh the JWT access token using the refresh token",
+        "/auth/logout": {
+            "get": {
+                "description": "Revoke the session of the refresh or access token on the server and clear JWT tokens.\nAccess tokens of the revoked session stop working at once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -106,16 +159,16 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Refresh JWT access token",
+                "summary": "Log out the user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.JWT"
+                            "$ref": "#/definitions/v1.response"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/v1.response"
                         }
@@ -123,9 +176,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/register": {
+        "/auth/recover": {
             "post": {
-                "description": "Register a new user and generate initial JWT tokens",
+                "description": "Check the recovery key secret of the user and set the new password with the data key wrapped by it,\nthe user is signed out on every device",
                 "consumes": [
                     "application/json"
                 ],
@@ -135,23 +188,23 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Sign up a new user",
+                "summary": "Set a new password with the recovery key",
                 "parameters": [
                     {
-                        "description": "Registration credentials",
+                        "description": "Email, recovery key secret, new password and wrapped data key",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/v1.loginPayload"
+                            "$ref": "#/definitions/entity.Recovery"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "$ref": "#/definitions/v1.response"
                         }
                     },
                     "400": {
@@ -160,6 +213,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -169,21 +234,53 @@ const docTemplate = `{
                 }
             }
         },
-        "/ping": {
-            "get": {
-                "description": "Endpoint to check if the application is running correctly",
+        "/auth/recover/key": {
+            "post": {
+                "description": "Check the recovery key secret of the user and return the data key wrapped by the recovery key",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "auth"
+                ],
+                "summary": "Get the data key wrapped by the recovery key",
+                "parameters": [
+                    {
+                        "description": "Email and recovery key secret",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Recovery"
+                        }
+                    }
                 ],
-                "summary": "Check the health of the application",
                 "responses": {
                     "200": {
-                        "description": "connected",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.RecoveryKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
                         }
                     },
                     "500": {
@@ -195,70 +292,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/binary": {
+        "/auth/refresh": {
             "get": {
-                "description": "Retrieve all binaries uploaded by the current user",
+                "description": "Refresh the JWT access token using the refresh token. The refresh token is rotated:\nthe response holds a new one and the given one stops working. Reusing a rotated refresh token revokes the session.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "binaries"
+                    "auth"
                 ],
-                "summary": "Get all binaries for the current user",
+                "summary": "Refresh JWT access token",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entity.Binary"
-                            }
+                            "$ref": "#/definitions/entity.JWT"
                         }
                     },
-                    "204": {
-                        "description": "No content"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.response"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/register": {
             "post": {
-                "description": "Upload a new binary for the current user",
+                "description": "Register a new user together with the data key of the vault wrapped by the master password\nand by the recovery key",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "binaries"
+                    "auth"
                 ],
-                "summary": "Add a new binary",
+                "summary": "Sign up a new user",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Binary name",
-                        "name": "name",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Binary file",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "Registration credentials and vault keys",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.signUpPayload"
+                        }
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/entity.Binary"
+                            "$ref": "#/definitions/entity.User"
                         }
                     },
                     "400": {
@@ -267,6 +364,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -276,27 +379,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/binary/{id}": {
-            "get": {
-                "description": "Download a specific binary identified by its UUID",
+        "/auth/totp/disable": {
+            "post": {
+                "description": "Turn the TOTP second factor off for a user who has lost the authenticator, the recovery codes stop working",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "binaries"
+                    "auth"
                 ],
-                "summary": "Download a binary by UUID",
+                "summary": "Disable two-factor authentication with a recovery code",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Binary UUID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Credentials and recovery code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.disableTOTPPayload"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Two-factor authentication disabled",
                         "schema": {
-                            "type": "file"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -305,38 +416,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.response"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Delete a specific binary identified by its UUID",
-                "tags": [
-                    "binaries"
-                ],
-                "summary": "Delete a binary by UUID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Binary UUID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "202": {
-                        "description": "delete accepted",
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/v1.response"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/v1.response"
                         }
@@ -350,9 +443,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/binary/{id}/meta": {
+        "/auth/upgrade": {
             "post": {
-                "description": "Add metadata to a specific binary identified by its UUID",
+                "description": "Replace the master password of a user registered before the auth hash with the auth hash and generate JWT tokens",
                 "consumes": [
                     "application/json"
                 ],
@@ -360,38 +453,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "binaries"
+                    "auth"
                 ],
-                "summary": "Add metadata to a binary",
+                "summary": "Upgrade a user to the auth hash",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Binary UUID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Metadata for the binary",
-                        "name": "metadata",
+                        "description": "Master password and auth hash",
+                        "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entity.Meta"
-                            }
+                            "$ref": "#/definitions/v1.upgradePayload"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entity.Meta"
-                            }
+                            "$ref": "#/definitions/entity.JWT"
+                        }
+                    },
+                    "202": {
+                        "description": "TOTP challenge to be completed at /auth/login/totp",
+                        "schema": {
+                            "$ref": "#/definitions/entity.JWT"
                         }
                     },
                     "400": {
@@ -400,6 +486,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -409,23 +501,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/cards": {
+        "/ping": {
             "get": {
-                "description": "Retrieve all cards for the current user",
+                "description": "Endpoint to check if the application is running correctly",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "cards"
+                    "health"
                 ],
-                "summary": "Get all cards for the current user",
+                "summary": "Check the health of the application",
+                "responses": {
+                    "200": {
+                        "description": "connected",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/binary": {
+            "get": {
+                "description": "Retrieve all binaries uploaded by the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "binaries"
+                ],
+                "summary": "Get all binaries for the current user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/entity.Card"
+                                "$ref": "#/definitions/entity.Binary"
                             }
                         }
                     },
@@ -441,33 +559,44 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Upload a new card for the current user",
+                "description": "Upload a new binary for the current user",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "cards"
+                    "binaries"
                 ],
-                "summary": "Add a new card",
+                "summary": "Add a new binary",
                 "parameters": [
                     {
-                        "description": "Card data",
-                        "name": "card",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.Card"
-                        }
+                        "type": "string",
+                        "description": "Binary name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Binary ID chosen by the client",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Binary file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/entity.Card"
+                            "$ref": "#/definitions/entity.Binary"
                         }
                     },
                     "400": {
@@ -485,27 +614,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/cards/{id}": {
-            "delete": {
-                "description": "Delete a specific card identified by its UUID",
+        "/user/binary/{id}": {
+            "get": {
+                "description": "Download a specific binary identified by its UUID",
                 "tags": [
-                    "cards"
+                    "binaries"
                 ],
-                "summary": "Delete a card by UUID",
+                "summary": "Download a binary by UUID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Card UUID",
+                        "description": "Binary UUID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Delete accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -522,39 +651,24 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
-                "description": "Update a specific card identified by its UUID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+            "delete": {
+                "description": "Delete a specific binary identified by its UUID",
                 "tags": [
-                    "cards"
+                    "binaries"
                 ],
-                "summary": "Update a card by UUID",
+                "summary": "Delete a binary by UUID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Card UUID",
+                        "description": "Binary UUID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated card data",
-                        "name": "card",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.Card"
-                        }
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "Update accepted",
+                        "description": "delete accepted",
                         "schema": {
                             "type": "string"
                         }
@@ -574,21 +688,60 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/info": {
-            "get": {
-                "description": "Retrieve information about the current user",
+        "/user/binary/{id}/meta": {
+            "post": {
+                "description": "Add metadata to a specific binary identified by its UUID",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "binaries"
+                ],
+                "summary": "Add metadata to a binary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Binary UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Metadata for the binary",
+                        "name": "metadata",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Meta"
+                            }
+                        }
+                    }
                 ],
-                "summary": "Get current user information",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Meta"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
                         }
                     },
                     "500": {
@@ -600,23 +753,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/logins": {
+        "/user/cards": {
             "get": {
-                "description": "Retrieve all logins for the current user",
+                "description": "Retrieve all cards for the current user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "logins"
+                    "cards"
                 ],
-                "summary": "Get all logins for the current user",
+                "summary": "Get all cards for the current user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/entity.Login"
+                                "$ref": "#/definitions/entity.Card"
                             }
                         }
                     },
@@ -632,7 +785,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Upload a new login for the current user",
+                "description": "Upload a new card for the current user",
                 "consumes": [
                     "application/json"
                 ],
@@ -640,17 +793,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "logins"
+                    "cards"
                 ],
-                "summary": "Add a new login",
+                "summary": "Add a new card",
                 "parameters": [
                     {
-                        "description": "Login data",
-                        "name": "login",
+                        "description": "Card data",
+                        "name": "card",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Login"
+                            "$ref": "#/definitions/entity.Card"
                         }
                     }
                 ],
@@ -658,7 +811,7 @@ const docTemplate = `{
                     "202": {
                         "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/entity.Login"
+                            "$ref": "#/definitions/entity.Card"
                         }
                     },
                     "400": {
@@ -676,17 +829,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/logins/{id}": {
+        "/user/cards/{id}": {
             "delete": {
-                "description": "Delete a specific login identified by its UUID",
+                "description": "Delete a specific card identified by its UUID",
                 "tags": [
-                    "logins"
+                    "cards"
                 ],
-                "summary": "Delete a login by UUID",
+                "summary": "Delete a card by UUID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Login UUID",
+                        "description": "Card UUID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -714,7 +867,7 @@ const docTemplate = `{
                 }
             },
             "patch": {
-                "description": "Update a specific login identified by its UUID",
+                "description": "Update a specific card identified by its UUID",
                 "consumes": [
                     "application/json"
                 ],
@@ -722,24 +875,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "logins"
+                    "cards"
                 ],
-                "summary": "Update a login by UUID",
+                "summary": "Update a card by UUID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Login UUID",
+                        "description": "Card UUID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated login data",
-                        "name": "login",
+                        "description": "Updated card data",
+                        "name": "card",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Login"
+                            "$ref": "#/definitions/entity.Card"
                         }
                     }
                 ],
@@ -756,6 +909,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -765,28 +930,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/notes": {
+        "/user/changes": {
             "get": {
-                "description": "Retrieve all notes for the current user",
+                "description": "Retrieve the items of the current user created, updated or deleted since the cursor,\nthe whole vault without a cursor. The cursor of the response is the one of the next request",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "notes"
+                    "sync"
+                ],
+                "summary": "Get the items changed since a cursor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cursor of the previous request",
+                        "name": "since",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all notes for the current user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entity.SecretNote"
-                            }
+                            "$ref": "#/definitions/entity.Changes"
                         }
                     },
-                    "204": {
-                        "description": "No content"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -795,27 +968,814 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Upload a new note for the current user",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/user/devices": {
+            "get": {
+                "description": "Retrieve the devices the current user is signed in on, the one of the request is marked as current",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "notes"
+                    "devices"
                 ],
-                "summary": "Add a new note",
+                "summary": "Get devices of the current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Session"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/devices/{id}": {
+            "delete": {
+                "description": "Sign the current user out on a device, its access and refresh tokens stop working at once",
+                "tags": [
+                    "devices"
+                ],
+                "summary": "Revoke a device by UUID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Delete accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Rename a device the current user is signed in on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "devices"
+                ],
+                "summary": "Rename a device by UUID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New device name",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.renameDevicePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Update accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/events": {
+            "get": {
+                "description": "Stream Server-Sent Events about the changes made on other devices: item-changed and item-deleted\nwith the uuid, type and revision of the item, session-revoked with the uuid of the session.\nA stream resumed with the Last-Event-ID header gets the events it has missed first, or a resync\nevent when they are no longer kept. The stream ends once the session of the request is revoked",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "Stream the changes of the current user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/info": {
+            "get": {
+                "description": "Retrieve information about the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get current user information",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.User"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/key": {
+            "get": {
+                "description": "Retrieve the vault data key wrapped by the master password",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "password"
+                ],
+                "summary": "Get the wrapped data key",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.DataKey"
+                        }
+                    },
+                    "204": {
+                        "description": "No content"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the vault with the staged copy re-encrypted with a new data key in one transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "password"
+                ],
+                "summary": "Rotate the data key",
+                "parameters": [
+                    {
+                        "description": "Password and the new wrapped data key",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.DataKeyRotation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/logins": {
+            "get": {
+                "description": "Retrieve all logins for the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "logins"
+                ],
+                "summary": "Get all logins for the current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Login"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "No content"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Upload a new login for the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "logins"
+                ],
+                "summary": "Add a new login",
+                "parameters": [
+                    {
+                        "description": "Login data",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Login"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Login"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/logins/{id}": {
+            "delete": {
+                "description": "Delete a specific login identified by its UUID",
+                "tags": [
+                    "logins"
+                ],
+                "summary": "Delete a login by UUID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Login UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Delete accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update a specific login identified by its UUID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "logins"
+                ],
+                "summary": "Update a login by UUID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Login UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated login data",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Login"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Update accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/me": {
+            "delete": {
+                "description": "Delete the account with all of its items, files, sessions and personal tokens.\nThe password, and the TOTP code if two-factor authentication is enabled, are checked again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete the current user",
+                "parameters": [
+                    {
+                        "description": "Password and TOTP code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.deleteUserPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/notes": {
+            "get": {
+                "description": "Retrieve all notes for the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notes"
+                ],
+                "summary": "Get all notes for the current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.SecretNote"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "No content"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Upload a new note for the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notes"
+                ],
+                "summary": "Add a new note",
+                "parameters": [
+                    {
+                        "description": "Note data",
+                        "name": "note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.SecretNote"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SecretNote"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/notes/{id}": {
+            "delete": {
+                "description": "Delete a specific note identified by its UUID",
+                "tags": [
+                    "notes"
+                ],
+                "summary": "Delete a note by UUID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Note UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Delete accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update a specific note identified by its UUID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notes"
+                ],
+                "summary": "Update a note by UUID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Note UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated note data",
+                        "name": "note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.SecretNote"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Update accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/password": {
+            "post": {
+                "description": "Change the password together with the data key wrapped by the new password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "password"
+                ],
+                "summary": "Change the master password",
+                "parameters": [
+                    {
+                        "description": "Old and new passwords with the re-wrapped data key",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.PasswordChange"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/recovery": {
+            "put": {
+                "description": "Store the data key wrapped by a new recovery key, the previous recovery key stops working",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Set a new recovery key",
                 "parameters": [
                     {
-                        "description": "Note data",
-                        "name": "note",
+                        "description": "Password, recovery key secret and wrapped data key",
+                        "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.SecretNote"
+                            "$ref": "#/definitions/entity.RecoveryKey"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/rekey": {
+            "post": {
+                "description": "Store the whole vault re-encrypted with a new data key until the key rotation commits it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "password"
+                ],
+                "summary": "Stage the re-encrypted vault",
+                "parameters": [
+                    {
+                        "description": "Re-encrypted vault",
+                        "name": "rekey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.Rekey"
                         }
                     }
                 ],
@@ -823,7 +1783,7 @@ const docTemplate = `{
                     "202": {
                         "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/entity.SecretNote"
+                            "$ref": "#/definitions/v1.response"
                         }
                     },
                     "400": {
@@ -841,27 +1801,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/notes/{id}": {
-            "delete": {
-                "description": "Delete a specific note identified by its UUID",
+        "/user/rekey/binary/{id}": {
+            "post": {
+                "description": "Store the binary file re-encrypted with a new data key until the key rotation commits it",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "notes"
+                    "password"
                 ],
-                "summary": "Delete a note by UUID",
+                "summary": "Stage a re-encrypted binary file",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Note UUID",
+                        "description": "Binary UUID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Re-encrypted binary file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "202": {
-                        "description": "Delete accepted",
+                        "description": "Accepted",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/v1.response"
                         }
                     },
                     "400": {
@@ -877,9 +1850,38 @@ const docTemplate = `{
                         }
                     }
                 }
+            }
+        },
+        "/user/tokens": {
+            "get": {
+                "description": "Retrieve the unexpired personal access tokens of the current user, without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get personal access tokens of the current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.PersonalToken"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
             },
-            "patch": {
-                "description": "Update a specific note identified by its UUID",
+            "post": {
+                "description": "Create a long-lived token for automation, limited to its scope: read only access,\nitem types and item IDs. The secret of the token is only returned once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -887,32 +1889,147 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "notes"
+                    "tokens"
                 ],
-                "summary": "Update a note by UUID",
+                "summary": "Create a personal access token",
+                "parameters": [
+                    {
+                        "description": "Name, expiry and scope of the token",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.personalTokenPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.PersonalToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/tokens/{id}": {
+            "delete": {
+                "description": "Delete a personal access token of the current user, it stops working at once",
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Revoke a personal access token by UUID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Note UUID",
+                        "description": "Token UUID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Delete accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/totp": {
+            "post": {
+                "description": "Generate the secret and the provisioning URI of a TOTP second factor, it is required at sign in once confirmed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Enroll a TOTP second factor",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.TOTPEnrollment"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/totp/confirm": {
+            "post": {
+                "description": "Turn the enrolled second factor on with a code of it and generate one-time recovery codes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Confirm the enrolled TOTP second factor",
+                "parameters": [
                     {
-                        "description": "Updated note data",
-                        "name": "note",
+                        "description": "TOTP code",
+                        "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.SecretNote"
+                            "$ref": "#/definitions/v1.totpCodePayload"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Update accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.RecoveryCodes"
                         }
                     },
                     "400": {
@@ -921,6 +2038,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -971,23 +2094,110 @@ const docTemplate = `{
                     "description": "Expiration year.",
                     "type": "string"
                 },
-                "meta": {
-                    "description": "Associated metadata.",
+                "meta": {
+                    "description": "Associated metadata.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Meta"
+                    }
+                },
+                "name": {
+                    "description": "Card name.",
+                    "type": "string"
+                },
+                "number": {
+                    "description": "Card number.",
+                    "type": "string"
+                },
+                "security_code": {
+                    "description": "Security code (CVV).",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Changes": {
+            "type": "object",
+            "properties": {
+                "binaries": {
+                    "description": "Created or updated binaries.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Binary"
+                    }
+                },
+                "cards": {
+                    "description": "Created or updated cards.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Card"
+                    }
+                },
+                "cursor": {
+                    "description": "Revision of the latest change, the cursor of the next request.",
+                    "type": "integer"
+                },
+                "deleted": {
+                    "description": "Deleted items.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.DeletedItem"
+                    }
+                },
+                "full": {
+                    "description": "The items are the whole vault, the local copy is replaced with them.",
+                    "type": "boolean"
+                },
+                "logins": {
+                    "description": "Created or updated logins.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Login"
+                    }
+                },
+                "notes": {
+                    "description": "Created or updated notes.",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/entity.Meta"
+                        "$ref": "#/definitions/entity.SecretNote"
                     }
+                }
+            }
+        },
+        "entity.DataKey": {
+            "type": "object",
+            "properties": {
+                "data_key": {
+                    "description": "Wrapped data key, opaque to the server.",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.DataKeyRotation": {
+            "type": "object",
+            "properties": {
+                "data_key": {
+                    "description": "New data key wrapped by the password.",
+                    "type": "string"
                 },
-                "name": {
-                    "description": "Card name.",
+                "password": {
+                    "description": "Auth hash of the current password.",
                     "type": "string"
+                }
+            }
+        },
+        "entity.DeletedItem": {
+            "type": "object",
+            "properties": {
+                "revision": {
+                    "description": "Revision of the deletion.",
+                    "type": "integer"
                 },
-                "number": {
-                    "description": "Card number.",
+                "type": {
+                    "description": "Item type, see ItemTypes.",
                     "type": "string"
                 },
-                "security_code": {
-                    "description": "Security code (CVV).",
+                "uuid": {
+                    "description": "Unique identifier of the item.",
                     "type": "string"
                 }
             }
@@ -1002,6 +2212,10 @@ const docTemplate = `{
                 "refresh_token": {
                     "description": "Refresh token for obtaining a new access token.",
                     "type": "string"
+                },
+                "totp_challenge": {
+                    "description": "TOTPChallenge is set instead of the tokens when the user has to confirm the sign in with a TOTP code.",
+                    "type": "string"
                 }
             }
         },
@@ -1046,6 +2260,141 @@ const docTemplate = `{
                 }
             }
         },
+        "entity.PasswordChange": {
+            "type": "object",
+            "properties": {
+                "data_key": {
+                    "description": "Data key wrapped by the new password.",
+                    "type": "string"
+                },
+                "new_password": {
+                    "description": "Auth hash of the new password.",
+                    "type": "string"
+                },
+                "old_password": {
+                    "description": "Auth hash of the current password.",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.PersonalToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Time the token was created.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Time the token stops working.",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "Time the token was last used, nil if never.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name given by the user.",
+                    "type": "string"
+                },
+                "scope": {
+                    "description": "What the token grants access to.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.TokenScope"
+                        }
+                    ]
+                },
+                "token": {
+                    "description": "Secret of the token, only returned once on creation.",
+                    "type": "string"
+                },
+                "uuid": {
+                    "description": "Unique identifier for the token.",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Recovery": {
+            "type": "object",
+            "properties": {
+                "data_key": {
+                    "description": "Data key wrapped by the new password.",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Auth hash of the new password.",
+                    "type": "string"
+                },
+                "recovery_auth": {
+                    "description": "Secret derived from the recovery key.",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.RecoveryCodes": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "entity.RecoveryKey": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "Auth hash of the password.",
+                    "type": "string"
+                },
+                "recovery_auth": {
+                    "description": "Secret derived from the recovery key.",
+                    "type": "string"
+                },
+                "recovery_key": {
+                    "description": "Data key wrapped by the recovery key, opaque to the server.",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Rekey": {
+            "type": "object",
+            "properties": {
+                "binaries": {
+                    "description": "Binaries with re-encrypted metadata, the files are staged one by one.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Binary"
+                    }
+                },
+                "cards": {
+                    "description": "Re-encrypted cards.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Card"
+                    }
+                },
+                "logins": {
+                    "description": "Re-encrypted logins.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Login"
+                    }
+                },
+                "notes": {
+                    "description": "Re-encrypted notes.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.SecretNote"
+                    }
+                }
+            }
+        },
         "entity.SecretNote": {
             "type": "object",
             "properties": {
@@ -1066,6 +2415,83 @@ const docTemplate = `{
                 }
             }
         },
+        "entity.Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Time of the sign in, the device is first seen.",
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Whether the session is the one of the request.",
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "description": "Time the current refresh token expires.",
+                    "type": "string"
+                },
+                "last_ip": {
+                    "description": "IP address the device was last seen from.",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "Time the device was last seen.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name of the device, the host name unless renamed by the user.",
+                    "type": "string"
+                },
+                "platform": {
+                    "description": "Operating system and architecture of the client.",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "Time the session was revoked, nil while it is active.",
+                    "type": "string"
+                },
+                "uuid": {
+                    "description": "Unique identifier for the session.",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.TOTPEnrollment": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "description": "Secret in base32, to be entered in an authenticator app.",
+                    "type": "string"
+                },
+                "uri": {
+                    "description": "Provisioning otpauth URI of the secret.",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.TokenScope": {
+            "type": "object",
+            "properties": {
+                "item_ids": {
+                    "description": "Items the token may access; no items can be added.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "item_types": {
+                    "description": "Item types the token may access, see ItemTypes.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "read_only": {
+                    "description": "Only items may be read, nothing is changed.",
+                    "type": "boolean"
+                }
+            }
+        },
         "entity.User": {
             "type": "object",
             "properties": {
@@ -1073,13 +2499,40 @@ const docTemplate = `{
                     "description": "Email address of the user.",
                     "type": "string"
                 },
+                "totp_enabled": {
+                    "description": "Whether sign in requires a TOTP code.",
+                    "type": "boolean"
+                },
                 "uuid": {
                     "description": "Unique identifier for the user.",
                     "type": "string"
                 }
             }
         },
-        "v1.loginPayload": {
+        "v1.deleteUserPayload": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "TOTP code, required if two-factor authentication is enabled.",
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.devicePayload": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.disableTOTPPayload": {
             "type": "object",
             "properties": {
                 "email": {
@@ -1087,6 +2540,31 @@ const docTemplate = `{
                 },
                 "password": {
                     "type": "string"
+                },
+                "recovery_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.personalTokenPayload": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scope": {
+                    "$ref": "#/definitions/entity.TokenScope"
+                }
+            }
+        },
+        "v1.renameDevicePayload": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
                 }
             }
         },
@@ -1102,6 +2580,82 @@ const docTemplate = `{
                     "example": "message"
                 }
             }
+        },
+        "v1.signInPayload": {
+            "type": "object",
+            "properties": {
+                "device": {
+                    "$ref": "#/definitions/v1.devicePayload"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.signUpPayload": {
+            "type": "object",
+            "properties": {
+                "data_key": {
+                    "description": "Data key wrapped by the master password.",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "recovery_auth": {
+                    "description": "Secret derived from the recovery key.",
+                    "type": "string"
+                },
+                "recovery_key": {
+                    "description": "Data key wrapped by the recovery key.",
+                    "type": "string"
+                }
+            }
+        },
+        "v1.totpCodePayload": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.totpSignInPayload": {
+            "type": "object",
+            "properties": {
+                "challenge": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.upgradePayload": {
+            "type": "object",
+            "properties": {
+                "auth_hash": {
+                    "type": "string"
+                },
+                "device": {
+                    "$ref": "#/definitions/v1.devicePayload"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
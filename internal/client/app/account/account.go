@@ -0,0 +1,24 @@
+package account
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+)
+
+var App = config.Load().App.Name
+var Account = &cobra.Command{
+	Use:   "account",
+	Short: "Manage the account",
+	Long:  `Manage the account of the logged-in user on the server.`,
+	Example: fmt.Sprintf(`
+# Delete the account with all of its data
+%s account delete
+	`, App),
+}
+
+func init() {
+	Account.AddCommand(Delete)
+}
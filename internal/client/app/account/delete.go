@@ -0,0 +1,48 @@
+package account
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+// confirmation is the answer confirming the deletion.
+const confirmation = "delete"
+
+var Delete = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the account with all of its data",
+	Long: fmt.Sprintf(`
+This command deletes the account of the logged-in user for good: all logins,
+cards, notes and files, the devices and the personal access tokens are deleted
+on the server and the local storage is wiped. It asks for the master password,
+the two-factor code if it is enabled, and a confirmation
+Usage: %s account delete`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// One reader for all prompts, so piped input is not lost between them.
+		in := bufio.NewReader(os.Stdin)
+
+		err := utils.Confirm(in, os.Stderr,
+			fmt.Sprintf("The account and all of its data will be deleted, type %q to confirm: ", confirmation), confirmation)
+		if err != nil {
+			color.Yellow("The account has not been deleted: %v", err)
+			return
+		}
+		userPassword, err := utils.PromptPassword(in, os.Stderr, "Master password: ")
+		if err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+
+		usecase.GetClientUseCase().DeleteAccount(userPassword, func() (string, error) {
+			return utils.PromptPassword(in, os.Stderr, "Two-factor code: ")
+		})
+	},
+}
@@ -12,7 +12,8 @@ var App = config.Load().App.Name
 var Add = &cobra.Command{
 	Use:   "add",
 	Short: "Add resources",
-	Long:  `Add different types of resources like login, card, note or binary.`,
+	Long: `Add different types of resources like login, card, note or binary.
+Resources are added locally and uploaded to the server by the next sync, so no connection is needed.`,
 	Example: fmt.Sprintf(`
 # Add a login
 %s add login -t "Login Title" -l "user@example.com" -s "password" -u "https://example.com" --meta '[{"name":"meta","value":"value"}]'
@@ -20,12 +20,11 @@ Example:
   %s add binary -t "name" -f "file_location" --meta '[{"name":"meta","value":"value"}]'`, App),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
-		usecase.GetClientUseCase().AddBinary(userPassword, &binaryForAdditing)
+		usecase.GetClientUseCase().AddBinary(&binaryForAdditing)
 	},
 }
 
@@ -22,12 +22,11 @@ Example:
   --meta '[{"name":"meta","value":"value"}]'`, App),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
-		usecase.GetClientUseCase().AddLogin(userPassword, &loginForAdditing)
+		usecase.GetClientUseCase().AddLogin(&loginForAdditing)
 	},
 }
 
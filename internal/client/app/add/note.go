@@ -20,12 +20,11 @@ Example:
  %s add note -t name -n content --meta '[{"name":"meta","value":"value"}]'`, App),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
-		usecase.GetClientUseCase().AddNote(userPassword, &noteForAdditing)
+		usecase.GetClientUseCase().AddNote(&noteForAdditing)
 	},
 }
 
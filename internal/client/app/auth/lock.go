@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+)
+
+var LockVault = &cobra.Command{
+	Use:   "lock",
+	Short: "Lock user`s vault",
+	Long: `
+This command drops the vault key kept by unlock`,
+	Run: func(cmd *cobra.Command, args []string) {
+		usecase.GetClientUseCase().Lock()
+	},
+}
@@ -2,12 +2,14 @@ package auth
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	config "github.com/nextlag/keeper/config/client"
 	"github.com/nextlag/keeper/internal/client/usecase"
 	"github.com/nextlag/keeper/internal/entity"
+	utils "github.com/nextlag/keeper/internal/utils/client"
 )
 
 var RequiredUserArgs = 2
@@ -16,6 +18,7 @@ var LoginUser = &cobra.Command{
 	Use:   "login",
 	Short: "Login user to the service",
 	Long: fmt.Sprintf(`This is the user login command.
+An account with two-factor authentication is asked for the code of the authenticator app.
 Usage: %s login user_email user_password`, config.Load().App.Name),
 	Args: cobra.MinimumNArgs(RequiredUserArgs),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -24,6 +27,11 @@ Usage: %s login user_email user_password`, config.Load().App.Name),
 			Password: args[1],
 		}
 		usecase.GetClientUseCase().Logout()
-		usecase.GetClientUseCase().Login(&account)
+		usecase.GetClientUseCase().Login(&account, PromptTOTPCode)
 	},
 }
+
+// PromptTOTPCode asks for the code of the authenticator app.
+func PromptTOTPCode() (string, error) {
+	return utils.PromptPassword(os.Stdin, os.Stderr, "Two-factor code: ")
+}
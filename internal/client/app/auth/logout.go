@@ -10,7 +10,8 @@ var LogoutUser = &cobra.Command{
 	Use:   "logout",
 	Short: "Logout user",
 	Long: `
-This command drops users tokens`,
+This command revokes the session on the server and drops users tokens.
+A locked vault asks for the master password.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		usecase.GetClientUseCase().Logout()
 	},
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+	"github.com/nextlag/keeper/internal/client/usecase"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var errPasswordMismatch = errors.New("passwords do not match")
+
+var ChangePassword = &cobra.Command{
+	Use:   "passwd",
+	Short: "Change the master password",
+	Long: fmt.Sprintf(`This command changes the master password. The vault is encrypted with a data key,
+so only the data key is re-wrapped with the new password; the server switches the key
+and the password at once, so an interrupted run leaves everything under the old password.
+Usage: %s passwd`, config.Load().App.Name),
+	Run: func(cmd *cobra.Command, args []string) {
+		// One reader for all prompts, so piped input is not lost between them.
+		in := bufio.NewReader(os.Stdin)
+
+		oldPassword, err := utils.PromptPassword(in, os.Stderr, "Current master password: ")
+		if err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		newPassword, err := utils.PromptPassword(in, os.Stderr, "New master password: ")
+		if err != nil {
+			color.Red("New password required. Error: %v", err)
+			return
+		}
+		repeatedPassword, err := utils.PromptPassword(in, os.Stderr, "Repeat new master password: ")
+		if err != nil {
+			color.Red("New password required. Error: %v", err)
+			return
+		}
+		if newPassword != repeatedPassword {
+			color.Red("New password required. Error: %v", errPasswordMismatch)
+			return
+		}
+
+		usecase.GetClientUseCase().ChangePassword(oldPassword, newPassword)
+	},
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+	"github.com/nextlag/keeper/internal/client/usecase"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var Recover = &cobra.Command{
+	Use:   "recover",
+	Short: "Set a new master password with the recovery key",
+	Long: fmt.Sprintf(`This command sets a new master password when the current one is forgotten.
+It asks for the recovery key shown when the vault got its data key and for the new password;
+the data key is re-wrapped with the new password and the user is signed out on every device.
+Usage: %s recover user_email`, config.Load().App.Name),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// One reader for all prompts, so piped input is not lost between them.
+		in := bufio.NewReader(os.Stdin)
+
+		recoveryKey, err := utils.PromptPassword(in, os.Stderr, "Recovery key: ")
+		if err != nil {
+			color.Red("Recovery key required. Error: %v", err)
+			return
+		}
+		newPassword, err := utils.PromptPassword(in, os.Stderr, "New master password: ")
+		if err != nil {
+			color.Red("New password required. Error: %v", err)
+			return
+		}
+		repeatedPassword, err := utils.PromptPassword(in, os.Stderr, "Repeat new master password: ")
+		if err != nil {
+			color.Red("New password required. Error: %v", err)
+			return
+		}
+		if newPassword != repeatedPassword {
+			color.Red("New password required. Error: %v", errPasswordMismatch)
+			return
+		}
+
+		usecase.GetClientUseCase().Recover(args[0], recoveryKey, newPassword)
+	},
+}
+
+var RegenerateRecoveryKey = &cobra.Command{
+	Use:   "recovery-key",
+	Short: "Generate a new recovery key",
+	Long: fmt.Sprintf(`This command generates a new recovery key and shows it once,
+the previous recovery key stops working.
+Usage: %s recovery-key`, config.Load().App.Name),
+	Run: func(cmd *cobra.Command, args []string) {
+		userPassword, err := utils.PromptPassword(bufio.NewReader(os.Stdin), os.Stderr, "Master password: ")
+		if err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+
+		usecase.GetClientUseCase().RegenerateRecoveryKey(userPassword)
+	},
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+	"github.com/nextlag/keeper/internal/client/usecase"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var UnlockVault = &cobra.Command{
+	Use:   "unlock",
+	Short: "Unlock user`s vault",
+	Long: fmt.Sprintf(`This command asks for the master password and keeps the vault key
+until the session stays idle longer than the configured timeout.
+Usage: %s unlock`, config.Load().App.Name),
+	Run: func(cmd *cobra.Command, args []string) {
+		userPassword, err := utils.PromptPassword(os.Stdin, os.Stderr, "Master password: ")
+		if err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		usecase.GetClientUseCase().Unlock(userPassword)
+	},
+}
@@ -17,12 +17,11 @@ This command remove file
 Usage: %s del binary -i binary_id`, App),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
-		usecase.GetClientUseCase().DelBinary(userPassword, delBinaryID)
+		usecase.GetClientUseCase().DelBinary(delBinaryID)
 	},
 }
 
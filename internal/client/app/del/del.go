@@ -12,7 +12,8 @@ var App = config.Load().App.Name
 var Del = &cobra.Command{
 	Use:   "del",
 	Short: "Del resources",
-	Long:  `Del different types of resources like login, card, note or binary.`,
+	Long: `Del different types of resources like login, card, note or binary.
+Resources are deleted locally and the deletion is uploaded to the server by the next sync.`,
 	Example: fmt.Sprintf(`
 # Get a card
 %s del card -i card_id
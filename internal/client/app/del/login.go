@@ -17,12 +17,11 @@ This command remove login
 Usage: %s del login -i <login_id>`, App),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
-		usecase.GetClientUseCase().DelLogin(userPassword, delLoginID)
+		usecase.GetClientUseCase().DelLogin(delLoginID)
 	},
 }
 
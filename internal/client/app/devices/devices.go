@@ -0,0 +1,28 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+)
+
+var App = config.Load().App.Name
+var Devices = &cobra.Command{
+	Use:   "devices",
+	Short: "Manage devices",
+	Long:  `List the devices the user is signed in on and revoke them.`,
+	Example: fmt.Sprintf(`
+# List devices
+%s devices list
+
+# Revoke a device
+%s devices revoke -i device_id
+	`, App, App),
+}
+
+func init() {
+	Devices.AddCommand(List)
+	Devices.AddCommand(Revoke)
+}
@@ -0,0 +1,27 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+)
+
+var List = &cobra.Command{
+	Use:   "list",
+	Short: "List user devices",
+	Long: fmt.Sprintf(`
+This command shows the devices the user is signed in on
+with their platform, first and last seen time and last IP address
+Usage: %s devices list`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		usecase.GetClientUseCase().ListDevices()
+	},
+}
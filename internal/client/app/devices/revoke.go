@@ -0,0 +1,37 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+)
+
+var Revoke = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke user device by id",
+	Long: fmt.Sprintf(`
+This command signs the user out on the device,
+its tokens stop working at once
+Usage: %s devices revoke -i <device_id>`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		usecase.GetClientUseCase().RevokeDevice(revokeDeviceID)
+	},
+}
+
+var revokeDeviceID string
+
+func init() {
+	Revoke.Flags().StringVarP(&revokeDeviceID, "id", "i", "", "Device id")
+	if err := Revoke.MarkFlagRequired("id"); err != nil {
+		color.Red("%v", err)
+		return
+	}
+}
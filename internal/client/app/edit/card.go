@@ -0,0 +1,76 @@
+package edit
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+	"github.com/nextlag/keeper/internal/entity"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var (
+	editCardID     string
+	cardForEditing entity.Card
+)
+
+var Card = &cobra.Command{
+	Use:   "card",
+	Short: "Edit card",
+	Long: fmt.Sprintf(`This command changes the given fields of a card.
+Example:
+  %s edit card -i card_id -m "12" -y "2030" -c "456"`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		flags := cmd.Flags()
+		usecase.GetClientUseCase().EditCard(editCardID, func(card *entity.Card) {
+			if flags.Changed("title") {
+				card.Name = cardForEditing.Name
+			}
+			if flags.Changed("number") {
+				card.Number = cardForEditing.Number
+			}
+			if flags.Changed("owner") {
+				card.CardHolderName = cardForEditing.CardHolderName
+			}
+			if flags.Changed("brand") {
+				card.Brand = cardForEditing.Brand
+			}
+			if flags.Changed("code") {
+				card.SecurityCode = cardForEditing.SecurityCode
+			}
+			if flags.Changed("month") {
+				card.ExpirationMonth = cardForEditing.ExpirationMonth
+			}
+			if flags.Changed("year") {
+				card.ExpirationYear = cardForEditing.ExpirationYear
+			}
+			if flags.Changed("meta") {
+				card.Meta = cardForEditing.Meta
+			}
+		})
+	},
+}
+
+func init() {
+	Card.Flags().StringVarP(&editCardID, "id", "i", "", "Card id")
+	Card.Flags().StringVarP(&cardForEditing.Name, "title", "t", "", "Card title")
+	Card.Flags().StringVarP(&cardForEditing.Number, "number", "n", "", "Card number")
+	Card.Flags().StringVarP(&cardForEditing.CardHolderName, "owner", "o", "", "Cardholder name")
+	Card.Flags().StringVarP(&cardForEditing.Brand, "brand", "b", "", "Card brand")
+	Card.Flags().StringVarP(&cardForEditing.SecurityCode, "code", "c", "", "CVV/CVC")
+	Card.Flags().StringVarP(&cardForEditing.ExpirationMonth, "month", "m", "", "Card expiration month")
+	Card.Flags().StringVarP(&cardForEditing.ExpirationYear, "year", "y", "", "Card expiration year")
+	Card.Flags().Var(&utils.JSONFlag{Target: &cardForEditing.Meta}, "meta", `Replace meta fields of entity`)
+
+	if err := Card.MarkFlagRequired("id"); err != nil {
+		color.Red("%v", err)
+		return
+	}
+}
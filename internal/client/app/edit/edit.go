@@ -0,0 +1,34 @@
+package edit
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+)
+
+var App = config.Load().App.Name
+var Edit = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit resources",
+	Long: `Edit logins, cards and notes. Only the given fields are changed.
+The change is uploaded to the server by the next sync. An item changed on another device
+meanwhile is kept as a conflict, see the conflicts command.`,
+	Example: fmt.Sprintf(`
+# Change the password of a login
+%s edit login -i login_id -s "new password"
+
+# Change the expiration date of a card
+%s edit card -i card_id -m "12" -y "2030"
+
+# Replace the content and the meta fields of a note
+%s edit note -i note_id -n "Content" --meta '[{"name":"meta","value":"value"}]'
+	`, App, App, App),
+}
+
+func init() {
+	Edit.AddCommand(Login)
+	Edit.AddCommand(Card)
+	Edit.AddCommand(Note)
+}
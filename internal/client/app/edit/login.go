@@ -0,0 +1,64 @@
+package edit
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+	"github.com/nextlag/keeper/internal/entity"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var (
+	editLoginID     string
+	loginForEditing entity.Login
+)
+
+var Login = &cobra.Command{
+	Use:   "login",
+	Short: "Edit login",
+	Long: fmt.Sprintf(`This command changes the given fields of a login.
+Example:
+  %s edit login -i login_id -s "new password" -u "https://example.com"`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		flags := cmd.Flags()
+		usecase.GetClientUseCase().EditLogin(editLoginID, func(login *entity.Login) {
+			if flags.Changed("title") {
+				login.Name = loginForEditing.Name
+			}
+			if flags.Changed("login") {
+				login.Login = loginForEditing.Login
+			}
+			if flags.Changed("secret") {
+				login.Password = loginForEditing.Password
+			}
+			if flags.Changed("uri") {
+				login.URI = loginForEditing.URI
+			}
+			if flags.Changed("meta") {
+				login.Meta = loginForEditing.Meta
+			}
+		})
+	},
+}
+
+func init() {
+	Login.Flags().StringVarP(&editLoginID, "id", "i", "", "Login id")
+	Login.Flags().StringVarP(&loginForEditing.Name, "title", "t", "", "Login title")
+	Login.Flags().StringVarP(&loginForEditing.Login, "login", "l", "", "Site login")
+	Login.Flags().StringVarP(&loginForEditing.Password, "secret", "s", "", "Site password|secret")
+	Login.Flags().StringVarP(&loginForEditing.URI, "uri", "u", "", "Site endpoint")
+	Login.Flags().Var(&utils.JSONFlag{Target: &loginForEditing.Meta}, "meta", `Replace meta fields of entity`)
+
+	if err := Login.MarkFlagRequired("id"); err != nil {
+		color.Red("%v", err)
+		return
+	}
+}
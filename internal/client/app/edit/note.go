@@ -0,0 +1,56 @@
+package edit
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+	"github.com/nextlag/keeper/internal/entity"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var (
+	editNoteID     string
+	noteForEditing entity.SecretNote
+)
+
+var Note = &cobra.Command{
+	Use:   "note",
+	Short: "Edit note",
+	Long: fmt.Sprintf(`This command changes the given fields of a note.
+Example:
+  %s edit note -i note_id -n "Content"`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		flags := cmd.Flags()
+		usecase.GetClientUseCase().EditNote(editNoteID, func(note *entity.SecretNote) {
+			if flags.Changed("title") {
+				note.Name = noteForEditing.Name
+			}
+			if flags.Changed("note") {
+				note.Note = noteForEditing.Note
+			}
+			if flags.Changed("meta") {
+				note.Meta = noteForEditing.Meta
+			}
+		})
+	},
+}
+
+func init() {
+	Note.Flags().StringVarP(&editNoteID, "id", "i", "", "Note id")
+	Note.Flags().StringVarP(&noteForEditing.Name, "title", "t", "", "Note title")
+	Note.Flags().StringVarP(&noteForEditing.Note, "note", "n", "", "User note")
+	Note.Flags().Var(&utils.JSONFlag{Target: &noteForEditing.Meta}, "meta", `Replace meta fields of entity`)
+
+	if err := Note.MarkFlagRequired("id"); err != nil {
+		color.Red("%v", err)
+		return
+	}
+}
@@ -26,13 +26,12 @@ Usage: %s get binary -i binary_id -f some_file`, App),
 			os.Exit(1)
 		}
 
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
 
-		usecase.GetClientUseCase().GetBinary(userPassword, getBinaryID, filePath)
+		usecase.GetClientUseCase().GetBinary(getBinaryID, filePath)
 	},
 }
 
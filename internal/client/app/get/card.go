@@ -17,12 +17,11 @@ This command add card
 Usage: %s get card -i card_id`, App),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
-		usecase.GetClientUseCase().ShowCard(userPassword, getCardID)
+		usecase.GetClientUseCase().ShowCard(getCardID)
 	},
 }
 
@@ -17,12 +17,11 @@ This command login
 Usage: %s get login -i <login_id>`, App),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
-		usecase.GetClientUseCase().ShowLogin(userPassword, getLoginID)
+		usecase.GetClientUseCase().ShowLogin(getLoginID)
 	},
 }
 
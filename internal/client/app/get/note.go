@@ -16,12 +16,11 @@ var Note = &cobra.Command{
 This command show user note
 Usage: %s get note -i <note_id>`, App),
 	Run: func(cmd *cobra.Command, args []string) {
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
-		usecase.GetClientUseCase().ShowNote(userPassword, getNoteID)
+		usecase.GetClientUseCase().ShowNote(getNoteID)
 	},
 }
 
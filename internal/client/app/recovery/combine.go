@@ -0,0 +1,51 @@
+package recovery
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var errPasswordMismatch = errors.New("passwords do not match")
+
+var Combine = &cobra.Command{
+	Use:   "combine",
+	Short: "Set a new master password with recovery key shares",
+	Long: fmt.Sprintf(`
+This command combines the recovery key from its shares and sets a new master
+password with it, like recover does. It asks for the shares one by one until
+the threshold is reached, words may be shortened to their first four letters
+Usage: %s recovery combine user_email`, App),
+	Args: cobra.ExactArgs(1),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// One reader for all prompts, so piped input is not lost between them.
+		in := bufio.NewReader(os.Stdin)
+
+		promptShare := func(number int) (string, error) {
+			return utils.PromptPassword(in, os.Stderr, fmt.Sprintf("Share %d: ", number))
+		}
+		promptPassword := func() (string, error) {
+			newPassword, err := utils.PromptPassword(in, os.Stderr, "New master password: ")
+			if err != nil {
+				return "", err
+			}
+			repeatedPassword, err := utils.PromptPassword(in, os.Stderr, "Repeat new master password: ")
+			if err != nil {
+				return "", err
+			}
+			if newPassword != repeatedPassword {
+				return "", errPasswordMismatch
+			}
+			return newPassword, nil
+		}
+
+		usecase.GetClientUseCase().CombineRecoveryKey(args[0], promptShare, promptPassword)
+	},
+}
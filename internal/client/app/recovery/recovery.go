@@ -0,0 +1,29 @@
+package recovery
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+)
+
+var App = config.Load().App.Name
+var Recovery = &cobra.Command{
+	Use:   "recovery",
+	Short: "Split the recovery key into shares and combine them",
+	Long: `Split a new recovery key into shares to print or hand to trusted people,
+any threshold number of them set a new master password.`,
+	Example: fmt.Sprintf(`
+# Split a new recovery key into 5 shares, any 3 of them recover the vault
+%s recovery split --shares 5 --threshold 3
+
+# Set a new master password with the shares
+%s recovery combine user_email
+	`, App, App),
+}
+
+func init() {
+	Recovery.AddCommand(Split)
+	Recovery.AddCommand(Combine)
+}
@@ -0,0 +1,44 @@
+package recovery
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var Split = &cobra.Command{
+	Use:   "split",
+	Short: "Split a new recovery key into shares",
+	Long: fmt.Sprintf(`
+This command generates a new recovery key and splits it into shares with
+Shamir's secret sharing: any threshold number of shares recover it, fewer
+tell nothing about it. The shares are word lists with a checksum, shown once;
+the previous recovery key stops working
+Usage: %s recovery split --shares <n> --threshold <k>`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		userPassword, err := utils.PromptPassword(bufio.NewReader(os.Stdin), os.Stderr, "Master password: ")
+		if err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+
+		usecase.GetClientUseCase().SplitRecoveryKey(userPassword, splitShares, splitThreshold)
+	},
+}
+
+var (
+	splitShares    int
+	splitThreshold int
+)
+
+func init() {
+	Split.Flags().IntVarP(&splitShares, "shares", "n", 5, "Number of shares")
+	Split.Flags().IntVarP(&splitThreshold, "threshold", "k", 3, "Number of shares recovering the key")
+}
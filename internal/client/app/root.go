@@ -8,16 +8,23 @@ import (
 	"github.com/spf13/cobra"
 
 	config "github.com/nextlag/keeper/config/client"
+	"github.com/nextlag/keeper/internal/client/app/account"
 	"github.com/nextlag/keeper/internal/client/app/add"
 	"github.com/nextlag/keeper/internal/client/app/auth"
 	"github.com/nextlag/keeper/internal/client/app/build"
 	"github.com/nextlag/keeper/internal/client/app/del"
+	"github.com/nextlag/keeper/internal/client/app/devices"
+	"github.com/nextlag/keeper/internal/client/app/edit"
 	"github.com/nextlag/keeper/internal/client/app/get"
+	"github.com/nextlag/keeper/internal/client/app/recovery"
 	"github.com/nextlag/keeper/internal/client/app/storage"
+	"github.com/nextlag/keeper/internal/client/app/tokens"
+	"github.com/nextlag/keeper/internal/client/app/totp"
 	"github.com/nextlag/keeper/internal/client/app/vault"
 	"github.com/nextlag/keeper/internal/client/usecase"
 	"github.com/nextlag/keeper/internal/client/usecase/api"
 	"github.com/nextlag/keeper/internal/client/usecase/repo"
+	"github.com/nextlag/keeper/internal/client/usecase/session"
 )
 
 var (
@@ -48,10 +55,20 @@ func init() {
 	commands := []*cobra.Command{
 		storage.InitLocalStorage, // Command to initialize local storage.
 		storage.SyncUserData,     // Command to sync user data with the server.
+		storage.Conflicts,        // Command to list and resolve sync conflicts.
+		storage.ReencryptVault,   // Command to re-encrypt user data.
+		storage.RotateDataKey,    // Command to rotate the vault data key.
 
-		auth.LoginUser,    // Command to log in a user.
-		auth.RegisterUser, // Command to register a new user.
-		auth.LogoutUser,   // Command to log out a user.
+		auth.LoginUser,      // Command to log in a user.
+		auth.RegisterUser,   // Command to register a new user.
+		auth.LogoutUser,     // Command to log out a user.
+		auth.UnlockVault,    // Command to unlock the vault.
+		auth.LockVault,      // Command to lock the vault.
+		auth.ChangePassword, // Command to change the master password.
+
+		auth.Recover,               // Command to set a new master password with the recovery key.
+		auth.RegenerateRecoveryKey, // Command to generate a new recovery key.
+		recovery.Recovery,          // Command to split the recovery key into shares and combine them.
 
 		add.Add,    // Command to add new entities.
 		add.Login,  // Command to add a new login.
@@ -71,7 +88,14 @@ func init() {
 		del.Note,   // Command to delete a note.
 		del.Binary, // Command to delete a binary file.
 
+		edit.Edit, // Command to edit entities.
+
 		vault.ShowVault, // Command to display the vault.
+
+		devices.Devices, // Command to manage the devices of the user.
+		totp.TwoFactor,  // Command to manage two-factor authentication.
+		tokens.Tokens,   // Command to manage personal access tokens.
+		account.Account, // Command to manage the account.
 	}
 
 	rootCmd.AddCommand(commands...)
@@ -86,6 +110,7 @@ func initApp() {
 		usecase.SetAPI(api.New(cfg.Server.ServerURL)),
 		usecase.SetConfig(cfg),
 		usecase.SetRepo(repo.New(cfg.SQLite.DSN)),
+		usecase.SetSession(session.New(cfg.Session.Path, cfg.Session.IdleTimeout)),
 	}
 
 	for _, opt := range clientOpts {
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+	"github.com/nextlag/keeper/internal/client/usecase"
+)
+
+var (
+	conflictItemID string
+	conflictKeep   string
+)
+
+var Conflicts = &cobra.Command{
+	Use:   "conflicts",
+	Short: "List and resolve sync conflicts",
+	Long: fmt.Sprintf(`This command lists the local changes the server has rejected because the items
+had been changed on another device, with the fields changed on each side.
+Conflicts of changes to different fields are merged by sync automatically.
+With --keep the conflict of the item, or every conflict without -i, is resolved:
+local replaces the remote version, remote drops the local one, both adds the local version as a copy.
+Usage: %s conflicts [-i item_id] [--keep local|remote|both]`, config.Load().App.Name),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		usecase.GetClientUseCase().Conflicts(conflictItemID, conflictKeep)
+	},
+}
+
+func init() {
+	Conflicts.Flags().StringVarP(&conflictItemID, "id", "i", "", "Item id")
+	Conflicts.Flags().StringVar(&conflictKeep, "keep", "",
+		fmt.Sprintf("Version to keep: %s, %s or %s", usecase.KeepLocal, usecase.KeepRemote, usecase.KeepBoth))
+}
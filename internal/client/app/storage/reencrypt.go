@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+	"github.com/nextlag/keeper/internal/client/usecase"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var ReencryptVault = &cobra.Command{
+	Use:   "reencrypt",
+	Short: "Re-encrypt user`s vault",
+	Long: fmt.Sprintf(`This command re-encrypts all users private data with the current key derivation settings
+and encrypts items stored in plaintext, the master password is always requested
+Usage: %s reencrypt`, config.Load().App.Name),
+	Run: func(cmd *cobra.Command, args []string) {
+		userPassword, err := utils.PromptPassword(os.Stdin, os.Stderr, "Master password: ")
+		if err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		usecase.GetClientUseCase().ReencryptVault(userPassword)
+	},
+}
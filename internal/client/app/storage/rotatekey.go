@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+	"github.com/nextlag/keeper/internal/client/usecase"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var RotateDataKey = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Rotate the data key of user`s vault",
+	Long: fmt.Sprintf(`This command re-encrypts all users private data, including binary files,
+with a new random data key. The server switches the vault at once, so an interrupted run
+leaves everything under the old key. Other devices have to log in again afterwards
+Usage: %s rotate-key`, config.Load().App.Name),
+	Run: func(cmd *cobra.Command, args []string) {
+		userPassword, err := utils.PromptPassword(os.Stdin, os.Stderr, "Master password: ")
+		if err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		usecase.GetClientUseCase().RotateDataKey(userPassword)
+	},
+}
@@ -13,14 +13,16 @@ import (
 var SyncUserData = &cobra.Command{
 	Use:   "sync",
 	Short: "Sync user`s data",
-	Long: fmt.Sprintf(`This command update users private data from server 
+	Long: fmt.Sprintf(`This command update users private data from server.
+The local changes are uploaded first, in the order they were made; a change the server rejects is reported and dropped.
+If the server cannot be reached the changes left are kept for the next sync.
+Only the items changed since the previous sync are transferred, items deleted on other devices are removed locally.
 Usage: %s sync`, config.Load().App.Name),
 	Run: func(cmd *cobra.Command, args []string) {
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
-		usecase.GetClientUseCase().Sync(userPassword)
+		usecase.GetClientUseCase().Sync()
 	},
 }
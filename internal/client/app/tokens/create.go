@@ -0,0 +1,61 @@
+package tokens
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+var Create = &cobra.Command{
+	Use:   "create",
+	Short: "Create a personal access token",
+	Long: fmt.Sprintf(`
+This command creates a long-lived token for automation and prints it once.
+The token only reaches the items of the user: it can be limited to reading,
+to item types (logins, cards, notes, binary) and to item ids
+Usage: %s tokens create -n <name> [-d <days>] [--read-only] [-t <type>]... [-i <item_id>]...`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		scope := entity.TokenScope{ReadOnly: createReadOnly, ItemTypes: createItemTypes}
+		for _, itemID := range createItemIDs {
+			itemUUID, err := uuid.Parse(itemID)
+			if err != nil {
+				color.Red("Error parsing item ID %s: %v", itemID, err)
+				return
+			}
+			scope.ItemIDs = append(scope.ItemIDs, itemUUID)
+		}
+
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		usecase.GetClientUseCase().CreateToken(createName, time.Duration(createDays)*24*time.Hour, scope)
+	},
+}
+
+var (
+	createName      string
+	createDays      int
+	createReadOnly  bool
+	createItemTypes []string
+	createItemIDs   []string
+)
+
+func init() {
+	Create.Flags().StringVarP(&createName, "name", "n", "", "Token name")
+	Create.Flags().IntVarP(&createDays, "days", "d", 90, "Days the token is valid for")
+	Create.Flags().BoolVar(&createReadOnly, "read-only", false, "Only allow reading items")
+	Create.Flags().StringSliceVarP(&createItemTypes, "type", "t", nil, "Item type the token may access")
+	Create.Flags().StringSliceVarP(&createItemIDs, "id", "i", nil, "Item id the token may access")
+	if err := Create.MarkFlagRequired("name"); err != nil {
+		color.Red("%v", err)
+		return
+	}
+}
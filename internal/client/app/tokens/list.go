@@ -0,0 +1,27 @@
+package tokens
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+)
+
+var List = &cobra.Command{
+	Use:   "list",
+	Short: "List personal access tokens",
+	Long: fmt.Sprintf(`
+This command shows the personal access tokens of the user
+with their scope, expiry and last use
+Usage: %s tokens list`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		usecase.GetClientUseCase().ListTokens()
+	},
+}
@@ -0,0 +1,37 @@
+package tokens
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+)
+
+var Revoke = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke personal access token by id",
+	Long: fmt.Sprintf(`
+This command deletes the personal access token,
+it stops working at once
+Usage: %s tokens revoke -i <token_id>`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		usecase.GetClientUseCase().RevokeToken(revokeTokenID)
+	},
+}
+
+var revokeTokenID string
+
+func init() {
+	Revoke.Flags().StringVarP(&revokeTokenID, "id", "i", "", "Token id")
+	if err := Revoke.MarkFlagRequired("id"); err != nil {
+		color.Red("%v", err)
+		return
+	}
+}
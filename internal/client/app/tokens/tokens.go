@@ -0,0 +1,32 @@
+package tokens
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+)
+
+var App = config.Load().App.Name
+var Tokens = &cobra.Command{
+	Use:   "tokens",
+	Short: "Manage personal access tokens",
+	Long:  `Create scoped personal access tokens for automation, list and revoke them.`,
+	Example: fmt.Sprintf(`
+# List tokens
+%s tokens list
+
+# Create a read-only token for the logins, valid for 30 days
+%s tokens create -n ci -d 30 --read-only -t logins
+
+# Revoke a token
+%s tokens revoke -i token_id
+	`, App, App, App),
+}
+
+func init() {
+	Tokens.AddCommand(List)
+	Tokens.AddCommand(Create)
+	Tokens.AddCommand(Revoke)
+}
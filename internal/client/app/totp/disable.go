@@ -0,0 +1,43 @@
+package totp
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/usecase"
+	"github.com/nextlag/keeper/internal/entity"
+	utils "github.com/nextlag/keeper/internal/utils/client"
+)
+
+var Disable = &cobra.Command{
+	Use:   "disable",
+	Short: "Disable two-factor authentication with a recovery code",
+	Long: fmt.Sprintf(`
+This command turns two-factor authentication off when the authenticator
+app is lost. It asks for the master password and one of the recovery codes,
+all recovery codes stop working afterwards
+Usage: %s 2fa disable user_email`, App),
+	Args: cobra.ExactArgs(1),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// One reader for all prompts, so piped input is not lost between them.
+		in := bufio.NewReader(os.Stdin)
+
+		userPassword, err := utils.PromptPassword(in, os.Stderr, "Master password: ")
+		if err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		recoveryCode, err := utils.PromptPassword(in, os.Stderr, "Recovery code: ")
+		if err != nil {
+			color.Red("Recovery code required. Error: %v", err)
+			return
+		}
+
+		usecase.GetClientUseCase().DisableTOTP(&entity.User{Email: args[0], Password: userPassword}, recoveryCode)
+	},
+}
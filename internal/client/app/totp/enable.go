@@ -0,0 +1,29 @@
+package totp
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/nextlag/keeper/internal/client/app/auth"
+	"github.com/nextlag/keeper/internal/client/usecase"
+)
+
+var Enable = &cobra.Command{
+	Use:   "enable",
+	Short: "Enable two-factor authentication",
+	Long: fmt.Sprintf(`
+This command shows the provisioning URI of a TOTP second factor
+to add to an authenticator app, asks for its code and prints
+the one-time recovery codes
+Usage: %s 2fa enable`, App),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
+			color.Red("Authentication required. Error: %v", err)
+			return
+		}
+		usecase.GetClientUseCase().EnableTOTP(auth.PromptTOTPCode)
+	},
+}
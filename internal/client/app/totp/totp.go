@@ -0,0 +1,28 @@
+package totp
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	config "github.com/nextlag/keeper/config/client"
+)
+
+var App = config.Load().App.Name
+var TwoFactor = &cobra.Command{
+	Use:   "2fa",
+	Short: "Manage two-factor authentication",
+	Long:  `Enable a TOTP second factor required at login or disable it with a recovery code.`,
+	Example: fmt.Sprintf(`
+# Enable two-factor authentication
+%s 2fa enable
+
+# Disable two-factor authentication with a recovery code
+%s 2fa disable user_email
+	`, App, App),
+}
+
+func init() {
+	TwoFactor.AddCommand(Enable)
+	TwoFactor.AddCommand(Disable)
+}
@@ -26,12 +26,11 @@ Flags:
   `, config.Load().App.Name),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		userPassword, err := usecase.GetClientUseCase().GetTempPass()
-		if err != nil {
+		if err := usecase.GetClientUseCase().OpenVault(); err != nil {
 			color.Red("Authentication required. Error: %v", err)
 			return
 		}
-		usecase.GetClientUseCase().ShowVault(userPassword, showVaultOption)
+		usecase.GetClientUseCase().ShowVault(showVaultOption)
 	},
 }
 
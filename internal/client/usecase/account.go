@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"github.com/fatih/color"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// DeleteAccount deletes the account of the logged-in user with all of its data on the server
+// and wipes the local cache. The server checks the master password again, an account with
+// a second factor is asked for the TOTP code by promptCode.
+func (uc *ClientUseCase) DeleteAccount(userPassword string, promptCode func() (string, error)) {
+	if !uc.verifyPassword(userPassword) {
+		color.Red("Password verification failed")
+		return
+	}
+
+	if _, err := uc.unlockVault(userPassword); err != nil {
+		color.Red("Failed to unlock the vault: %v", err)
+		return
+	}
+
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization failed: %v", err)
+		return
+	}
+
+	user, err := uc.clientAPI.GetUserInfo(accessToken)
+	if err != nil {
+		color.Red("Failed to get current user: %v", err)
+		return
+	}
+
+	var code string
+	if user.TOTPEnabled {
+		if code, err = promptCode(); err != nil {
+			color.Red("Two-factor code required. Error: %v", err)
+			return
+		}
+	}
+
+	credentials := authCredentials(&entity.User{Email: user.Email, Password: userPassword})
+	if err = uc.clientAPI.DeleteAccount(accessToken, credentials.Password, code); err != nil {
+		color.Red("Failed to delete the account, it has not been deleted: %v", err)
+		return
+	}
+	color.Green("Account %s deleted", user.Email)
+
+	if err = uc.session.Remove(); err != nil {
+		color.Red("Failed to lock the vault: %v", err)
+	}
+	uc.vault = nil
+	if err = uc.repo.WipeDB(); err != nil {
+		color.Red("Failed to wipe the local storage: %v", err)
+		return
+	}
+	color.Green("Local storage wiped")
+}
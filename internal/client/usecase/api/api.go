@@ -40,6 +40,28 @@ func (api *ClientAPI) addEntity(entity any, accessToken, endpoint string) error
 	return nil
 }
 
+// updateEntity sends a PATCH request to update an existing entity on the server.
+func (api *ClientAPI) updateEntity(entity any, accessToken, endpoint, id string) error {
+	client := resty.New()
+	client.SetAuthToken(accessToken)
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(entity).
+		Patch(fmt.Sprintf("%s/%s/%s", api.serverURL, endpoint, id))
+	if err != nil {
+		return err
+	}
+	// The item has been changed on the server since the revision the update is based on.
+	if resp.StatusCode() == http.StatusConflict {
+		return errs.ErrRevisionConflict
+	}
+	if err = api.checkResCode(resp); err != nil {
+		return errServer
+	}
+
+	return nil
+}
+
 // getEntities sends a GET request to retrieve entities from the server.
 func (api *ClientAPI) getEntities(entity any, accessToken, endpoint string) error {
 	client := resty.New()
@@ -61,7 +83,14 @@ func (api *ClientAPI) getEntities(entity any, accessToken, endpoint string) erro
 
 // checkResCode checks the response code and returns an error if the status code indicates a failure.
 func (api *ClientAPI) checkResCode(resp *resty.Response) error {
-	badCodes := []int{http.StatusBadRequest, http.StatusInternalServerError, http.StatusUnauthorized}
+	badCodes := []int{
+		http.StatusBadRequest,
+		http.StatusInternalServerError,
+		http.StatusUnauthorized,
+		http.StatusConflict,
+		http.StatusNotFound,
+		http.StatusTooManyRequests,
+	}
 	if slices.Contains(badCodes, resp.StatusCode()) {
 		errMessage := errs.ParseServerError(resp.Body())
 		color.Red("Server error: %s", errMessage)
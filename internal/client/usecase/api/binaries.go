@@ -2,11 +2,16 @@ package api
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"mime/multipart"
+	"net/http"
 
+	"github.com/fatih/color"
 	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
 
 	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
 )
 
 const binaryEndpoint = "api/v1/user/binary"
@@ -18,20 +23,19 @@ func (api *ClientAPI) GetBinaries(accessToken string) (binaries []entity.Binary,
 	return binaries, nil
 }
 
-func (api *ClientAPI) AddBinary(accessToken string, binary *entity.Binary, tmpFilePath string) error {
+// AddBinary uploads the file as a streamed multipart form, so the file is never held in memory.
+func (api *ClientAPI) AddBinary(accessToken string, binary *entity.Binary, file io.Reader) error {
 	var responseBinary entity.Binary
+
 	client := resty.New()
 	client.SetAuthToken(accessToken)
-	file, err := os.Open(tmpFilePath)
-	if err != nil {
-		return fmt.Errorf("ClientAPI - AddBinary - %w ", err)
-	}
-	resp, err := client.R().
-		SetHeader("Content-Type", "multipart/form-data").
+	request := client.R().
 		SetQueryParam("name", binary.Name).
-		SetFileReader("file", binary.FileName, file).
-		SetResult(&responseBinary).
-		Post(fmt.Sprintf("%s/%s", api.serverURL, binaryEndpoint))
+		SetResult(&responseBinary)
+	if binary.ID != uuid.Nil {
+		request.SetQueryParam("id", binary.ID.String())
+	}
+	resp, err := postFile(request, fmt.Sprintf("%s/%s", api.serverURL, binaryEndpoint), binary.FileName, "", file)
 	if err != nil {
 		return fmt.Errorf("ClientAPI - AddBinary - %w ", err)
 	}
@@ -49,22 +53,56 @@ func (api *ClientAPI) AddBinary(accessToken string, binary *entity.Binary, tmpFi
 	return nil
 }
 
+// postFile sends the file as a multipart form streamed through a pipe.
+// The form is delimited by the boundary or by a random one if the boundary is empty.
+func postFile(request *resty.Request, url, fileName, boundary string, file io.Reader) (*resty.Response, error) {
+	body, bodyWriter := io.Pipe()
+	defer body.Close()
+	form := multipart.NewWriter(bodyWriter)
+	if boundary != "" {
+		if err := form.SetBoundary(boundary); err != nil {
+			return nil, err
+		}
+	}
+	go func() {
+		part, err := form.CreateFormFile("file", fileName)
+		if err == nil {
+			_, err = io.Copy(part, file)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		bodyWriter.CloseWithError(err)
+	}()
+
+	return request.
+		SetHeader("Content-Type", form.FormDataContentType()).
+		SetBody(body).
+		Post(url)
+}
+
 func (api *ClientAPI) DelBinary(accessToken, binaryID string) error {
 	return api.delEntity(accessToken, binaryEndpoint, binaryID)
 }
 
-func (api *ClientAPI) DownloadBinary(accessToken, outputFilePath string, binary *entity.Binary) error {
+// DownloadBinary returns the response body with the binary contents.
+// The caller reads the file as it arrives and must close the body.
+func (api *ClientAPI) DownloadBinary(accessToken string, binary *entity.Binary) (io.ReadCloser, error) {
 	client := resty.New()
 	client.SetAuthToken(accessToken)
 	resp, err := client.R().
-		SetOutput(outputFilePath).
+		SetDoNotParseResponse(true).
 		Get(fmt.Sprintf("%s/%s/%s", api.serverURL, binaryEndpoint, binary.ID.String()))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err = api.checkResCode(resp); err != nil {
-		return err
+	if resp.StatusCode() != http.StatusOK {
+		defer resp.RawBody().Close()
+		message, _ := io.ReadAll(resp.RawBody())
+		color.Red("Server error: %s", errs.ParseServerError(message))
+		return nil, errServer
 	}
-	return nil
+
+	return resp.RawBody(), nil
 }
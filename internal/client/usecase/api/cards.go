@@ -21,3 +21,7 @@ func (api *ClientAPI) AddCard(accessToken string, card *entity.Card) error {
 func (api *ClientAPI) DelCard(accessToken, cardID string) error {
 	return api.delEntity(accessToken, cardsEndpoint, cardID)
 }
+
+func (api *ClientAPI) UpdateCard(accessToken string, card *entity.Card) error {
+	return api.updateEntity(card, accessToken, cardsEndpoint, card.ID.String())
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// GetChanges returns the items changed on the server since the cursor of the previous sync.
+func (api *ClientAPI) GetChanges(accessToken string, since int64) (changes entity.Changes, err error) {
+	client := resty.New()
+	client.SetAuthToken(accessToken)
+	resp, err := client.R().
+		SetQueryParam("since", strconv.FormatInt(since, 10)).
+		SetResult(&changes).
+		Get(fmt.Sprintf("%s/api/v1/user/changes", api.serverURL))
+	if err != nil {
+		return changes, fmt.Errorf("ClientAPI - GetChanges - %w ", err)
+	}
+
+	return changes, api.checkResCode(resp)
+}
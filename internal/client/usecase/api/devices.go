@@ -0,0 +1,32 @@
+package api
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+const devicesEndpoint = "api/v1/user/devices"
+
+// currentDevice describes the machine the client runs on; the server takes the IP address itself.
+func currentDevice() entity.Device {
+	name, err := os.Hostname()
+	if err != nil {
+		name = "unknown"
+	}
+
+	return entity.Device{Name: name, Platform: runtime.GOOS + "/" + runtime.GOARCH}
+}
+
+func (api *ClientAPI) GetDevices(accessToken string) (devices []entity.Session, err error) {
+	if err := api.getEntities(&devices, accessToken, devicesEndpoint); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+func (api *ClientAPI) RevokeDevice(accessToken, deviceID string) error {
+	return api.delEntity(accessToken, devicesEndpoint, deviceID)
+}
@@ -21,3 +21,7 @@ func (api *ClientAPI) AddLogin(accessToken string, login *entity.Login) error {
 func (api *ClientAPI) DelLogin(accessToken, loginID string) error {
 	return api.delEntity(accessToken, loginsEndpoint, loginID)
 }
+
+func (api *ClientAPI) UpdateLogin(accessToken string, login *entity.Login) error {
+	return api.updateEntity(login, accessToken, loginsEndpoint, login.ID.String())
+}
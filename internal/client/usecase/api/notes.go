@@ -19,3 +19,7 @@ func (api *ClientAPI) AddNote(accessToken string, note *entity.SecretNote) error
 func (api *ClientAPI) DelNote(accessToken, noteID string) error {
 	return api.delEntity(accessToken, notesEndpoint, noteID)
 }
+
+func (api *ClientAPI) UpdateNote(accessToken string, note *entity.SecretNote) error {
+	return api.updateEntity(note, accessToken, notesEndpoint, note.ID.String())
+}
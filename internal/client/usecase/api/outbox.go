@@ -0,0 +1,128 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+)
+
+// idempotencyKeyHeader makes the server apply a replayed operation only once.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+var errUnknownOperation = errors.New("unknown operation")
+
+// operationEndpoints of the items by type.
+var operationEndpoints = map[string]string{
+	entity.ItemLogins: loginsEndpoint,
+	entity.ItemCards:  cardsEndpoint,
+	entity.ItemNotes:  notesEndpoint,
+	entity.ItemBinary: binaryEndpoint,
+}
+
+// ReplayOperation sends the queued operation to the server with its idempotency key, so an operation
+// that reached the server before is not applied twice. The file is the encrypted contents of an added binary.
+// A stale update is reported with errs.ErrRevisionConflict and an operation the server refuses with
+// errs.ErrOperationRejected; any other error means the operation may be retried later.
+func (api *ClientAPI) ReplayOperation(accessToken string, op *entity.Operation, file io.Reader) error {
+	endpoint, ok := operationEndpoints[op.Type]
+	if !ok {
+		return errUnknownOperation
+	}
+	if op.Type == entity.ItemBinary && op.Action == entity.OperationAdd {
+		return api.replayBinary(accessToken, op, file)
+	}
+
+	client := resty.New()
+	client.SetAuthToken(accessToken)
+	request := client.R().SetHeader(idempotencyKeyHeader, op.Key)
+	itemURL := fmt.Sprintf("%s/%s/%s", api.serverURL, endpoint, op.ItemID.String())
+
+	var (
+		resp *resty.Response
+		err  error
+	)
+	switch op.Action {
+	case entity.OperationAdd:
+		resp, err = request.
+			SetHeader("Content-Type", "application/json").
+			SetBody([]byte(op.Item)).
+			Post(fmt.Sprintf("%s/%s", api.serverURL, endpoint))
+	case entity.OperationUpdate:
+		resp, err = request.
+			SetHeader("Content-Type", "application/json").
+			SetBody([]byte(op.Item)).
+			Patch(itemURL)
+	case entity.OperationDelete:
+		resp, err = request.Delete(itemURL)
+	default:
+		return errUnknownOperation
+	}
+	if err != nil {
+		return fmt.Errorf("ClientAPI - ReplayOperation - %w ", err)
+	}
+	// Only an update is based on a revision.
+	if op.Action == entity.OperationUpdate && resp.StatusCode() == http.StatusConflict {
+		return errs.ErrRevisionConflict
+	}
+
+	return replayResult(resp)
+}
+
+// replayBinary uploads the added binary and then its metadata, each with its own idempotency key.
+// The form boundary is derived from the key, so every attempt sends the same body and the server
+// takes it for a retry.
+func (api *ClientAPI) replayBinary(accessToken string, op *entity.Operation, file io.Reader) error {
+	var binary entity.Binary
+	if err := json.Unmarshal(op.Item, &binary); err != nil {
+		return fmt.Errorf("ClientAPI - ReplayOperation - %w ", err)
+	}
+
+	client := resty.New()
+	client.SetAuthToken(accessToken)
+	request := client.R().
+		SetHeader(idempotencyKeyHeader, op.Key).
+		SetQueryParam("name", binary.Name).
+		SetQueryParam("id", binary.ID.String())
+	boundary := fmt.Sprintf("%x", sha256.Sum256([]byte(op.Key)))
+	resp, err := postFile(request, fmt.Sprintf("%s/%s", api.serverURL, binaryEndpoint), binary.FileName, boundary, file)
+	if err != nil {
+		return fmt.Errorf("ClientAPI - ReplayOperation - %w ", err)
+	}
+	if err = replayResult(resp); err != nil || len(binary.Meta) == 0 {
+		return err
+	}
+
+	resp, err = client.R().
+		SetHeader(idempotencyKeyHeader, op.Key+"/meta").
+		SetHeader("Content-Type", "application/json").
+		SetBody(binary.Meta).
+		Post(fmt.Sprintf("%s/%s/%s/meta", api.serverURL, binaryEndpoint, binary.ID.String()))
+	if err != nil {
+		return fmt.Errorf("ClientAPI - ReplayOperation - AddMeta %w ", err)
+	}
+
+	return replayResult(resp)
+}
+
+// replayResult returns the error of the response to a replayed operation. A request refused
+// by the server is rejected for good, while an expired token, throttling, server errors and
+// an earlier attempt still running on the server leave the operation to be retried.
+func replayResult(resp *resty.Response) error {
+	switch status := resp.StatusCode(); {
+	case status < http.StatusBadRequest:
+		return nil
+	case status == http.StatusUnauthorized, status == http.StatusTooEarly, status == http.StatusTooManyRequests,
+		status >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: %s", errServer, errs.ParseServerError(resp.Body()))
+	default:
+		return fmt.Errorf("%w: %s", errs.ErrOperationRejected, errs.ParseServerError(resp.Body()))
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+)
+
+const (
+	rekeyEndpoint   = "api/v1/user/rekey"
+	dataKeyEndpoint = "api/v1/user/key"
+)
+
+// StageRekey uploads the re-encrypted vault, the server keeps it until RotateDataKey.
+func (api *ClientAPI) StageRekey(accessToken string, rekey *entity.Rekey) error {
+	return api.addEntity(rekey, accessToken, rekeyEndpoint)
+}
+
+// StageRekeyBinary uploads the re-encrypted file of the binary, the server keeps it until RotateDataKey.
+func (api *ClientAPI) StageRekeyBinary(accessToken string, binary *entity.Binary, file io.Reader) error {
+	client := resty.New()
+	client.SetAuthToken(accessToken)
+	url := fmt.Sprintf("%s/%s/binary/%s", api.serverURL, rekeyEndpoint, binary.ID.String())
+	resp, err := postFile(client.R(), url, binary.FileName, "", file)
+	if err != nil {
+		return fmt.Errorf("ClientAPI - StageRekeyBinary - %w ", err)
+	}
+
+	return api.checkResCode(resp)
+}
+
+// ChangePassword changes the password together with the data key wrapped by the new password.
+func (api *ClientAPI) ChangePassword(accessToken, oldPassword, newPassword, dataKey string) error {
+	return api.addEntity(&entity.PasswordChange{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+		DataKey:     dataKey,
+	}, accessToken, "api/v1/user/password")
+}
+
+// GetDataKey returns the wrapped data key, empty if the vault has none yet.
+func (api *ClientAPI) GetDataKey(accessToken string) (string, error) {
+	var dataKey entity.DataKey
+	if err := api.getEntities(&dataKey, accessToken, dataKeyEndpoint); err != nil {
+		return "", err
+	}
+
+	return dataKey.DataKey, nil
+}
+
+// RotateDataKey switches the vault to the staged copy re-encrypted with the new data key.
+func (api *ClientAPI) RotateDataKey(accessToken, password, dataKey string) error {
+	client := resty.New()
+	client.SetAuthToken(accessToken)
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(&entity.DataKeyRotation{Password: password, DataKey: dataKey}).
+		Put(fmt.Sprintf("%s/%s", api.serverURL, dataKeyEndpoint))
+	if err != nil {
+		return fmt.Errorf("ClientAPI - RotateDataKey - %w ", err)
+	}
+	if resp.StatusCode() == http.StatusConflict {
+		return errs.ErrRekeyIncomplete
+	}
+
+	return api.checkResCode(resp)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// SetRecoveryKey stores the data key wrapped by a new recovery key, the previous recovery key stops working.
+func (api *ClientAPI) SetRecoveryKey(accessToken string, recoveryKey *entity.RecoveryKey) error {
+	client := resty.New()
+	client.SetAuthToken(accessToken)
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(recoveryKey).
+		Put(fmt.Sprintf("%s/api/v1/user/recovery", api.serverURL))
+	if err != nil {
+		return fmt.Errorf("ClientAPI - SetRecoveryKey - %w ", err)
+	}
+
+	return api.checkResCode(resp)
+}
+
+// GetRecoveryKey returns the data key wrapped by the recovery key the secret is derived from.
+func (api *ClientAPI) GetRecoveryKey(email, recoveryAuth string) (string, error) {
+	var recoveryKey entity.RecoveryKey
+	client := resty.New()
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(&entity.Recovery{Email: email, RecoveryAuth: recoveryAuth}).
+		SetResult(&recoveryKey).
+		Post(fmt.Sprintf("%s/api/v1/auth/recover/key", api.serverURL))
+	if err != nil {
+		return "", fmt.Errorf("ClientAPI - GetRecoveryKey - %w ", err)
+	}
+
+	return recoveryKey.RecoveryKey, api.checkResCode(resp)
+}
+
+// RecoverAccount sets the new password of the user with the recovery key,
+// together with the data key wrapped by the new password.
+func (api *ClientAPI) RecoverAccount(recovery *entity.Recovery) error {
+	client := resty.New()
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(recovery).
+		Post(fmt.Sprintf("%s/api/v1/auth/recover", api.serverURL))
+	if err != nil {
+		return fmt.Errorf("ClientAPI - RecoverAccount - %w ", err)
+	}
+
+	return api.checkResCode(resp)
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+const tokensEndpoint = "api/v1/user/tokens"
+
+func (api *ClientAPI) GetPersonalTokens(accessToken string) (tokens []entity.PersonalToken, err error) {
+	if err := api.getEntities(&tokens, accessToken, tokensEndpoint); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// CreatePersonalToken creates the personal token, the server fills in its ID and secret.
+func (api *ClientAPI) CreatePersonalToken(accessToken string, token *entity.PersonalToken) error {
+	return api.addEntity(token, accessToken, tokensEndpoint)
+}
+
+func (api *ClientAPI) RevokePersonalToken(accessToken, tokenID string) error {
+	return api.delEntity(accessToken, tokensEndpoint, tokenID)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+const totpEndpoint = "api/v1/user/totp"
+
+// SignInTOTP completes a sign in that has returned a TOTP challenge with a code of the authenticator.
+// The email of the user is sent along, the server throttles the sign ins of the account by it.
+func (api *ClientAPI) SignInTOTP(email, challenge, code string) (token entity.JWT, err error) {
+	client := resty.New()
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]string{"email": email, "challenge": challenge, "code": code}).
+		SetResult(&token).
+		Post(fmt.Sprintf("%s/api/v1/auth/login/totp", api.serverURL))
+	if err != nil {
+		return token, fmt.Errorf("ClientAPI - SignInTOTP - %w ", err)
+	}
+
+	return token, api.checkResCode(resp)
+}
+
+// EnrollTOTP generates the secret of a new second factor, it is required once ConfirmTOTP confirms it.
+func (api *ClientAPI) EnrollTOTP(accessToken string) (enrollment entity.TOTPEnrollment, err error) {
+	client := resty.New()
+	client.SetAuthToken(accessToken)
+	resp, err := client.R().
+		SetResult(&enrollment).
+		Post(fmt.Sprintf("%s/%s", api.serverURL, totpEndpoint))
+	if err != nil {
+		return enrollment, fmt.Errorf("ClientAPI - EnrollTOTP - %w ", err)
+	}
+
+	return enrollment, api.checkResCode(resp)
+}
+
+// ConfirmTOTP turns the enrolled second factor on and returns the recovery codes.
+func (api *ClientAPI) ConfirmTOTP(accessToken, code string) (recoveryCodes entity.RecoveryCodes, err error) {
+	client := resty.New()
+	client.SetAuthToken(accessToken)
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]string{"code": code}).
+		SetResult(&recoveryCodes).
+		Post(fmt.Sprintf("%s/%s/confirm", api.serverURL, totpEndpoint))
+	if err != nil {
+		return recoveryCodes, fmt.Errorf("ClientAPI - ConfirmTOTP - %w ", err)
+	}
+
+	return recoveryCodes, api.checkResCode(resp)
+}
+
+// DisableTOTP turns the second factor of the user off with a recovery code.
+// The password is the auth hash, the same one the user signs in with.
+func (api *ClientAPI) DisableTOTP(user *entity.User, recoveryCode string) error {
+	client := resty.New()
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]string{"email": user.Email, "password": user.Password, "recovery_code": recoveryCode}).
+		Post(fmt.Sprintf("%s/api/v1/auth/totp/disable", api.serverURL))
+	if err != nil {
+		return fmt.Errorf("ClientAPI - DisableTOTP - %w ", err)
+	}
+
+	return api.checkResCode(resp)
+}
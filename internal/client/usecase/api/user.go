@@ -14,9 +14,13 @@ import (
 
 var errServer = errors.New("got server error")
 
+const userEndpoint = "api/v1/user/me"
+
 func (api *ClientAPI) Login(user *entity.User) (token entity.JWT, err error) {
 	client := resty.New()
-	body := fmt.Sprintf(`{"email":%q, "password":%q}`, user.Email, user.Password)
+	device := currentDevice()
+	body := fmt.Sprintf(`{"email":%q, "password":%q, "device":{"name":%q, "platform":%q}}`,
+		user.Email, user.Password, device.Name, device.Platform)
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
 		SetBody(body).
@@ -26,7 +30,15 @@ func (api *ClientAPI) Login(user *entity.User) (token entity.JWT, err error) {
 		return
 	}
 
-	if resp.StatusCode() == http.StatusBadRequest || resp.StatusCode() == http.StatusInternalServerError {
+	if resp.StatusCode() == http.StatusUnauthorized {
+		return token, errs.ErrWrongCredentials
+	}
+	if resp.StatusCode() == http.StatusConflict {
+		return token, errs.ErrAuthUpgradeRequired
+	}
+
+	if resp.StatusCode() == http.StatusBadRequest || resp.StatusCode() == http.StatusInternalServerError ||
+		resp.StatusCode() == http.StatusTooManyRequests {
 		errMessage := errs.ParseServerError(resp.Body())
 		color.Red("Server error: %s", errMessage)
 		return token, errServer
@@ -34,9 +46,36 @@ func (api *ClientAPI) Login(user *entity.User) (token entity.JWT, err error) {
 	return token, nil
 }
 
-func (api *ClientAPI) Register(user *entity.User) error {
+// UpgradeAuth sends the master password of the user one last time together with the auth hash
+// that replaces it on the server.
+func (api *ClientAPI) UpgradeAuth(user *entity.User, authHash string) (token entity.JWT, err error) {
 	client := resty.New()
-	body := fmt.Sprintf(`{"email":%q, "password":%q}`, user.Email, user.Password)
+	device := currentDevice()
+	body := fmt.Sprintf(`{"email":%q, "password":%q, "auth_hash":%q, "device":{"name":%q, "platform":%q}}`,
+		user.Email, user.Password, authHash, device.Name, device.Platform)
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).
+		SetResult(&token).
+		Post(fmt.Sprintf("%s/api/v1/auth/upgrade", api.serverURL))
+	if err != nil {
+		return
+	}
+
+	if resp.StatusCode() == http.StatusBadRequest || resp.StatusCode() == http.StatusInternalServerError ||
+		resp.StatusCode() == http.StatusTooManyRequests {
+		errMessage := errs.ParseServerError(resp.Body())
+		color.Red("Server error: %s", errMessage)
+		return token, errServer
+	}
+	return token, nil
+}
+
+// Register registers the user together with the keys of their new vault.
+func (api *ClientAPI) Register(user *entity.User, keys *entity.VaultKeys) error {
+	client := resty.New()
+	body := fmt.Sprintf(`{"email":%q, "password":%q, "data_key":%q, "recovery_auth":%q, "recovery_key":%q}`,
+		user.Email, user.Password, keys.DataKey, keys.RecoveryAuth, keys.RecoveryKey)
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
 		SetBody(body).
@@ -47,7 +86,28 @@ func (api *ClientAPI) Register(user *entity.User) error {
 		return err
 	}
 
-	if resp.StatusCode() == http.StatusBadRequest || resp.StatusCode() == http.StatusInternalServerError {
+	if resp.StatusCode() == http.StatusBadRequest || resp.StatusCode() == http.StatusInternalServerError ||
+		resp.StatusCode() == http.StatusTooManyRequests {
+		errMessage := errs.ParseServerError(resp.Body())
+		color.Red("Server error: %s", errMessage)
+		return errServer
+	}
+
+	return nil
+}
+
+// Logout revokes the session of the tokens on the server.
+func (api *ClientAPI) Logout(token entity.JWT) error {
+	client := resty.New()
+	client.SetAuthToken(token.AccessToken)
+	resp, err := client.R().
+		SetCookie(&http.Cookie{Name: "refresh_token", Value: token.RefreshToken}).
+		Get(fmt.Sprintf("%s/api/v1/auth/logout", api.serverURL))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
 		errMessage := errs.ParseServerError(resp.Body())
 		color.Red("Server error: %s", errMessage)
 		return errServer
@@ -55,3 +115,28 @@ func (api *ClientAPI) Register(user *entity.User) error {
 
 	return nil
 }
+
+// GetUserInfo returns the user the access token belongs to.
+func (api *ClientAPI) GetUserInfo(accessToken string) (user entity.User, err error) {
+	if err = api.getEntities(&user, accessToken, userEndpoint); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// DeleteAccount deletes the account of the user with all of its data on the server.
+// The password is the auth hash, the same one the user signs in with.
+func (api *ClientAPI) DeleteAccount(accessToken, password, code string) error {
+	client := resty.New()
+	client.SetAuthToken(accessToken)
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]string{"password": password, "code": code}).
+		Delete(fmt.Sprintf("%s/%s", api.serverURL, userEndpoint))
+	if err != nil {
+		return fmt.Errorf("ClientAPI - DeleteAccount - %w ", err)
+	}
+
+	return api.checkResCode(resp)
+}
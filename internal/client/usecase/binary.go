@@ -1,7 +1,10 @@
 package usecase
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/fatih/color"
 	"github.com/google/uuid"
@@ -10,46 +13,52 @@ import (
 	"github.com/nextlag/keeper/internal/utils"
 )
 
-// AddBinary adds a binary file.
-func (uc *ClientUseCase) AddBinary(userPassword string, binary *entity.Binary) {
-	accessToken, err := uc.authorisationCheck(userPassword)
+// AddBinary adds a binary file to the local cache, it is uploaded by the next sync.
+// The file is encrypted while it is being copied to the spool directory, so only the ciphertext waits there.
+func (uc *ClientUseCase) AddBinary(binary *entity.Binary) {
+	_, err := uc.authorisationCheck()
 	if err != nil {
 		color.Red("Authorization failed: %v", err)
 		return
 	}
 
-	file, err := os.Stat(binary.FileName)
+	file, err := os.Open(binary.FileName)
 	if err != nil {
-		color.Red("Error statting file %s: %v", binary.FileName, err)
+		color.Red("Error opening file %s: %v", binary.FileName, err)
 		return
 	}
-	tmpFilePath := uc.cfg.FilesStorage.ClientLocation + file.Name()
+	defer file.Close()
 
-	if err = utils.EncryptFile(userPassword, binary.FileName, tmpFilePath); err != nil {
-		color.Red("Error encrypting file %s: %v", binary.FileName, err)
+	cipher, err := uc.vaultCipher()
+	if err != nil {
+		color.Red("Failed to prepare encryption: %v", err)
+		return
+	}
+	if err = encryptItem(cipher, binary); err != nil {
+		color.Red("Failed to encrypt binary %q: %v", binary.Name, err)
 		return
 	}
 
-	if err = uc.clientAPI.AddBinary(accessToken, binary, tmpFilePath); err != nil {
-		color.Red("Error adding binary file %s: %v", binary.FileName, err)
+	encrypted := cipher.EncryptReader(file)
+	defer encrypted.Close()
+
+	if err = uc.spoolBinary(binary.ID, encrypted); err != nil {
+		color.Red("Error spooling binary file %s: %v", binary.FileName, err)
 		return
 	}
 
-	if err = uc.repo.AddBinary(binary); err != nil {
+	if err = uc.queueItem(entity.OperationAdd, binary, nil); err != nil {
+		uc.removeSpool(binary.ID)
 		color.Red("Error saving binary file %s to repository: %v", binary.FileName, err)
 		return
 	}
-	defer func() {
-		if removeErr := os.Remove(tmpFilePath); removeErr != nil {
-			color.Red("Failed to remove temporary file %s: %v", tmpFilePath, removeErr)
-		}
-	}()
-	color.Green("Binary %v - %s saved successfully", binary.ID, binary.FileName)
+	color.Green("Binary %v - %s saved locally, it is uploaded by the next `sync`", binary.ID, binary.FileName)
 }
 
-// DelBinary deletes a binary file.
-func (uc *ClientUseCase) DelBinary(userPassword, binaryID string) {
-	accessToken, err := uc.authorisationCheck(userPassword)
+// DelBinary deletes a binary file from the local cache, the deletion is uploaded by the next sync.
+// The spooled file of a binary that has not been uploaded yet is removed by queueDelete.
+func (uc *ClientUseCase) DelBinary(binaryID string) {
+	_, err := uc.authorisationCheck()
 	if err != nil {
 		color.Red("Authorization failed: %v", err)
 		return
@@ -61,22 +70,19 @@ func (uc *ClientUseCase) DelBinary(userPassword, binaryID string) {
 		return
 	}
 
-	if err = uc.repo.DelBinary(binaryUUID); err != nil {
+	if err = uc.queueDelete(entity.ItemBinary, binaryUUID); err != nil {
 		color.Red("Error deleting binary file with ID %s from repository: %v", binaryID, err)
 		return
 	}
 
-	if err = uc.clientAPI.DelBinary(accessToken, binaryID); err != nil {
-		color.Red("Error deleting binary file %s with ID %s through API: %v", binaryID, binaryID, err)
-		return
-	}
-
-	color.Green("Binary %q removed successfully", binaryID)
+	color.Green("Binary %q removed locally, the deletion is uploaded by the next `sync`", binaryID)
 }
 
-// GetBinary downloads and decrypts a binary file.
-func (uc *ClientUseCase) GetBinary(userPassword, binaryID, filePath string) {
-	accessToken, err := uc.authorisationCheck(userPassword)
+// GetBinary downloads and decrypts a binary file, a file that has not been uploaded yet is read from the spool.
+// The file is decrypted as it arrives and written next to filePath first,
+// so a download that fails authentication never leaves plaintext at filePath.
+func (uc *ClientUseCase) GetBinary(binaryID, filePath string) {
+	accessToken, err := uc.authorisationCheck()
 	if err != nil {
 		color.Red("Authorization failed: %v", err)
 		return
@@ -94,21 +100,24 @@ func (uc *ClientUseCase) GetBinary(userPassword, binaryID, filePath string) {
 		return
 	}
 
-	tmpFilePath := filePath + binary.FileName
-
-	defer func() {
-		if removeErr := os.Remove(tmpFilePath); removeErr != nil {
-			color.Red("Failed to remove temporary file %s: %v", tmpFilePath, removeErr)
-		}
-	}()
+	cipher, err := uc.vaultCipher()
+	if err != nil {
+		color.Red("Failed to prepare decryption: %v", err)
+		return
+	}
 
-	if err = uc.clientAPI.DownloadBinary(accessToken, tmpFilePath, &binary); err != nil {
+	var body io.ReadCloser
+	if body, err = os.Open(uc.spoolPath(binary.ID)); os.IsNotExist(err) {
+		body, err = uc.clientAPI.DownloadBinary(accessToken, &binary)
+	}
+	if err != nil {
 		color.Red("Error downloading binary file %s: %v", binary.FileName, err)
 		return
 	}
+	defer body.Close()
 
-	if err = utils.DecryptFile(userPassword, tmpFilePath, filePath); err != nil {
-		color.Red("Error decrypting file %s: %v", tmpFilePath, err)
+	if err = decryptToFile(cipher, body, filePath); err != nil {
+		color.Red("Error decrypting file %s: %v", binary.FileName, err)
 		return
 	}
 
@@ -116,18 +125,31 @@ func (uc *ClientUseCase) GetBinary(userPassword, binaryID, filePath string) {
 	color.Green("File '%v' successfully downloaded", binary.FileName)
 }
 
-// loadBinaries loads binaries and saves them to the repository.
-func (uc *ClientUseCase) loadBinaries(accessToken string) {
-	binaries, err := uc.clientAPI.GetBinaries(accessToken)
+// decryptToFile decrypts the stream into a temporary file and moves it to filePath once
+// the whole stream has been authenticated.
+func decryptToFile(cipher *utils.Cipher, encrypted io.Reader, filePath string) error {
+	plain, err := cipher.DecryptStream(encrypted)
 	if err != nil {
-		color.Red("Error fetching binaries with access token %s: %v", accessToken, err)
-		return
+		return err
 	}
 
-	if err = uc.repo.SaveBinaries(binaries); err != nil {
-		color.Red("Error saving binaries to repository: %v", err)
-		return
+	partFile, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.part")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp - %w", err)
+	}
+	defer os.Remove(partFile.Name())
+
+	if _, err = io.Copy(partFile, plain); err != nil {
+		partFile.Close()
+		return fmt.Errorf("io.Copy - %w", err)
+	}
+	if err = partFile.Close(); err != nil {
+		return fmt.Errorf("partFile.Close - %w", err)
+	}
+
+	if err = os.Rename(partFile.Name(), filePath); err != nil {
+		return fmt.Errorf("os.Rename - %w", err)
 	}
 
-	color.Green("Loaded %v binaries successfully", len(binaries))
+	return nil
 }
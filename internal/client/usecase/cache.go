@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+// cacheKeyInfo names the key derived from the vault key to seal the local cache.
+const cacheKeyInfo = "keeper local cache"
+
+// openCache keeps the cipher of the opened vault and unlocks the local cache of the user
+// with the key derived from the vault key. It reports whether the cache was sealed with
+// another key and has been cleared together with the tokens.
+func (uc *ClientUseCase) openCache(email string, cipher *utils.Cipher) (bool, error) {
+	cacheKey, err := cipher.SubKey(cacheKeyInfo)
+	if err != nil {
+		return false, fmt.Errorf("failed to derive the cache key: %w", err)
+	}
+
+	cleared, err := uc.repo.SetCacheKey(email, cacheKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to open the local cache: %w", err)
+	}
+	uc.vault = cipher
+
+	return cleared, nil
+}
+
+// setVault opens the vault and the local cache of the user with the cipher.
+// A cleared cache has lost the tokens, so the user is asked to log in again.
+func (uc *ClientUseCase) setVault(email string, cipher *utils.Cipher) error {
+	cleared, err := uc.openCache(email, cipher)
+	if err != nil {
+		return err
+	}
+	if cleared {
+		color.Yellow("The local cache was sealed with another key and has been cleared, log in again")
+	}
+
+	return nil
+}
+
+// unlockVault opens the vault of the current user with the master password.
+func (uc *ClientUseCase) unlockVault(userPassword string) (*utils.Cipher, error) {
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		return nil, err
+	}
+
+	cipher, _, err := uc.unlockCipher(userPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher, uc.setVault(user.Email, cipher)
+}
+
+// lockVault forgets the vault cipher and the cache key.
+func (uc *ClientUseCase) lockVault() {
+	uc.vault = nil
+	if _, err := uc.repo.SetCacheKey("", nil); err != nil {
+		color.Red("Failed to lock the local cache: %v", err)
+	}
+}
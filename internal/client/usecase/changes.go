@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"github.com/fatih/color"
+)
+
+// loadChanges loads the items changed on the server since the saved cursor and applies them to the repository.
+// Without a cursor, or with one the server no longer knows, the server sends every item.
+func (uc *ClientUseCase) loadChanges(accessToken string) {
+	cursor, err := uc.repo.GetSyncCursor()
+	if err != nil {
+		color.Red("Error reading the sync cursor: %v", err)
+		return
+	}
+
+	changes, err := uc.clientAPI.GetChanges(accessToken, cursor)
+	if err != nil {
+		color.Red("Error fetching changes: %v", err)
+		return
+	}
+
+	if err = uc.repo.ApplyChanges(&changes); err != nil {
+		color.Red("Error saving changes to repository: %v", err)
+		return
+	}
+
+	if changes.Full {
+		color.Green("Loaded %v logins, %v cards, %v notes and %v binaries successfully",
+			len(changes.Logins), len(changes.Cards), len(changes.Notes), len(changes.Binaries))
+		return
+	}
+	updated := len(changes.Logins) + len(changes.Cards) + len(changes.Notes) + len(changes.Binaries)
+	color.Green("Synced %v changed and %v deleted items successfully", updated, len(changes.Deleted))
+}
@@ -0,0 +1,471 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+// Ways to resolve a conflict.
+const (
+	KeepLocal  = "local"  // The local version replaces the remote one.
+	KeepRemote = "remote" // The local version is dropped.
+	KeepBoth   = "both"   // The local version is added as a copy of the item.
+)
+
+var (
+	errUnknownKeep     = errors.New("unknown version to keep, use local, remote or both")
+	errUnknownItemType = errors.New("unknown item type")
+	errBinaryConflict  = errors.New("binaries are only added and deleted, their conflicts cannot be merged")
+)
+
+// conflictCopySuffix marks the name of the local version kept as a copy.
+const conflictCopySuffix = " (conflict copy)"
+
+// editItem decrypts the cached item, changes it with edit and queues it encrypted again.
+// The update is based on the cached revision; if the server rejects it as stale on the next sync,
+// the local version is kept as a conflict and merged when the sides changed different fields.
+func (uc *ClientUseCase) editItem(item any, edit func()) {
+	_, itemID, revision := itemInfo(item)
+	if _, err := uc.repo.GetConflict(itemID); err == nil {
+		color.Red("Item %s has an unresolved conflict, resolve it with `conflicts` first", itemID)
+		return
+	}
+
+	cipher, err := uc.vaultCipher()
+	if err != nil {
+		color.Red("Failed to prepare encryption: %v", err)
+		return
+	}
+	base, err := json.Marshal(item)
+	if err != nil {
+		color.Red("Failed to encode item %s: %v", itemID, err)
+		return
+	}
+	if err = decryptItem(cipher, item); err != nil {
+		color.Red("Failed to decrypt item %s: %v", itemID, err)
+		return
+	}
+	edit()
+	if err = encryptItem(cipher, item); err != nil {
+		color.Red("Failed to encrypt item %s: %v", itemID, err)
+		return
+	}
+
+	setBaseRevision(item, revision)
+	if err = uc.queueItem(entity.OperationUpdate, item, base); err != nil {
+		color.Red("Error updating item %s in repository: %v", itemID, err)
+		return
+	}
+	color.Green("Item %s updated locally, it is uploaded by the next `sync`", itemID)
+}
+
+// mergeConflicts merges every conflict whose sides changed different fields and queues the result.
+// It returns the number of merged conflicts; the conflicts left have to be resolved by the user.
+func (uc *ClientUseCase) mergeConflicts() int {
+	conflicts, err := uc.repo.GetConflicts()
+	if err != nil {
+		color.Red("Error reading conflicts: %v", err)
+		return 0
+	}
+	if len(conflicts) == 0 {
+		return 0
+	}
+	cipher, err := uc.vaultCipher()
+	if err != nil {
+		color.Red("Failed to prepare decryption: %v", err)
+		return 0
+	}
+
+	var merged int
+	for index := range conflicts {
+		ok, err := uc.mergeConflict(cipher, &conflicts[index])
+		if err != nil {
+			color.Red("Error merging the conflict of item %s: %v", conflicts[index].ItemID, err)
+			continue
+		}
+		if ok {
+			merged++
+		}
+	}
+
+	if merged > 0 {
+		color.Green("Merged %v conflicts successfully", merged)
+	}
+	if left := len(conflicts) - merged; left > 0 {
+		color.Yellow("%v conflicts left, list them with `conflicts` and resolve with `conflicts --keep local|remote|both`", left)
+	}
+
+	return merged
+}
+
+// mergeConflict applies the fields changed locally to the cached remote version and queues it.
+// It reports false when both sides changed a field or when the item has been deleted on another device.
+func (uc *ClientUseCase) mergeConflict(cipher *utils.Cipher, conflict *entity.Conflict) (bool, error) {
+	base, local, err := openConflict(cipher, conflict)
+	if err != nil {
+		return false, err
+	}
+	remote, err := uc.cachedItem(conflict.Type, conflict.ItemID)
+	if err != nil {
+		// The item is gone from the cache, it has been deleted on another device.
+		return false, nil
+	}
+	_, _, revision := itemInfo(remote)
+	remoteBase, err := json.Marshal(remote)
+	if err != nil {
+		return false, err
+	}
+	if err = decryptItem(cipher, remote); err != nil {
+		return false, err
+	}
+
+	if len(mergeItems(base, local, remote)) > 0 {
+		return false, nil
+	}
+	if err = encryptItem(cipher, remote); err != nil {
+		return false, err
+	}
+	setBaseRevision(remote, revision)
+	if err = uc.queueItem(entity.OperationUpdate, remote, remoteBase); err != nil {
+		return false, err
+	}
+
+	return true, uc.repo.DelConflict(conflict.ItemID)
+}
+
+// Conflicts lists the unresolved conflicts when keep is empty. Otherwise it resolves the conflict
+// of the item, or every conflict without an item ID, keeping the local version, the remote one or both.
+func (uc *ClientUseCase) Conflicts(itemID, keep string) {
+	cipher, err := uc.vaultCipher()
+	if err != nil {
+		color.Red("Failed to prepare decryption: %v", err)
+		return
+	}
+
+	var conflicts []entity.Conflict
+	if itemID == "" {
+		conflicts, err = uc.repo.GetConflicts()
+	} else {
+		var itemUUID uuid.UUID
+		if itemUUID, err = uuid.Parse(itemID); err != nil {
+			color.Red("Error parsing item ID %s: %v", itemID, err)
+			return
+		}
+		var conflict entity.Conflict
+		conflict, err = uc.repo.GetConflict(itemUUID)
+		conflicts = []entity.Conflict{conflict}
+	}
+	if err != nil {
+		color.Red("Error reading conflicts: %v", err)
+		return
+	}
+	if len(conflicts) == 0 {
+		color.Green("No conflicts")
+		return
+	}
+
+	if keep == "" {
+		uc.showConflicts(cipher, conflicts)
+		return
+	}
+	if keep != KeepLocal && keep != KeepRemote && keep != KeepBoth {
+		color.Red("Failed to resolve conflicts: %v", errUnknownKeep)
+		return
+	}
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization failed: %v", err)
+		return
+	}
+
+	for index := range conflicts {
+		if err = uc.resolveConflict(cipher, &conflicts[index], keep); err != nil {
+			color.Red("Error resolving the conflict of item %s: %v", conflicts[index].ItemID, err)
+			continue
+		}
+		color.Green("Conflict of item %s resolved, kept %s", conflicts[index].ItemID, keep)
+	}
+	uc.syncVault(accessToken)
+}
+
+// showConflicts prints every conflict with the fields changed on each side.
+func (uc *ClientUseCase) showConflicts(cipher *utils.Cipher, conflicts []entity.Conflict) {
+	yellow := color.New(color.FgYellow).SprintFunc()
+	for index := range conflicts {
+		conflict := &conflicts[index]
+		base, local, err := openConflict(cipher, conflict)
+		if err != nil {
+			color.Red("Failed to open the conflict of item %s: %v", conflict.ItemID, err)
+			continue
+		}
+
+		remoteChanges := "deleted"
+		if remote, err := uc.cachedItem(conflict.Type, conflict.ItemID); err == nil {
+			if err = decryptItem(cipher, remote); err != nil {
+				color.Red("Failed to decrypt item %s: %v", conflict.ItemID, err)
+				continue
+			}
+			remoteChanges = strings.Join(changedFields(base, remote), ", ")
+		}
+		fmt.Printf("ID: %s\nType: %s\nName: %s\nLocal changes: %s\nRemote changes: %s\n\n",
+			yellow(conflict.ItemID),
+			yellow(conflict.Type),
+			yellow(*itemFields(local)["name"]),
+			yellow(strings.Join(changedFields(base, local), ", ")),
+			yellow(remoteChanges),
+		)
+	}
+}
+
+// resolveConflict resolves the conflict keeping the given version, queues the change and forgets the conflict.
+// The local version of an item deleted on another device is added again as a new item.
+func (uc *ClientUseCase) resolveConflict(cipher *utils.Cipher, conflict *entity.Conflict, keep string) error {
+	if keep != KeepRemote {
+		_, local, err := openConflict(cipher, conflict)
+		if err != nil {
+			return err
+		}
+
+		remote, err := uc.cachedItem(conflict.Type, conflict.ItemID)
+		switch {
+		case keep == KeepBoth:
+			*itemFields(local)["name"] += conflictCopySuffix
+			err = uc.addItemCopy(cipher, local)
+		case err != nil:
+			// The item has been deleted on another device.
+			err = uc.addItemCopy(cipher, local)
+		default:
+			_, _, revision := itemInfo(remote)
+			var remoteBase []byte
+			if remoteBase, err = json.Marshal(remote); err != nil {
+				return err
+			}
+			if err = encryptItem(cipher, local); err != nil {
+				return err
+			}
+			setBaseRevision(local, revision)
+			err = uc.queueItem(entity.OperationUpdate, local, remoteBase)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return uc.repo.DelConflict(conflict.ItemID)
+}
+
+// addItemCopy queues the decrypted item as a new item with its own IDs.
+func (uc *ClientUseCase) addItemCopy(cipher *utils.Cipher, item any) error {
+	var meta []entity.Meta
+	switch v := item.(type) {
+	case *entity.Login:
+		v.ID, meta = uuid.Nil, v.Meta
+	case *entity.Card:
+		v.ID, meta = uuid.Nil, v.Meta
+	case *entity.SecretNote:
+		v.ID, meta = uuid.Nil, v.Meta
+	}
+	for index := range meta {
+		meta[index].ID = uuid.Nil
+	}
+	if err := encryptItem(cipher, item); err != nil {
+		return err
+	}
+	setBaseRevision(item, 0)
+
+	return uc.queueItem(entity.OperationAdd, item, nil)
+}
+
+// setBaseRevision sets the revision the update of the item is based on.
+func setBaseRevision(item any, base int64) {
+	switch v := item.(type) {
+	case *entity.Login:
+		v.BaseRevision = base
+	case *entity.Card:
+		v.BaseRevision = base
+	case *entity.SecretNote:
+		v.BaseRevision = base
+	}
+}
+
+// cachedItem returns the cached item of the type by its ID.
+func (uc *ClientUseCase) cachedItem(itemType string, itemID uuid.UUID) (any, error) {
+	switch itemType {
+	case entity.ItemLogins:
+		login, err := uc.repo.GetLoginByID(itemID)
+		return &login, err
+	case entity.ItemCards:
+		card, err := uc.repo.GetCardByID(itemID)
+		return &card, err
+	case entity.ItemNotes:
+		note, err := uc.repo.GetNoteByID(itemID)
+		return &note, err
+	}
+
+	return nil, errUnknownItemType
+}
+
+// openConflict decodes and decrypts both versions of the conflict. Binaries never conflict, a binary
+// is replaced by adding a new one, so a conflict of a binary is rejected with errBinaryConflict.
+func openConflict(cipher *utils.Cipher, conflict *entity.Conflict) (base, local any, err error) {
+	if conflict.Type == entity.ItemBinary {
+		return nil, nil, errBinaryConflict
+	}
+	base, local = newItem(conflict.Type), newItem(conflict.Type)
+	if base == nil {
+		return nil, nil, errUnknownItemType
+	}
+	if err = json.Unmarshal(conflict.Base, base); err != nil {
+		return nil, nil, err
+	}
+	if err = json.Unmarshal(conflict.Local, local); err != nil {
+		return nil, nil, err
+	}
+	if err = decryptItem(cipher, base); err != nil {
+		return nil, nil, err
+	}
+	if err = decryptItem(cipher, local); err != nil {
+		return nil, nil, err
+	}
+
+	return base, local, nil
+}
+
+// newItem returns an empty item of the type.
+func newItem(itemType string) any {
+	switch itemType {
+	case entity.ItemLogins:
+		return &entity.Login{}
+	case entity.ItemCards:
+		return &entity.Card{}
+	case entity.ItemNotes:
+		return &entity.SecretNote{}
+	}
+
+	return nil
+}
+
+// itemInfo returns the type, the ID and the revision of the item.
+func itemInfo(item any) (itemType string, itemID uuid.UUID, revision int64) {
+	switch v := item.(type) {
+	case *entity.Login:
+		return entity.ItemLogins, v.ID, v.Revision
+	case *entity.Card:
+		return entity.ItemCards, v.ID, v.Revision
+	case *entity.SecretNote:
+		return entity.ItemNotes, v.ID, v.Revision
+	case *entity.Binary:
+		return entity.ItemBinary, v.ID, v.Revision
+	}
+
+	return "", uuid.Nil, 0
+}
+
+// metaField is the name of the metadata in a field-level merge, the metadata is merged as a whole.
+const metaField = "meta"
+
+// itemFields returns the fields of the decrypted item a field-level merge works on by name.
+// Binaries have none, see openConflict.
+func itemFields(item any) map[string]*string {
+	switch v := item.(type) {
+	case *entity.Login:
+		return map[string]*string{"name": &v.Name, "uri": &v.URI, "login": &v.Login, "password": &v.Password}
+	case *entity.Card:
+		return map[string]*string{
+			"name":             &v.Name,
+			"brand":            &v.Brand,
+			"number":           &v.Number,
+			"security_code":    &v.SecurityCode,
+			"expiration_month": &v.ExpirationMonth,
+			"expiration_year":  &v.ExpirationYear,
+			"card_holder_name": &v.CardHolderName,
+		}
+	case *entity.SecretNote:
+		return map[string]*string{"name": &v.Name, "note": &v.Note}
+	}
+
+	return nil
+}
+
+// itemMeta returns the metadata of the item.
+func itemMeta(item any) *[]entity.Meta {
+	switch v := item.(type) {
+	case *entity.Login:
+		return &v.Meta
+	case *entity.Card:
+		return &v.Meta
+	case *entity.SecretNote:
+		return &v.Meta
+	}
+
+	return nil
+}
+
+// metaValue returns the names and values of the metadata, the IDs are left out.
+func metaValue(meta []entity.Meta) string {
+	pairs := make([]string, len(meta))
+	for index := range meta {
+		pairs[index] = fmt.Sprintf("%q=%q", meta[index].Name, meta[index].Value)
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// changedFields returns the names of the fields of the decrypted item that differ from the base version.
+func changedFields(base, item any) []string {
+	var changed []string
+	baseFields, fields := itemFields(base), itemFields(item)
+	for name, value := range fields {
+		if *value != *baseFields[name] {
+			changed = append(changed, name)
+		}
+	}
+	if metaValue(*itemMeta(item)) != metaValue(*itemMeta(base)) {
+		changed = append(changed, metaField)
+	}
+	sort.Strings(changed)
+
+	return changed
+}
+
+// mergeItems merges the fields changed in the local version into the remote one, both decrypted
+// and based on the base version. It returns the fields changed on both sides to different values;
+// the remote version is changed only when there are none.
+func mergeItems(base, local, remote any) (conflicting []string) {
+	localFields, remoteFields, baseFields := itemFields(local), itemFields(remote), itemFields(base)
+	changed := changedFields(base, local)
+	for _, name := range changed {
+		if name == metaField {
+			remoteMeta := metaValue(*itemMeta(remote))
+			if remoteMeta != metaValue(*itemMeta(base)) && remoteMeta != metaValue(*itemMeta(local)) {
+				conflicting = append(conflicting, name)
+			}
+			continue
+		}
+		if *remoteFields[name] != *baseFields[name] && *remoteFields[name] != *localFields[name] {
+			conflicting = append(conflicting, name)
+		}
+	}
+	if len(conflicting) > 0 {
+		return conflicting
+	}
+
+	for _, name := range changed {
+		if name == metaField {
+			*itemMeta(remote) = *itemMeta(local)
+			continue
+		}
+		*remoteFields[name] = *localFields[name]
+	}
+
+	return nil
+}
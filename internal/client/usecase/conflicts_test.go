@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+func TestMergeItems(t *testing.T) {
+	base := entity.Login{Name: "mail", URI: "mail.example.com", Login: "user", Password: "old",
+		Meta: []entity.Meta{{Name: "tag", Value: "work"}}}
+	with := func(edit func(login *entity.Login)) *entity.Login {
+		login := base
+		login.Meta = append([]entity.Meta(nil), base.Meta...)
+		edit(&login)
+		return &login
+	}
+
+	tests := []struct {
+		name        string
+		local       *entity.Login
+		remote      *entity.Login
+		expected    *entity.Login
+		conflicting []string
+	}{
+		{
+			name:     "disjoint fields",
+			local:    with(func(login *entity.Login) { login.Password = "new" }),
+			remote:   with(func(login *entity.Login) { login.URI = "example.com" }),
+			expected: with(func(login *entity.Login) { login.Password, login.URI = "new", "example.com" }),
+		},
+		{
+			name:     "local metadata and remote field",
+			local:    with(func(login *entity.Login) { login.Meta[0].Value = "home" }),
+			remote:   with(func(login *entity.Login) { login.Name = "Mail" }),
+			expected: with(func(login *entity.Login) { login.Name, login.Meta[0].Value = "Mail", "home" }),
+		},
+		{
+			name:     "same field changed to the same value",
+			local:    with(func(login *entity.Login) { login.Password = "new" }),
+			remote:   with(func(login *entity.Login) { login.Password = "new" }),
+			expected: with(func(login *entity.Login) { login.Password = "new" }),
+		},
+		{
+			name:        "same field changed to different values",
+			local:       with(func(login *entity.Login) { login.Password, login.Name = "local", "Mail" }),
+			remote:      with(func(login *entity.Login) { login.Password = "remote" }),
+			expected:    with(func(login *entity.Login) { login.Password = "remote" }),
+			conflicting: []string{"password"},
+		},
+		{
+			name:        "metadata changed on both sides",
+			local:       with(func(login *entity.Login) { login.Meta[0].Value = "home" }),
+			remote:      with(func(login *entity.Login) { login.Meta = nil }),
+			expected:    with(func(login *entity.Login) { login.Meta = nil }),
+			conflicting: []string{metaField},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseCopy := base
+			conflicting := mergeItems(&baseCopy, tt.local, tt.remote)
+			require.Equal(t, tt.conflicting, conflicting)
+			require.Equal(t, tt.expected, tt.remote)
+		})
+	}
+}
+
+// conflictStorage fakes the cache of a login changed locally and deleted on another device.
+type conflictStorage struct {
+	ClientRepo
+	queued    []entity.Operation
+	conflicts map[uuid.UUID]bool
+}
+
+func (s *conflictStorage) GetLoginByID(uuid.UUID) (entity.Login, error) {
+	return entity.Login{}, errors.New("record not found")
+}
+
+func (s *conflictStorage) QueueOperation(op *entity.Operation) error {
+	s.queued = append(s.queued, *op)
+	return nil
+}
+
+func (s *conflictStorage) DelConflict(itemID uuid.UUID) error {
+	delete(s.conflicts, itemID)
+	return nil
+}
+
+// sealedConflict returns the conflict of the login with both versions encrypted.
+func sealedConflict(t *testing.T, cipher *utils.Cipher, base, local entity.Login) entity.Conflict {
+	t.Helper()
+
+	require.NoError(t, encryptItem(cipher, &base))
+	require.NoError(t, encryptItem(cipher, &local))
+	baseData, err := json.Marshal(base)
+	require.NoError(t, err)
+	localData, err := json.Marshal(local)
+	require.NoError(t, err)
+
+	return entity.Conflict{ItemID: local.ID, Type: entity.ItemLogins, Base: baseData, Local: localData}
+}
+
+func TestUpdateOfDeletedItem(t *testing.T) {
+	cipher := testCipher("secretKey")
+	itemID := uuid.New()
+	base := entity.Login{ID: itemID, Name: "mail", Password: "old", Revision: 3}
+	local := base
+	local.Password = "new"
+
+	conflict := sealedConflict(t, cipher, base, local)
+	storage := &conflictStorage{conflicts: map[uuid.UUID]bool{itemID: true}}
+	uc := &ClientUseCase{repo: storage}
+
+	merged, err := uc.mergeConflict(cipher, &conflict)
+	require.NoError(t, err)
+	require.False(t, merged)
+	require.Empty(t, storage.queued)
+	require.True(t, storage.conflicts[itemID])
+
+	require.NoError(t, uc.resolveConflict(cipher, &conflict, KeepLocal))
+	require.Len(t, storage.queued, 1)
+	require.Equal(t, entity.OperationAdd, storage.queued[0].Action)
+	require.NotContains(t, storage.conflicts, itemID)
+
+	var added entity.Login
+	require.NoError(t, json.Unmarshal(storage.queued[0].Item, &added))
+	require.NotEqual(t, itemID, added.ID)
+	require.NoError(t, decryptItem(cipher, &added))
+	require.Equal(t, "new", added.Password)
+}
+
+func TestBinaryConflict(t *testing.T) {
+	cipher := testCipher("secretKey")
+	conflict := entity.Conflict{ItemID: uuid.New(), Type: entity.ItemBinary, Base: []byte(`{}`), Local: []byte(`{}`)}
+	storage := &conflictStorage{conflicts: map[uuid.UUID]bool{conflict.ItemID: true}}
+	uc := &ClientUseCase{repo: storage}
+
+	_, err := uc.mergeConflict(cipher, &conflict)
+	require.ErrorIs(t, err, errBinaryConflict)
+	require.ErrorIs(t, uc.resolveConflict(cipher, &conflict, KeepLocal), errBinaryConflict)
+	require.Empty(t, storage.queued)
+}
@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+	"github.com/nextlag/keeper/internal/utils/errs"
+)
+
+// rekeyAttempts limits how many times the vault is staged again when it changes during a rotation.
+const rekeyAttempts = 3
+
+// RotateDataKey re-encrypts the whole vault with a new random data key.
+// Every item is re-encrypted locally and staged on the server, then the server swaps
+// the vault and the wrapped key in one transaction. A failure before the swap leaves
+// the vault under the old data key, so an interrupted run can simply be repeated.
+func (uc *ClientUseCase) RotateDataKey(userPassword string) {
+	if !uc.verifyPassword(userPassword) {
+		color.Red("Password verification failed")
+		return
+	}
+
+	if _, err := uc.unlockVault(userPassword); err != nil {
+		color.Red("Failed to prepare encryption: %v", err)
+		return
+	}
+
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization failed: %v", err)
+		return
+	}
+
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		color.Red("Failed to get current user: %v", err)
+		return
+	}
+
+	// The server copy of the wrapped key is authoritative, another device may have rotated it.
+	wrappedKey, err := uc.clientAPI.GetDataKey(accessToken)
+	if err != nil {
+		color.Red("Failed to get the data key: %v", err)
+		return
+	}
+
+	master := utils.NewCipher(userPassword, uc.kdfParams(user.Email))
+	oldCipher := master
+	if wrappedKey != "" {
+		dataKey, err := utils.UnwrapDataKey(wrappedKey, master)
+		if err != nil {
+			color.Red("Failed to unwrap the data key: %v", err)
+			return
+		}
+		oldCipher = master.WithDataKey(dataKey)
+	}
+
+	if _, err = uc.replaceDataKey(accessToken, userPassword, oldCipher); err != nil {
+		color.Red("Failed to rotate the data key, the vault has not been changed: %v", err)
+		return
+	}
+	color.Green("Data key rotated, other devices have to log in again")
+}
+
+// fetchDataKey returns the wrapped data key from the server and keeps it locally.
+// A vault without a data key is moved to a new one first.
+func (uc *ClientUseCase) fetchDataKey(accessToken, userPassword string) (string, error) {
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := uc.clientAPI.GetDataKey(accessToken)
+	if err != nil {
+		return "", fmt.Errorf("GetDataKey - %w", err)
+	}
+	if wrappedKey == "" {
+		color.Yellow("The vault has no data key yet, re-encrypting it with a new one")
+		return uc.replaceDataKey(accessToken, userPassword, utils.NewCipher(userPassword, uc.kdfParams(user.Email)))
+	}
+
+	if err = uc.repo.UpdateUserDataKey(user.Email, wrappedKey); err != nil {
+		return "", fmt.Errorf("UpdateUserDataKey - %w", err)
+	}
+
+	return wrappedKey, nil
+}
+
+// replaceDataKey re-encrypts the vault from the old cipher to a new random data key,
+// commits it on the server with the new key wrapped by the password and reloads the local storage.
+// A new recovery key is issued for the new data key. It returns the new wrapped key.
+func (uc *ClientUseCase) replaceDataKey(accessToken, userPassword string, oldCipher *utils.Cipher) (string, error) {
+	// The queued changes are encrypted with the old cipher, they have to reach the server first.
+	if err := uc.checkOutbox(); err != nil {
+		return "", err
+	}
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := utils.NewDataKey()
+	if err != nil {
+		return "", err
+	}
+	wrappedKey, err := dataKey.Wrap(utils.NewCipher(userPassword, uc.kdfParams(user.Email)))
+	if err != nil {
+		return "", err
+	}
+
+	// The staged copy is rejected when an item has changed since it was fetched, it is staged again then.
+	for attempt := 1; ; attempt++ {
+		if err = uc.stageRekey(accessToken, oldCipher, utils.NewDataKeyCipher(dataKey)); err != nil {
+			return "", err
+		}
+		err = uc.clientAPI.RotateDataKey(accessToken, utils.AuthHash(userPassword, user.Email), wrappedKey)
+		if !errors.Is(err, errs.ErrRekeyIncomplete) || attempt == rekeyAttempts {
+			break
+		}
+		color.Yellow("The vault has changed while it was re-encrypted, re-encrypting it again")
+	}
+	if err != nil {
+		return "", fmt.Errorf("RotateDataKey - %w", err)
+	}
+	// The rotation has dropped the recovery key, which wraps the old data key.
+	if err = uc.issueRecoveryKey(accessToken, userPassword, user.Email, dataKey); err != nil {
+		color.Red("Failed to set a recovery key, regenerate it with `recovery-key`: %v", err)
+	}
+
+	if err = uc.repo.UpdateUserDataKey(user.Email, wrappedKey); err != nil {
+		return "", fmt.Errorf("UpdateUserDataKey - %w", err)
+	}
+	if err = uc.startSession(userPassword); err != nil {
+		return "", err
+	}
+	uc.loadChanges(accessToken)
+
+	return wrappedKey, nil
+}
+
+// stageRekey re-encrypts every item and binary file of the vault with the new cipher
+// and uploads them to the server staging area. Nothing is staged partially:
+// any value that fails to decrypt aborts the whole run.
+func (uc *ClientUseCase) stageRekey(accessToken string, oldCipher, newCipher *utils.Cipher) error {
+	var (
+		rekey entity.Rekey
+		err   error
+	)
+
+	if rekey.Logins, err = uc.clientAPI.GetLogins(accessToken); err != nil {
+		return fmt.Errorf("GetLogins - %w", err)
+	}
+	for index := range rekey.Logins {
+		if err = rekeyItem(oldCipher, newCipher, &rekey.Logins[index]); err != nil {
+			return fmt.Errorf("login %v - %w", rekey.Logins[index].ID, err)
+		}
+	}
+
+	if rekey.Cards, err = uc.clientAPI.GetCards(accessToken); err != nil {
+		return fmt.Errorf("GetCards - %w", err)
+	}
+	for index := range rekey.Cards {
+		if err = rekeyItem(oldCipher, newCipher, &rekey.Cards[index]); err != nil {
+			return fmt.Errorf("card %v - %w", rekey.Cards[index].ID, err)
+		}
+	}
+
+	if rekey.Notes, err = uc.clientAPI.GetNotes(accessToken); err != nil {
+		return fmt.Errorf("GetNotes - %w", err)
+	}
+	for index := range rekey.Notes {
+		if err = rekeyItem(oldCipher, newCipher, &rekey.Notes[index]); err != nil {
+			return fmt.Errorf("note %v - %w", rekey.Notes[index].ID, err)
+		}
+	}
+
+	if rekey.Binaries, err = uc.clientAPI.GetBinaries(accessToken); err != nil {
+		return fmt.Errorf("GetBinaries - %w", err)
+	}
+	for index := range rekey.Binaries {
+		binary := &rekey.Binaries[index]
+		if err = uc.stageRekeyBinary(accessToken, oldCipher, newCipher, binary); err != nil {
+			return fmt.Errorf("binary %v - %w", binary.ID, err)
+		}
+		if err = rekeyItem(oldCipher, newCipher, binary); err != nil {
+			return fmt.Errorf("binary %v - %w", binary.ID, err)
+		}
+	}
+
+	if err = uc.clientAPI.StageRekey(accessToken, &rekey); err != nil {
+		return fmt.Errorf("StageRekey - %w", err)
+	}
+
+	return nil
+}
+
+// stageRekeyBinary streams the binary from the server through decryption with the old cipher
+// and encryption with the new one back to the server staging area.
+func (uc *ClientUseCase) stageRekeyBinary(accessToken string, oldCipher, newCipher *utils.Cipher, binary *entity.Binary) error {
+	body, err := uc.clientAPI.DownloadBinary(accessToken, binary)
+	if err != nil {
+		return fmt.Errorf("DownloadBinary - %w", err)
+	}
+	defer body.Close()
+
+	plain, err := oldCipher.DecryptStream(body)
+	if err != nil {
+		return err
+	}
+	encrypted := newCipher.EncryptReader(plain)
+	defer encrypted.Close()
+
+	if err = uc.clientAPI.StageRekeyBinary(accessToken, binary, encrypted); err != nil {
+		return fmt.Errorf("StageRekeyBinary - %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+)
+
+// ListDevices prints out the devices the user is signed in on.
+func (uc *ClientUseCase) ListDevices() {
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization check failed for user with provided password: %v", err)
+		return
+	}
+
+	devices, err := uc.clientAPI.GetDevices(accessToken)
+	if err != nil {
+		color.Red("Failed to get devices: %v", err)
+		return
+	}
+
+	color.Yellow("Users devices:")
+	yellow := color.New(color.FgYellow).SprintFunc()
+	for _, device := range devices {
+		current := ""
+		if device.Current {
+			current = color.GreenString(" (this device)")
+		}
+		fmt.Printf("ID: %s name: %s platform: %s first seen: %s last seen: %s last ip: %s%s\n",
+			yellow(device.ID),
+			yellow(device.Name),
+			yellow(device.Platform),
+			yellow(device.CreatedAt.Local().Format(time.DateTime)),
+			yellow(device.LastUsedAt.Local().Format(time.DateTime)),
+			yellow(device.LastIP),
+			current)
+	}
+	fmt.Printf("Total %s devices\n", yellow(len(devices)))
+}
+
+// RevokeDevice signs the user out on the device, its tokens stop working at once.
+// Revoking this device logs the user out locally as well.
+func (uc *ClientUseCase) RevokeDevice(deviceID string) {
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization check failed for user with provided password: %v", err)
+		return
+	}
+	deviceUUID, err := uuid.Parse(deviceID)
+	if err != nil {
+		color.Red("Error parsing device ID %s: %v", deviceID, err)
+		return
+	}
+
+	devices, err := uc.clientAPI.GetDevices(accessToken)
+	if err != nil {
+		color.Red("Failed to get devices: %v", err)
+		return
+	}
+	var current bool
+	for _, device := range devices {
+		if device.ID == deviceUUID {
+			current = device.Current
+		}
+	}
+
+	if err = uc.clientAPI.RevokeDevice(accessToken, deviceID); err != nil {
+		color.Red("Error revoking device %s: %v", deviceID, err)
+		return
+	}
+	color.Green("Device %q revoked successfully", deviceID)
+
+	if current {
+		color.Yellow("This device has been revoked, logging out")
+		uc.dropSession()
+	}
+}
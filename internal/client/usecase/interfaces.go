@@ -1,11 +1,16 @@
 package usecase
 
 import (
+	"io"
+	"time"
+
 	"github.com/google/uuid"
 
 	"github.com/nextlag/keeper/internal/client/usecase/repo/models"
+	"github.com/nextlag/keeper/internal/client/usecase/session"
 	"github.com/nextlag/keeper/internal/client/usecase/viewsets"
 	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
 )
 
 type (
@@ -14,40 +19,71 @@ type (
 		InitDB()
 
 		Register(user *entity.User)
-		Login(user *entity.User)
+		Login(user *entity.User, promptCode func() (string, error))
 		Logout()
-		GetTempPass() (string, error)
+		DeleteAccount(userPassword string, promptCode func() (string, error))
+
+		EnableTOTP(promptCode func() (string, error))
+		DisableTOTP(user *entity.User, recoveryCode string)
+
+		Unlock(userPassword string)
+		Lock()
+		OpenVault() error
+
+		AddCard(card *entity.Card)
+		ShowCard(cardID string)
+		EditCard(cardID string, edit func(card *entity.Card))
+		DelCard(cardID string)
+
+		AddLogin(login *entity.Login)
+		ShowLogin(loginID string)
+		EditLogin(loginID string, edit func(login *entity.Login))
+		DelLogin(loginID string)
 
-		AddCard(userPassword string, card *entity.Card)
-		ShowCard(userPassword, cardID string)
-		DelCard(userPassword, cardID string)
+		AddNote(note *entity.SecretNote)
+		ShowNote(noteID string)
+		EditNote(noteID string, edit func(note *entity.SecretNote))
+		DelNote(noteID string)
 
-		AddLogin(userPassword string, login *entity.Login)
-		ShowLogin(userPassword, loginID string)
-		DelLogin(userPassword, loginID string)
+		AddBinary(binary *entity.Binary)
+		DelBinary(binaryID string)
+		GetBinary(getBinaryID, filePath string)
 
-		AddNote(userPassword string, note *entity.SecretNote)
-		ShowNote(userPassword, noteID string)
-		DelNote(userPassword, noteID string)
+		Conflicts(itemID, keep string)
 
-		AddBinary(userPassword string, binary *entity.Binary)
-		DelBinary(userPassword, binaryID string)
-		GetBinary(userPassword, getBinaryID, filePath string)
+		ReencryptVault(userPassword string)
+		ChangePassword(oldPassword, newPassword string)
+		RotateDataKey(userPassword string)
+
+		RegenerateRecoveryKey(userPassword string)
+		Recover(email, recoveryKey, newPassword string)
+		SplitRecoveryKey(userPassword string, shares, threshold int)
+		CombineRecoveryKey(email string, promptShare func(number int) (string, error), promptPassword func() (string, error))
+
+		ListDevices()
+		RevokeDevice(deviceID string)
+
+		ListTokens()
+		CreateToken(name string, validFor time.Duration, scope entity.TokenScope)
+		RevokeToken(tokenID string)
 	}
 
 	ClientRepo interface {
 		MigrateDB()
 
 		AddUser(user *entity.User) error
-		AddTempPass(user *entity.User) error
+		UpdateUserPassword(user *entity.User) error
+		UpdateUserDataKey(email, dataKey string) error
 		UpdateUserToken(user *entity.User, token *entity.JWT) error
 		DropUserToken(email string) error
 		RemoveUsers()
-		RemoveTempUser()
+		WipeDB() error
 		UserExistsByEmail(email string) bool
 		GetUserPasswordHash() (string, error)
 		GetSavedAccessToken() (string, error)
-		GetTempUser() (*models.TempUser, error)
+		GetSavedTokens() (entity.JWT, error)
+		GetCurrentUser() (*models.User, error)
+		SetCacheKey(email string, cacheKey *utils.DataKey) (bool, error)
 
 		AddLogin(*entity.Login) error
 		SaveLogins([]entity.Login) error
@@ -72,27 +108,80 @@ type (
 		AddBinary(*entity.Binary) error
 		GetBinaryByID(binarydID uuid.UUID) (entity.Binary, error)
 		DelBinary(binaryID uuid.UUID) error
+
+		GetSyncCursor() (int64, error)
+		ApplyChanges(changes *entity.Changes) error
+
+		AddConflict(conflict *entity.Conflict) error
+		GetConflicts() ([]entity.Conflict, error)
+		GetConflict(itemID uuid.UUID) (entity.Conflict, error)
+		DelConflict(itemID uuid.UUID) error
+
+		QueueOperation(op *entity.Operation) error
+		GetOperations() ([]entity.Operation, error)
+		MarkOperationSent(key string) error
+		DelOperation(key string) error
+		ResetSyncCursor() error
 	}
 
 	ClientAPI interface {
 		Login(user *entity.User) (entity.JWT, error)
-		Register(user *entity.User) error
+		UpgradeAuth(user *entity.User, authHash string) (entity.JWT, error)
+		Register(user *entity.User, keys *entity.VaultKeys) error
+		Logout(token entity.JWT) error
+		GetUserInfo(accessToken string) (entity.User, error)
+		DeleteAccount(accessToken, password, code string) error
+
+		SignInTOTP(email, challenge, code string) (entity.JWT, error)
+		EnrollTOTP(accessToken string) (entity.TOTPEnrollment, error)
+		ConfirmTOTP(accessToken, code string) (entity.RecoveryCodes, error)
+		DisableTOTP(user *entity.User, recoveryCode string) error
 
 		AddCard(accessToken string, card *entity.Card) error
+		UpdateCard(accessToken string, card *entity.Card) error
 		GetCards(accessToken string) ([]entity.Card, error)
 		DelCard(accessToken, cardID string) error
 
 		AddLogin(accessToken string, login *entity.Login) error
+		UpdateLogin(accessToken string, login *entity.Login) error
 		GetLogins(accessToken string) ([]entity.Login, error)
 		DelLogin(accessToken, loginID string) error
 
 		GetNotes(accessToken string) ([]entity.SecretNote, error)
 		AddNote(accessToken string, note *entity.SecretNote) error
+		UpdateNote(accessToken string, note *entity.SecretNote) error
 		DelNote(accessToken, noteID string) error
 
 		GetBinaries(accessToken string) ([]entity.Binary, error)
-		AddBinary(accessToken string, binary *entity.Binary, tmpFilePath string) error
+		AddBinary(accessToken string, binary *entity.Binary, file io.Reader) error
 		DelBinary(accessToken, binaryID string) error
-		DownloadBinary(accessToken, outpuFilePath string, binary *entity.Binary) error
+		DownloadBinary(accessToken string, binary *entity.Binary) (io.ReadCloser, error)
+
+		GetChanges(accessToken string, since int64) (entity.Changes, error)
+		ReplayOperation(accessToken string, op *entity.Operation, file io.Reader) error
+
+		StageRekey(accessToken string, rekey *entity.Rekey) error
+		StageRekeyBinary(accessToken string, binary *entity.Binary, file io.Reader) error
+		GetDataKey(accessToken string) (string, error)
+		ChangePassword(accessToken, oldPassword, newPassword, dataKey string) error
+		RotateDataKey(accessToken, password, dataKey string) error
+
+		SetRecoveryKey(accessToken string, recoveryKey *entity.RecoveryKey) error
+		GetRecoveryKey(email, recoveryAuth string) (string, error)
+		RecoverAccount(recovery *entity.Recovery) error
+
+		GetDevices(accessToken string) ([]entity.Session, error)
+		RevokeDevice(accessToken, deviceID string) error
+
+		GetPersonalTokens(accessToken string) ([]entity.PersonalToken, error)
+		CreatePersonalToken(accessToken string, token *entity.PersonalToken) error
+		RevokePersonalToken(accessToken, tokenID string) error
+	}
+
+	// ClientSession - storage of the unlocked vault session.
+	ClientSession interface {
+		Save(session *session.Session) error
+		Load() (*session.Session, error)
+		Remove() error
 	}
 )
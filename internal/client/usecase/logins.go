@@ -7,51 +7,34 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/nextlag/keeper/internal/entity"
-	"github.com/nextlag/keeper/internal/utils"
 )
 
-// loadLogins loads logins using the API and saves them to the repository.
-func (uc *ClientUseCase) loadLogins(accessToken string) {
-	logins, err := uc.clientAPI.GetLogins(accessToken)
+// AddLogin adds a new login for the user to the local cache, it is uploaded by the next sync.
+func (uc *ClientUseCase) AddLogin(login *entity.Login) {
+	_, err := uc.authorisationCheck()
 	if err != nil {
-		color.Red("Error fetching logins with access token %s: %v", accessToken, err)
-		return
-	}
-
-	if err = uc.repo.SaveLogins(logins); err != nil {
-		color.Red("Error saving logins to repository: %v", err)
+		color.Red("Authorization check failed for user with provided password: %v", err)
 		return
 	}
-	color.Green("Loaded %v logins successfully", len(logins))
-}
-
-// AddLogin adds a new login for the user.
-func (uc *ClientUseCase) AddLogin(userPassword string, login *entity.Login) {
-	accessToken, err := uc.authorisationCheck(userPassword)
+	cipher, err := uc.vaultCipher()
 	if err != nil {
-		color.Red("Authorization check failed for user with provided password: %v", err)
+		color.Red("Failed to prepare encryption: %v", err)
 		return
 	}
-	uc.encryptLogin(userPassword, login)
-
-	if err = uc.clientAPI.AddLogin(accessToken, login); err != nil {
-		color.Red("Error adding login %q with access token %s: %v", login.Name, accessToken, err)
+	if err = encryptItem(cipher, login); err != nil {
+		color.Red("Failed to encrypt login %q: %v", login.Name, err)
 		return
 	}
 
-	if err = uc.repo.AddLogin(login); err != nil {
+	if err = uc.queueItem(entity.OperationAdd, login, nil); err != nil {
 		color.Red("Error adding login %q to repository: %v", login.Name, err)
 		return
 	}
-	color.Green("Login %q added successfully, ID: %v", login.Name, login.ID)
+	color.Green("Login %q added locally, ID: %v, it is uploaded by the next `sync`", login.Name, login.ID)
 }
 
 // ShowLogin displays the login by its ID.
-func (uc *ClientUseCase) ShowLogin(userPassword, loginID string) {
-	if !uc.verifyPassword(userPassword) {
-		color.Red("Password verification failed")
-		return
-	}
+func (uc *ClientUseCase) ShowLogin(loginID string) {
 	loginUUID, err := uuid.Parse(loginID)
 	if err != nil {
 		color.Red("Error parsing login ID %s: %v", loginID, err)
@@ -64,7 +47,15 @@ func (uc *ClientUseCase) ShowLogin(userPassword, loginID string) {
 		return
 	}
 
-	uc.decryptLogin(userPassword, &login)
+	cipher, err := uc.vaultCipher()
+	if err != nil {
+		color.Red("Failed to prepare decryption: %v", err)
+		return
+	}
+	if err = decryptItem(cipher, &login); err != nil {
+		color.Red("Failed to decrypt login %s: %v", loginID, err)
+		return
+	}
 	yellow := color.New(color.FgYellow).SprintFunc()
 	fmt.Printf("ID: %s\nName: %s\nURI: %s\nLogin: %s\nPassword: %s\nMeta: %v\n",
 		yellow(login.ID),
@@ -76,21 +67,31 @@ func (uc *ClientUseCase) ShowLogin(userPassword, loginID string) {
 	)
 }
 
-// encryptLogin encrypts the login and password using the user's password.
-func (uc *ClientUseCase) encryptLogin(userPassword string, login *entity.Login) {
-	login.Login = utils.Encrypt(userPassword, login.Login)
-	login.Password = utils.Encrypt(userPassword, login.Password)
-}
+// EditLogin changes the login by its ID with edit in the local cache, the next sync uploads it
+// based on the cached revision.
+func (uc *ClientUseCase) EditLogin(loginID string, edit func(login *entity.Login)) {
+	_, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization check failed for user with provided password: %v", err)
+		return
+	}
+	loginUUID, err := uuid.Parse(loginID)
+	if err != nil {
+		color.Red("Error parsing login ID %s: %v", loginID, err)
+		return
+	}
 
-// decryptLogin decrypts the login and password using the user's password.
-func (uc *ClientUseCase) decryptLogin(userPassword string, login *entity.Login) {
-	login.Login = utils.Decrypt(userPassword, login.Login)
-	login.Password = utils.Decrypt(userPassword, login.Password)
+	login, err := uc.repo.GetLoginByID(loginUUID)
+	if err != nil {
+		color.Red("Error fetching login with ID %s from repository: %v", loginID, err)
+		return
+	}
+	uc.editItem(&login, func() { edit(&login) })
 }
 
-// DelLogin deletes a login by its ID.
-func (uc *ClientUseCase) DelLogin(userPassword, loginID string) {
-	accessToken, err := uc.authorisationCheck(userPassword)
+// DelLogin deletes a login by its ID from the local cache, the deletion is uploaded by the next sync.
+func (uc *ClientUseCase) DelLogin(loginID string) {
+	_, err := uc.authorisationCheck()
 	if err != nil {
 		color.Red("Authorization check failed for user with provided password: %v", err)
 		return
@@ -101,14 +102,9 @@ func (uc *ClientUseCase) DelLogin(userPassword, loginID string) {
 		return
 	}
 
-	if err = uc.repo.DelLogin(loginUUID); err != nil {
+	if err = uc.queueDelete(entity.ItemLogins, loginUUID); err != nil {
 		color.Red("Error deleting login with ID %s from repository: %v", loginID, err)
 		return
 	}
-
-	if err = uc.clientAPI.DelLogin(accessToken, loginID); err != nil {
-		color.Red("Error deleting login %s with access token %s: %v", loginID, accessToken, err)
-		return
-	}
-	color.Green("Login %q removed successfully", loginID)
+	color.Green("Login %q removed locally, the deletion is uploaded by the next `sync`", loginID)
 }
@@ -7,51 +7,34 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/nextlag/keeper/internal/entity"
-	"github.com/nextlag/keeper/internal/utils"
 )
 
-// loadNotes loads notes using the API and saves them to the repository.
-func (uc *ClientUseCase) loadNotes(accessToken string) {
-	notes, err := uc.clientAPI.GetNotes(accessToken)
+// AddNote adds a new note for the user to the local cache, it is uploaded by the next sync.
+func (uc *ClientUseCase) AddNote(note *entity.SecretNote) {
+	_, err := uc.authorisationCheck()
 	if err != nil {
-		color.Red("Error while fetching notes with access token %s: %v", accessToken, err)
-		return
-	}
-
-	if err = uc.repo.SaveNotes(notes); err != nil {
-		color.Red("Error while saving notes to repository: %v", err)
+		color.Red("Authorization check failed for user with provided password: %v", err)
 		return
 	}
-	color.Green("Loaded %v notes successfully", len(notes))
-}
-
-// AddNote adds a new note for the user.
-func (uc *ClientUseCase) AddNote(userPassword string, note *entity.SecretNote) {
-	accessToken, err := uc.authorisationCheck(userPassword)
+	cipher, err := uc.vaultCipher()
 	if err != nil {
-		color.Red("Authorization check failed for user with provided password: %v", err)
+		color.Red("Failed to prepare encryption: %v", err)
 		return
 	}
-	uc.encryptNote(userPassword, note)
-
-	if err = uc.clientAPI.AddNote(accessToken, note); err != nil {
-		color.Red("Error while adding note %q with access token %s: %v", note.Name, accessToken, err)
+	if err = encryptItem(cipher, note); err != nil {
+		color.Red("Failed to encrypt note %q: %v", note.Name, err)
 		return
 	}
 
-	if err = uc.repo.AddNote(note); err != nil {
+	if err = uc.queueItem(entity.OperationAdd, note, nil); err != nil {
 		color.Red("Error while adding note %q to repository: %v", note.Name, err)
 		return
 	}
-	color.Green("Note %q added successfully, ID: %v", note.Name, note.ID)
+	color.Green("Note %q added locally, ID: %v, it is uploaded by the next `sync`", note.Name, note.ID)
 }
 
 // ShowNote displays a note by its ID.
-func (uc *ClientUseCase) ShowNote(userPassword, noteID string) {
-	if !uc.verifyPassword(userPassword) {
-		color.Red("Password verification failed")
-		return
-	}
+func (uc *ClientUseCase) ShowNote(noteID string) {
 	noteUUID, err := uuid.Parse(noteID)
 	if err != nil {
 		color.Red("Error parsing note ID %s: %v", noteID, err)
@@ -64,7 +47,15 @@ func (uc *ClientUseCase) ShowNote(userPassword, noteID string) {
 		return
 	}
 
-	uc.decryptNote(userPassword, &note)
+	cipher, err := uc.vaultCipher()
+	if err != nil {
+		color.Red("Failed to prepare decryption: %v", err)
+		return
+	}
+	if err = decryptItem(cipher, &note); err != nil {
+		color.Red("Failed to decrypt note %s: %v", noteID, err)
+		return
+	}
 	yellow := color.New(color.FgYellow).SprintFunc()
 	fmt.Printf("ID: %s\nName: %s\nNote: %s\nMeta: %v\n",
 		yellow(note.ID),
@@ -74,19 +65,31 @@ func (uc *ClientUseCase) ShowNote(userPassword, noteID string) {
 	)
 }
 
-// encryptNote encrypts the note using the user's password.
-func (uc *ClientUseCase) encryptNote(userPassword string, note *entity.SecretNote) {
-	note.Note = utils.Encrypt(userPassword, note.Note)
-}
+// EditNote changes the note by its ID with edit in the local cache, the next sync uploads it
+// based on the cached revision.
+func (uc *ClientUseCase) EditNote(noteID string, edit func(note *entity.SecretNote)) {
+	_, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization check failed for user with provided password: %v", err)
+		return
+	}
+	noteUUID, err := uuid.Parse(noteID)
+	if err != nil {
+		color.Red("Error parsing note ID %s: %v", noteID, err)
+		return
+	}
 
-// decryptNote decrypts the note using the user's password.
-func (uc *ClientUseCase) decryptNote(userPassword string, note *entity.SecretNote) {
-	note.Note = utils.Decrypt(userPassword, note.Note)
+	note, err := uc.repo.GetNoteByID(noteUUID)
+	if err != nil {
+		color.Red("Error fetching note with ID %s from repository: %v", noteID, err)
+		return
+	}
+	uc.editItem(&note, func() { edit(&note) })
 }
 
-// DelNote deletes a note by its ID.
-func (uc *ClientUseCase) DelNote(userPassword, noteID string) {
-	accessToken, err := uc.authorisationCheck(userPassword)
+// DelNote deletes a note by its ID from the local cache, the deletion is uploaded by the next sync.
+func (uc *ClientUseCase) DelNote(noteID string) {
+	_, err := uc.authorisationCheck()
 	if err != nil {
 		color.Red("Authorization check failed for user with provided password: %v", err)
 		return
@@ -97,15 +100,10 @@ func (uc *ClientUseCase) DelNote(userPassword, noteID string) {
 		return
 	}
 
-	if err = uc.repo.DelNote(noteUUID); err != nil {
+	if err = uc.queueDelete(entity.ItemNotes, noteUUID); err != nil {
 		color.Red("Error while deleting note with ID %s from repository: %v", noteID, err)
 		return
 	}
 
-	if err = uc.clientAPI.DelNote(accessToken, noteID); err != nil {
-		color.Red("Error while deleting note %s with access token %s: %v", noteID, accessToken, err)
-		return
-	}
-
-	color.Green("Note %q removed successfully", noteID)
+	color.Green("Note %q removed locally, the deletion is uploaded by the next `sync`", noteID)
 }
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+)
+
+var errPendingOperations = errors.New("local changes have not been synced yet, run `sync` first")
+
+// queueItem saves the encrypted item to the local cache and queues its upload for the next sync.
+// An update carries the encrypted version it is based on.
+func (uc *ClientUseCase) queueItem(action string, item any, base []byte) error {
+	itemType, itemID, _ := itemInfo(item)
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	return uc.repo.QueueOperation(&entity.Operation{
+		Key:    uuid.NewString(),
+		Action: action,
+		Type:   itemType,
+		ItemID: itemID,
+		Item:   data,
+		Base:   base,
+	})
+}
+
+// queueDelete deletes the item from the local cache and queues its deletion for the next sync.
+// The spooled file of a binary is removed, an added binary folded away with its deletion is never uploaded.
+func (uc *ClientUseCase) queueDelete(itemType string, itemID uuid.UUID) error {
+	err := uc.repo.QueueOperation(&entity.Operation{
+		Key:    uuid.NewString(),
+		Action: entity.OperationDelete,
+		Type:   itemType,
+		ItemID: itemID,
+	})
+	if err == nil && itemType == entity.ItemBinary {
+		uc.removeSpool(itemID)
+	}
+
+	return err
+}
+
+// syncVault replays the queued operations and then fetches the changes from the server.
+// The changes are fetched only once every operation has been delivered, so a local change
+// is never overwritten. Conflicts merged on the way are queued and delivered as well.
+func (uc *ClientUseCase) syncVault(accessToken string) {
+	if !uc.replayOutbox(accessToken) {
+		return
+	}
+	uc.loadChanges(accessToken)
+	if uc.mergeConflicts() > 0 && uc.replayOutbox(accessToken) {
+		uc.loadChanges(accessToken)
+	}
+}
+
+// replayOutbox replays the queued operations on the server in the order they were made and reports
+// every operation that fails. A stale update is kept as a conflict; an operation the server rejects is
+// dropped and the whole cache is fetched again to undo it locally. When the server cannot be reached
+// the replay stops and reports false, the operations left are replayed by the next sync.
+func (uc *ClientUseCase) replayOutbox(accessToken string) bool {
+	ops, err := uc.repo.GetOperations()
+	if err != nil {
+		color.Red("Error reading queued operations: %v", err)
+		return false
+	}
+	if len(ops) == 0 {
+		return true
+	}
+
+	var replayed, rejected int
+	for index := range ops {
+		op := &ops[index]
+		if err = uc.repo.MarkOperationSent(op.Key); err != nil {
+			color.Red("Error marking the operation %s sent: %v", op.Key, err)
+			return false
+		}
+		err = uc.replayOperation(accessToken, op)
+		switch {
+		case err == nil:
+			replayed++
+		case errors.Is(err, errs.ErrRevisionConflict) && op.Type == entity.ItemBinary:
+			rejected++
+			color.Red("Failed to %s %s %s, the change is dropped: %v", op.Action, op.Type, op.ItemID, errBinaryConflict)
+		case errors.Is(err, errs.ErrRevisionConflict):
+			conflict := entity.Conflict{ItemID: op.ItemID, Type: op.Type, Base: op.Base, Local: op.Item}
+			if err = uc.repo.AddConflict(&conflict); err != nil {
+				color.Red("Error saving the conflict of item %s: %v", op.ItemID, err)
+				return false
+			}
+			color.Yellow("Item %s has been changed on another device, merging the changes", op.ItemID)
+		case errors.Is(err, errs.ErrOperationRejected):
+			rejected++
+			color.Red("Failed to %s %s %s, the change is dropped: %v", op.Action, op.Type, op.ItemID, err)
+		default:
+			color.Red("Failed to %s %s %s: %v", op.Action, op.Type, op.ItemID, err)
+			color.Yellow("%v local changes are left for the next sync", len(ops)-index)
+			return false
+		}
+
+		if err = uc.repo.DelOperation(op.Key); err != nil {
+			color.Red("Error removing the replayed operation %s: %v", op.Key, err)
+			return false
+		}
+	}
+
+	if rejected > 0 {
+		if err = uc.repo.ResetSyncCursor(); err != nil {
+			color.Red("Error resetting the sync cursor: %v", err)
+		}
+	}
+	color.Green("Uploaded %v of %v local changes", replayed, len(ops))
+
+	return true
+}
+
+// replayOperation replays the operation on the server. The encrypted file of an added binary is
+// read from the spool directory and removed once it has been uploaded.
+func (uc *ClientUseCase) replayOperation(accessToken string, op *entity.Operation) error {
+	if op.Type != entity.ItemBinary || op.Action != entity.OperationAdd {
+		return uc.clientAPI.ReplayOperation(accessToken, op, nil)
+	}
+
+	file, err := os.Open(uc.spoolPath(op.ItemID))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errs.ErrOperationRejected, err)
+	}
+	defer file.Close()
+
+	if err = uc.clientAPI.ReplayOperation(accessToken, op, file); err != nil {
+		return err
+	}
+	uc.removeSpool(op.ItemID)
+
+	return nil
+}
+
+// checkOutbox returns errPendingOperations while local changes wait to be synced.
+func (uc *ClientUseCase) checkOutbox() error {
+	ops, err := uc.repo.GetOperations()
+	if err != nil {
+		return err
+	}
+	if len(ops) > 0 {
+		return errPendingOperations
+	}
+
+	return nil
+}
+
+// spoolPath returns the path of the encrypted file of a binary waiting to be uploaded.
+func (uc *ClientUseCase) spoolPath(binaryID uuid.UUID) string {
+	return filepath.Join(uc.cfg.FilesStorage.ClientLocation, binaryID.String())
+}
+
+// spoolBinary writes the encrypted file of the binary to the spool directory until it is uploaded.
+func (uc *ClientUseCase) spoolBinary(binaryID uuid.UUID, encrypted io.Reader) error {
+	if err := os.MkdirAll(uc.cfg.FilesStorage.ClientLocation, 0o700); err != nil {
+		return fmt.Errorf("os.MkdirAll - %w", err)
+	}
+
+	spool, err := os.OpenFile(uc.spoolPath(binaryID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile - %w", err)
+	}
+	if _, err = io.Copy(spool, encrypted); err != nil {
+		spool.Close()
+		uc.removeSpool(binaryID)
+		return fmt.Errorf("io.Copy - %w", err)
+	}
+	if err = spool.Close(); err != nil {
+		uc.removeSpool(binaryID)
+		return fmt.Errorf("spool.Close - %w", err)
+	}
+
+	return nil
+}
+
+// removeSpool removes the encrypted file of the binary from the spool directory, if it is there.
+func (uc *ClientUseCase) removeSpool(binaryID uuid.UUID) {
+	if err := os.Remove(uc.spoolPath(binaryID)); err != nil && !os.IsNotExist(err) {
+		color.Yellow("Failed to remove the spooled file of binary %s: %v", binaryID, err)
+	}
+}
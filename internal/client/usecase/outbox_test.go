@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/nextlag/keeper/config/client"
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// outboxServer fakes a server storing the response to every idempotency key it has applied.
+// The response to the first request with lostResponseKey is lost on the way back.
+type outboxServer struct {
+	ClientAPI
+	applied         map[string]int
+	lostResponseKey string
+}
+
+func (s *outboxServer) ReplayOperation(_ string, op *entity.Operation, _ io.Reader) error {
+	if _, seen := s.applied[op.Key]; seen {
+		return nil
+	}
+	s.applied[op.Key]++
+	if op.Key == s.lostResponseKey {
+		s.lostResponseKey = ""
+		return errors.New("connection reset by peer")
+	}
+
+	return nil
+}
+
+// outboxStorage fakes the queue of operations.
+type outboxStorage struct {
+	ClientRepo
+	ops  []entity.Operation
+	sent map[string]bool
+}
+
+func (s *outboxStorage) GetOperations() ([]entity.Operation, error) {
+	return append([]entity.Operation(nil), s.ops...), nil
+}
+
+func (s *outboxStorage) QueueOperation(op *entity.Operation) error {
+	for index := range s.ops {
+		if s.ops[index].ItemID == op.ItemID && !s.sent[s.ops[index].Key] &&
+			s.ops[index].Action == entity.OperationAdd && op.Action == entity.OperationDelete {
+			s.ops = append(s.ops[:index], s.ops[index+1:]...)
+			return nil
+		}
+	}
+	s.ops = append(s.ops, *op)
+	return nil
+}
+
+func (s *outboxStorage) MarkOperationSent(key string) error {
+	s.sent[key] = true
+	return nil
+}
+
+func (s *outboxStorage) DelOperation(key string) error {
+	for index := range s.ops {
+		if s.ops[index].Key == key {
+			s.ops = append(s.ops[:index], s.ops[index+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestReplayOutboxWithSeenKey(t *testing.T) {
+	ops := []entity.Operation{
+		{Key: "k1", Action: entity.OperationAdd, Type: entity.ItemLogins, ItemID: uuid.New(), Item: []byte(`{}`)},
+		{Key: "k2", Action: entity.OperationDelete, Type: entity.ItemNotes, ItemID: uuid.New()},
+	}
+	server := &outboxServer{applied: map[string]int{}, lostResponseKey: "k1"}
+	storage := &outboxStorage{ops: ops, sent: map[string]bool{}}
+	uc := &ClientUseCase{clientAPI: server, repo: storage}
+
+	require.False(t, uc.replayOutbox("token"))
+	require.Equal(t, ops, storage.ops)
+	require.True(t, storage.sent["k1"])
+	require.False(t, storage.sent["k2"])
+
+	require.True(t, uc.replayOutbox("token"))
+	require.Empty(t, storage.ops)
+	require.Equal(t, map[string]int{"k1": 1, "k2": 1}, server.applied)
+}
+
+func TestQueueDeleteRemovesSpool(t *testing.T) {
+	uc := &ClientUseCase{
+		repo: &outboxStorage{sent: map[string]bool{}},
+		cfg:  &config.Config{FilesStorage: &config.FilesStorage{ClientLocation: t.TempDir()}},
+	}
+	binaryID := uuid.New()
+	require.NoError(t, uc.spoolBinary(binaryID, strings.NewReader("encrypted")))
+	require.NoError(t, uc.queueItem(entity.OperationAdd, &entity.Binary{ID: binaryID, FileName: "file.txt"}, nil))
+
+	require.NoError(t, uc.queueDelete(entity.ItemBinary, binaryID))
+
+	ops, err := uc.repo.GetOperations()
+	require.NoError(t, err)
+	require.Empty(t, ops)
+	_, err = os.Stat(uc.spoolPath(binaryID))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
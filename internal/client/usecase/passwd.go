@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"github.com/fatih/color"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+// ChangePassword changes the master password. The vault is sealed with the data key,
+// so only the data key is re-wrapped with the new password; the server swaps the wrapped key
+// and the hash of the auth hash in one transaction. A vault without a data key is moved to one first.
+func (uc *ClientUseCase) ChangePassword(oldPassword, newPassword string) {
+	if !uc.verifyPassword(oldPassword) {
+		color.Red("Password verification failed")
+		return
+	}
+
+	if _, err := uc.unlockVault(oldPassword); err != nil {
+		color.Red("Failed to prepare encryption: %v", err)
+		return
+	}
+
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization failed: %v", err)
+		return
+	}
+
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		color.Red("Failed to get current user: %v", err)
+		return
+	}
+
+	wrappedKey, err := uc.fetchDataKey(accessToken, oldPassword)
+	if err != nil {
+		color.Red("Failed to get the data key, the password has not been changed: %v", err)
+		return
+	}
+	dataKey, err := utils.UnwrapDataKey(wrappedKey, utils.NewCipher(oldPassword, uc.kdfParams(user.Email)))
+	if err != nil {
+		color.Red("Failed to unwrap the data key, the password has not been changed: %v", err)
+		return
+	}
+	newWrappedKey, err := dataKey.Wrap(utils.NewCipher(newPassword, uc.kdfParams(user.Email)))
+	if err != nil {
+		color.Red("Failed to wrap the data key, the password has not been changed: %v", err)
+		return
+	}
+
+	oldAuthHash := utils.AuthHash(oldPassword, user.Email)
+	newAuthHash := utils.AuthHash(newPassword, user.Email)
+	if err = uc.clientAPI.ChangePassword(accessToken, oldAuthHash, newAuthHash, newWrappedKey); err != nil {
+		color.Red("Failed to change the password, it has not been changed: %v", err)
+		return
+	}
+	color.Green("Master password changed")
+
+	if err = uc.repo.UpdateUserPassword(&entity.User{Email: user.Email, Password: newPassword}); err != nil {
+		color.Red("Failed to update the local password, log in again with the new one: %v", err)
+		return
+	}
+	if err = uc.repo.UpdateUserDataKey(user.Email, newWrappedKey); err != nil {
+		color.Red("Failed to save the data key, log in again with the new password: %v", err)
+		return
+	}
+	if err = uc.startSession(newPassword); err != nil {
+		color.Red("Failed to unlock the vault: %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+// RegenerateRecoveryKey replaces the recovery key of the user with a new one and shows it once.
+// The server keeps only the data key wrapped by the latest recovery key, so the previous one stops working.
+func (uc *ClientUseCase) RegenerateRecoveryKey(userPassword string) {
+	accessToken, email, dataKey, ok := uc.recoveryDataKey(userPassword)
+	if !ok {
+		return
+	}
+
+	if err := uc.issueRecoveryKey(accessToken, userPassword, email, dataKey); err != nil {
+		color.Red("Failed to set a new recovery key, the previous one still works: %v", err)
+	}
+}
+
+// SplitRecoveryKey replaces the recovery key of the user with a new one split into shares,
+// any threshold of them recover it. The shares are shown once and the recovery key itself is not,
+// the previous recovery key stops working.
+func (uc *ClientUseCase) SplitRecoveryKey(userPassword string, shares, threshold int) {
+	key, err := utils.NewRecoveryKey()
+	if err != nil {
+		color.Red("Failed to generate a recovery key: %v", err)
+		return
+	}
+	keyShares, err := utils.SplitSecret(key, shares, threshold)
+	if err != nil {
+		color.Red("Failed to split the recovery key: %v", err)
+		return
+	}
+
+	accessToken, email, dataKey, ok := uc.recoveryDataKey(userPassword)
+	if !ok {
+		return
+	}
+	if err = uc.setRecoveryKey(accessToken, userPassword, email, dataKey, key); err != nil {
+		color.Red("Failed to set a new recovery key, the previous one still works: %v", err)
+		return
+	}
+
+	color.Yellow("Recovery key shares, any %d of %d recover the vault, they are shown only once:", threshold, shares)
+	for index := range keyShares {
+		color.Yellow("Share %d: %s", keyShares[index].Index, keyShares[index])
+	}
+	color.Yellow("They set a new master password with `recovery combine` if the current one is forgotten")
+}
+
+// recoveryDataKey checks the password of the logged-in user and returns the access token,
+// the email and the data key of the user. Failures are reported, ok is false then.
+func (uc *ClientUseCase) recoveryDataKey(userPassword string) (accessToken, email string, dataKey *utils.DataKey, ok bool) {
+	if !uc.verifyPassword(userPassword) {
+		color.Red("Password verification failed")
+		return "", "", nil, false
+	}
+
+	if _, err := uc.unlockVault(userPassword); err != nil {
+		color.Red("Failed to prepare encryption: %v", err)
+		return "", "", nil, false
+	}
+
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization failed: %v", err)
+		return "", "", nil, false
+	}
+
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		color.Red("Failed to get current user: %v", err)
+		return "", "", nil, false
+	}
+
+	wrappedKey, err := uc.fetchDataKey(accessToken, userPassword)
+	if err != nil {
+		color.Red("Failed to get the data key: %v", err)
+		return "", "", nil, false
+	}
+	dataKey, err = utils.UnwrapDataKey(wrappedKey, utils.NewCipher(userPassword, uc.kdfParams(user.Email)))
+	if err != nil {
+		color.Red("Failed to unwrap the data key: %v", err)
+		return "", "", nil, false
+	}
+
+	return accessToken, user.Email, dataKey, true
+}
+
+// Recover sets a new master password with the recovery key when the old one is forgotten.
+// The data key is unwrapped with the recovery key and wrapped again with the new password,
+// the vault itself is not re-encrypted. The user is signed out on every device and logs in again.
+func (uc *ClientUseCase) Recover(email, recoveryKey, newPassword string) {
+	key, err := utils.ParseRecoveryKey(recoveryKey)
+	if err != nil {
+		color.Red("Invalid recovery key: %v", err)
+		return
+	}
+
+	uc.recoverWithKey(email, key, newPassword)
+}
+
+// CombineRecoveryKey sets a new master password like Recover does, with the recovery key
+// combined from its shares. The shares are asked by promptShare until the threshold written
+// in them is reached, then the new password is asked by promptPassword.
+func (uc *ClientUseCase) CombineRecoveryKey(
+	email string,
+	promptShare func(number int) (string, error),
+	promptPassword func() (string, error),
+) {
+	var shares []utils.Share
+	for number := 1; len(shares) == 0 || len(shares) < int(shares[0].Threshold); number++ {
+		encoded, err := promptShare(number)
+		if err != nil {
+			color.Red("Share required. Error: %v", err)
+			return
+		}
+		share, err := utils.ParseShare(encoded)
+		if err != nil {
+			color.Red("Invalid share %d: %v", number, err)
+			return
+		}
+		shares = append(shares, share)
+	}
+
+	secret, err := utils.CombineShares(shares)
+	if err != nil {
+		color.Red("Failed to combine the recovery key: %v", err)
+		return
+	}
+
+	newPassword, err := promptPassword()
+	if err != nil {
+		color.Red("New password required. Error: %v", err)
+		return
+	}
+
+	uc.recoverWithKey(email, secret, newPassword)
+}
+
+// recoverWithKey sets the new master password of the user with the recovery key.
+func (uc *ClientUseCase) recoverWithKey(email string, key utils.RecoveryKey, newPassword string) {
+	wrappedKey, err := uc.clientAPI.GetRecoveryKey(email, key.Auth())
+	if err != nil {
+		color.Red("Failed to get the data key: %v", err)
+		return
+	}
+	dataKey, err := utils.UnwrapDataKey(wrappedKey, key.Cipher())
+	if err != nil {
+		color.Red("Failed to unwrap the data key with the recovery key: %v", err)
+		return
+	}
+	newWrappedKey, err := dataKey.Wrap(utils.NewCipher(newPassword, uc.kdfParams(email)))
+	if err != nil {
+		color.Red("Failed to wrap the data key, the password has not been changed: %v", err)
+		return
+	}
+
+	err = uc.clientAPI.RecoverAccount(&entity.Recovery{
+		Email:        email,
+		RecoveryAuth: key.Auth(),
+		Password:     utils.AuthHash(newPassword, email),
+		DataKey:      newWrappedKey,
+	})
+	if err != nil {
+		color.Red("Failed to set the new password, it has not been changed: %v", err)
+		return
+	}
+	color.Green("Master password set with the recovery key")
+
+	if uc.repo.UserExistsByEmail(email) {
+		if err = uc.repo.UpdateUserPassword(&entity.User{Email: email, Password: newPassword}); err != nil {
+			color.Red("Failed to update the local password: %v", err)
+			return
+		}
+		if err = uc.repo.UpdateUserDataKey(email, newWrappedKey); err != nil {
+			color.Red("Failed to save the data key: %v", err)
+			return
+		}
+		// Every session has been revoked on the server.
+		if err = uc.repo.DropUserToken(email); err != nil {
+			color.Red("Failed to drop token for user %s: %v", email, err)
+			return
+		}
+	}
+	color.Yellow("The recovery key still works, regenerate it after logging in if it may have been seen")
+	color.Green("Log in with the new master password")
+}
+
+// issueRecoveryKey generates a new recovery key, stores the data key wrapped by it on the server
+// and shows it once. Nothing but the user keeps the recovery key.
+func (uc *ClientUseCase) issueRecoveryKey(accessToken, userPassword, email string, dataKey *utils.DataKey) error {
+	key, err := utils.NewRecoveryKey()
+	if err != nil {
+		return err
+	}
+	if err = uc.setRecoveryKey(accessToken, userPassword, email, dataKey, key); err != nil {
+		return err
+	}
+	showRecoveryKey(key)
+
+	return nil
+}
+
+// showRecoveryKey prints the recovery key, the only time it is shown.
+func showRecoveryKey(key utils.RecoveryKey) {
+	color.Yellow("Recovery key, write it down and keep it safe, it is shown only once:")
+	color.Yellow("%s", key)
+	color.Yellow("It sets a new master password with `recover` if the current one is forgotten")
+}
+
+// setRecoveryKey stores the data key wrapped by the recovery key on the server.
+func (uc *ClientUseCase) setRecoveryKey(accessToken, userPassword, email string, dataKey *utils.DataKey, key utils.RecoveryKey) error {
+	wrappedKey, err := dataKey.Wrap(key.Cipher())
+	if err != nil {
+		return err
+	}
+
+	err = uc.clientAPI.SetRecoveryKey(accessToken, &entity.RecoveryKey{
+		Password:     utils.AuthHash(userPassword, email),
+		RecoveryAuth: key.Auth(),
+		RecoveryKey:  wrappedKey,
+	})
+	if err != nil {
+		return fmt.Errorf("SetRecoveryKey - %w", err)
+	}
+
+	return nil
+}
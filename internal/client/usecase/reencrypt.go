@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+// ReencryptVault re-encrypts the whole vault into the current ciphertext format.
+// Values stored in the legacy headerless format or sealed with outdated KDF parameters
+// are decrypted and sealed again, values stored in plaintext are encrypted.
+// Changed items are uploaded to the server and saved to the local storage.
+func (uc *ClientUseCase) ReencryptVault(userPassword string) {
+	if !uc.verifyPassword(userPassword) {
+		color.Red("Password verification failed")
+		return
+	}
+
+	// Legacy values can only be opened with the password itself, so the session key is not enough.
+	cipher, err := uc.unlockVault(userPassword)
+	if err != nil {
+		color.Red("Failed to prepare encryption: %v", err)
+		return
+	}
+
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization failed: %v", err)
+		return
+	}
+	// The items are re-encrypted from the server, the local changes would be overwritten.
+	if err = uc.checkOutbox(); err != nil {
+		color.Red("Failed to re-encrypt the vault: %v", err)
+		return
+	}
+
+	uc.reencryptLogins(accessToken, cipher)
+	uc.reencryptCards(accessToken, cipher)
+	uc.reencryptNotes(accessToken, cipher)
+	uc.reencryptBinaries(accessToken, cipher)
+}
+
+// reencryptLogins re-encrypts user logins on the server and in the repository.
+func (uc *ClientUseCase) reencryptLogins(accessToken string, cipher *utils.Cipher) {
+	logins, err := uc.clientAPI.GetLogins(accessToken)
+	if err != nil {
+		color.Red("Error fetching logins: %v", err)
+		return
+	}
+
+	var upgraded int
+	for index := range logins {
+		login := &logins[index]
+		changed, err := migrateItem(cipher, login)
+		if err != nil {
+			color.Red("Error re-encrypting login %v: %v", login.ID, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		// A login changed on another device meanwhile is not overwritten.
+		login.BaseRevision = login.Revision
+		if err = uc.clientAPI.UpdateLogin(accessToken, login); err != nil {
+			color.Red("Error updating login %v: %v", login.ID, err)
+			continue
+		}
+		upgraded++
+	}
+
+	if err = uc.repo.SaveLogins(logins); err != nil {
+		color.Red("Error saving logins to repository: %v", err)
+		return
+	}
+	color.Green("Re-encrypted %v of %v logins", upgraded, len(logins))
+}
+
+// reencryptCards re-encrypts user cards on the server and in the repository.
+func (uc *ClientUseCase) reencryptCards(accessToken string, cipher *utils.Cipher) {
+	cards, err := uc.clientAPI.GetCards(accessToken)
+	if err != nil {
+		color.Red("Error fetching cards: %v", err)
+		return
+	}
+
+	var upgraded int
+	for index := range cards {
+		card := &cards[index]
+		changed, err := migrateItem(cipher, card)
+		if err != nil {
+			color.Red("Error re-encrypting card %v: %v", card.ID, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		card.BaseRevision = card.Revision
+		if err = uc.clientAPI.UpdateCard(accessToken, card); err != nil {
+			color.Red("Error updating card %v: %v", card.ID, err)
+			continue
+		}
+		upgraded++
+	}
+
+	if err = uc.repo.SaveCards(cards); err != nil {
+		color.Red("Error saving cards to repository: %v", err)
+		return
+	}
+	color.Green("Re-encrypted %v of %v cards", upgraded, len(cards))
+}
+
+// reencryptNotes re-encrypts user notes on the server and in the repository.
+func (uc *ClientUseCase) reencryptNotes(accessToken string, cipher *utils.Cipher) {
+	notes, err := uc.clientAPI.GetNotes(accessToken)
+	if err != nil {
+		color.Red("Error fetching notes: %v", err)
+		return
+	}
+
+	var upgraded int
+	for index := range notes {
+		note := &notes[index]
+		changed, err := migrateItem(cipher, note)
+		if err != nil {
+			color.Red("Error re-encrypting note %v: %v", note.ID, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		note.BaseRevision = note.Revision
+		if err = uc.clientAPI.UpdateNote(accessToken, note); err != nil {
+			color.Red("Error updating note %v: %v", note.ID, err)
+			continue
+		}
+		upgraded++
+	}
+
+	if err = uc.repo.SaveNotes(notes); err != nil {
+		color.Red("Error saving notes to repository: %v", err)
+		return
+	}
+	color.Green("Re-encrypted %v of %v notes", upgraded, len(notes))
+}
+
+// reencryptBinaries re-encrypts user files.
+// The server has no update for file contents, so an upgraded file is uploaded
+// as a new binary and the old one is removed.
+func (uc *ClientUseCase) reencryptBinaries(accessToken string, cipher *utils.Cipher) {
+	binaries, err := uc.clientAPI.GetBinaries(accessToken)
+	if err != nil {
+		color.Red("Error fetching binaries: %v", err)
+		return
+	}
+
+	var upgraded int
+	for index := range binaries {
+		changed, err := uc.reencryptBinary(accessToken, cipher, &binaries[index])
+		if err != nil {
+			color.Red("Error re-encrypting binary %v: %v", binaries[index].ID, err)
+			continue
+		}
+		if changed {
+			upgraded++
+		}
+	}
+
+	color.Green("Re-encrypted %v of %v binaries", upgraded, len(binaries))
+}
+
+// reencryptBinary downloads a binary and replaces it when its file or meta values need an upgrade.
+func (uc *ClientUseCase) reencryptBinary(accessToken string, cipher *utils.Cipher, binary *entity.Binary) (bool, error) {
+	encryptedFile, err := os.CreateTemp("", "keeper-*")
+	if err != nil {
+		return false, fmt.Errorf("os.CreateTemp - %w", err)
+	}
+	defer os.Remove(encryptedFile.Name())
+	defer encryptedFile.Close()
+
+	if err = uc.downloadTo(accessToken, binary, encryptedFile); err != nil {
+		return false, err
+	}
+
+	fileNeedsUpgrade, err := cipher.FileNeedsUpgrade(encryptedFile.Name())
+	if err != nil {
+		return false, err
+	}
+	metaChanged, err := migrateItem(cipher, binary)
+	if err != nil {
+		return false, err
+	}
+	if !fileNeedsUpgrade && !metaChanged {
+		return false, nil
+	}
+
+	if _, err = encryptedFile.Seek(0, io.SeekStart); err != nil {
+		return false, fmt.Errorf("Seek - %w", err)
+	}
+	var upload io.Reader = encryptedFile
+	if fileNeedsUpgrade {
+		plain, err := cipher.DecryptStream(encryptedFile)
+		if err != nil {
+			return false, err
+		}
+		encrypted := cipher.EncryptReader(plain)
+		defer encrypted.Close()
+		upload = encrypted
+	}
+
+	// The upload is a new binary: the server assigns fresh IDs to it and its meta,
+	// the old ones stay with the binary removed below.
+	oldID := binary.ID
+	binary.ID = uuid.Nil
+	for index := range binary.Meta {
+		binary.Meta[index].ID = uuid.Nil
+	}
+	if err = uc.clientAPI.AddBinary(accessToken, binary, upload); err != nil {
+		return false, fmt.Errorf("AddBinary - %w", err)
+	}
+	if err = uc.clientAPI.DelBinary(accessToken, oldID.String()); err != nil {
+		return false, fmt.Errorf("DelBinary - %w", err)
+	}
+
+	if err = uc.repo.DelBinary(oldID); err != nil {
+		return false, fmt.Errorf("repo.DelBinary - %w", err)
+	}
+	if err = uc.repo.AddBinary(binary); err != nil {
+		return false, fmt.Errorf("repo.AddBinary - %w", err)
+	}
+
+	return true, nil
+}
+
+// downloadTo copies the encrypted binary from the server into the file.
+func (uc *ClientUseCase) downloadTo(accessToken string, binary *entity.Binary, file io.Writer) error {
+	body, err := uc.clientAPI.DownloadBinary(accessToken, binary)
+	if err != nil {
+		return fmt.Errorf("DownloadBinary - %w", err)
+	}
+	defer body.Close()
+
+	if _, err = io.Copy(file, body); err != nil {
+		return fmt.Errorf("io.Copy - %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// binaryServer fakes the binary endpoints of the server.
+type binaryServer struct {
+	ClientAPI
+	files   map[uuid.UUID][]byte
+	deleted []string
+}
+
+func (s *binaryServer) DownloadBinary(_ string, binary *entity.Binary) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.files[binary.ID])), nil
+}
+
+func (s *binaryServer) AddBinary(_ string, binary *entity.Binary, file io.Reader) error {
+	if _, ok := s.files[binary.ID]; ok {
+		return errors.New("duplicate key value violates unique constraint")
+	}
+	if binary.ID == uuid.Nil {
+		binary.ID = uuid.New()
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	s.files[binary.ID] = content
+	return nil
+}
+
+func (s *binaryServer) DelBinary(_, binaryID string) error {
+	s.deleted = append(s.deleted, binaryID)
+	delete(s.files, uuid.MustParse(binaryID))
+	return nil
+}
+
+// binaryStorage fakes the local storage of binaries.
+type binaryStorage struct {
+	ClientRepo
+	binaries map[uuid.UUID]entity.Binary
+}
+
+func (s *binaryStorage) AddBinary(binary *entity.Binary) error {
+	s.binaries[binary.ID] = *binary
+	return nil
+}
+
+func (s *binaryStorage) DelBinary(binaryID uuid.UUID) error {
+	delete(s.binaries, binaryID)
+	return nil
+}
+
+func TestReencryptBinaryUploadsUnderNewID(t *testing.T) {
+	cipher := testCipher("secretKey")
+	encrypted, err := io.ReadAll(cipher.EncryptReader(bytes.NewReader([]byte(phrase))))
+	require.NoError(t, err)
+
+	oldID, metaID := uuid.New(), uuid.New()
+	binary := entity.Binary{
+		ID:       oldID,
+		Name:     "file",
+		FileName: "file.txt",
+		Meta:     []entity.Meta{{ID: metaID, Name: "tag", Value: legacyPhrase}},
+	}
+	server := &binaryServer{files: map[uuid.UUID][]byte{oldID: encrypted}}
+	storage := &binaryStorage{binaries: map[uuid.UUID]entity.Binary{oldID: binary}}
+	uc := &ClientUseCase{clientAPI: server, repo: storage}
+
+	changed, err := uc.reencryptBinary("token", cipher, &binary)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	require.NotEqual(t, oldID, binary.ID)
+	require.Equal(t, uuid.Nil, binary.Meta[0].ID)
+	require.Equal(t, []string{oldID.String()}, server.deleted)
+	require.Equal(t, encrypted, server.files[binary.ID])
+	require.NotContains(t, storage.binaries, oldID)
+	require.Contains(t, storage.binaries, binary.ID)
+}
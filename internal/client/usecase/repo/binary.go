@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"github.com/fatih/color"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
@@ -23,6 +24,10 @@ func (r *Repo) LoadBinaries() []viewsets.BinaryForList {
 	binariesViewSet := make([]viewsets.BinaryForList, len(binaries))
 
 	for index := range binaries {
+		if err := r.openRow(&binaries[index]); err != nil {
+			color.Red("Load error %s", err.Error())
+			return nil
+		}
 		binariesViewSet[index].ID = binaries[index].ID
 		binariesViewSet[index].Name = binaries[index].Name
 		binariesViewSet[index].FileName = binaries[index].FileName
@@ -31,16 +36,21 @@ func (r *Repo) LoadBinaries() []viewsets.BinaryForList {
 }
 
 func (r *Repo) SaveBinaries(binaries []entity.Binary) error {
+	return r.saveBinaries(r.db, r.getUserID(), binaries)
+}
+
+// saveBinaries upserts the binaries of the user with their metadata in the database session.
+func (r *Repo) saveBinaries(db *gorm.DB, userID uint, binaries []entity.Binary) error {
 	if len(binaries) == 0 {
 		return nil
 	}
-	userID := r.getUserID()
 	binariesForDB := make([]models.Binary, len(binaries))
 	for index := range binaries {
 		binariesForDB[index].ID = binaries[index].ID
 		binariesForDB[index].Name = binaries[index].Name
 		binariesForDB[index].FileName = binaries[index].FileName
 		binariesForDB[index].UserID = userID
+		binariesForDB[index].Revision = binaries[index].Revision
 		for _, meta := range binaries[index].Meta {
 			binariesForDB[index].Meta = append(binariesForDB[index].Meta,
 				models.MetaBinary{
@@ -50,7 +60,14 @@ func (r *Repo) SaveBinaries(binaries []entity.Binary) error {
 				})
 		}
 	}
-	return r.db.Save(binariesForDB).Error
+
+	for index := range binariesForDB {
+		if err := r.sealRow(&binariesForDB[index]); err != nil {
+			return err
+		}
+	}
+
+	return db.Session(&gorm.Session{FullSaveAssociations: true}).Save(binariesForDB).Error
 }
 
 func (r *Repo) AddBinary(binary *entity.Binary) error {
@@ -61,6 +78,9 @@ func (r *Repo) AddBinary(binary *entity.Binary) error {
 			FileName: binary.FileName,
 			UserID:   r.getUserID(),
 		}
+		if err := r.sealRow(&binaryForSaving); err != nil {
+			return err
+		}
 		if err := tx.Save(&binaryForSaving).Error; err != nil {
 			return err
 		}
@@ -71,6 +91,9 @@ func (r *Repo) AddBinary(binary *entity.Binary) error {
 				BinaryID: binaryForSaving.ID,
 				ID:       meta.ID,
 			}
+			if err := r.sealRow(&metaForBinary); err != nil {
+				return err
+			}
 			if err := tx.Create(&metaForBinary).Error; err != nil {
 				return err
 			}
@@ -87,6 +110,9 @@ func (r *Repo) GetBinaryByID(binaryID uuid.UUID) (binary entity.Binary, err erro
 		Find(&binaryFromDB, binaryID).Error; binaryFromDB.ID == uuid.Nil || err != nil {
 		return binary, errNoteNotFound
 	}
+	if err = r.openRow(&binaryFromDB); err != nil {
+		return binary, err
+	}
 
 	binary.ID = binaryFromDB.ID
 	binary.Name = binaryFromDB.Name
@@ -0,0 +1,217 @@
+package repo
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/nextlag/keeper/internal/client/usecase/repo/models"
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+var errCacheLocked = errors.New("local cache is locked, open the vault first")
+
+// sealedFields returns pointers to every field of the row sealed with the cache key.
+// Secret fields are not listed, they already hold vault ciphertext.
+func sealedFields(row any) []*string {
+	var fields []*string
+
+	switch v := row.(type) {
+	case *models.User:
+		fields = []*string{&v.AccessToken, &v.RefreshToken}
+	case *models.Login:
+		fields = []*string{&v.Name, &v.URI}
+		for index := range v.Meta {
+			fields = append(fields, &v.Meta[index].Name)
+		}
+	case *models.Card:
+		fields = []*string{&v.Name, &v.Brand}
+		for index := range v.Meta {
+			fields = append(fields, &v.Meta[index].Name)
+		}
+	case *models.Note:
+		fields = []*string{&v.Name}
+		for index := range v.Meta {
+			fields = append(fields, &v.Meta[index].Name)
+		}
+	case *models.Binary:
+		fields = []*string{&v.Name, &v.FileName}
+		for index := range v.Meta {
+			fields = append(fields, &v.Meta[index].Name)
+		}
+	case *models.MetaLogin:
+		fields = []*string{&v.Name}
+	case *models.MetaCard:
+		fields = []*string{&v.Name}
+	case *models.MetaNote:
+		fields = []*string{&v.Name}
+	case *models.MetaBinary:
+		fields = []*string{&v.Name}
+	case *models.Conflict:
+		fields = []*string{&v.Base, &v.Local}
+	case *models.Outbox:
+		fields = []*string{&v.Item, &v.Base}
+	}
+
+	return fields
+}
+
+// SetCacheKey sets the key that seals the local cache of the user: item names, URIs, brands,
+// file names, metadata names and tokens. A cache sealed with the key set before is re-sealed
+// with the new one; a cache sealed with an unknown key, or not sealed at all, cannot be opened
+// and is cleared together with the tokens. Cleared reports the latter. A nil key locks the cache.
+func (r *Repo) SetCacheKey(email string, cacheKey *utils.DataKey) (cleared bool, err error) {
+	if cacheKey == nil {
+		r.cacheKey, r.cipher = nil, nil
+		return false, nil
+	}
+
+	var user models.User
+	if err = r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return false, fmt.Errorf("repo - SetCacheKey - %w", err)
+	}
+
+	keyID := hex.EncodeToString(cacheKey.ID)
+	cipher := utils.NewDataKeyCipher(cacheKey)
+	switch {
+	case user.CacheKeyID == keyID:
+	case r.cacheKey != nil && user.CacheKeyID == hex.EncodeToString(r.cacheKey.ID):
+		err = r.reseal(user.ID, cipher, keyID)
+	default:
+		cleared = true
+		err = r.clearCache(user.ID, keyID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("repo - SetCacheKey - %w", err)
+	}
+
+	r.cacheKey, r.cipher = cacheKey, cipher
+	return cleared, nil
+}
+
+// reseal opens every sealed field of the user cache with the current cipher
+// and seals it with the new one in a single transaction.
+func (r *Repo) reseal(userID uint, cipher *utils.Cipher, keyID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var (
+			user     models.User
+			logins   []models.Login
+			cards    []models.Card
+			notes    []models.Note
+			binaries []models.Binary
+		)
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		rows := []any{&user}
+		for _, table := range []any{&logins, &cards, &notes, &binaries} {
+			if err := tx.Preload("Meta").Where("user_id = ?", userID).Find(table).Error; err != nil {
+				return err
+			}
+		}
+		for index := range logins {
+			rows = append(rows, &logins[index])
+		}
+		for index := range cards {
+			rows = append(rows, &cards[index])
+		}
+		for index := range notes {
+			rows = append(rows, &notes[index])
+		}
+		for index := range binaries {
+			rows = append(rows, &binaries[index])
+		}
+		var conflicts []models.Conflict
+		if err := tx.Where("user_id = ?", userID).Find(&conflicts).Error; err != nil {
+			return err
+		}
+		for index := range conflicts {
+			rows = append(rows, &conflicts[index])
+		}
+		var outbox []models.Outbox
+		if err := tx.Where("user_id = ?", userID).Find(&outbox).Error; err != nil {
+			return err
+		}
+		for index := range outbox {
+			rows = append(rows, &outbox[index])
+		}
+
+		for _, row := range rows {
+			for _, field := range sealedFields(row) {
+				plain, err := r.cipher.Decrypt(*field)
+				if err != nil {
+					return err
+				}
+				if *field, err = cipher.Encrypt(plain); err != nil {
+					return err
+				}
+			}
+			if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(row).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&user).Update("cache_key_id", keyID).Error
+	})
+}
+
+// clearCache removes the cached items, the conflicts, the queued operations and the tokens of the user
+// in a single transaction.
+func (r *Repo) clearCache(userID uint, keyID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range itemTables {
+			if err := deleteItems(tx, table, "user_id = ?", userID); err != nil {
+				return err
+			}
+		}
+		// The conflicts and the queued operations are sealed with the unknown key as well.
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.Conflict{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.Outbox{}).Error; err != nil {
+			return err
+		}
+
+		// The items are gone, the next sync fetches them all.
+		return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]any{
+			"access_token":  "",
+			"refresh_token": "",
+			"cache_key_id":  keyID,
+			"sync_cursor":   0,
+		}).Error
+	})
+}
+
+// sealRow seals the cached fields of the row with the cache key.
+func (r *Repo) sealRow(row any) error {
+	if r.cipher == nil {
+		return errCacheLocked
+	}
+	for _, field := range sealedFields(row) {
+		sealed, err := r.cipher.Encrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = sealed
+	}
+
+	return nil
+}
+
+// openRow opens the cached fields of the row with the cache key.
+func (r *Repo) openRow(row any) error {
+	if r.cipher == nil {
+		return errCacheLocked
+	}
+	for _, field := range sealedFields(row) {
+		plain, err := r.cipher.Decrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = plain
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package repo
 import (
 	"errors"
 
+	"github.com/fatih/color"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
@@ -26,6 +27,9 @@ func (r *Repo) AddCard(card *entity.Card) error {
 			ExpirationYear:  card.ExpirationYear,
 			UserID:          r.getUserID(),
 		}
+		if err := r.sealRow(&cardForSaving); err != nil {
+			return err
+		}
 		if err := tx.Save(&cardForSaving).Error; err != nil {
 			return nil
 		}
@@ -36,6 +40,9 @@ func (r *Repo) AddCard(card *entity.Card) error {
 				CardID: cardForSaving.ID,
 				ID:     meta.ID,
 			}
+			if err := r.sealRow(&metaForCard); err != nil {
+				return err
+			}
 			if err := tx.Create(&metaForCard).Error; err != nil {
 				return err
 			}
@@ -46,10 +53,14 @@ func (r *Repo) AddCard(card *entity.Card) error {
 }
 
 func (r *Repo) SaveCards(cards []entity.Card) error {
+	return r.saveCards(r.db, r.getUserID(), cards)
+}
+
+// saveCards upserts the cards of the user with their metadata in the database session.
+func (r *Repo) saveCards(db *gorm.DB, userID uint, cards []entity.Card) error {
 	if len(cards) == 0 {
 		return nil
 	}
-	userID := r.getUserID()
 	cardsForDB := make([]models.Card, len(cards))
 	for index := range cards {
 		cardsForDB[index].ID = cards[index].ID
@@ -61,6 +72,7 @@ func (r *Repo) SaveCards(cards []entity.Card) error {
 		cardsForDB[index].Number = cards[index].Number
 		cardsForDB[index].SecurityCode = cards[index].SecurityCode
 		cardsForDB[index].UserID = userID
+		cardsForDB[index].Revision = cards[index].Revision
 		for _, meta := range cards[index].Meta {
 			cardsForDB[index].Meta = append(cardsForDB[index].Meta, models.MetaCard{
 				Name:   meta.Name,
@@ -71,7 +83,13 @@ func (r *Repo) SaveCards(cards []entity.Card) error {
 		}
 	}
 
-	return r.db.Save(cardsForDB).Error
+	for index := range cardsForDB {
+		if err := r.sealRow(&cardsForDB[index]); err != nil {
+			return err
+		}
+	}
+
+	return db.Session(&gorm.Session{FullSaveAssociations: true}).Save(cardsForDB).Error
 }
 
 func (r *Repo) LoadCards() []viewsets.CardForList {
@@ -88,6 +106,10 @@ func (r *Repo) LoadCards() []viewsets.CardForList {
 	cardsViewSet := make([]viewsets.CardForList, len(cards))
 
 	for index := range cards {
+		if err := r.openRow(&cards[index]); err != nil {
+			color.Red("Load error %s", err.Error())
+			return nil
+		}
 		cardsViewSet[index].ID = cards[index].ID
 		cardsViewSet[index].Name = cards[index].Name
 		cardsViewSet[index].Brand = cards[index].Brand
@@ -104,6 +126,9 @@ func (r *Repo) GetCardByID(cardID uuid.UUID) (card entity.Card, err error) {
 		Find(&cardFromDB, cardID).Error; cardFromDB.ID == uuid.Nil || err != nil {
 		return card, errCardNotFound
 	}
+	if err = r.openRow(&cardFromDB); err != nil {
+		return card, err
+	}
 
 	card.ID = cardFromDB.ID
 	card.Brand = cardFromDB.Brand
@@ -113,6 +138,7 @@ func (r *Repo) GetCardByID(cardID uuid.UUID) (card entity.Card, err error) {
 	card.ExpirationMonth = cardFromDB.ExpirationMonth
 	card.ExpirationYear = cardFromDB.ExpirationYear
 	card.SecurityCode = cardFromDB.SecurityCode
+	card.Revision = cardFromDB.Revision
 
 	for index := range cardFromDB.Meta {
 		card.Meta = append(card.Meta, entity.Meta{
@@ -0,0 +1,109 @@
+package repo
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/nextlag/keeper/internal/client/usecase/repo/models"
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// itemTable is a table of cached items together with the table of their metadata.
+type itemTable struct {
+	itemType    string
+	model, meta any
+	column      string // Column of the metadata referencing the item.
+}
+
+// itemTables of the local cache by item type.
+var itemTables = []itemTable{
+	{entity.ItemLogins, &models.Login{}, &models.MetaLogin{}, "login_id"},
+	{entity.ItemCards, &models.Card{}, &models.MetaCard{}, "card_id"},
+	{entity.ItemNotes, &models.Note{}, &models.MetaNote{}, "note_id"},
+	{entity.ItemBinary, &models.Binary{}, &models.MetaBinary{}, "binary_id"},
+}
+
+// GetSyncCursor returns the revision of the latest server change the cache of the logged-in user has,
+// zero if it has none.
+func (r *Repo) GetSyncCursor() (int64, error) {
+	user, err := r.GetCurrentUser()
+	if err != nil {
+		return 0, err
+	}
+
+	return user.SyncCursor, nil
+}
+
+// ApplyChanges applies the changes from the server to the cache of the logged-in user and moves
+// the cursor past them in a single transaction. Changed items replace the cached ones with their metadata,
+// deleted ones are removed; full changes replace the whole cache.
+func (r *Repo) ApplyChanges(changes *entity.Changes) error {
+	user, err := r.GetCurrentUser()
+	if err != nil {
+		return err
+	}
+
+	removed := make(map[string][]uuid.UUID)
+	for index := range changes.Logins {
+		removed[entity.ItemLogins] = append(removed[entity.ItemLogins], changes.Logins[index].ID)
+	}
+	for index := range changes.Cards {
+		removed[entity.ItemCards] = append(removed[entity.ItemCards], changes.Cards[index].ID)
+	}
+	for index := range changes.Notes {
+		removed[entity.ItemNotes] = append(removed[entity.ItemNotes], changes.Notes[index].ID)
+	}
+	for index := range changes.Binaries {
+		removed[entity.ItemBinary] = append(removed[entity.ItemBinary], changes.Binaries[index].ID)
+	}
+	for _, deleted := range changes.Deleted {
+		removed[deleted.Type] = append(removed[deleted.Type], deleted.ID)
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range itemTables {
+			var err error
+			switch ids := removed[table.itemType]; {
+			case changes.Full:
+				err = deleteItems(tx, table, "user_id = ?", user.ID)
+			case len(ids) > 0:
+				err = deleteItems(tx, table, "user_id = ? AND id IN ?", user.ID, ids)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := r.saveLogins(tx, user.ID, changes.Logins); err != nil {
+			return err
+		}
+		if err := r.saveCards(tx, user.ID, changes.Cards); err != nil {
+			return err
+		}
+		if err := r.saveNotes(tx, user.ID, changes.Notes); err != nil {
+			return err
+		}
+		if err := r.saveBinaries(tx, user.ID, changes.Binaries); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Update("sync_cursor", changes.Cursor).Error
+	})
+	if err != nil {
+		return fmt.Errorf("repo - ApplyChanges - %w", err)
+	}
+
+	return nil
+}
+
+// deleteItems deletes the items of the table matching the query together with their metadata.
+func deleteItems(tx *gorm.DB, table itemTable, query string, args ...any) error {
+	ids := tx.Model(table.model).Select("id").Where(query, args...)
+	if err := tx.Unscoped().Where(table.column+" IN (?)", ids).Delete(table.meta).Error; err != nil {
+		return err
+	}
+
+	return tx.Unscoped().Where(query, args...).Delete(table.model).Error
+}
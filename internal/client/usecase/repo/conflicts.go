@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/client/usecase/repo/models"
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+var errConflictNotFound = errors.New("conflict not found")
+
+// AddConflict keeps the conflict of the logged-in user, both versions sealed with the cache key.
+func (r *Repo) AddConflict(conflict *entity.Conflict) error {
+	conflictForSaving := models.Conflict{
+		ItemID: conflict.ItemID,
+		Type:   conflict.Type,
+		Base:   string(conflict.Base),
+		Local:  string(conflict.Local),
+		UserID: r.getUserID(),
+	}
+	if err := r.sealRow(&conflictForSaving); err != nil {
+		return err
+	}
+	if err := r.db.Create(&conflictForSaving).Error; err != nil {
+		return fmt.Errorf("repo - AddConflict - %w", err)
+	}
+
+	return nil
+}
+
+// GetConflicts returns the unresolved conflicts of the logged-in user, oldest first.
+func (r *Repo) GetConflicts() ([]entity.Conflict, error) {
+	var conflictsFromDB []models.Conflict
+	if err := r.db.Where("user_id = ?", r.getUserID()).Order("id").Find(&conflictsFromDB).Error; err != nil {
+		return nil, fmt.Errorf("repo - GetConflicts - %w", err)
+	}
+
+	conflicts := make([]entity.Conflict, len(conflictsFromDB))
+	for index := range conflictsFromDB {
+		if err := r.openRow(&conflictsFromDB[index]); err != nil {
+			return nil, err
+		}
+		conflicts[index] = toConflict(&conflictsFromDB[index])
+	}
+
+	return conflicts, nil
+}
+
+// GetConflict returns the unresolved conflict of the item.
+func (r *Repo) GetConflict(itemID uuid.UUID) (conflict entity.Conflict, err error) {
+	var conflictFromDB models.Conflict
+	if err = r.db.
+		Where("item_id = ? AND user_id = ?", itemID, r.getUserID()).
+		Limit(1).Find(&conflictFromDB).Error; conflictFromDB.ItemID == uuid.Nil || err != nil {
+		return conflict, errConflictNotFound
+	}
+	if err = r.openRow(&conflictFromDB); err != nil {
+		return conflict, err
+	}
+
+	return toConflict(&conflictFromDB), nil
+}
+
+// DelConflict forgets the conflict of the item once it has been resolved.
+func (r *Repo) DelConflict(itemID uuid.UUID) error {
+	return r.db.Unscoped().Where("item_id = ? AND user_id = ?", itemID, r.getUserID()).Delete(&models.Conflict{}).Error
+}
+
+// toConflict converts the opened conflict row to the entity.
+func toConflict(conflict *models.Conflict) entity.Conflict {
+	return entity.Conflict{
+		ItemID: conflict.ItemID,
+		Type:   conflict.Type,
+		Base:   []byte(conflict.Base),
+		Local:  []byte(conflict.Local),
+	}
+}
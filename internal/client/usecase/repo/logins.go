@@ -3,6 +3,7 @@ package repo
 import (
 	"errors"
 
+	"github.com/fatih/color"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
@@ -23,6 +24,9 @@ func (r *Repo) AddLogin(login *entity.Login) error {
 			Password: login.Password,
 			UserID:   r.getUserID(),
 		}
+		if err := r.sealRow(&loginForSaving); err != nil {
+			return err
+		}
 		if err := tx.Save(&loginForSaving).Error; err != nil {
 			return err
 		}
@@ -33,6 +37,9 @@ func (r *Repo) AddLogin(login *entity.Login) error {
 				LoginID: loginForSaving.ID,
 				ID:      meta.ID,
 			}
+			if err := r.sealRow(&metaForLogin); err != nil {
+				return err
+			}
 			if err := tx.Create(&metaForLogin).Error; err != nil {
 				return err
 			}
@@ -43,10 +50,14 @@ func (r *Repo) AddLogin(login *entity.Login) error {
 }
 
 func (r *Repo) SaveLogins(logins []entity.Login) error {
+	return r.saveLogins(r.db, r.getUserID(), logins)
+}
+
+// saveLogins upserts the logins of the user with their metadata in the database session.
+func (r *Repo) saveLogins(db *gorm.DB, userID uint, logins []entity.Login) error {
 	if len(logins) == 0 {
 		return nil
 	}
-	userID := r.getUserID()
 	loginsForDB := make([]models.Login, len(logins))
 	for index := range logins {
 		loginsForDB[index].ID = logins[index].ID
@@ -55,6 +66,7 @@ func (r *Repo) SaveLogins(logins []entity.Login) error {
 		loginsForDB[index].Login = logins[index].Login
 		loginsForDB[index].Password = logins[index].Password
 		loginsForDB[index].UserID = userID
+		loginsForDB[index].Revision = logins[index].Revision
 		for _, meta := range logins[index].Meta {
 			loginsForDB[index].Meta = append(loginsForDB[index].Meta, models.MetaLogin{
 				Name:    meta.Name,
@@ -65,7 +77,13 @@ func (r *Repo) SaveLogins(logins []entity.Login) error {
 		}
 	}
 
-	return r.db.Save(loginsForDB).Error
+	for index := range loginsForDB {
+		if err := r.sealRow(&loginsForDB[index]); err != nil {
+			return err
+		}
+	}
+
+	return db.Session(&gorm.Session{FullSaveAssociations: true}).Save(loginsForDB).Error
 }
 
 func (r *Repo) LoadLogins() []viewsets.LoginForList {
@@ -82,6 +100,10 @@ func (r *Repo) LoadLogins() []viewsets.LoginForList {
 	loginsViewSet := make([]viewsets.LoginForList, len(logins))
 
 	for index := range logins {
+		if err := r.openRow(&logins[index]); err != nil {
+			color.Red("Load error %s", err.Error())
+			return nil
+		}
 		loginsViewSet[index].ID = logins[index].ID
 		loginsViewSet[index].Name = logins[index].Name
 		loginsViewSet[index].URI = logins[index].URI
@@ -98,12 +120,16 @@ func (r *Repo) GetLoginByID(loginID uuid.UUID) (login entity.Login, err error) {
 		Find(&loginFromDB, loginID).Error; loginFromDB.ID == uuid.Nil || err != nil {
 		return login, errLoginNotFound
 	}
+	if err = r.openRow(&loginFromDB); err != nil {
+		return login, err
+	}
 
 	login.ID = loginFromDB.ID
 	login.Login = loginFromDB.Login
 	login.Name = loginFromDB.Name
 	login.Password = loginFromDB.Password
 	login.URI = loginFromDB.URI
+	login.Revision = loginFromDB.Revision
 	for index := range loginFromDB.Meta {
 		login.Meta = append(
 			login.Meta,
@@ -20,4 +20,5 @@ type Binary struct {
 	FileName string
 	UserID   uint
 	Meta     []MetaBinary
+	Revision int64
 }
@@ -25,4 +25,5 @@ type Card struct {
 	SecurityCode    string
 	UserID          uint
 	Meta            []MetaCard `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Revision        int64
 }
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Conflict is a local update rejected by the server, kept until it is merged or resolved by the user.
+type Conflict struct {
+	gorm.Model
+	ItemID uuid.UUID `gorm:"type:uuid;uniqueIndex"`
+	Type   string
+	Base   string // Version the update is based on.
+	Local  string // Locally updated version.
+	UserID uint
+}
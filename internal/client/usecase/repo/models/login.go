@@ -21,4 +21,5 @@ type Login struct {
 	Password string
 	UserID   uint
 	Meta     []MetaLogin `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Revision int64
 }
@@ -14,9 +14,10 @@ type MetaNote struct {
 }
 type Note struct {
 	gorm.Model
-	ID     uuid.UUID `gorm:"type:uuid;primary_key"`
-	Name   string    `gorm:"size:100"`
-	Note   string
-	UserID uint
-	Meta   []MetaNote `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ID       uuid.UUID `gorm:"type:uuid;primary_key"`
+	Name     string    `gorm:"size:100"`
+	Note     string
+	UserID   uint
+	Meta     []MetaNote `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Revision int64
 }
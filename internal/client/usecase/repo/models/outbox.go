@@ -0,0 +1,21 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outbox is a change made to the local cache, kept until it is replayed on the server.
+// The operations are replayed in the order of their IDs.
+type Outbox struct {
+	gorm.Model
+	Key    string `gorm:"uniqueIndex"`
+	Action string
+	Type   string
+	ItemID uuid.UUID `gorm:"type:uuid;index"`
+	Item   string    // Item to add or update.
+	Base   string    // Version an update is based on.
+	UserID uint
+
+	Sent bool // Set once the operation has been sent, the changes made after it are queued separately.
+}
@@ -12,16 +12,14 @@ type User struct {
 	Password     string `gorm:"not null"`
 	AccessToken  string
 	RefreshToken string
+	DataKey      string
+	CacheKeyID   string
+	SyncCursor   int64
 	Cards        []Card  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	Logins       []Login `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	Notes        []Note  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	Binary       []Note  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
-type TempUser struct {
-	gorm.Model
-	Email    string
-	Password string
-}
 
 func (user *User) ToString() string {
 	return fmt.Sprintf("id: %v\nemail: %s", user.ID, user.Email)
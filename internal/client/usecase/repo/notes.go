@@ -3,6 +3,7 @@ package repo
 import (
 	"errors"
 
+	"github.com/fatih/color"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
@@ -21,6 +22,9 @@ func (r *Repo) AddNote(note *entity.SecretNote) error {
 			Note:   note.Note,
 			UserID: r.getUserID(),
 		}
+		if err := r.sealRow(&noteForSaving); err != nil {
+			return err
+		}
 		if err := tx.Save(&noteForSaving).Error; err != nil {
 			return err
 		}
@@ -31,6 +35,9 @@ func (r *Repo) AddNote(note *entity.SecretNote) error {
 				NoteID: noteForSaving.ID,
 				ID:     meta.ID,
 			}
+			if err := r.sealRow(&metaForLogin); err != nil {
+				return err
+			}
 			if err := tx.Create(&metaForLogin).Error; err != nil {
 				return err
 			}
@@ -54,6 +61,10 @@ func (r *Repo) LoadNotes() []viewsets.NoteForList {
 	notesViewSet := make([]viewsets.NoteForList, len(notes))
 
 	for index := range notes {
+		if err := r.openRow(&notes[index]); err != nil {
+			color.Red("Load error %s", err.Error())
+			return nil
+		}
 		notesViewSet[index].ID = notes[index].ID
 		notesViewSet[index].Name = notes[index].Name
 	}
@@ -62,19 +73,38 @@ func (r *Repo) LoadNotes() []viewsets.NoteForList {
 }
 
 func (r *Repo) SaveNotes(notes []entity.SecretNote) error {
+	return r.saveNotes(r.db, r.getUserID(), notes)
+}
+
+// saveNotes upserts the notes of the user with their metadata in the database session.
+func (r *Repo) saveNotes(db *gorm.DB, userID uint, notes []entity.SecretNote) error {
 	if len(notes) == 0 {
 		return nil
 	}
-	userID := r.getUserID()
 	notesForDB := make([]models.Note, len(notes))
 	for index := range notes {
 		notesForDB[index].ID = notes[index].ID
 		notesForDB[index].Name = notes[index].Name
 		notesForDB[index].Note = notes[index].Note
 		notesForDB[index].UserID = userID
+		notesForDB[index].Revision = notes[index].Revision
+		for _, meta := range notes[index].Meta {
+			notesForDB[index].Meta = append(notesForDB[index].Meta, models.MetaNote{
+				Name:   meta.Name,
+				Value:  meta.Value,
+				NoteID: notes[index].ID,
+				ID:     meta.ID,
+			})
+		}
 	}
 
-	return r.db.Save(notesForDB).Error
+	for index := range notesForDB {
+		if err := r.sealRow(&notesForDB[index]); err != nil {
+			return err
+		}
+	}
+
+	return db.Session(&gorm.Session{FullSaveAssociations: true}).Save(notesForDB).Error
 }
 
 func (r *Repo) GetNoteByID(noteID uuid.UUID) (note entity.SecretNote, err error) {
@@ -85,10 +115,14 @@ func (r *Repo) GetNoteByID(noteID uuid.UUID) (note entity.SecretNote, err error)
 		Find(&noteFromDB, noteID).Error; noteFromDB.ID == uuid.Nil || err != nil {
 		return note, errNoteNotFound
 	}
+	if err = r.openRow(&noteFromDB); err != nil {
+		return note, err
+	}
 
 	note.ID = noteFromDB.ID
 	note.Note = noteFromDB.Note
 	note.Name = noteFromDB.Name
+	note.Revision = noteFromDB.Revision
 	for index := range noteFromDB.Meta {
 		note.Meta = append(
 			note.Meta,
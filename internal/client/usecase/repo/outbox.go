@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/nextlag/keeper/internal/client/usecase/repo/models"
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+var (
+	errItemNotFound    = errors.New("item not found")
+	errUnknownItemType = errors.New("unknown item type")
+)
+
+// QueueOperation applies the operation to the cache of the logged-in user and queues it for the next sync
+// in a single transaction. An operation on an item that already has a queued one is folded into it:
+// an item added and then changed is added in its latest version, an item added and then deleted is
+// never sent, an item changed twice is updated once based on the version the first change was based on
+// and a changed item that is deleted is only deleted. The folded operation keeps the key of the queued one.
+// An operation that has been sent may have reached the server already, so nothing is folded into it:
+// a retry with its key would get the stored response without the later change.
+func (r *Repo) QueueOperation(op *entity.Operation) error {
+	userID := r.getUserID()
+	table, ok := itemTableOf(op.Type)
+	if !ok {
+		return errUnknownItemType
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(table.model).Where("user_id = ? AND id = ?", userID, op.ItemID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 && op.Action != entity.OperationAdd {
+			return errItemNotFound
+		}
+		if err := deleteItems(tx, table, "user_id = ? AND id = ?", userID, op.ItemID); err != nil {
+			return err
+		}
+		if op.Action != entity.OperationDelete {
+			if err := r.saveItem(tx, userID, op.Type, op.Item); err != nil {
+				return err
+			}
+		}
+
+		var queued []models.Outbox
+		if err := tx.Where("user_id = ? AND item_id = ?", userID, op.ItemID).
+			Order("id DESC").Limit(1).Find(&queued).Error; err != nil {
+			return err
+		}
+		row := models.Outbox{Key: op.Key, Action: op.Action, Type: op.Type, ItemID: op.ItemID, UserID: userID}
+		if len(queued) > 0 && !queued[0].Sent {
+			if err := r.openRow(&queued[0]); err != nil {
+				return err
+			}
+			row = queued[0]
+			switch {
+			case row.Action == entity.OperationAdd && op.Action == entity.OperationDelete:
+				return tx.Unscoped().Delete(&row).Error
+			case row.Action == entity.OperationAdd:
+			default:
+				row.Action = op.Action
+			}
+		} else {
+			row.Base = string(op.Base)
+		}
+		row.Item = string(op.Item)
+
+		if err := r.sealRow(&row); err != nil {
+			return err
+		}
+
+		return tx.Save(&row).Error
+	})
+	if err != nil {
+		return fmt.Errorf("repo - QueueOperation - %w", err)
+	}
+
+	return nil
+}
+
+// GetOperations returns the queued operations of the logged-in user in the order they are replayed.
+func (r *Repo) GetOperations() ([]entity.Operation, error) {
+	var rows []models.Outbox
+	if err := r.db.Where("user_id = ?", r.getUserID()).Order("id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("repo - GetOperations - %w", err)
+	}
+
+	ops := make([]entity.Operation, len(rows))
+	for index := range rows {
+		if err := r.openRow(&rows[index]); err != nil {
+			return nil, err
+		}
+		ops[index] = entity.Operation{
+			Key:    rows[index].Key,
+			Action: rows[index].Action,
+			Type:   rows[index].Type,
+			ItemID: rows[index].ItemID,
+			Item:   []byte(rows[index].Item),
+			Base:   []byte(rows[index].Base),
+		}
+	}
+
+	return ops, nil
+}
+
+// MarkOperationSent records that the queued operation is about to be sent to the server.
+func (r *Repo) MarkOperationSent(key string) error {
+	return r.db.Model(&models.Outbox{}).Where("key = ? AND user_id = ?", key, r.getUserID()).Update("sent", true).Error
+}
+
+// DelOperation forgets the queued operation once it has been replayed.
+func (r *Repo) DelOperation(key string) error {
+	return r.db.Unscoped().Where("key = ? AND user_id = ?", key, r.getUserID()).Delete(&models.Outbox{}).Error
+}
+
+// ResetSyncCursor drops the sync cursor of the logged-in user, so the next sync fetches every item.
+func (r *Repo) ResetSyncCursor() error {
+	return r.db.Model(&models.User{}).Where("id = ?", r.getUserID()).Update("sync_cursor", 0).Error
+}
+
+// itemTableOf returns the table of the cached items of the type.
+func itemTableOf(itemType string) (itemTable, bool) {
+	for _, table := range itemTables {
+		if table.itemType == itemType {
+			return table, true
+		}
+	}
+
+	return itemTable{}, false
+}
+
+// saveItem saves the encoded item of the type to the cache of the user in the database session.
+func (r *Repo) saveItem(db *gorm.DB, userID uint, itemType string, data []byte) error {
+	switch itemType {
+	case entity.ItemLogins:
+		var login entity.Login
+		if err := json.Unmarshal(data, &login); err != nil {
+			return err
+		}
+		return r.saveLogins(db, userID, []entity.Login{login})
+	case entity.ItemCards:
+		var card entity.Card
+		if err := json.Unmarshal(data, &card); err != nil {
+			return err
+		}
+		return r.saveCards(db, userID, []entity.Card{card})
+	case entity.ItemNotes:
+		var note entity.SecretNote
+		if err := json.Unmarshal(data, &note); err != nil {
+			return err
+		}
+		return r.saveNotes(db, userID, []entity.SecretNote{note})
+	case entity.ItemBinary:
+		var binary entity.Binary
+		if err := json.Unmarshal(data, &binary); err != nil {
+			return err
+		}
+		return r.saveBinaries(db, userID, []entity.Binary{binary})
+	}
+
+	return errUnknownItemType
+}
@@ -0,0 +1,159 @@
+package repo
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/client/usecase/repo/models"
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+// newTestRepo creates a repository in a temporary database with a logged-in user and an opened cache.
+func newTestRepo(t *testing.T) *Repo {
+	t.Helper()
+
+	r := New(filepath.Join(t.TempDir(), "keeper.db"))
+	require.NoError(t, r.db.AutoMigrate(tables...))
+	require.NoError(t, r.db.Create(&models.User{Email: "user@example.com", Password: "hash", AccessToken: "token"}).Error)
+
+	cacheKey, err := utils.NewDataKey()
+	require.NoError(t, err)
+	_, err = r.SetCacheKey("user@example.com", cacheKey)
+	require.NoError(t, err)
+
+	return r
+}
+
+// loginOperation returns an operation on the login with the name as its content.
+func loginOperation(t *testing.T, key, action string, id uuid.UUID, name, base string) entity.Operation {
+	t.Helper()
+
+	op := entity.Operation{Key: key, Action: action, Type: entity.ItemLogins, ItemID: id}
+	if action != entity.OperationDelete {
+		item, err := json.Marshal(entity.Login{ID: id, Name: name})
+		require.NoError(t, err)
+		op.Item = item
+	}
+	if base != "" {
+		op.Base = json.RawMessage(base)
+	}
+
+	return op
+}
+
+func TestQueueOperationFolding(t *testing.T) {
+	id := uuid.New()
+	add := func(t *testing.T, key, name string) entity.Operation {
+		return loginOperation(t, key, entity.OperationAdd, id, name, "")
+	}
+	update := func(t *testing.T, key, name, base string) entity.Operation {
+		return loginOperation(t, key, entity.OperationUpdate, id, name, base)
+	}
+	del := func(t *testing.T, key string) entity.Operation {
+		return loginOperation(t, key, entity.OperationDelete, id, "", "")
+	}
+
+	tests := []struct {
+		name     string
+		cached   bool // The item is in the cache before the operations.
+		ops      func(t *testing.T) []entity.Operation
+		sentKey  string // Key of an operation marked sent before the last one is queued.
+		expected func(t *testing.T) []entity.Operation
+	}{
+		{
+			name: "add and update is the latest add",
+			ops: func(t *testing.T) []entity.Operation {
+				return []entity.Operation{add(t, "k1", "first"), update(t, "k2", "second", `"v1"`)}
+			},
+			expected: func(t *testing.T) []entity.Operation {
+				return []entity.Operation{add(t, "k1", "second")}
+			},
+		},
+		{
+			name: "add and delete is dropped",
+			ops: func(t *testing.T) []entity.Operation {
+				return []entity.Operation{add(t, "k1", "first"), del(t, "k2")}
+			},
+		},
+		{
+			name:   "updates are based on the first base",
+			cached: true,
+			ops: func(t *testing.T) []entity.Operation {
+				return []entity.Operation{update(t, "k1", "first", `"v0"`), update(t, "k2", "second", `"v1"`)}
+			},
+			expected: func(t *testing.T) []entity.Operation {
+				return []entity.Operation{update(t, "k1", "second", `"v0"`)}
+			},
+		},
+		{
+			name:   "update and delete is a delete",
+			cached: true,
+			ops: func(t *testing.T) []entity.Operation {
+				return []entity.Operation{update(t, "k1", "first", `"v0"`), del(t, "k2")}
+			},
+			expected: func(t *testing.T) []entity.Operation {
+				op := del(t, "k1")
+				op.Base = json.RawMessage(`"v0"`)
+				return []entity.Operation{op}
+			},
+		},
+		{
+			name: "nothing is folded into a sent operation",
+			ops: func(t *testing.T) []entity.Operation {
+				return []entity.Operation{add(t, "k1", "first"), update(t, "k2", "second", `"v1"`)}
+			},
+			sentKey: "k1",
+			expected: func(t *testing.T) []entity.Operation {
+				return []entity.Operation{add(t, "k1", "first"), update(t, "k2", "second", `"v1"`)}
+			},
+		},
+		{
+			name: "deletion of a sent add is queued",
+			ops: func(t *testing.T) []entity.Operation {
+				return []entity.Operation{add(t, "k1", "first"), del(t, "k2")}
+			},
+			sentKey: "k1",
+			expected: func(t *testing.T) []entity.Operation {
+				return []entity.Operation{add(t, "k1", "first"), del(t, "k2")}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRepo(t)
+			if tt.cached {
+				item, err := json.Marshal(entity.Login{ID: id, Name: "cached"})
+				require.NoError(t, err)
+				require.NoError(t, r.saveItem(r.db, r.getUserID(), entity.ItemLogins, item))
+			}
+
+			ops := tt.ops(t)
+			for index := range ops {
+				if index == len(ops)-1 && tt.sentKey != "" {
+					require.NoError(t, r.MarkOperationSent(tt.sentKey))
+				}
+				require.NoError(t, r.QueueOperation(&ops[index]))
+			}
+
+			queued, err := r.GetOperations()
+			require.NoError(t, err)
+			var expected []entity.Operation
+			if tt.expected != nil {
+				expected = tt.expected(t)
+			}
+			require.Len(t, queued, len(expected))
+			for index := range expected {
+				require.Equal(t, expected[index].Key, queued[index].Key)
+				require.Equal(t, expected[index].Action, queued[index].Action)
+				require.Equal(t, string(expected[index].Item), string(queued[index].Item))
+				require.Equal(t, string(expected[index].Base), string(queued[index].Base))
+			}
+		})
+	}
+}
@@ -1,18 +1,26 @@
 package repo
 
 import (
+	"fmt"
+
 	"github.com/fatih/color"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
 	"github.com/nextlag/keeper/internal/client/usecase/repo/models"
+	"github.com/nextlag/keeper/internal/utils"
 )
 
 type Repo struct {
-	db *gorm.DB
+	db       *gorm.DB
+	cacheKey *utils.DataKey // Key sealing the local cache, set once the vault is opened.
+	cipher   *utils.Cipher  // Cipher of the cache key.
 }
 
+// legacyTempUsersTable held master passwords in plaintext before vault sessions were introduced.
+const legacyTempUsersTable = "temp_users"
+
 func New(dbFileName string) *Repo {
 	db, err := gorm.Open(sqlite.Open(dbFileName), &gorm.Config{})
 	db.Logger = db.Logger.LogMode(logger.Silent)
@@ -20,24 +28,40 @@ func New(dbFileName string) *Repo {
 		color.Red("Load error %s", err.Error())
 	}
 
+	if db.Migrator().HasTable(legacyTempUsersTable) {
+		if err = db.Migrator().DropTable(legacyTempUsersTable); err != nil {
+			color.Red("Load error %s", err.Error())
+		}
+	}
+
+	// Databases created before the data key, the sealed cache and the revisions lack their columns.
+	if db.Migrator().HasTable(&models.User{}) {
+		if err = db.AutoMigrate(tables...); err != nil {
+			color.Red("Load error %s", err.Error())
+		}
+	}
+
 	return &Repo{
 		db: db,
 	}
 }
 
+// tables of the local cache.
+var tables = []interface{}{
+	&models.User{},
+	&models.Card{},
+	&models.MetaCard{},
+	&models.Login{},
+	&models.MetaLogin{},
+	&models.Note{},
+	&models.MetaNote{},
+	&models.Binary{},
+	&models.MetaBinary{},
+	&models.Conflict{},
+	&models.Outbox{},
+}
+
 func (r *Repo) MigrateDB() {
-	tables := []interface{}{
-		&models.User{},
-		&models.TempUser{},
-		&models.Card{},
-		&models.MetaCard{},
-		&models.Login{},
-		&models.MetaLogin{},
-		&models.Note{},
-		&models.MetaNote{},
-		&models.Binary{},
-		&models.MetaBinary{},
-	}
 	var err error
 	for _, table := range tables {
 
@@ -52,3 +76,17 @@ func (r *Repo) MigrateDB() {
 	color.Green("Initialization status: success")
 	color.Green("You can use keeper")
 }
+
+// WipeDB deletes every row of the local cache, soft deleted ones included, and compacts the database file,
+// so the deleted rows are not left in its free pages.
+func (r *Repo) WipeDB() error {
+	for _, table := range tables {
+		err := r.db.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(table).Error
+		if err != nil {
+			return fmt.Errorf("repo - WipeDB - Delete - %w", err)
+		}
+	}
+	r.cacheKey, r.cipher = nil, nil
+
+	return r.db.Exec("VACUUM").Error
+}
@@ -14,12 +14,8 @@ import (
 func (r *Repo) RemoveUsers() {
 	r.db.Exec("DELETE FROM users")
 }
-func (r *Repo) RemoveTempUser() {
-	r.db.Exec("DELETE FROM temp_users")
-}
 
 func (r *Repo) AddUser(user *entity.User) error {
-	r.RemoveTempUser()
 	hashedPassword, err := utils.HashPassword(user.Password)
 	if err != nil {
 		return fmt.Errorf("repo - AddUser - HashPassword - %w", err)
@@ -28,9 +24,21 @@ func (r *Repo) AddUser(user *entity.User) error {
 	return r.db.Create(&newUser).Error
 }
 
-func (r *Repo) AddTempPass(user *entity.User) error {
-	tempUser := models.TempUser{Email: user.Email, Password: user.Password}
-	return r.db.Create(&tempUser).Error
+func (r *Repo) UpdateUserPassword(user *entity.User) error {
+	hashedPassword, err := utils.HashPassword(user.Password)
+	if err != nil {
+		return fmt.Errorf("repo - UpdateUserPassword - HashPassword - %w", err)
+	}
+
+	return r.db.Model(&models.User{}).
+		Where("email = ?", user.Email).
+		Update("password", hashedPassword).Error
+}
+
+func (r *Repo) UpdateUserDataKey(email, dataKey string) error {
+	return r.db.Model(&models.User{}).
+		Where("email = ?", email).
+		Update("data_key", dataKey).Error
 }
 
 func (r *Repo) UpdateUserToken(user *entity.User, token *entity.JWT) error {
@@ -39,6 +47,9 @@ func (r *Repo) UpdateUserToken(user *entity.User, token *entity.JWT) error {
 	r.db.Where("email", user.Email).First(&existedUser)
 	existedUser.AccessToken = token.AccessToken
 	existedUser.RefreshToken = token.RefreshToken
+	if err := r.sealRow(&existedUser); err != nil {
+		return err
+	}
 
 	return r.db.Save(&existedUser).Error
 }
@@ -67,62 +78,61 @@ func (r *Repo) DropUserToken(email string) error {
 }
 
 func (r *Repo) GetUserPasswordHash() (string, error) {
-	var existedUser models.User
-
-	tempUser, err := r.GetTempUser()
+	user, err := r.GetCurrentUser()
 	if err != nil {
 		return "", err
 	}
 
-	result := r.db.Where("email = ?", tempUser.Email).First(&existedUser)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return "", fmt.Errorf("user with email %s not found", tempUser.Email)
-		}
-		return "", fmt.Errorf("failed to query user with email %s: %v", tempUser.Email, result.Error)
-	}
-
-	return existedUser.Password, nil
+	return user.Password, nil
 }
 
 func (r *Repo) GetSavedAccessToken() (accessToken string, err error) {
-	var user models.User
-
-	tempUser, err := r.GetTempUser()
+	user, err := r.GetCurrentUser()
 	if err != nil {
 		return "", err
 	}
-	result := r.db.Where("email = ?", tempUser.Email).First(&user)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return "", fmt.Errorf("user with email %s not found", tempUser.Email)
-		}
-		return "", result.Error
+	if err = r.openRow(user); err != nil {
+		return "", err
 	}
-	return user.AccessToken, err
+
+	return user.AccessToken, nil
 }
 
-func (r *Repo) getUserID() uint {
-	var user models.User
-	tempUser, err := r.GetTempUser()
+// GetSavedTokens returns the access and refresh tokens of the logged-in user.
+func (r *Repo) GetSavedTokens() (token entity.JWT, err error) {
+	user, err := r.GetCurrentUser()
 	if err != nil {
-		return 0
+		return token, err
 	}
-	result := r.db.Where("email = ?", tempUser.Email).First(&user)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return 0
-		}
+	if err = r.openRow(user); err != nil {
+		return token, err
+	}
+
+	token.AccessToken = user.AccessToken
+	token.RefreshToken = user.RefreshToken
+	return token, nil
+}
+
+func (r *Repo) getUserID() uint {
+	user, err := r.GetCurrentUser()
+	if err != nil {
 		return 0
 	}
 
 	return user.ID
 }
 
-func (r *Repo) GetTempUser() (*models.TempUser, error) {
-	var tempUser models.TempUser
-	if err := r.db.First(&tempUser).Error; err != nil {
-		return nil, err
+// GetCurrentUser returns the logged-in user, the one holding the tokens.
+func (r *Repo) GetCurrentUser() (*models.User, error) {
+	var user models.User
+
+	result := r.db.Where("access_token <> ''").First(&user)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no user is logged in")
+		}
+		return nil, result.Error
 	}
-	return &tempUser, nil
+
+	return &user, nil
 }
@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+// secretField is a secret-bearing field of an item together with the associated data
+// its ciphertext is bound to.
+type secretField struct {
+	name  string
+	value *string
+	ad    []byte
+}
+
+// fieldAD returns the associated data of a field: the item type, the item ID and the field name.
+// A ciphertext moved to another item or field no longer opens.
+func fieldAD(itemType string, itemID uuid.UUID, field string) []byte {
+	return []byte(fmt.Sprintf("keeper:%s:%s:%s", itemType, itemID, field))
+}
+
+// secretFields returns every secret-bearing field of the item, including meta values.
+// Names, brands, URIs and file names stay in plaintext so that the vault can be listed without a key.
+func secretFields(item any) []secretField {
+	var (
+		itemType string
+		itemID   uuid.UUID
+		named    map[string]*string
+		meta     []entity.Meta
+	)
+
+	switch v := item.(type) {
+	case *entity.Login:
+		itemType, itemID, meta = "login", v.ID, v.Meta
+		named = map[string]*string{"login": &v.Login, "password": &v.Password}
+	case *entity.Card:
+		itemType, itemID, meta = "card", v.ID, v.Meta
+		named = map[string]*string{
+			"number":           &v.Number,
+			"security_code":    &v.SecurityCode,
+			"expiration_month": &v.ExpirationMonth,
+			"expiration_year":  &v.ExpirationYear,
+			"card_holder_name": &v.CardHolderName,
+		}
+	case *entity.SecretNote:
+		itemType, itemID, meta = "note", v.ID, v.Meta
+		named = map[string]*string{"note": &v.Note}
+	case *entity.Binary:
+		itemType, itemID, meta = "binary", v.ID, v.Meta
+	}
+
+	fields := make([]secretField, 0, len(named)+len(meta))
+	for name, value := range named {
+		fields = append(fields, secretField{name: name, value: value, ad: fieldAD(itemType, itemID, name)})
+	}
+	for index := range meta {
+		name := "meta:" + meta[index].ID.String()
+		fields = append(fields, secretField{name: name, value: &meta[index].Value, ad: fieldAD(itemType, itemID, name)})
+	}
+
+	return fields
+}
+
+// assignIDs gives the item and its metadata the IDs the ciphertexts are bound to.
+// IDs that are already set are kept; the server stores the item under the same IDs.
+func assignIDs(item any) {
+	var (
+		itemID *uuid.UUID
+		meta   []entity.Meta
+	)
+
+	switch v := item.(type) {
+	case *entity.Login:
+		itemID, meta = &v.ID, v.Meta
+	case *entity.Card:
+		itemID, meta = &v.ID, v.Meta
+	case *entity.SecretNote:
+		itemID, meta = &v.ID, v.Meta
+	case *entity.Binary:
+		itemID, meta = &v.ID, v.Meta
+	default:
+		return
+	}
+
+	if *itemID == uuid.Nil {
+		*itemID = uuid.New()
+	}
+	for index := range meta {
+		if meta[index].ID == uuid.Nil {
+			meta[index].ID = uuid.New()
+		}
+	}
+}
+
+// encryptItem assigns the IDs of a new item and encrypts all its secret fields with the vault cipher,
+// binding every field to the item. The error names the field that failed.
+func encryptItem(cipher *utils.Cipher, item any) error {
+	assignIDs(item)
+	for _, field := range secretFields(item) {
+		encrypted, err := cipher.EncryptBound(*field.value, field.ad)
+		if err != nil {
+			return fmt.Errorf("field %s - %w", field.name, err)
+		}
+		*field.value = encrypted
+	}
+
+	return nil
+}
+
+// decryptItem decrypts all secret fields of the item with the vault cipher.
+// The error names the field that failed and matches one of the utils crypto errors;
+// a field moved from another item or field, or modified, is reported with utils.ErrTampered.
+func decryptItem(cipher *utils.Cipher, item any) error {
+	for _, field := range secretFields(item) {
+		plain, err := cipher.DecryptBound(*field.value, field.ad)
+		if err != nil {
+			return fmt.Errorf("field %s - %w", field.name, err)
+		}
+		*field.value = plain
+	}
+
+	return nil
+}
+
+// migrateItem brings all secret fields of the item to the current ciphertext format.
+// Legacy, outdated and unbound envelopes are sealed again bound to the item; values that are
+// not ciphertext at all are treated as plaintext stored before the fields were encrypted, see openField.
+// It reports whether the item has been changed. On error the item is left untouched.
+func migrateItem(cipher *utils.Cipher, item any) (bool, error) {
+	fields := secretFields(item)
+	sealed := make([]string, len(fields))
+	var changed bool
+	for index, field := range fields {
+		sealed[index] = *field.value
+		if !cipher.NeedsUpgradeBound(*field.value) {
+			continue
+		}
+
+		plain, err := openField(cipher, field)
+		if err != nil {
+			return false, err
+		}
+		if sealed[index], err = cipher.EncryptBound(plain, field.ad); err != nil {
+			return false, fmt.Errorf("field %s - %w", field.name, err)
+		}
+		changed = true
+	}
+
+	for index, field := range fields {
+		*field.value = sealed[index]
+	}
+
+	return changed, nil
+}
+
+// rekeyItem seals all secret fields of the item with the new cipher.
+// The fields are opened with the old cipher the same way migrateItem opens them.
+func rekeyItem(oldCipher, newCipher *utils.Cipher, item any) error {
+	for _, field := range secretFields(item) {
+		plain, err := openField(oldCipher, field)
+		if err != nil {
+			return err
+		}
+		if *field.value, err = newCipher.EncryptBound(plain, field.ad); err != nil {
+			return fmt.Errorf("field %s - %w", field.name, err)
+		}
+	}
+
+	return nil
+}
+
+// openField decrypts the value of a secret field.
+// A value that is not ciphertext is returned as is. An envelope or a well-formed legacy blob that fails
+// to open is an error naming the field: it is sealed with another key or corrupted, and sealing it again
+// as plaintext would lose the secret for good.
+func openField(cipher *utils.Cipher, field secretField) (string, error) {
+	plain, err := cipher.DecryptBound(*field.value, field.ad)
+	if err == nil {
+		return plain, nil
+	}
+	if utils.IsEnvelope(*field.value) || utils.IsLegacyCiphertext(*field.value) {
+		return "", fmt.Errorf("field %s - %w", field.name, err)
+	}
+
+	return *field.value, nil
+}
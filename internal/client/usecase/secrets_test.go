@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+const (
+	phrase = "This is top secret"
+
+	// legacyPhrase is phrase sealed with "secretKey" by the headerless format.
+	legacyPhrase = "nM5dT55UrtBeMq8CSyGEjMIzrrp8NwQTUpxIJCk5Ozs-1k5e_yUcXZ_MKUoa5g=="
+)
+
+// testCipher creates a cipher with cheap key derivation for tests.
+func testCipher(password string) *utils.Cipher {
+	return utils.NewCipher(password, utils.KDFParams{Time: 1, Memory: 1024, Threads: 1, Salt: utils.UserSalt("user@example.com")})
+}
+
+func TestOpenField(t *testing.T) {
+	cipher := testCipher("secretKey")
+	ad := fieldAD("login", uuid.New(), "password")
+	envelope, err := cipher.EncryptBound(phrase, ad)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		cipher    *utils.Cipher
+		value     string
+		expected  string
+		expectErr error
+	}{
+		{
+			name:     "plaintext",
+			cipher:   cipher,
+			value:    phrase,
+			expected: phrase,
+		},
+		{
+			name:     "plaintext that looks like base64",
+			cipher:   cipher,
+			value:    "c2hvcnQ=",
+			expected: "c2hvcnQ=",
+		},
+		{
+			name:     "legacy blob",
+			cipher:   cipher,
+			value:    legacyPhrase,
+			expected: phrase,
+		},
+		{
+			name:      "legacy blob with a wrong key",
+			cipher:    testCipher("wrongKey"),
+			value:     legacyPhrase,
+			expectErr: utils.ErrAuthFailed,
+		},
+		{
+			name:     "envelope",
+			cipher:   cipher,
+			value:    envelope,
+			expected: phrase,
+		},
+		{
+			name:      "envelope with a wrong key",
+			cipher:    testCipher("wrongKey"),
+			value:     envelope,
+			expectErr: utils.ErrAuthFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := tt.value
+			plain, err := openField(tt.cipher, secretField{name: "password", value: &value, ad: ad})
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+				require.ErrorContains(t, err, "field password")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, plain)
+		})
+	}
+}
+
+func TestMigrateItemKeepsUnreadableLegacy(t *testing.T) {
+	login := &entity.Login{ID: uuid.New(), Login: "user", Password: legacyPhrase}
+
+	changed, err := migrateItem(testCipher("wrongKey"), login)
+	require.ErrorIs(t, err, utils.ErrAuthFailed)
+	require.False(t, changed)
+	require.Equal(t, legacyPhrase, login.Password)
+
+	cipher := testCipher("secretKey")
+	changed, err = migrateItem(cipher, login)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.NoError(t, decryptItem(cipher, login))
+	require.Equal(t, phrase, login.Password)
+	require.Equal(t, "user", login.Login)
+}
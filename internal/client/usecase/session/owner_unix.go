@@ -0,0 +1,23 @@
+//go:build !windows
+
+package session
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByUser reports whether the file belongs to the current user.
+func ownedByUser(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
+
+// insecureMode reports whether the permissions let other users read the file
+// or, for a directory, replace its entries.
+func insecureMode(info os.FileInfo) bool {
+	if info.IsDir() {
+		return info.Mode().Perm()&0o022 != 0
+	}
+	return info.Mode().Perm()&0o077 != 0
+}
@@ -0,0 +1,15 @@
+package session
+
+import "os"
+
+// ownedByUser reports whether the file belongs to the current user. Windows keeps the
+// files of the user profile private with ACLs, which are not checked here.
+func ownedByUser(os.FileInfo) bool {
+	return true
+}
+
+// insecureMode reports whether the permissions let other users read the file.
+// Windows has no Unix permission bits, see ownedByUser.
+func insecureMode(os.FileInfo) bool {
+	return false
+}
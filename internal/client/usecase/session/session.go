@@ -0,0 +1,195 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+var (
+	ErrNoSession = errors.New("vault is locked")
+	ErrExpired   = errors.New("vault session expired")
+	ErrInsecure  = errors.New("session file or its directory is not private to the current user")
+)
+
+// Session is an unlocked vault: the data key with its ID, or for a vault without
+// a data key the key derived from the master password with the parameters it was derived with.
+type Session struct {
+	Email    string          `json:"email"`            // Owner of the vault.
+	Key      []byte          `json:"key"`              // Vault key.
+	KeyID    []byte          `json:"key_id,omitempty"` // Data key ID, empty for a derived key.
+	Params   utils.KDFParams `json:"params"`           // Parameters the derived key was derived with.
+	LastUsed time.Time       `json:"last_used"`        // Time of the last command that used the session.
+}
+
+// Storage keeps the session in a file readable only by the current user.
+// The vault key is stored in plaintext and protected by the permissions alone, so the file
+// and its directory have to belong to the user, be no symbolic links and be private to the user.
+// The idle timeout is checked by the next command that loads the session: until then an expired
+// session stays on disk, `lock` removes it at once.
+type Storage struct {
+	path        string
+	dirs        []string // Directories of the session created by the user, outermost first.
+	idleTimeout time.Duration
+}
+
+// New creates session storage. An empty path selects the user runtime directory.
+func New(path string, idleTimeout time.Duration) *Storage {
+	dirs := []string{filepath.Dir(path)}
+	if path == "" {
+		path, dirs = defaultLocation()
+	}
+
+	return &Storage{
+		path:        path,
+		dirs:        dirs,
+		idleTimeout: idleTimeout,
+	}
+}
+
+// DefaultPath returns the session file path in the user runtime directory,
+// which is usually kept in memory and cleared on logout or reboot.
+func DefaultPath() string {
+	path, _ := defaultLocation()
+	return path
+}
+
+// defaultLocation returns the default session file path and its directories created by the user.
+// Without a runtime directory the session is kept in the shared temporary directory under a predictable
+// name, so that directory has to be created by the user as well and is never taken over from someone else.
+func defaultLocation() (path string, dirs []string) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("keeper-%d", os.Getuid()))
+		dirs = append(dirs, dir)
+	}
+	dirs = append(dirs, filepath.Join(dir, "keeper"))
+
+	return filepath.Join(dir, "keeper", "session"), dirs
+}
+
+// prepareDirs creates the missing directories of the session with owner-only permissions
+// and checks that all of them are private to the user.
+func (s *Storage) prepareDirs() error {
+	if err := os.MkdirAll(filepath.Dir(s.dirs[0]), 0o700); err != nil {
+		return fmt.Errorf("session - os.MkdirAll - %w", err)
+	}
+	for _, dir := range s.dirs {
+		if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("session - os.Mkdir - %w", err)
+		}
+	}
+
+	return s.checkDirs()
+}
+
+// checkDirs checks that the directories of the session are private to the user.
+func (s *Storage) checkDirs() error {
+	for _, dir := range s.dirs {
+		if err := checkPrivate(dir, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkPrivate checks that the file or directory is not a symbolic link, belongs to the current user
+// and cannot be read, or for a directory changed, by other users. Otherwise it reports ErrInsecure.
+func checkPrivate(path string, dir bool) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return fmt.Errorf("session - os.Lstat - %w", err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 || info.IsDir() != dir || !ownedByUser(info) || insecureMode(info) {
+		return fmt.Errorf("%w: %s", ErrInsecure, path)
+	}
+
+	return nil
+}
+
+// Save writes the session atomically with owner-only permissions.
+func (s *Storage) Save(session *Session) error {
+	dir := filepath.Dir(s.path)
+	if err := s.prepareDirs(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("session - Save - json.Marshal - %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, ".session-*")
+	if err != nil {
+		return fmt.Errorf("session - Save - os.CreateTemp - %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("session - Save - file.Write - %w", err)
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("session - Save - file.Close - %w", err)
+	}
+
+	if err = os.Rename(file.Name(), s.path); err != nil {
+		return fmt.Errorf("session - Save - os.Rename - %w", err)
+	}
+
+	return nil
+}
+
+// Load returns the live session and extends it.
+// An expired session is removed and ErrExpired is returned. A session file or directory
+// that is not private to the user is not read and ErrInsecure is returned.
+func (s *Storage) Load() (*Session, error) {
+	if _, err := os.Lstat(s.path); errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSession
+	}
+	if err := s.checkDirs(); err != nil {
+		return nil, err
+	}
+	if err := checkPrivate(s.path, false); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("session - Load - os.ReadFile - %w", err)
+	}
+
+	var session Session
+	if err = json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("session - Load - json.Unmarshal - %w", err)
+	}
+
+	if s.idleTimeout > 0 && time.Since(session.LastUsed) > s.idleTimeout {
+		if err = s.Remove(); err != nil {
+			return nil, err
+		}
+		return nil, ErrExpired
+	}
+
+	session.LastUsed = time.Now()
+	if err = s.Save(&session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Remove deletes the session. Removing a missing session is not an error.
+func (s *Storage) Remove() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("session - Remove - os.Remove - %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package session_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/client/usecase/session"
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+func TestStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keeper", "session")
+	storage := session.New(path, time.Minute)
+
+	_, err := storage.Load()
+	require.ErrorIs(t, err, session.ErrNoSession)
+
+	saved := &session.Session{
+		Email:    "user@example.com",
+		Key:      []byte("0123456789abcdef0123456789abcdef"),
+		Params:   utils.DefaultKDFParams(utils.UserSalt("user@example.com")),
+		LastUsed: time.Now(),
+	}
+	require.NoError(t, storage.Save(saved))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := storage.Load()
+	require.NoError(t, err)
+	require.Equal(t, saved.Email, loaded.Email)
+	require.Equal(t, saved.Key, loaded.Key)
+	require.Equal(t, saved.Params, loaded.Params)
+
+	require.NoError(t, storage.Remove())
+	require.NoError(t, storage.Remove())
+	_, err = storage.Load()
+	require.ErrorIs(t, err, session.ErrNoSession)
+}
+
+func TestStorageExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session")
+	storage := session.New(path, time.Minute)
+
+	require.NoError(t, storage.Save(&session.Session{
+		Email:    "user@example.com",
+		LastUsed: time.Now().Add(-2 * time.Minute),
+	}))
+
+	_, err := storage.Load()
+	require.ErrorIs(t, err, session.ErrExpired)
+
+	_, err = os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestStorageInsecure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session")
+	storage := session.New(path, time.Minute)
+
+	require.NoError(t, storage.Save(&session.Session{LastUsed: time.Now()}))
+	require.NoError(t, os.Chmod(path, 0o644))
+
+	_, err := storage.Load()
+	require.ErrorIs(t, err, session.ErrInsecure)
+}
+
+func TestStorageSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	storage := session.New(target, time.Minute)
+	require.NoError(t, storage.Save(&session.Session{LastUsed: time.Now()}))
+
+	path := filepath.Join(dir, "session")
+	require.NoError(t, os.Symlink(target, path))
+	_, err := session.New(path, time.Minute).Load()
+	require.ErrorIs(t, err, session.ErrInsecure)
+
+	linkedDir := filepath.Join(t.TempDir(), "keeper")
+	require.NoError(t, os.Symlink(dir, linkedDir))
+	storage = session.New(filepath.Join(linkedDir, "target"), time.Minute)
+	_, err = storage.Load()
+	require.ErrorIs(t, err, session.ErrInsecure)
+	require.ErrorIs(t, storage.Save(&session.Session{LastUsed: time.Now()}), session.ErrInsecure)
+}
+
+func TestStorageSharedDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shared")
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	require.NoError(t, os.Chmod(dir, 0o777))
+
+	storage := session.New(filepath.Join(dir, "session"), time.Minute)
+	require.ErrorIs(t, storage.Save(&session.Session{LastUsed: time.Now()}), session.ErrInsecure)
+}
+
+func TestStorageDefaultPath(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", tmp)
+
+	storage := session.New("", time.Minute)
+	require.NoError(t, storage.Save(&session.Session{LastUsed: time.Now()}))
+	_, err := storage.Load()
+	require.NoError(t, err)
+
+	fallback := filepath.Dir(filepath.Dir(session.DefaultPath()))
+	require.Equal(t, tmp, filepath.Dir(fallback))
+	info, err := os.Stat(fallback)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	// A fallback directory prepared by someone else with loose permissions is not used.
+	require.NoError(t, os.Chmod(fallback, 0o777))
+	_, err = storage.Load()
+	require.ErrorIs(t, err, session.ErrInsecure)
+	require.ErrorIs(t, storage.Save(&session.Session{LastUsed: time.Now()}), session.ErrInsecure)
+}
@@ -17,8 +17,9 @@ const (
 )
 
 // ShowVault displays the user's vault contents based on the specified option.
-func (uc *ClientUseCase) ShowVault(userPassword, showVaultOption string) {
-	if !uc.verifyPassword(userPassword) {
+func (uc *ClientUseCase) ShowVault(showVaultOption string) {
+	if _, err := uc.vaultCipher(); err != nil {
+		color.Red("Failed to open the vault: %v", err)
 		return
 	}
 
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// ListTokens prints out the personal access tokens of the user.
+func (uc *ClientUseCase) ListTokens() {
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization check failed for user with provided password: %v", err)
+		return
+	}
+
+	tokens, err := uc.clientAPI.GetPersonalTokens(accessToken)
+	if err != nil {
+		color.Red("Failed to get tokens: %v", err)
+		return
+	}
+
+	color.Yellow("Users personal access tokens:")
+	yellow := color.New(color.FgYellow).SprintFunc()
+	for _, token := range tokens {
+		lastUsed := "never"
+		if token.LastUsedAt != nil {
+			lastUsed = token.LastUsedAt.Local().Format(time.DateTime)
+		}
+		fmt.Printf("ID: %s name: %s scope: %s expires: %s last used: %s\n",
+			yellow(token.ID),
+			yellow(token.Name),
+			yellow(describeScope(token.Scope)),
+			yellow(token.ExpiresAt.Local().Format(time.DateTime)),
+			yellow(lastUsed))
+	}
+	fmt.Printf("Total %s tokens\n", yellow(len(tokens)))
+}
+
+// CreateToken creates a personal access token valid for the given time and prints its secret,
+// which cannot be shown again.
+func (uc *ClientUseCase) CreateToken(name string, validFor time.Duration, scope entity.TokenScope) {
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization check failed for user with provided password: %v", err)
+		return
+	}
+
+	token := entity.PersonalToken{Name: name, ExpiresAt: time.Now().Add(validFor).UTC(), Scope: scope}
+	if err = uc.clientAPI.CreatePersonalToken(accessToken, &token); err != nil {
+		color.Red("Error creating token %q: %v", name, err)
+		return
+	}
+
+	color.Green("Token %q created with ID %s, it expires %s", name, token.ID,
+		token.ExpiresAt.Local().Format(time.DateTime))
+	color.Yellow("Copy the token now, it is not shown again:")
+	fmt.Println(token.Token)
+}
+
+// RevokeToken deletes the personal access token, it stops working at once.
+func (uc *ClientUseCase) RevokeToken(tokenID string) {
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization check failed for user with provided password: %v", err)
+		return
+	}
+	if _, err = uuid.Parse(tokenID); err != nil {
+		color.Red("Error parsing token ID %s: %v", tokenID, err)
+		return
+	}
+
+	if err = uc.clientAPI.RevokePersonalToken(accessToken, tokenID); err != nil {
+		color.Red("Error revoking token %s: %v", tokenID, err)
+		return
+	}
+	color.Green("Token %q revoked successfully", tokenID)
+}
+
+// describeScope returns a short description of the scope of a personal token.
+func describeScope(scope entity.TokenScope) string {
+	access := "read-write"
+	if scope.ReadOnly {
+		access = "read-only"
+	}
+	types := "all items"
+	if len(scope.ItemTypes) > 0 {
+		types = strings.Join(scope.ItemTypes, ",")
+	}
+	if len(scope.ItemIDs) > 0 {
+		return fmt.Sprintf("%s %s, %d items", access, types, len(scope.ItemIDs))
+	}
+
+	return fmt.Sprintf("%s %s", access, types)
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// completeTOTP completes a sign in challenged by the server with the TOTP code asked by promptCode.
+func (uc *ClientUseCase) completeTOTP(email, challenge string, promptCode func() (string, error)) (entity.JWT, error) {
+	code, err := promptCode()
+	if err != nil {
+		return entity.JWT{}, fmt.Errorf("two-factor code required: %w", err)
+	}
+
+	return uc.clientAPI.SignInTOTP(email, challenge, code)
+}
+
+// EnableTOTP enrolls a TOTP second factor: it prints the secret to enter in an authenticator app,
+// confirms it with the code asked by promptCode and prints the one-time recovery codes.
+func (uc *ClientUseCase) EnableTOTP(promptCode func() (string, error)) {
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization check failed for user with provided password: %v", err)
+		return
+	}
+
+	enrollment, err := uc.clientAPI.EnrollTOTP(accessToken)
+	if err != nil {
+		color.Red("Failed to enroll two-factor authentication: %v", err)
+		return
+	}
+	yellow := color.New(color.FgYellow).SprintFunc()
+	fmt.Printf("Add the account to an authenticator app with the URI:\n%s\n", yellow(enrollment.URI))
+	fmt.Printf("or enter the secret: %s\n", yellow(enrollment.Secret))
+
+	code, err := promptCode()
+	if err != nil {
+		color.Red("Two-factor code required. Error: %v", err)
+		return
+	}
+	recoveryCodes, err := uc.clientAPI.ConfirmTOTP(accessToken, code)
+	if err != nil {
+		color.Red("Failed to enable two-factor authentication: %v", err)
+		return
+	}
+
+	color.Green("Two-factor authentication enabled")
+	color.Yellow("Recovery codes, each of them disables two-factor authentication once. Keep them safe, they are not shown again:")
+	for _, recoveryCode := range recoveryCodes.Codes {
+		fmt.Println(yellow(recoveryCode))
+	}
+}
+
+// DisableTOTP turns the second factor of the user off with a recovery code.
+// It works without signing in, for a user who has lost the authenticator.
+func (uc *ClientUseCase) DisableTOTP(user *entity.User, recoveryCode string) {
+	if err := uc.clientAPI.DisableTOTP(authCredentials(user), recoveryCode); err != nil {
+		color.Red("Failed to disable two-factor authentication: %v", err)
+		return
+	}
+
+	color.Green("Two-factor authentication disabled")
+}
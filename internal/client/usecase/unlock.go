@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/nextlag/keeper/internal/client/usecase/session"
+	"github.com/nextlag/keeper/internal/utils"
+	"github.com/nextlag/keeper/internal/utils/client"
+)
+
+// Unlock derives the vault key and keeps it in the session storage
+// until the session stays idle longer than the configured timeout.
+func (uc *ClientUseCase) Unlock(userPassword string) {
+	if !uc.verifyPassword(userPassword) {
+		color.Red("Password verification failed")
+		return
+	}
+
+	if err := uc.startSession(userPassword); err != nil {
+		color.Red("Failed to unlock the vault: %v", err)
+		return
+	}
+
+	if uc.cfg != nil && uc.cfg.Session != nil && uc.cfg.Session.IdleTimeout > 0 {
+		color.Green("Vault unlocked, it locks after %v of inactivity", uc.cfg.Session.IdleTimeout)
+		return
+	}
+	color.Green("Vault unlocked")
+}
+
+// Lock removes the vault session.
+func (uc *ClientUseCase) Lock() {
+	if err := uc.session.Remove(); err != nil {
+		color.Red("Failed to lock the vault: %v", err)
+		return
+	}
+	uc.lockVault()
+
+	color.Green("Vault locked")
+}
+
+// OpenVault makes the vault key available to the following operations and unlocks the local cache.
+// The key is taken from a live session; without one the master password is requested.
+func (uc *ClientUseCase) OpenVault() error {
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		return err
+	}
+
+	current, err := uc.session.Load()
+	switch {
+	case err == nil && current.Email == user.Email && len(current.KeyID) > 0:
+		return uc.setVault(user.Email, utils.NewDataKeyCipher(&utils.DataKey{ID: current.KeyID, Key: current.Key}))
+	case err == nil && current.Email == user.Email:
+		return uc.setVault(user.Email, utils.NewKeyCipher(current.Key, current.Params))
+	case err == nil, errors.Is(err, session.ErrNoSession):
+	case errors.Is(err, session.ErrExpired):
+		color.Yellow("Vault session expired")
+	default:
+		color.Red("Failed to load the vault session: %v", err)
+	}
+
+	userPassword, err := client.PromptPassword(os.Stdin, os.Stderr, "Master password: ")
+	if err != nil {
+		return err
+	}
+	if !uc.verifyPassword(userPassword) {
+		return errPasswordCheck
+	}
+
+	_, err = uc.unlockVault(userPassword)
+	return err
+}
+
+// startSession unwraps the data key of the current user, or derives the master key
+// for a vault without a data key, and saves the session.
+func (uc *ClientUseCase) startSession(userPassword string) error {
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		return err
+	}
+
+	cipher, dataKey, err := uc.unlockCipher(userPassword)
+	if err != nil {
+		return err
+	}
+
+	current := &session.Session{
+		Email:    user.Email,
+		LastUsed: time.Now(),
+	}
+	if dataKey != nil {
+		current.Key, current.KeyID = dataKey.Key, dataKey.ID
+		cipher = utils.NewDataKeyCipher(dataKey)
+	} else {
+		current.Params = uc.kdfParams(user.Email)
+		current.Key = utils.DeriveKey(userPassword, current.Params)
+	}
+
+	if err = uc.session.Save(current); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return uc.setVault(user.Email, cipher)
+}
@@ -2,17 +2,21 @@ package usecase
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/fatih/color"
 
 	config "github.com/nextlag/keeper/config/client"
+	"github.com/nextlag/keeper/internal/utils"
 )
 
 type ClientUseCase struct {
 	repo      ClientRepo
 	clientAPI ClientAPI
+	session   ClientSession
 	cfg       *config.Config
+	vault     *utils.Cipher // Cipher of the opened vault, set by OpenVault.
 }
 
 var (
@@ -42,6 +46,12 @@ func SetAPI(clientAPI ClientAPI) OptsUseCase {
 	}
 }
 
+func SetSession(s ClientSession) OptsUseCase {
+	return func(uc *ClientUseCase) {
+		uc.session = s
+	}
+}
+
 func SetConfig(cfg *config.Config) OptsUseCase {
 	return func(uc *ClientUseCase) {
 		uc.cfg = cfg
@@ -55,11 +65,12 @@ func (uc *ClientUseCase) InitDB() {
 var (
 	errPasswordCheck = errors.New("wrong password")
 	errToken         = errors.New("user token erroe")
+	errLocked        = errors.New("vault is not opened")
 )
 
-func (uc *ClientUseCase) authorisationCheck(userPassword string) (string, error) {
-	if !uc.verifyPassword(userPassword) {
-		return "", errPasswordCheck
+func (uc *ClientUseCase) authorisationCheck() (string, error) {
+	if uc.vault == nil {
+		return "", errLocked
 	}
 	accessToken, err := uc.repo.GetSavedAccessToken()
 	if err != nil || accessToken == "" {
@@ -70,3 +81,65 @@ func (uc *ClientUseCase) authorisationCheck(userPassword string) (string, error)
 
 	return accessToken, nil
 }
+
+// vaultCipher returns the cipher of the vault opened by OpenVault.
+func (uc *ClientUseCase) vaultCipher() (*utils.Cipher, error) {
+	if uc.vault == nil {
+		return nil, errLocked
+	}
+
+	return uc.vault, nil
+}
+
+// passwordCipher creates the cipher that seals the current user's vault with the master password.
+// The key is derived with the per-user salt and the KDF parameters from the config.
+func (uc *ClientUseCase) passwordCipher(userPassword string) (*utils.Cipher, error) {
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return utils.NewCipher(userPassword, uc.kdfParams(user.Email)), nil
+}
+
+// unlockCipher returns the vault cipher for the master password.
+// For a vault with a data key it seals with the unwrapped data key and still opens
+// values sealed with the master password; without a data key it is the password cipher.
+func (uc *ClientUseCase) unlockCipher(userPassword string) (*utils.Cipher, *utils.DataKey, error) {
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	master := utils.NewCipher(userPassword, uc.kdfParams(user.Email))
+	if user.DataKey == "" {
+		return master, nil, nil
+	}
+
+	dataKey, err := utils.UnwrapDataKey(user.DataKey, master)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	return master.WithDataKey(dataKey), dataKey, nil
+}
+
+// kdfParams returns the key derivation parameters for the user, overridden by the config if set.
+func (uc *ClientUseCase) kdfParams(email string) utils.KDFParams {
+	params := utils.DefaultKDFParams(utils.UserSalt(email))
+	if uc.cfg == nil || uc.cfg.Crypto == nil {
+		return params
+	}
+
+	if uc.cfg.Crypto.KDFTime != 0 {
+		params.Time = uc.cfg.Crypto.KDFTime
+	}
+	if uc.cfg.Crypto.KDFMemory != 0 {
+		params.Memory = uc.cfg.Crypto.KDFMemory
+	}
+	if uc.cfg.Crypto.KDFThreads != 0 {
+		params.Threads = uc.cfg.Crypto.KDFThreads
+	}
+
+	return params
+}
@@ -1,17 +1,33 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/fatih/color"
 
 	"github.com/nextlag/keeper/internal/entity"
 	"github.com/nextlag/keeper/internal/utils"
+	"github.com/nextlag/keeper/internal/utils/errs"
 )
 
 // Login authenticates a user and performs necessary actions after successful authentication.
-func (uc *ClientUseCase) Login(user *entity.User) {
-	token, err := uc.clientAPI.Login(user)
+// The server gets the auth hash of the master password. The server cannot tell an account registered
+// before the auth hash from wrong credentials without the master password, so when the auth hash
+// is rejected the master password is sent to upgrade the account; the server rejects it as well
+// unless the account still has to be upgraded. An account with a second factor is signed in
+// with the TOTP code asked by promptCode.
+func (uc *ClientUseCase) Login(user *entity.User, promptCode func() (string, error)) {
+	credentials := authCredentials(user)
+	token, err := uc.clientAPI.Login(credentials)
+	if errors.Is(err, errs.ErrWrongCredentials) || errors.Is(err, errs.ErrAuthUpgradeRequired) {
+		if token, err = uc.clientAPI.UpgradeAuth(user, credentials.Password); err == nil {
+			color.Yellow("The account has been upgraded to the auth hash, the master password is not sent anymore")
+		}
+	}
+	if err == nil && token.TOTPChallenge != "" {
+		token, err = uc.completeTOTP(user.Email, token.TOTPChallenge, promptCode)
+	}
 	if err != nil {
 		color.Red("Login failed for user %s: %v", user.Email, err)
 		return
@@ -23,39 +39,151 @@ func (uc *ClientUseCase) Login(user *entity.User) {
 			color.Red("Failed to add user %s to repository: %v", user.Email, err)
 			return
 		}
+	} else if err = uc.repo.UpdateUserPassword(user); err != nil {
+		// The password may have been changed on another device.
+		color.Red("Failed to update password for user %s: %v", user.Email, err)
+		return
+	}
+
+	// The tokens are sealed with the cache key, so the vault is opened before they are stored.
+	cipher, wrappedKey, err := uc.loginCipher(token.AccessToken, user)
+	if err != nil {
+		color.Red("Failed to get the data key: %v", err)
+		return
+	}
+	cleared, err := uc.openCache(user.Email, cipher)
+	if err != nil {
+		color.Red("Failed to unlock the vault: %v", err)
+		return
 	}
 	if err = uc.repo.UpdateUserToken(user, &token); err != nil {
 		color.Red("Failed to update token for user %s: %v", user.Email, err)
 		return
 	}
-	if err = uc.repo.AddTempPass(user); err != nil {
-		color.Red("Failed to add temporary password for user %s: %v", user.Email, err)
+	color.Green("Got authorization token for %q", user.Email)
+
+	if wrappedKey == "" {
+		color.Yellow("The vault has no data key yet, re-encrypting it with a new one")
+		if _, err = uc.replaceDataKey(token.AccessToken, user.Password, cipher); err != nil {
+			color.Red("Failed to move the vault to a data key: %v", err)
+			return
+		}
+		color.Green("Vault unlocked")
 		return
 	}
-	color.Green("Got authorization token for %q", user.Email)
+
+	if err = uc.startSession(user.Password); err != nil {
+		color.Red("Failed to unlock the vault: %v", err)
+		return
+	}
+	color.Green("Vault unlocked")
+
+	// The cache sealed with another key has been cleared, it is filled again from the server.
+	if cleared {
+		uc.loadChanges(token.AccessToken)
+	}
 }
 
-// Register registers a new user and adds them to the repository.
+// loginCipher returns the vault cipher of the user logging in and the wrapped data key from the server.
+// Without a data key on the server the cipher is the master password cipher.
+func (uc *ClientUseCase) loginCipher(accessToken string, user *entity.User) (*utils.Cipher, string, error) {
+	wrappedKey, err := uc.clientAPI.GetDataKey(accessToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("GetDataKey - %w", err)
+	}
+
+	master := utils.NewCipher(user.Password, uc.kdfParams(user.Email))
+	if wrappedKey == "" {
+		return master, "", nil
+	}
+
+	if err = uc.repo.UpdateUserDataKey(user.Email, wrappedKey); err != nil {
+		return nil, "", fmt.Errorf("UpdateUserDataKey - %w", err)
+	}
+	dataKey, err := utils.UnwrapDataKey(wrappedKey, master)
+	if err != nil {
+		return nil, "", fmt.Errorf("UnwrapDataKey - %w", err)
+	}
+
+	return master.WithDataKey(dataKey), wrappedKey, nil
+}
+
+// Register registers a new user and adds them to the repository. The vault of the user is created
+// with them: a new data key is wrapped by the master password and by a new recovery key,
+// which is shown once the registration succeeds.
 func (uc *ClientUseCase) Register(user *entity.User) {
-	if err := uc.clientAPI.Register(user); err != nil {
+	keys, recoveryKey, err := uc.newVaultKeys(user)
+	if err != nil {
+		color.Red("Failed to create the vault keys: %v", err)
+		return
+	}
+
+	credentials := authCredentials(user)
+	if err = uc.clientAPI.Register(credentials, &keys); err != nil {
 		color.Red("Registration failed for user %s: %v", user.Email, err)
 		return
 	}
+	user.ID = credentials.ID
 
-	if err := uc.repo.AddUser(user); err != nil {
+	if err = uc.repo.AddUser(user); err != nil {
 		color.Red("Failed to add registered user %s to repository: %v", user.Email, err)
 		return
 	}
 
 	color.Green("User registered successfully")
 	color.Green("ID: %v, email: %s", user.ID, user.Email)
+	showRecoveryKey(recoveryKey)
+}
+
+// newVaultKeys creates the data key of a new vault and a recovery key, and wraps the data key by both
+// the master password of the user and the recovery key.
+func (uc *ClientUseCase) newVaultKeys(user *entity.User) (entity.VaultKeys, utils.RecoveryKey, error) {
+	dataKey, err := utils.NewDataKey()
+	if err != nil {
+		return entity.VaultKeys{}, nil, err
+	}
+	wrappedKey, err := dataKey.Wrap(utils.NewCipher(user.Password, uc.kdfParams(user.Email)))
+	if err != nil {
+		return entity.VaultKeys{}, nil, err
+	}
+
+	recoveryKey, err := utils.NewRecoveryKey()
+	if err != nil {
+		return entity.VaultKeys{}, nil, err
+	}
+	recoveryWrappedKey, err := dataKey.Wrap(recoveryKey.Cipher())
+	if err != nil {
+		return entity.VaultKeys{}, nil, err
+	}
+
+	return entity.VaultKeys{
+		DataKey:      wrappedKey,
+		RecoveryAuth: recoveryKey.Auth(),
+		RecoveryKey:  recoveryWrappedKey,
+	}, recoveryKey, nil
 }
 
-// Logout handles the process of logging out a user by removing their temporary data.
+// Logout handles the process of logging out a user by revoking the session on the server,
+// dropping their tokens and locking the vault. The tokens are sealed in the local cache,
+// so a locked vault is opened with the master password first. The tokens are kept
+// when the session could not be revoked, so the logout can be repeated.
 func (uc *ClientUseCase) Logout() {
-	user, err := uc.repo.GetTempUser()
+	if err := uc.revokeSession(); err != nil {
+		color.Red("Logout failed, the session has not been revoked on the server: %v", err)
+		return
+	}
+	uc.dropSession()
+}
+
+// dropSession locks the vault and drops the tokens of the logged-in user.
+func (uc *ClientUseCase) dropSession() {
+	if err := uc.session.Remove(); err != nil {
+		color.Red("Failed to lock the vault: %v", err)
+	}
+
+	user, err := uc.repo.GetCurrentUser()
 	if err != nil {
-		color.Red("Failed to get temporary user for logout: %v", err)
+		color.Red("Failed to get current user for logout: %v", err)
 		return
 	}
 
@@ -64,29 +192,87 @@ func (uc *ClientUseCase) Logout() {
 		return
 	}
 
-	uc.repo.RemoveTempUser()
-
 	color.Green("User tokens were successfully dropped")
 }
 
-// Sync synchronizes user data with the server using a valid access token.
-func (uc *ClientUseCase) Sync(userPassword string) {
-	if !uc.verifyPassword(userPassword) {
-		color.Red("Password verification failed")
+// revokeSession revokes the session of the logged-in user on the server.
+// Nothing is revoked without a logged-in user or saved tokens.
+func (uc *ClientUseCase) revokeSession() error {
+	if _, err := uc.repo.GetCurrentUser(); err != nil {
+		return nil
+	}
+	if uc.vault == nil {
+		if err := uc.OpenVault(); err != nil {
+			return fmt.Errorf("OpenVault - %w", err)
+		}
+	}
+
+	token, err := uc.repo.GetSavedTokens()
+	if err != nil {
+		return fmt.Errorf("GetSavedTokens - %w", err)
+	}
+	if token.AccessToken == "" && token.RefreshToken == "" {
+		return nil
+	}
+
+	return uc.clientAPI.Logout(token)
+}
+
+// Sync synchronizes user data with the server using a valid access token:
+// the local changes are uploaded first, then the changes from the server are fetched.
+func (uc *ClientUseCase) Sync() {
+	accessToken, err := uc.authorisationCheck()
+	if err != nil {
+		color.Red("Authorization failed: %v", err)
+		return
+	}
+	uc.checkDataKey(accessToken)
+	if uc.vault == nil {
+		// checkDataKey has locked the vault, the data key has been changed on another device.
 		return
 	}
-	accessToken, err := uc.repo.GetSavedAccessToken()
+	uc.syncVault(accessToken)
+}
+
+// checkDataKey keeps the local wrapped data key in line with the server.
+// When the key has been rotated on another device the session holds a stale key,
+// so the vault is locked until the user logs in again.
+func (uc *ClientUseCase) checkDataKey(accessToken string) {
+	user, err := uc.repo.GetCurrentUser()
+	if err != nil {
+		color.Red("Failed to get current user: %v", err)
+		return
+	}
+
+	wrappedKey, err := uc.clientAPI.GetDataKey(accessToken)
 	if err != nil {
-		color.Red("Failed to get saved access token: %v", err)
+		color.Red("Error fetching the data key: %v", err)
+		return
+	}
+	if wrappedKey == "" || wrappedKey == user.DataKey {
+		return
+	}
+
+	if err = uc.repo.UpdateUserDataKey(user.Email, wrappedKey); err != nil {
+		color.Red("Error saving the data key: %v", err)
 		return
 	}
-	uc.loadLogins(accessToken)
-	uc.loadCards(accessToken)
-	uc.loadNotes(accessToken)
-	uc.loadBinaries(accessToken)
+	if err = uc.session.Remove(); err != nil {
+		color.Red("Failed to lock the vault: %v", err)
+		return
+	}
+	uc.lockVault()
+	color.Yellow("The data key or the master password has been changed on another device, log in again")
+}
+
+// authCredentials returns the credentials sent to the server: the email and the auth hash
+// of the master password in place of the password itself.
+func authCredentials(user *entity.User) *entity.User {
+	return &entity.User{Email: user.Email, Password: utils.AuthHash(user.Password, user.Email)}
 }
 
 // verifyPassword checks if the provided password matches the stored password hash.
+// A bcrypt hash stored before Argon2id, or one with other parameters, is replaced once the password matches.
 func (uc *ClientUseCase) verifyPassword(userPassword string) bool {
 	hashPassword, err := uc.repo.GetUserPasswordHash()
 	if err != nil {
@@ -97,14 +283,15 @@ func (uc *ClientUseCase) verifyPassword(userPassword string) bool {
 		color.Red("Password check failed: %v", err)
 		return false
 	}
-	return true
-}
 
-// GetTempPass retrieves the temporary password for the current user.
-func (uc *ClientUseCase) GetTempPass() (string, error) {
-	user, err := uc.repo.GetTempUser()
-	if err != nil {
-		return "", fmt.Errorf("failed to get temporary user: %w", err)
+	if utils.PasswordNeedsRehash(hashPassword, utils.DefaultPasswordParams()) {
+		user, err := uc.repo.GetCurrentUser()
+		if err == nil {
+			err = uc.repo.UpdateUserPassword(&entity.User{Email: user.Email, Password: userPassword})
+		}
+		if err != nil {
+			color.Yellow("Failed to rehash the local password: %v", err)
+		}
 	}
-	return user.Password, nil
+	return true
 }
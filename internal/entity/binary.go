@@ -4,8 +4,11 @@ import "github.com/google/uuid"
 
 // Binary represents a file.
 type Binary struct {
-	ID       uuid.UUID `json:"uuid" swaggerignore:"true"` // Unique identifier.
-	Name     string    `json:"name"`                      // File name.
-	FileName string    `json:"file_name"`                 // Filesystem name.
-	Meta     []Meta    `json:"meta"`                      // Associated metadata.
+	ID         uuid.UUID `json:"uuid" swaggerignore:"true"` // Unique identifier.
+	Name       string    `json:"name"`                      // File name.
+	FileName   string    `json:"file_name"`                 // Filesystem name.
+	StoredName string    `json:"-"`                         // Name of the file in the server storage.
+	Meta       []Meta    `json:"meta"`                      // Associated metadata.
+
+	Revision int64 `json:"revision,omitempty" swaggerignore:"true"` // Revision of the latest change, set by the server.
 }
@@ -13,4 +13,7 @@ type Card struct {
 	ExpirationYear  string    `json:"expiration_year"`           // Expiration year.
 	SecurityCode    string    `json:"security_code"`             // Security code (CVV).
 	Meta            []Meta    `json:"meta"`                      // Associated metadata.
+
+	Revision     int64 `json:"revision,omitempty" swaggerignore:"true"`      // Revision of the latest change, set by the server.
+	BaseRevision int64 `json:"base_revision,omitempty" swaggerignore:"true"` // Revision the update is based on, a stale one is rejected.
 }
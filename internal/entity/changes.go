@@ -0,0 +1,23 @@
+package entity
+
+import "github.com/google/uuid"
+
+// Changes holds the items of a user created, updated or deleted since a cursor.
+// Revisions increase with every change of the user items, the cursor is the revision
+// of the latest change the client has seen.
+type Changes struct {
+	Cursor   int64         `json:"cursor"`   // Revision of the latest change, the cursor of the next request.
+	Full     bool          `json:"full"`     // The items are the whole vault, the local copy is replaced with them.
+	Logins   []Login       `json:"logins"`   // Created or updated logins.
+	Cards    []Card        `json:"cards"`    // Created or updated cards.
+	Notes    []SecretNote  `json:"notes"`    // Created or updated notes.
+	Binaries []Binary      `json:"binaries"` // Created or updated binaries.
+	Deleted  []DeletedItem `json:"deleted"`  // Deleted items.
+}
+
+// DeletedItem names an item that has been deleted.
+type DeletedItem struct {
+	ID       uuid.UUID `json:"uuid"`     // Unique identifier of the item.
+	Type     string    `json:"type"`     // Item type, see ItemTypes.
+	Revision int64     `json:"revision"` // Revision of the deletion.
+}
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Conflict is a local update of an item the server has rejected, because the item had been changed
+// on another device since. Both versions are items of the type encoded as JSON.
+type Conflict struct {
+	ItemID uuid.UUID       `json:"uuid"`  // Unique identifier of the item.
+	Type   string          `json:"type"`  // Item type, see ItemTypes.
+	Base   json.RawMessage `json:"base"`  // Version of the item the update is based on.
+	Local  json.RawMessage `json:"local"` // Locally updated version of the item.
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "github.com/google/uuid"
+
+// Types of the events streamed to the devices of a user.
+const (
+	EventItemChanged    = "item-changed"    // An item has been created or updated.
+	EventItemDeleted    = "item-deleted"    // An item has been deleted.
+	EventSessionRevoked = "session-revoked" // A session of the user has been revoked.
+	EventResync         = "resync"          // Events may have been missed, the device syncs the whole vault.
+)
+
+// Event notifies the devices of a user about a change made elsewhere.
+// IDs increase with every event, a device resumes the stream after the ID of the last event it has seen.
+type Event struct {
+	ID   int64  // Identifier of the event.
+	Type string // Event type, see the Event constants.
+	Data any    // Payload of the event, ItemEvent or SessionEvent; nil for EventResync.
+}
+
+// ItemEvent names the item that has changed.
+type ItemEvent struct {
+	ID       uuid.UUID `json:"uuid"`               // Unique identifier of the item.
+	Type     string    `json:"type"`               // Item type, see ItemTypes.
+	Revision int64     `json:"revision,omitempty"` // Revision of the change, unset for a deletion.
+}
+
+// SessionEvent names the session that has been revoked.
+type SessionEvent struct {
+	ID uuid.UUID `json:"uuid"` // Unique identifier of the session.
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "net/http"
+
+// IdempotentResponse is the response to a request sent with an idempotency key,
+// returned again when the request is retried with the same key.
+type IdempotentResponse struct {
+	Status int         // Status code of the response.
+	Header http.Header // Headers of the response.
+	Body   []byte      // Body of the response.
+}
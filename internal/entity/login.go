@@ -10,4 +10,7 @@ type Login struct {
 	Password string    `json:"password"`                  // Password for the login.
 	URI      string    `json:"uri"`                       // URI or website related to the login.
 	Meta     []Meta    `json:"meta"`                      // Associated metadata.
+
+	Revision     int64 `json:"revision,omitempty" swaggerignore:"true"`      // Revision of the latest change, set by the server.
+	BaseRevision int64 `json:"base_revision,omitempty" swaggerignore:"true"` // Revision the update is based on, a stale one is rejected.
 }
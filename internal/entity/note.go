@@ -8,4 +8,7 @@ type SecretNote struct {
 	Name string    `json:"name"`                      // Name or title of the note.
 	Note string    `json:"note"`                      // Content of the note.
 	Meta []Meta    `json:"meta"`                      // Associated metadata for the note.
+
+	Revision     int64 `json:"revision,omitempty" swaggerignore:"true"`      // Revision of the latest change, set by the server.
+	BaseRevision int64 `json:"base_revision,omitempty" swaggerignore:"true"` // Revision the update is based on, a stale one is rejected.
 }
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Actions of a queued operation.
+const (
+	OperationAdd    = "add"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Operation is a change made to the local cache, queued to be replayed on the server by the next sync.
+type Operation struct {
+	Key    string          // Idempotency key the operation is replayed with.
+	Action string          // Add, update or delete.
+	Type   string          // Item type.
+	ItemID uuid.UUID       // ID of the item.
+	Item   json.RawMessage // Encrypted item to add or update.
+	Base   json.RawMessage // Encrypted version an update is based on.
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Item types a personal token can be scoped to, named after their routes.
+const (
+	ItemLogins = "logins"
+	ItemCards  = "cards"
+	ItemNotes  = "notes"
+	ItemBinary = "binary"
+)
+
+// ItemTypes lists the item types a personal token can be scoped to.
+var ItemTypes = []string{ItemLogins, ItemCards, ItemNotes, ItemBinary}
+
+// TokenScope limits what a personal token grants access to. A token only ever reaches the items
+// of the user, account settings stay with the sessions. Empty lists do not limit anything.
+type TokenScope struct {
+	ReadOnly  bool        `json:"read_only"`            // Only items may be read, nothing is changed.
+	ItemTypes []string    `json:"item_types,omitempty"` // Item types the token may access, see ItemTypes.
+	ItemIDs   []uuid.UUID `json:"item_ids,omitempty"`   // Items the token may access; no items can be added.
+}
+
+// AllowsType reports whether the scope grants access to items of the type.
+func (s TokenScope) AllowsType(itemType string) bool {
+	return len(s.ItemTypes) == 0 || slices.Contains(s.ItemTypes, itemType)
+}
+
+// AllowsItem reports whether the scope grants access to the item with the ID.
+func (s TokenScope) AllowsItem(itemID uuid.UUID) bool {
+	return len(s.ItemIDs) == 0 || slices.Contains(s.ItemIDs, itemID)
+}
+
+// PersonalToken is a long-lived token of a user for automation, it authenticates requests
+// to the items of the user within its scope without a session.
+type PersonalToken struct {
+	ID         uuid.UUID  `json:"uuid"`                   // Unique identifier for the token.
+	UserID     uuid.UUID  `json:"-"`                      // Owner of the token.
+	Name       string     `json:"name"`                   // Name given by the user.
+	Scope      TokenScope `json:"scope"`                  // What the token grants access to.
+	CreatedAt  time.Time  `json:"created_at"`             // Time the token was created.
+	ExpiresAt  time.Time  `json:"expires_at"`             // Time the token stops working.
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // Time the token was last used, nil if never.
+	Token      string     `json:"token,omitempty"`        // Secret of the token, only returned once on creation.
+}
@@ -0,0 +1,52 @@
+package entity
+
+// Rekey represents the whole vault re-encrypted with a new master key.
+type Rekey struct {
+	Logins   []Login      `json:"logins"`   // Re-encrypted logins.
+	Cards    []Card       `json:"cards"`    // Re-encrypted cards.
+	Notes    []SecretNote `json:"notes"`    // Re-encrypted notes.
+	Binaries []Binary     `json:"binaries"` // Binaries with re-encrypted metadata, the files are staged one by one.
+}
+
+// PasswordChange represents a request to change the master password.
+type PasswordChange struct {
+	OldPassword string `json:"old_password"` // Auth hash of the current password.
+	NewPassword string `json:"new_password"` // Auth hash of the new password.
+	DataKey     string `json:"data_key"`     // Data key wrapped by the new password.
+}
+
+// DataKey represents the vault data key wrapped by the master password.
+type DataKey struct {
+	DataKey string `json:"data_key"` // Wrapped data key, opaque to the server.
+}
+
+// DataKeyRotation represents a request to switch the vault to a new data key.
+type DataKeyRotation struct {
+	Password string `json:"password"` // Auth hash of the current password.
+	DataKey  string `json:"data_key"` // New data key wrapped by the password.
+}
+
+// VaultKeys holds the keys of the vault created together with the account: its data key
+// wrapped by the master password and by the recovery key.
+type VaultKeys struct {
+	DataKey      string `json:"data_key,omitempty"`      // Data key wrapped by the master password.
+	RecoveryAuth string `json:"recovery_auth,omitempty"` // Secret derived from the recovery key.
+	RecoveryKey  string `json:"recovery_key,omitempty"`  // Data key wrapped by the recovery key.
+}
+
+// RecoveryKey holds the data key wrapped by the recovery key. A new recovery key is set
+// with the password and the secret the recovery key is checked with.
+type RecoveryKey struct {
+	Password     string `json:"password,omitempty"`      // Auth hash of the password.
+	RecoveryAuth string `json:"recovery_auth,omitempty"` // Secret derived from the recovery key.
+	RecoveryKey  string `json:"recovery_key"`            // Data key wrapped by the recovery key, opaque to the server.
+}
+
+// Recovery represents a request to the account with the recovery key: to get the data key wrapped by it,
+// or to set a new password together with the data key wrapped by the new password.
+type Recovery struct {
+	Email        string `json:"email"`
+	RecoveryAuth string `json:"recovery_auth"`      // Secret derived from the recovery key.
+	Password     string `json:"password,omitempty"` // Auth hash of the new password.
+	DataKey      string `json:"data_key,omitempty"` // Data key wrapped by the new password.
+}
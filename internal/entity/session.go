@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Device describes the client a session has been started from.
+type Device struct {
+	Name     string `json:"name"`     // Name of the device, the host name unless renamed by the user.
+	Platform string `json:"platform"` // Operating system and architecture of the client.
+	LastIP   string `json:"last_ip"`  // IP address the device was last seen from.
+}
+
+// Session represents a signed in client. Every refresh rotates the refresh token of the session;
+// the access and refresh tokens carry the session ID, so revoking the session revokes them all.
+// The account owner sees sessions as devices.
+type Session struct {
+	ID         uuid.UUID  `json:"uuid"` // Unique identifier for the session.
+	UserID     uuid.UUID  `json:"-"`    // Owner of the session.
+	Device                // Client the session has been started from.
+	CreatedAt  time.Time  `json:"created_at"`           // Time of the sign in, the device is first seen.
+	LastUsedAt time.Time  `json:"last_used_at"`         // Time the device was last seen.
+	ExpiresAt  time.Time  `json:"expires_at"`           // Time the current refresh token expires.
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"` // Time the session was revoked, nil while it is active.
+	Current    bool       `json:"current"`              // Whether the session is the one of the request.
+}
+
+// Active reports whether the session can still be used at the given time.
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
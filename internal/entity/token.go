@@ -7,4 +7,22 @@ type JWT struct {
 	AccessTokenMaxAge  int    `json:"-"`             // Maximum age of the access token in seconds (not serialized).
 	RefreshTokenMaxAge int    `json:"-"`             // Maximum age of the refresh token in seconds (not serialized).
 	Domain             string `json:"-"`             // Domain to which the tokens are issued (not serialized).
+
+	// TOTPChallenge is set instead of the tokens when the user has to confirm the sign in with a TOTP code.
+	TOTPChallenge string `json:"totp_challenge,omitempty"`
+}
+
+// JWK is an RSA public key tokens are verified with, in the JSON Web Key format of RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"` // Key type, always "RSA".
+	Use string `json:"use"` // Intended use, always "sig".
+	Alg string `json:"alg"` // Signing algorithm, always "RS256".
+	Kid string `json:"kid"` // Key ID carried by the kid header of the tokens.
+	N   string `json:"n"`   // Modulus, base64url encoded.
+	E   string `json:"e"`   // Public exponent, base64url encoded.
+}
+
+// JWKS is a JSON Web Key Set of the keys access tokens are currently accepted with.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
 }
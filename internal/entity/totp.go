@@ -0,0 +1,12 @@
+package entity
+
+// TOTPEnrollment holds the secret of a TOTP second factor being enrolled.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"` // Secret in base32, to be entered in an authenticator app.
+	URI    string `json:"uri"`    // Provisioning otpauth URI of the secret.
+}
+
+// RecoveryCodes holds the one-time codes that disable the TOTP second factor when the authenticator is lost.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
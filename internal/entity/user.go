@@ -7,4 +7,10 @@ type User struct {
 	ID       uuid.UUID `json:"uuid"`  // Unique identifier for the user.
 	Email    string    `json:"email"` // Email address of the user.
 	Password string    `json:"-"`     // Password for the user (not serialized).
+
+	TOTPEnabled bool      `json:"totp_enabled"` // Whether sign in requires a TOTP code.
+	SessionID   uuid.UUID `json:"-"`            // Session the user is authenticated with in the current request.
+
+	// Scope of the personal token the user is authenticated with in the current request, nil for sessions.
+	Scope *TokenScope `json:"-"`
 }
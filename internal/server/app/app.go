@@ -42,7 +42,11 @@ func NewApp(cfg *config.Config) (*App, error) {
 	}
 	repo.Migrate()
 
-	uc := usecase.New(repo, cfg, cache.New(cfg.Cache.DefaultExpiration, cfg.Cache.CleanupInterval), log)
+	uc, err := usecase.New(repo, cfg, cache.New(cfg.Cache.DefaultExpiration, cfg.Cache.CleanupInterval), log)
+	if err != nil {
+		log.Error("error", l.ErrAttr(err))
+		os.Exit(1)
+	}
 
 	r := chi.NewRouter()
 	ctrl := v1.NewController(uc, cfg, log)
@@ -20,6 +20,10 @@ func (a *App) Run(ctx context.Context) {
 		Addr:    a.cfg.Network.Host,
 		Handler: a.ctrl.NewServer(a.router).Handler,
 	}
+	// Event streams never end on their own, they are closed for the shutdown not to wait for them.
+	srv.RegisterOnShutdown(a.uc.CloseEvents)
+
+	go a.uc.RunPurge(ctx)
 
 	go func() {
 		fmt.Println("\n----------- START SERVER --------------")
@@ -6,35 +6,83 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/server/mw/request"
 	"github.com/nextlag/keeper/internal/utils/errs"
 	"github.com/nextlag/keeper/pkg/logger/l"
 )
 
+var errAuthHashNotGiven = errors.New("auth hash has not given")
+
+// loginPayload holds the credentials; the password is the auth hash derived by the client,
+// never the master password itself.
 type loginPayload struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+// signUpPayload holds the credentials of a new user and the keys of their vault, if the client creates one.
+type signUpPayload struct {
+	loginPayload
+	entity.VaultKeys
+}
+
+// devicePayload describes the client signing in; the server takes the IP address from the request.
+type devicePayload struct {
+	Name     string `json:"name"`
+	Platform string `json:"platform"`
+}
+
+// signInPayload holds the credentials and the device they are given on.
+type signInPayload struct {
+	loginPayload
+	Device devicePayload `json:"device"`
+}
+
+// upgradePayload holds the master password checked one last time and the auth hash replacing it.
+type upgradePayload struct {
+	Email    string        `json:"email"`
+	Password string        `json:"password"`
+	AuthHash string        `json:"auth_hash"`
+	Device   devicePayload `json:"device"`
+}
+
+// device returns the device of the payload seen from the IP address of the request.
+// Returns errDeviceNameTooLong if the device describes itself with too long a name or platform.
+func (p devicePayload) device(r *http.Request) (entity.Device, error) {
+	if len(p.Name) > maxDeviceNameLength || len(p.Platform) > maxDeviceNameLength {
+		return entity.Device{}, errDeviceNameTooLong
+	}
+
+	return entity.Device{Name: p.Name, Platform: p.Platform, LastIP: request.ClientIP(r.Context())}, nil
+}
+
 // SignUpUser godoc
 // @Summary Sign up a new user
-// @Description Register a new user and generate initial JWT tokens
+// @Description Register a new user together with the data key of the vault wrapped by the master password
+// @Description and by the recovery key
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param payload body loginPayload true "Registration credentials"
+// @Param payload body signUpPayload true "Registration credentials and vault keys"
 // @Success 201 {object} entity.User
 // @Failure 400 {object} response
+// @Failure 429 {object} response
 // @Failure 500 {object} response
 // @Router /auth/register [post]
 func (c *Controller) SignUpUser(w http.ResponseWriter, r *http.Request) {
-	var payload *loginPayload
+	var payload *signUpPayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		c.log.Error("error", l.ErrAttr(err))
 		http.Error(w, jsonError(err), http.StatusBadRequest)
 		return
 	}
+	if (payload.RecoveryAuth == "") != (payload.RecoveryKey == "") || payload.RecoveryKey != "" && payload.DataKey == "" {
+		http.Error(w, jsonError(errRecoveryKeyNotGiven), http.StatusBadRequest)
+		return
+	}
 
-	user, err := c.uc.SignUpUser(r.Context(), payload.Email, payload.Password)
+	user, err := c.uc.SignUpUser(r.Context(), payload.Email, payload.Password, payload.VaultKeys)
 	if errors.Is(err, errs.ErrWrongEmail) || errors.Is(err, errs.ErrEmailAlreadyExists) {
 		c.log.Error("error", l.ErrAttr(err))
 		http.Error(w, jsonError(err), http.StatusBadRequest)
@@ -62,24 +110,36 @@ func (c *Controller) SignUpUser(w http.ResponseWriter, r *http.Request) {
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param payload body loginPayload true "Login credentials"
+// @Param payload body signInPayload true "Login credentials and device"
 // @Success 200 {object} entity.JWT
+// @Success 202 {object} entity.JWT "TOTP challenge to be completed at /auth/login/totp"
 // @Failure 400 {object} response
+// @Failure 401 {object} response
+// @Failure 409 {object} response
+// @Failure 429 {object} response
 // @Failure 500 {object} response
 // @Router /auth/login [post]
 func (c *Controller) SignInUser(w http.ResponseWriter, r *http.Request) {
-	var payload loginPayload
+	var payload signInPayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		c.log.Error("error", l.ErrAttr(err))
 		http.Error(w, jsonError(err), http.StatusBadRequest)
 		return
 	}
+	device, err := payload.Device.device(r)
+	if err != nil {
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
 
-	jwtToken, err := c.uc.SignInUser(r.Context(), payload.Email, payload.Password)
+	jwtToken, err := c.uc.SignInUser(r.Context(), payload.Email, payload.Password, device)
 	if err != nil {
 		if errors.Is(err, errs.ErrWrongCredentials) {
 			c.log.Error("error", l.ErrAttr(err))
-			http.Error(w, jsonError(err), http.StatusBadRequest)
+			http.Error(w, jsonError(err), http.StatusUnauthorized)
+		} else if errors.Is(err, errs.ErrAuthUpgradeRequired) {
+			c.log.Error("error", l.ErrAttr(err))
+			http.Error(w, jsonError(err), http.StatusConflict)
 		} else {
 			c.log.Error("error", l.ErrAttr(err))
 			http.Error(w, jsonError(err), http.StatusInternalServerError)
@@ -87,6 +147,92 @@ func (c *Controller) SignInUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if jwtToken.TOTPChallenge != "" {
+		c.writeTOTPChallenge(w, jwtToken)
+		return
+	}
+	c.setLoginCookies(w, jwtToken)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(jwtToken)
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpgradeUser godoc
+// @Summary Upgrade a user to the auth hash
+// @Description Replace the master password of a user registered before the auth hash with the auth hash and generate JWT tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body upgradePayload true "Master password and auth hash"
+// @Success 200 {object} entity.JWT
+// @Success 202 {object} entity.JWT "TOTP challenge to be completed at /auth/login/totp"
+// @Failure 400 {object} response
+// @Failure 429 {object} response
+// @Failure 500 {object} response
+// @Router /auth/upgrade [post]
+func (c *Controller) UpgradeUser(w http.ResponseWriter, r *http.Request) {
+	var payload upgradePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	if payload.AuthHash == "" {
+		http.Error(w, jsonError(errAuthHashNotGiven), http.StatusBadRequest)
+		return
+	}
+	device, err := payload.Device.device(r)
+	if err != nil {
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+
+	jwtToken, err := c.uc.UpgradeUser(r.Context(), payload.Email, payload.Password, payload.AuthHash, device)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongCredentials), errors.Is(err, errs.ErrWrongEmail):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	if jwtToken.TOTPChallenge != "" {
+		c.writeTOTPChallenge(w, jwtToken)
+		return
+	}
+	c.setLoginCookies(w, jwtToken)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(jwtToken); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeTOTPChallenge responds to a sign in that has to be completed with a TOTP code.
+// No tokens are issued and no cookies are set until then.
+func (c *Controller) writeTOTPChallenge(w http.ResponseWriter, jwtToken entity.JWT) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(entity.JWT{TOTPChallenge: jwtToken.TOTPChallenge}); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+	}
+}
+
+// setLoginCookies sets the token cookies of a signed in user.
+func (c *Controller) setLoginCookies(w http.ResponseWriter, jwtToken entity.JWT) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "access_token",
 		Value:    jwtToken.AccessToken,
@@ -119,26 +265,19 @@ func (c *Controller) SignInUser(w http.ResponseWriter, r *http.Request) {
 		Secure:   c.cfg.Network.HTTPS,
 		SameSite: http.SameSiteNoneMode,
 	})
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(jwtToken)
-	if err != nil {
-		c.log.Error("error", l.ErrAttr(err))
-		http.Error(w, jsonError(err), http.StatusInternalServerError)
-		return
-	}
 }
 
 // RefreshAccessToken godoc
 // @Summary Refresh JWT access token
-// @Description Refresh the JWT access token using the refresh token
+// @Description Refresh the JWT access token using the refresh token. The refresh token is rotated:
+// @Description the response holds a new one and the given one stops working. Reusing a rotated refresh token revokes the session.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Success 200 {object} entity.JWT
 // @Failure 400 {object} response
-// @Router /auth/refresh [post]
+// @Failure 401 {object} response
+// @Router /auth/refresh [get]
 func (c *Controller) RefreshAccessToken(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	refreshToken, err := r.Cookie("refresh_token")
@@ -148,34 +287,18 @@ func (c *Controller) RefreshAccessToken(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	jwt, err := c.uc.RefreshAccessToken(ctx, refreshToken.Value)
+	jwt, err := c.uc.RefreshAccessToken(ctx, refreshToken.Value, request.ClientIP(ctx))
 	if err != nil {
 		c.log.Error("error", l.ErrAttr(err))
-		http.Error(w, jsonError(err), http.StatusBadRequest)
+		if errors.Is(err, errs.ErrTokenReused) || errors.Is(err, errs.ErrSessionRevoked) {
+			http.Error(w, jsonError(err), http.StatusUnauthorized)
+		} else {
+			http.Error(w, jsonError(err), http.StatusBadRequest)
+		}
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "access_token",
-		Value:    jwt.AccessToken,
-		MaxAge:   jwt.AccessTokenMaxAge,
-		Path:     "/",
-		Domain:   jwt.Domain,
-		HttpOnly: true,
-		Secure:   c.cfg.Network.HTTPS,
-		SameSite: http.SameSiteNoneMode,
-	})
-
-	http.SetCookie(w, &http.Cookie{
-		Name:     "logged_in",
-		Value:    "true",
-		MaxAge:   jwt.AccessTokenMaxAge,
-		Path:     "/",
-		Domain:   jwt.Domain,
-		HttpOnly: false,
-		Secure:   c.cfg.Network.HTTPS,
-		SameSite: http.SameSiteNoneMode,
-	})
+	c.setLoginCookies(w, jwt)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -188,14 +311,30 @@ func (c *Controller) RefreshAccessToken(w http.ResponseWriter, r *http.Request)
 
 // LogoutUser godoc
 // @Summary Log out the user
-// @Description Clear JWT tokens and user session
+// @Description Revoke the session of the refresh or access token on the server and clear JWT tokens.
+// @Description Access tokens of the revoked session stop working at once.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Success 200 {object} response
 // @Failure 500 {object} response
-// @Router /auth/logout [post]
-func (c *Controller) LogoutUser(w http.ResponseWriter, _ *http.Request) {
+// @Router /auth/logout [get]
+func (c *Controller) LogoutUser(w http.ResponseWriter, r *http.Request) {
+	var refreshToken string
+	if refreshTokenFromCookie, err := r.Cookie("refresh_token"); err == nil {
+		refreshToken = refreshTokenFromCookie.Value
+	}
+	accessToken := accessTokenFromRequest(r)
+
+	if refreshToken != "" || accessToken != "" {
+		err := c.uc.LogoutUser(r.Context(), refreshToken, accessToken)
+		if err != nil && !errors.Is(err, errs.ErrTokenValidation) {
+			c.log.Error("error", l.ErrAttr(err))
+			http.Error(w, jsonError(err), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	domainName := c.uc.GetDomainName()
 
 	http.SetCookie(w, &http.Cookie{
@@ -22,26 +22,43 @@ func TestSignUpUser(t *testing.T) {
 
 	tests := []struct {
 		name           string
-		payload        *loginPayload
+		payload        *signUpPayload
+		invalid        bool // The payload is rejected before the use case is called.
 		expectedStatus int
 		expectedEmail  string
 		expectedError  error
 	}{
 		{
 			name: "successful signup",
-			payload: &loginPayload{
-				Email:    "test@example.com",
-				Password: "password",
+			payload: &signUpPayload{
+				loginPayload: loginPayload{Email: "test@example.com", Password: "password"},
 			},
 			expectedStatus: http.StatusCreated,
 			expectedEmail:  "test@example.com",
 			expectedError:  nil,
 		},
+		{
+			name: "signup with vault keys",
+			payload: &signUpPayload{
+				loginPayload: loginPayload{Email: "test@example.com", Password: "password"},
+				VaultKeys:    entity.VaultKeys{DataKey: "data key", RecoveryAuth: "auth", RecoveryKey: "recovery key"},
+			},
+			expectedStatus: http.StatusCreated,
+			expectedEmail:  "test@example.com",
+		},
+		{
+			name: "recovery key without data key",
+			payload: &signUpPayload{
+				loginPayload: loginPayload{Email: "test@example.com", Password: "password"},
+				VaultKeys:    entity.VaultKeys{RecoveryAuth: "auth", RecoveryKey: "recovery key"},
+			},
+			invalid:        true,
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name: "invalid email",
-			payload: &loginPayload{
-				Email:    "invalid",
-				Password: "password",
+			payload: &signUpPayload{
+				loginPayload: loginPayload{Email: "invalid", Password: "password"},
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedEmail:  "",
@@ -49,9 +66,8 @@ func TestSignUpUser(t *testing.T) {
 		},
 		{
 			name: "email already exists",
-			payload: &loginPayload{
-				Email:    "exists@example.com",
-				Password: "password",
+			payload: &signUpPayload{
+				loginPayload: loginPayload{Email: "exists@example.com", Password: "password"},
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedEmail:  "",
@@ -59,9 +75,8 @@ func TestSignUpUser(t *testing.T) {
 		},
 		{
 			name: "internal error",
-			payload: &loginPayload{
-				Email:    "test@example.com",
-				Password: "password",
+			payload: &signUpPayload{
+				loginPayload: loginPayload{Email: "test@example.com", Password: "password"},
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedEmail:  "",
@@ -71,17 +86,21 @@ func TestSignUpUser(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.expectedError != nil {
+			switch {
+			case tt.invalid:
+			case tt.expectedError != nil:
 				mockUseCase.EXPECT().SignUpUser(
 					gomock.Any(),
 					tt.payload.Email,
 					tt.payload.Password,
+					tt.payload.VaultKeys,
 				).Return(entity.User{}, tt.expectedError)
-			} else {
+			default:
 				mockUseCase.EXPECT().SignUpUser(
 					gomock.Any(),
 					tt.payload.Email,
 					tt.payload.Password,
+					tt.payload.VaultKeys,
 				).Return(entity.User{Email: tt.payload.Email}, nil)
 			}
 
@@ -111,52 +130,96 @@ func TestSignInUser(t *testing.T) {
 
 	tests := []struct {
 		name           string
-		payload        *loginPayload
+		payload        *signInPayload
+		mockCall       bool
+		mockReturn     entity.JWT
 		expectedStatus int
 		expectedBody   string
 		expectedError  error
 	}{
 		{
 			name: "successful signin",
-			payload: &loginPayload{
-				Email:    "test@example.com",
-				Password: "password",
+			payload: &signInPayload{
+				loginPayload: loginPayload{Email: "test@example.com", Password: "password"},
+				Device:       devicePayload{Name: "laptop", Platform: "linux/amd64"},
 			},
+			mockCall:       true,
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"access_token":"access-token","refresh_token":"refresh-token"}`,
 			expectedError:  nil,
 		},
 		{
 			name: "wrong credentials",
-			payload: &loginPayload{
-				Email:    "wrong@example.com",
-				Password: "wrong password",
+			payload: &signInPayload{
+				loginPayload: loginPayload{Email: "wrong@example.com", Password: "wrong password"},
+				Device:       devicePayload{Name: "laptop", Platform: "linux/amd64"},
 			},
-			expectedStatus: http.StatusBadRequest,
+			mockCall:       true,
+			expectedStatus: http.StatusUnauthorized,
 			expectedBody:   `{"error":"wrong credentials"}`,
 			expectedError:  errs.ErrWrongCredentials,
 		},
+		{
+			name: "upgrade required",
+			payload: &signInPayload{
+				loginPayload: loginPayload{Email: "legacy@example.com", Password: "master password"},
+				Device:       devicePayload{Name: "laptop", Platform: "linux/amd64"},
+			},
+			mockCall:       true,
+			expectedStatus: http.StatusConflict,
+			expectedError:  errs.ErrAuthUpgradeRequired,
+		},
 		{
 			name: "internal error",
-			payload: &loginPayload{
-				Email:    "test@example.com",
-				Password: "password",
+			payload: &signInPayload{
+				loginPayload: loginPayload{Email: "test@example.com", Password: "password"},
+				Device:       devicePayload{Name: "laptop", Platform: "linux/amd64"},
 			},
+			mockCall:       true,
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"internal error"}`,
 			expectedError:  errors.New("internal error"),
 		},
+		{
+			name: "totp challenge",
+			payload: &signInPayload{
+				loginPayload: loginPayload{Email: "totp@example.com", Password: "password"},
+				Device:       devicePayload{Name: "laptop", Platform: "linux/amd64"},
+			},
+			mockCall:       true,
+			mockReturn:     entity.JWT{TOTPChallenge: "challenge"},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "device name too long",
+			payload: &signInPayload{
+				loginPayload: loginPayload{Email: "test@example.com", Password: "password"},
+				Device:       devicePayload{Name: strings.Repeat("a", maxDeviceNameLength+1)},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.expectedError != nil {
+			device := entity.Device{Name: tt.payload.Device.Name, Platform: tt.payload.Device.Platform}
+			switch {
+			case !tt.mockCall:
+			case tt.expectedError != nil:
 				mockUseCase.EXPECT().SignInUser(
 					gomock.Any(),
 					tt.payload.Email,
 					tt.payload.Password,
+					device,
 				).Return(entity.JWT{}, tt.expectedError)
-			} else {
+			case tt.mockReturn.TOTPChallenge != "":
+				mockUseCase.EXPECT().SignInUser(
+					gomock.Any(),
+					tt.payload.Email,
+					tt.payload.Password,
+					device,
+				).Return(tt.mockReturn, nil)
+			default:
 				jwtToken := entity.JWT{
 					AccessToken:        "access-token",
 					RefreshToken:       "refresh-token",
@@ -168,6 +231,7 @@ func TestSignInUser(t *testing.T) {
 					gomock.Any(),
 					tt.payload.Email,
 					tt.payload.Password,
+					device,
 				).Return(jwtToken, nil)
 			}
 
@@ -189,6 +253,91 @@ func TestSignInUser(t *testing.T) {
 				assert.Contains(t, response, "access_token")
 				assert.Contains(t, response, "refresh_token")
 			}
+			if tt.expectedStatus == http.StatusAccepted {
+				assert.Empty(t, rr.Result().Cookies())
+				assert.Equal(t, `{"access_token":"","refresh_token":"","totp_challenge":"challenge"}`,
+					strings.TrimSpace(rr.Body.String()))
+			}
+		})
+	}
+}
+
+func TestUpgradeUser(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name           string
+		payload        *upgradePayload
+		mockCall       bool
+		expectedStatus int
+		expectedError  error
+	}{
+		{
+			name: "successful upgrade",
+			payload: &upgradePayload{
+				Email:    "test@example.com",
+				Password: "password",
+				AuthHash: "auth hash",
+			},
+			mockCall:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "no auth hash",
+			payload: &upgradePayload{
+				Email:    "test@example.com",
+				Password: "password",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "wrong credentials",
+			payload: &upgradePayload{
+				Email:    "test@example.com",
+				Password: "wrong password",
+				AuthHash: "auth hash",
+			},
+			mockCall:       true,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  errs.ErrWrongCredentials,
+		},
+		{
+			name: "internal error",
+			payload: &upgradePayload{
+				Email:    "test@example.com",
+				Password: "password",
+				AuthHash: "auth hash",
+			},
+			mockCall:       true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  errors.New("internal error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				mockUseCase.EXPECT().UpgradeUser(
+					gomock.Any(),
+					tt.payload.Email,
+					tt.payload.Password,
+					tt.payload.AuthHash,
+					entity.Device{},
+				).Return(entity.JWT{AccessToken: "access-token", RefreshToken: "refresh-token"}, tt.expectedError)
+			}
+
+			body, _ := json.Marshal(tt.payload)
+			req, _ := http.NewRequest(http.MethodPost, authUpgrade, bytes.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.UpgradeUser).ServeHTTP(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				assert.Len(t, rr.Result().Cookies(), 3)
+				assert.Contains(t, rr.Body.String(), "access-token")
+			}
 		})
 	}
 }
@@ -221,6 +370,14 @@ func TestRefreshAccessToken(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   `{"error":"unexpected error"}`,
 		},
+		{
+			name:           "reused refresh token",
+			cookieValue:    "rotated_refresh_token",
+			mockReturn:     entity.JWT{},
+			mockError:      errs.ErrTokenReused,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"refresh token has already been used, the session has been revoked"}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -233,9 +390,9 @@ func TestRefreshAccessToken(t *testing.T) {
 			rr := httptest.NewRecorder()
 
 			if tt.mockError != nil {
-				mockUseCase.EXPECT().RefreshAccessToken(gomock.Any(), tt.cookieValue).Return(entity.JWT{}, tt.mockError)
+				mockUseCase.EXPECT().RefreshAccessToken(gomock.Any(), tt.cookieValue, "").Return(entity.JWT{}, tt.mockError)
 			} else {
-				mockUseCase.EXPECT().RefreshAccessToken(gomock.Any(), tt.cookieValue).Return(tt.mockReturn, nil)
+				mockUseCase.EXPECT().RefreshAccessToken(gomock.Any(), tt.cookieValue, "").Return(tt.mockReturn, nil)
 			}
 
 			http.HandlerFunc(c.RefreshAccessToken).ServeHTTP(rr, req)
@@ -255,10 +412,11 @@ func TestLogoutUser(t *testing.T) {
 	c, mockUseCase, ctrl := loadTest(t)
 	defer ctrl.Finish()
 
-	mockUseCase.EXPECT().GetDomainName().Return("example.com")
-
 	tests := []struct {
 		name           string
+		refreshToken   string
+		accessToken    string
+		mockError      error
 		expectedStatus int
 		expectedBody   string
 	}{
@@ -267,14 +425,48 @@ func TestLogoutUser(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"status":"logout success"}`,
 		},
+		{
+			name:           "session revoked",
+			refreshToken:   "refresh-token",
+			accessToken:    "access-token",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"logout success"}`,
+		},
+		{
+			name:           "invalid tokens",
+			accessToken:    "access-token",
+			mockError:      errs.ErrTokenValidation,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"logout success"}`,
+		},
+		{
+			name:           "revocation failed",
+			refreshToken:   "refresh-token",
+			mockError:      errors.New("unexpected error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"unexpected error"}`,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			req, err := http.NewRequest(http.MethodPost, authLogout, nil)
+			req, err := http.NewRequest(http.MethodGet, authLogout, nil)
 			if err != nil {
 				t.Fatalf("Failed to create request: %v", err)
 			}
+			if tt.refreshToken != "" {
+				req.AddCookie(&http.Cookie{Name: "refresh_token", Value: tt.refreshToken})
+			}
+			if tt.accessToken != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.accessToken)
+			}
+
+			if tt.refreshToken != "" || tt.accessToken != "" {
+				mockUseCase.EXPECT().LogoutUser(gomock.Any(), tt.refreshToken, tt.accessToken).Return(tt.mockError)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				mockUseCase.EXPECT().GetDomainName().Return("example.com")
+			}
 
 			rr := httptest.NewRecorder()
 			http.HandlerFunc(c.LogoutUser).ServeHTTP(rr, req)
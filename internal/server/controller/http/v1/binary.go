@@ -22,6 +22,7 @@ var errBinaryNameNotGiven = errors.New("binary name has not given")
 // @Accept multipart/form-data
 // @Produce json
 // @Param name query string true "Binary name"
+// @Param id query string false "Binary ID chosen by the client"
 // @Param file formData file true "Binary file"
 // @Success 201 {object} entity.Binary
 // @Failure 400 {object} response
@@ -42,6 +43,13 @@ func (c *Controller) AddBinary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	binary.Name = r.URL.Query().Get("name")
+	if binaryID := r.URL.Query().Get("id"); binaryID != "" {
+		if binary.ID, err = uuid.Parse(binaryID); err != nil {
+			c.log.Error("error", l.ErrAttr(err))
+			http.Error(w, jsonError(err), http.StatusBadRequest)
+			return
+		}
+	}
 
 	_, file, err := r.FormFile("file")
 	if err != nil {
@@ -149,6 +157,7 @@ func (c *Controller) DownloadBinary(w http.ResponseWriter, r *http.Request) {
 // @Param metadata body []entity.Meta true "Metadata for the binary"
 // @Success 201 {array} entity.Meta
 // @Failure 400 {object} response
+// @Failure 404 {object} response
 // @Failure 500 {object} response
 // @Router /user/binary/{id}/meta [post]
 func (c *Controller) AddBinaryMeta(w http.ResponseWriter, r *http.Request) {
@@ -174,7 +183,12 @@ func (c *Controller) AddBinaryMeta(w http.ResponseWriter, r *http.Request) {
 	}
 
 	binary, err := c.uc.AddBinaryMeta(r.Context(), &currentUser, binaryUUID, payloadMeta)
-	if err != nil {
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongOwnerOrNotFound):
+		http.Error(w, jsonError(err), http.StatusNotFound)
+		return
+	default:
 		c.log.Error("error", l.ErrAttr(err))
 		http.Error(w, jsonError(err), http.StatusInternalServerError)
 		return
@@ -37,6 +37,7 @@ func TestAddBinary(t *testing.T) {
 		expectedStatus int
 		expectedBody   string
 		expectedError  error
+		skipUseCase    bool
 	}{
 		{
 			name: "successful add binary",
@@ -48,6 +49,27 @@ func TestAddBinary(t *testing.T) {
 			expectedBody:   `{"name":"test-binary","file_name":"file.jpg","uuid":"00000000-0000-0000-0000-000000000000","meta":null}`,
 			expectedError:  nil,
 		},
+		{
+			name: "client chosen id",
+			queryParams: map[string]string{
+				"name": "test-binary",
+				"id":   "89dacc37-e9cb-4e9a-833b-7b8c0062b449",
+			},
+			fileContent:    []byte("file content"),
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"name":"test-binary","file_name":"file.jpg","uuid":"89dacc37-e9cb-4e9a-833b-7b8c0062b449","meta":null}`,
+		},
+		{
+			name: "invalid id",
+			queryParams: map[string]string{
+				"name": "test-binary",
+				"id":   "invalid-uuid",
+			},
+			fileContent:    []byte("file content"),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid UUID length: 12"}`,
+			skipUseCase:    true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -81,9 +103,11 @@ func TestAddBinary(t *testing.T) {
 
 			rr := httptest.NewRecorder()
 
-			if tt.expectedError != nil {
+			switch {
+			case tt.skipUseCase:
+			case tt.expectedError != nil:
 				mockUseCase.EXPECT().AddBinary(gomock.Any(), gomock.Any(), gomock.Any(), expectedUser.ID).Return(tt.expectedError)
-			} else {
+			default:
 				mockUseCase.EXPECT().AddBinary(gomock.Any(), gomock.Any(), gomock.Any(), expectedUser.ID).Return(nil)
 			}
 
@@ -356,6 +380,13 @@ func TestAddBinaryMeta(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"internal error"}` + "\n",
 		},
+		{
+			name:           "Meta of another item",
+			binaryUUID:     "89dacc37-e9cb-4e9a-833b-7b8c0062b449",
+			payload:        []entity.Meta{{ID: uuid.New(), Name: "test", Value: "value"}},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"wrong owner or not found"}` + "\n",
+		},
 		{
 			name:           "Invalid JSON Payload",
 			binaryUUID:     "89dacc37-e9cb-4e9a-833b-7b8c0062b449",
@@ -386,6 +417,14 @@ func TestAddBinaryMeta(t *testing.T) {
 					gomock.Any(),
 				).Return(nil, errors.New("internal error")).Times(1)
 
+			case "Meta of another item":
+				mockUseCase.EXPECT().AddBinaryMeta(
+					gomock.Any(),
+					gomock.Any(),
+					uuid.MustParse(tt.binaryUUID),
+					gomock.Any(),
+				).Return(nil, errs.ErrWrongOwnerOrNotFound).Times(1)
+
 			case "Invalid UUID", "Invalid JSON Payload":
 			default:
 				t.Fatalf("Unknown test case: %s", tt.name)
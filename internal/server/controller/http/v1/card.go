@@ -2,6 +2,7 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -98,6 +99,8 @@ func (c *Controller) GetCards(w http.ResponseWriter, r *http.Request) {
 // @Param card body entity.Card true "Updated card data"
 // @Success 202 {string} string "Update accepted"
 // @Failure 400 {object} response
+// @Failure 404 {object} response
+// @Failure 409 {object} response
 // @Failure 500 {object} response
 // @Router /user/cards/{id} [patch]
 func (c *Controller) UpdateCard(w http.ResponseWriter, r *http.Request) {
@@ -123,7 +126,17 @@ func (c *Controller) UpdateCard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	payloadCard.ID = cardUUID
-	if err = c.uc.UpdateCard(r.Context(), payloadCard, currentUser.ID); err != nil {
+	err = c.uc.UpdateCard(r.Context(), payloadCard, currentUser.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrRevisionConflict):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusConflict)
+		return
+	case errors.Is(err, errs.ErrWrongOwnerOrNotFound):
+		http.Error(w, jsonError(err), http.StatusNotFound)
+		return
+	default:
 		c.log.Error("error", l.ErrAttr(err))
 		http.Error(w, jsonError(err), http.StatusInternalServerError)
 		return
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
 )
 
 func TestAddCard(t *testing.T) {
@@ -287,6 +288,22 @@ func TestUpdateCard(t *testing.T) {
 			cardID:         validUUID.String(),
 			reqBody:        card,
 		},
+		{
+			name:           "stale base revision",
+			mockReturn:     errs.ErrRevisionConflict,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"item has been changed since the base revision"}` + "\n",
+			cardID:         validUUID.String(),
+			reqBody:        card,
+		},
+		{
+			name:           "meta of another item",
+			mockReturn:     errs.ErrWrongOwnerOrNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"wrong owner or not found"}` + "\n",
+			cardID:         validUUID.String(),
+			reqBody:        card,
+		},
 		{
 			name:           "invalid UUID in URL",
 			mockReturn:     nil,
@@ -307,7 +324,8 @@ func TestUpdateCard(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.expectedStatus == http.StatusAccepted || tt.expectedStatus == http.StatusInternalServerError {
+			if tt.expectedStatus == http.StatusAccepted || tt.expectedStatus == http.StatusInternalServerError ||
+				tt.expectedStatus == http.StatusConflict || tt.expectedStatus == http.StatusNotFound {
 				mockUseCase.EXPECT().
 					UpdateCard(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(tt.mockReturn).Times(1)
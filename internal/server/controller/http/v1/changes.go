@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+var errWrongCursor = errors.New("wrong cursor")
+
+// GetChanges godoc
+// @Summary Get the items changed since a cursor
+// @Description Retrieve the items of the current user created, updated or deleted since the cursor,
+// @Description the whole vault without a cursor. The cursor of the response is the one of the next request
+// @Tags sync
+// @Produce json
+// @Param since query int false "Cursor of the previous request"
+// @Success 200 {object} entity.Changes
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Router /user/changes [get]
+func (c *Controller) GetChanges(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	var since int64
+	if cursor := r.URL.Query().Get("since"); cursor != "" {
+		if since, err = strconv.ParseInt(cursor, 10, 64); err != nil || since < 0 {
+			http.Error(w, jsonError(errWrongCursor), http.StatusBadRequest)
+			return
+		}
+	}
+
+	changes, err := c.uc.GetChanges(r.Context(), currentUser, since)
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(changes); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+func TestGetChanges(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New(), Email: "test@example.com"}
+	changes := entity.Changes{
+		Cursor:  42,
+		Logins:  []entity.Login{{ID: uuid.New(), Name: "login", Revision: 41}},
+		Deleted: []entity.DeletedItem{{ID: uuid.New(), Type: entity.ItemCards, Revision: 42}},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockCall       bool
+		since          int64
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "changes since the cursor",
+			query:          "?since=40",
+			mockCall:       true,
+			since:          40,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "without a cursor",
+			mockCall:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "internal error",
+			query:          "?since=40",
+			mockCall:       true,
+			since:          40,
+			mockError:      errors.New("internal error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "wrong cursor",
+			query:          "?since=abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative cursor",
+			query:          "?since=-1",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				mockUseCase.EXPECT().GetChanges(gomock.Any(), expectedUser, tt.since).Return(changes, tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, userChanges+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.GetChanges).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response entity.Changes
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, changes, response)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	config "github.com/nextlag/keeper/config/server"
 	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/server/mw/limiter"
 	"github.com/nextlag/keeper/internal/server/mw/request"
 	"github.com/nextlag/keeper/pkg/logger/l"
 )
@@ -23,11 +24,30 @@ import (
 //go:generate mockgen -destination=mocks/mocks.go -package=mocks github.com/nextlag/keeper/internal/server/controller/http/v1 UseCase
 type UseCase interface {
 	HealthCheck() error
-	SignUpUser(ctx context.Context, email, password string) (entity.User, error)
-	SignInUser(ctx context.Context, email, password string) (entity.JWT, error)
-	RefreshAccessToken(ctx context.Context, refreshToken string) (entity.JWT, error)
+	SignUpUser(ctx context.Context, email, password string, keys entity.VaultKeys) (entity.User, error)
+	SignInUser(ctx context.Context, email, password string, device entity.Device) (entity.JWT, error)
+	UpgradeUser(ctx context.Context, email, password, authHash string, device entity.Device) (entity.JWT, error)
+	RefreshAccessToken(ctx context.Context, refreshToken, ip string) (entity.JWT, error)
+	LogoutUser(ctx context.Context, refreshToken, accessToken string) error
+	SignInTOTP(ctx context.Context, email, challenge, code string) (entity.JWT, error)
+	DisableTOTP(ctx context.Context, email, password, recoveryCode string) error
+	EnrollTOTP(ctx context.Context, currentUser entity.User) (entity.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, currentUser entity.User, code string) (entity.RecoveryCodes, error)
+	SetRecoveryKey(ctx context.Context, currentUser entity.User, recoveryKey entity.RecoveryKey) error
+	GetRecoveryKey(ctx context.Context, email, recoveryAuth string) (string, error)
+	RecoverAccount(ctx context.Context, recovery entity.Recovery) error
+	DeleteUser(ctx context.Context, currentUser entity.User, password, code string) error
 	GetDomainName() string
-	CheckAccessToken(ctx context.Context, accessToken string) (entity.User, error)
+	JWKS() entity.JWKS
+	CheckAccessToken(ctx context.Context, accessToken, ip string) (entity.User, error)
+
+	GetDevices(ctx context.Context, currentUser entity.User) ([]entity.Session, error)
+	RenameDevice(ctx context.Context, currentUser entity.User, deviceID uuid.UUID, name string) error
+	RevokeDevice(ctx context.Context, currentUser entity.User, deviceID uuid.UUID) error
+
+	GetPersonalTokens(ctx context.Context, currentUser entity.User) ([]entity.PersonalToken, error)
+	CreatePersonalToken(ctx context.Context, currentUser entity.User, token entity.PersonalToken) (entity.PersonalToken, error)
+	RevokePersonalToken(ctx context.Context, currentUser entity.User, tokenID uuid.UUID) error
 
 	GetLogins(ctx context.Context, user entity.User) ([]entity.Login, error)
 	AddLogin(ctx context.Context, login *entity.Login, userID uuid.UUID) error
@@ -49,18 +69,31 @@ type UseCase interface {
 	GetUserBinary(ctx context.Context, currentUser *entity.User, binaryUUID uuid.UUID) (string, error)
 	DelUserBinary(ctx context.Context, currentUser *entity.User, binaryUUID uuid.UUID) error
 	AddBinaryMeta(ctx context.Context, currentUser *entity.User, binaryUUID uuid.UUID, meta []entity.Meta) (*entity.Binary, error)
+
+	GetChanges(ctx context.Context, currentUser entity.User, since int64) (entity.Changes, error)
+	SubscribeEvents(ctx context.Context, userID uuid.UUID, lastEventID int64) ([]entity.Event, <-chan entity.Event)
+	ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key, fingerprint string) (entity.IdempotentResponse, error)
+	SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, response entity.IdempotentResponse) error
+	ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error
+
+	StageRekey(ctx context.Context, rekey *entity.Rekey, userID uuid.UUID) error
+	StageRekeyBinary(ctx context.Context, currentUser *entity.User, binaryUUID uuid.UUID, file *multipart.FileHeader) error
+	GetDataKey(ctx context.Context, currentUser *entity.User) (string, error)
+	ChangePassword(ctx context.Context, currentUser *entity.User, oldPassword, newPassword, dataKey string) error
+	RotateDataKey(ctx context.Context, currentUser *entity.User, password, dataKey string) error
 }
 
 // Controller represents the HTTP handlers controller.
 type Controller struct {
-	uc  UseCase // The UseCase used to perform business logic operations.
-	cfg *config.Config
-	log *l.Logger
+	uc      UseCase // The UseCase used to perform business logic operations.
+	cfg     *config.Config
+	log     *l.Logger
+	limiter *limiter.Limiter // Throttles the authentication endpoints.
 }
 
 // NewController creates a new instance of the controller.
 func NewController(uc UseCase, cfg *config.Config, log *l.Logger) *Controller {
-	return &Controller{uc: uc, cfg: cfg, log: log}
+	return &Controller{uc: uc, cfg: cfg, log: log, limiter: limiter.New(cfg.Security)}
 }
 
 // NewServer creates a new HTTP server with specified routes and middleware.
@@ -69,14 +102,28 @@ func (c *Controller) NewServer(handler *chi.Mux) *http.Server {
 	handler.Use(request.MwRequest(c.log))
 	handler.Use(middleware.Recoverer)
 
+	// Public keys of the access tokens for other services
+	handler.Get("/.well-known/jwks.json", c.JWKS)
+
 	// Add version prefix here
 	handler.Route("/api/v1", func(r chi.Router) {
 		r.Get("/ping", c.HealthCheck) // Endpoint for health check
 
 		// Routes for authentication
 		r.Route("/auth", func(r chi.Router) {
-			r.Post("/register", c.SignUpUser)
-			r.Post("/login", c.SignInUser)
+			// Endpoints checking credentials are throttled per IP address,
+			// the ones checking a password or a recovery key also per account.
+			r.Group(func(r chi.Router) {
+				r.Use(c.MwLimitIP())
+				r.Post("/register", c.SignUpUser)
+				r.With(c.MwLimitAccount()).Post("/login/totp", c.SignInTOTP)
+
+				r.With(c.MwLimitAccount()).Post("/login", c.SignInUser)
+				r.With(c.MwLimitAccount()).Post("/totp/disable", c.DisableTOTP)
+				r.With(c.MwLimitAccount()).Post("/upgrade", c.UpgradeUser)
+				r.With(c.MwLimitAccount()).Post("/recover/key", c.GetRecoveryKey)
+				r.With(c.MwLimitAccount()).Post("/recover", c.RecoverAccount)
+			})
 			r.Get("/refresh", c.RefreshAccessToken)
 			r.Get("/logout", c.LogoutUser)
 		})
@@ -85,27 +132,53 @@ func (c *Controller) NewServer(handler *chi.Mux) *http.Server {
 		r.Route("/user", func(r chi.Router) {
 			r.Use(c.MwAuth())        // Middleware for user authentication
 			r.Get("/me", c.UserInfo) // Endpoint for retrieving current user information
-
-			r.Post("/logins", c.AddLogin)
-			r.Get("/logins", c.GetLogins)
-			r.Delete("/logins/{id}", c.DelLogin)
-			r.Patch("/logins/{id}", c.UpdateLogin)
-
-			r.Post("/cards", c.AddCard)
-			r.Get("/cards", c.GetCards)
-			r.Delete("/cards/{id}", c.DelCard)
-			r.Patch("/cards/{id}", c.UpdateCard)
-
-			r.Post("/notes", c.AddNote)
-			r.Get("/notes", c.GetNotes)
-			r.Delete("/notes/{id}", c.DelNote)
-			r.Patch("/notes/{id}", c.UpdateNote)
-
-			r.Post("/binary", c.AddBinary)
-			r.Post("/binary/{id}/meta", c.AddBinaryMeta)
-			r.Get("/binary", c.GetBinaries)
-			r.Get("/binary/{id}", c.DownloadBinary)
-			r.Delete("/binary/{id}", c.DelBinary)
+			r.Delete("/me", c.DeleteUser)
+
+			r.Post("/totp", c.EnrollTOTP)
+			r.Post("/totp/confirm", c.ConfirmTOTP)
+			r.Put("/recovery", c.SetRecoveryKey)
+
+			// Item changes replayed from the outbox of a client are applied once per idempotency key.
+			r.Group(func(r chi.Router) {
+				r.Use(c.MwIdempotency())
+				r.Post("/logins", c.AddLogin)
+				r.Get("/logins", c.GetLogins)
+				r.Delete("/logins/{id}", c.DelLogin)
+				r.Patch("/logins/{id}", c.UpdateLogin)
+
+				r.Post("/cards", c.AddCard)
+				r.Get("/cards", c.GetCards)
+				r.Delete("/cards/{id}", c.DelCard)
+				r.Patch("/cards/{id}", c.UpdateCard)
+
+				r.Post("/notes", c.AddNote)
+				r.Get("/notes", c.GetNotes)
+				r.Delete("/notes/{id}", c.DelNote)
+				r.Patch("/notes/{id}", c.UpdateNote)
+
+				r.Post("/binary", c.AddBinary)
+				r.Post("/binary/{id}/meta", c.AddBinaryMeta)
+				r.Get("/binary", c.GetBinaries)
+				r.Get("/binary/{id}", c.DownloadBinary)
+				r.Delete("/binary/{id}", c.DelBinary)
+			})
+
+			r.Get("/changes", c.GetChanges)
+			r.Get("/events", c.StreamEvents)
+
+			r.Post("/rekey", c.StageRekey)
+			r.Post("/rekey/binary/{id}", c.StageRekeyBinary)
+			r.Post("/password", c.ChangePassword)
+			r.Get("/key", c.GetDataKey)
+			r.Put("/key", c.RotateDataKey)
+
+			r.Get("/devices", c.GetDevices)
+			r.Patch("/devices/{id}", c.RenameDevice)
+			r.Delete("/devices/{id}", c.RevokeDevice)
+
+			r.Get("/tokens", c.GetPersonalTokens)
+			r.Post("/tokens", c.CreatePersonalToken)
+			r.Delete("/tokens/{id}", c.RevokePersonalToken)
 		})
 
 		// Swagger UI route
@@ -14,12 +14,22 @@ const (
 	// Health check
 	healthCheck = "/api/v1/ping"
 
+	// Public keys of the access tokens
+	wellKnownJWKS = "/.well-known/jwks.json"
+
 	// Authentication
 	userAuth     = "/api/v1/user/auth"
 	authRegister = "/api/v1/auth/register"
 	authLogin    = "/api/v1/auth/login"
+	authUpgrade  = "/api/v1/auth/upgrade"
 	authRefresh  = "/api/v1/auth/refresh"
 	authLogout   = "/api/v1/auth/logout"
+	authTOTP     = "/api/v1/auth/login/totp"
+	authTOTPOff  = "/api/v1/auth/totp/disable"
+
+	// Recovery with the recovery key
+	authRecover    = "/api/v1/auth/recover"
+	authRecoverKey = "/api/v1/auth/recover/key"
 
 	// User
 	userInfo          = "/api/v1/user/me"
@@ -28,6 +38,15 @@ const (
 	userNotes         = "/api/v1/user/notes"
 	userBinaryAddMeta = "/user/binary/{id}/meta"
 	userBinary        = "/api/v1/user/binary"
+	userRekey         = "/api/v1/user/rekey"
+	userPassword      = "/api/v1/user/password"
+	userKey           = "/api/v1/user/key"
+	userDevices       = "/api/v1/user/devices"
+	userTOTP          = "/api/v1/user/totp"
+	userTokens        = "/api/v1/user/tokens"
+	userRecovery      = "/api/v1/user/recovery"
+	userChanges       = "/api/v1/user/changes"
+	userEvents        = "/api/v1/user/events"
 )
 
 func loadTest(t *testing.T) (*Controller, *mocks.MockUseCase, *gomock.Controller) {
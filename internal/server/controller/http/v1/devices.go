@@ -0,0 +1,161 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// maxDeviceNameLength limits the name and the platform of a device.
+const maxDeviceNameLength = 255
+
+var (
+	errDeviceNameNotGiven = errors.New("device name has not given")
+	errDeviceNameTooLong  = errors.New("device name or platform is too long")
+)
+
+// renameDevicePayload holds the new name of a device.
+type renameDevicePayload struct {
+	Name string `json:"name"`
+}
+
+// GetDevices godoc
+// @Summary Get devices of the current user
+// @Description Retrieve the devices the current user is signed in on, the one of the request is marked as current
+// @Tags devices
+// @Produce json
+// @Success 200 {array} entity.Session
+// @Failure 500 {object} response
+// @Router /user/devices [get]
+func (c *Controller) GetDevices(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	devices, err := c.uc.GetDevices(r.Context(), currentUser)
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(devices); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+	}
+}
+
+// RenameDevice godoc
+// @Summary Rename a device by UUID
+// @Description Rename a device the current user is signed in on
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Param id path string true "Device UUID"
+// @Param payload body renameDevicePayload true "New device name"
+// @Success 202 {string} string "Update accepted"
+// @Failure 400 {object} response
+// @Failure 404 {object} response
+// @Failure 500 {object} response
+// @Router /user/devices/{id} [patch]
+func (c *Controller) RenameDevice(w http.ResponseWriter, r *http.Request) {
+	deviceUUID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err), "deviceUUID", deviceUUID)
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	var payload renameDevicePayload
+	if err = json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	if payload.Name == "" {
+		http.Error(w, jsonError(errDeviceNameNotGiven), http.StatusBadRequest)
+		return
+	}
+	if len(payload.Name) > maxDeviceNameLength {
+		http.Error(w, jsonError(errDeviceNameTooLong), http.StatusBadRequest)
+		return
+	}
+
+	err = c.uc.RenameDevice(r.Context(), currentUser, deviceUUID, payload.Name)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongOwnerOrNotFound):
+		http.Error(w, jsonError(err), http.StatusNotFound)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if _, err = w.Write([]byte(jsonResponse("update accepted"))); err != nil {
+		return
+	}
+}
+
+// RevokeDevice godoc
+// @Summary Revoke a device by UUID
+// @Description Sign the current user out on a device, its access and refresh tokens stop working at once
+// @Tags devices
+// @Param id path string true "Device UUID"
+// @Success 202 {string} string "Delete accepted"
+// @Failure 400 {object} response
+// @Failure 404 {object} response
+// @Failure 500 {object} response
+// @Router /user/devices/{id} [delete]
+func (c *Controller) RevokeDevice(w http.ResponseWriter, r *http.Request) {
+	deviceUUID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err), "deviceUUID", deviceUUID)
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	err = c.uc.RevokeDevice(r.Context(), currentUser, deviceUUID)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongOwnerOrNotFound):
+		http.Error(w, jsonError(err), http.StatusNotFound)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if _, err = w.Write([]byte(jsonResponse("delete accepted"))); err != nil {
+		return
+	}
+}
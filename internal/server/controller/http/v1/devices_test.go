@@ -0,0 +1,225 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+)
+
+func TestGetDevices(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New(), SessionID: uuid.New()}
+	devices := []entity.Session{
+		{
+			ID:      expectedUser.SessionID,
+			Device:  entity.Device{Name: "laptop", Platform: "linux/amd64", LastIP: "127.0.0.1"},
+			Current: true,
+		},
+		{ID: uuid.New(), Device: entity.Device{Name: "desktop", Platform: "windows/amd64"}},
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     []entity.Session
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful get devices",
+			mockReturn:     devices,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "error from use case",
+			mockError:      errors.New("get failed"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase.EXPECT().GetDevices(gomock.Any(), expectedUser).Return(tt.mockReturn, tt.mockError)
+
+			req := httptest.NewRequest(http.MethodGet, userDevices, nil)
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.GetDevices).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response []entity.Session
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Len(t, response, len(devices))
+				assert.True(t, response[0].Current)
+				assert.Equal(t, "laptop", response[0].Name)
+				assert.Equal(t, "127.0.0.1", response[0].LastIP)
+			}
+		})
+	}
+}
+
+func TestRenameDevice(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New()}
+	deviceID := uuid.New()
+
+	tests := []struct {
+		name           string
+		deviceID       string
+		body           string
+		mockReturn     error
+		expectCall     bool
+		expectedStatus int
+	}{
+		{
+			name:           "successful rename",
+			deviceID:       deviceID.String(),
+			body:           `{"name":"work laptop"}`,
+			expectCall:     true,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "unknown device",
+			deviceID:       deviceID.String(),
+			body:           `{"name":"work laptop"}`,
+			mockReturn:     errs.ErrWrongOwnerOrNotFound,
+			expectCall:     true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "error from use case",
+			deviceID:       deviceID.String(),
+			body:           `{"name":"work laptop"}`,
+			mockReturn:     errors.New("rename failed"),
+			expectCall:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "empty name",
+			deviceID:       deviceID.String(),
+			body:           `{"name":""}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "too long name",
+			deviceID:       deviceID.String(),
+			body:           `{"name":"` + strings.Repeat("a", maxDeviceNameLength+1) + `"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid UUID in URL",
+			deviceID:       "123a45test",
+			body:           `{"name":"work laptop"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectCall {
+				mockUseCase.EXPECT().
+					RenameDevice(gomock.Any(), expectedUser, deviceID, "work laptop").
+					Return(tt.mockReturn).Times(1)
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, userDevices+"/"+tt.deviceID, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.deviceID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.RenameDevice).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRevokeDevice(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New()}
+	deviceID := uuid.New()
+
+	tests := []struct {
+		name           string
+		deviceID       string
+		mockReturn     error
+		expectCall     bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "successful revoke",
+			deviceID:       deviceID.String(),
+			expectCall:     true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"status":"delete accepted"}`,
+		},
+		{
+			name:           "unknown device",
+			deviceID:       deviceID.String(),
+			mockReturn:     errs.ErrWrongOwnerOrNotFound,
+			expectCall:     true,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"wrong owner or not found"}` + "\n",
+		},
+		{
+			name:           "error from use case",
+			deviceID:       deviceID.String(),
+			mockReturn:     errors.New("revoke failed"),
+			expectCall:     true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"revoke failed"}` + "\n",
+		},
+		{
+			name:           "invalid UUID in URL",
+			deviceID:       "123a45test",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid UUID length: 10"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectCall {
+				mockUseCase.EXPECT().
+					RevokeDevice(gomock.Any(), expectedUser, deviceID).
+					Return(tt.mockReturn).Times(1)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, userDevices+"/"+tt.deviceID, nil)
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.deviceID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.RevokeDevice).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+const (
+	lastEventIDHeader = "Last-Event-ID"
+	eventsHeartbeat   = 30 * time.Second // Comments keeping an idle stream open through proxies.
+)
+
+var (
+	errWrongEventID         = errors.New("wrong last event ID")
+	errStreamingUnsupported = errors.New("response writer does not support streaming")
+)
+
+// StreamEvents godoc
+// @Summary Stream the changes of the current user
+// @Description Stream Server-Sent Events about the changes made on other devices: item-changed and item-deleted
+// @Description with the uuid, type and revision of the item, session-revoked with the uuid of the session.
+// @Description A stream resumed with the Last-Event-ID header gets the events it has missed first, or a resync
+// @Description event when they are no longer kept. The stream ends once the session of the request is revoked
+// @Tags sync
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "ID of the last event received"
+// @Success 200 {string} string "Stream of events"
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Router /user/events [get]
+func (c *Controller) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		c.log.Error("error", l.ErrAttr(errStreamingUnsupported))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	var lastEventID int64
+	if header := r.Header.Get(lastEventIDHeader); header != "" {
+		if lastEventID, err = strconv.ParseInt(header, 10, 64); err != nil || lastEventID < 0 {
+			http.Error(w, jsonError(errWrongEventID), http.StatusBadRequest)
+			return
+		}
+	}
+
+	missed, events := c.uc.SubscribeEvents(r.Context(), currentUser.ID, lastEventID)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for index := range missed {
+		if !c.sendEvent(w, currentUser, &missed[index]) {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err = io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, open := <-events:
+			if !open || !c.sendEvent(w, currentUser, &event) {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// sendEvent writes the event to the stream. It reports false once the stream is to end:
+// the event could not be written or it revokes the session of the stream.
+func (c *Controller) sendEvent(w io.Writer, currentUser entity.User, event *entity.Event) bool {
+	data := event.Data
+	if data == nil {
+		data = struct{}{}
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		return false
+	}
+	if _, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload); err != nil {
+		return false
+	}
+
+	session, revoked := event.Data.(entity.SessionEvent)
+	return !revoked || session.ID != currentUser.SessionID
+}
@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+func TestStreamEvents(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New(), Email: "test@example.com", SessionID: uuid.New()}
+	itemID := uuid.MustParse("0b9f4bde-94b6-4c3e-8a57-5b7d2a8e2a11")
+	otherSession := uuid.MustParse("6a1e0d3c-3f0e-4a43-9a0c-2c0a0c8b6f22")
+
+	changed := entity.Event{
+		ID:   11,
+		Type: entity.EventItemChanged,
+		Data: entity.ItemEvent{ID: itemID, Type: entity.ItemLogins, Revision: 7},
+	}
+	changedBody := "id: 11\nevent: item-changed\ndata: " +
+		`{"uuid":"0b9f4bde-94b6-4c3e-8a57-5b7d2a8e2a11","type":"logins","revision":7}` + "\n\n"
+	deleted := entity.Event{ID: 12, Type: entity.EventItemDeleted, Data: entity.ItemEvent{ID: itemID, Type: entity.ItemLogins}}
+	deletedBody := "id: 12\nevent: item-deleted\ndata: " +
+		`{"uuid":"0b9f4bde-94b6-4c3e-8a57-5b7d2a8e2a11","type":"logins"}` + "\n\n"
+	otherRevoked := entity.Event{ID: 13, Type: entity.EventSessionRevoked, Data: entity.SessionEvent{ID: otherSession}}
+	otherRevokedBody := "id: 13\nevent: session-revoked\ndata: " +
+		`{"uuid":"6a1e0d3c-3f0e-4a43-9a0c-2c0a0c8b6f22"}` + "\n\n"
+	ownRevoked := entity.Event{ID: 14, Type: entity.EventSessionRevoked, Data: entity.SessionEvent{ID: expectedUser.SessionID}}
+
+	tests := []struct {
+		name           string
+		lastEventID    string
+		mockCall       bool
+		since          int64
+		missed         []entity.Event
+		streamed       []entity.Event
+		closeStream    bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "new events until the stream is closed",
+			mockCall:       true,
+			streamed:       []entity.Event{changed, deleted, otherRevoked},
+			closeStream:    true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   changedBody + deletedBody + otherRevokedBody,
+		},
+		{
+			name:           "resumed with the missed events",
+			lastEventID:    "10",
+			mockCall:       true,
+			since:          10,
+			missed:         []entity.Event{changed},
+			streamed:       []entity.Event{deleted},
+			closeStream:    true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   changedBody + deletedBody,
+		},
+		{
+			name:           "resync",
+			lastEventID:    "3",
+			mockCall:       true,
+			since:          3,
+			missed:         []entity.Event{{ID: 20, Type: entity.EventResync}},
+			closeStream:    true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "id: 20\nevent: resync\ndata: {}\n\n",
+		},
+		{
+			name:           "revoked session of the stream",
+			mockCall:       true,
+			streamed:       []entity.Event{ownRevoked, changed},
+			expectedStatus: http.StatusOK,
+			expectedBody: "id: 14\nevent: session-revoked\ndata: {\"uuid\":\"" +
+				expectedUser.SessionID.String() + "\"}\n\n",
+		},
+		{
+			name:           "wrong last event ID",
+			lastEventID:    "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"wrong last event ID"}` + "\n",
+		},
+		{
+			name:           "negative last event ID",
+			lastEventID:    "-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"wrong last event ID"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				events := make(chan entity.Event, len(tt.streamed))
+				for _, event := range tt.streamed {
+					events <- event
+				}
+				if tt.closeStream {
+					close(events)
+				}
+				mockUseCase.EXPECT().
+					SubscribeEvents(gomock.Any(), expectedUser.ID, tt.since).
+					Return(tt.missed, (<-chan entity.Event)(events))
+			}
+
+			req := httptest.NewRequest(http.MethodGet, userEvents, nil)
+			if tt.lastEventID != "" {
+				req.Header.Set(lastEventIDHeader, tt.lastEventID)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.StreamEvents).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestStreamEventsWithoutUser(t *testing.T) {
+	c, _, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	req := httptest.NewRequest(http.MethodGet, userEvents, nil)
+	rr := httptest.NewRecorder()
+
+	http.HandlerFunc(c.StreamEvents).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
@@ -0,0 +1,25 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// jwksMaxAge is how long, in seconds, clients may cache the key set. A new key has to be
+// published at least this long before it starts signing, so every verifier knows it by then.
+const jwksMaxAge = 300
+
+// JWKS serves the public keys access tokens are currently accepted with as a JSON Web Key Set,
+// so other services can verify the tokens themselves. It is served at /.well-known/jwks.json,
+// outside of the versioned API, and is not documented in its swagger.
+func (c *Controller) JWKS(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(jwksMaxAge))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(c.uc.JWKS()); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+	}
+}
@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+func TestJWKS(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	jwks := entity.JWKS{Keys: []entity.JWK{{Kty: "RSA", Use: "sig", Alg: "RS256", Kid: "kid", N: "n", E: "AQAB"}}}
+	mockUseCase.EXPECT().JWKS().Return(jwks)
+
+	req := httptest.NewRequest(http.MethodGet, wellKnownJWKS, nil)
+	rr := httptest.NewRecorder()
+
+	http.HandlerFunc(c.JWKS).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "public, max-age=300", rr.Header().Get("Cache-Control"))
+	var response entity.JWKS
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, jwks, response)
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -100,6 +101,8 @@ func (c *Controller) GetLogins(w http.ResponseWriter, r *http.Request) {
 // @Param login body entity.Login true "Updated login data"
 // @Success 202 {string} string "Update accepted"
 // @Failure 400 {object} response
+// @Failure 404 {object} response
+// @Failure 409 {object} response
 // @Failure 500 {object} response
 // @Router /user/logins/{id} [patch]
 func (c *Controller) UpdateLogin(w http.ResponseWriter, r *http.Request) {
@@ -125,7 +128,17 @@ func (c *Controller) UpdateLogin(w http.ResponseWriter, r *http.Request) {
 	}
 	payloadLogin.ID = loginUUID
 
-	if err = c.uc.UpdateLogin(r.Context(), &payloadLogin, currentUser.ID); err != nil {
+	err = c.uc.UpdateLogin(r.Context(), &payloadLogin, currentUser.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrRevisionConflict):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusConflict)
+		return
+	case errors.Is(err, errs.ErrWrongOwnerOrNotFound):
+		http.Error(w, jsonError(err), http.StatusNotFound)
+		return
+	default:
 		c.log.Error("error", l.ErrAttr(err))
 		http.Error(w, jsonError(err), http.StatusInternalServerError)
 		return
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
 )
 
 func TestAddLogin(t *testing.T) {
@@ -193,6 +194,24 @@ func TestUpdateLogin(t *testing.T) {
 			reqBody:        login,
 			expectCall:     true,
 		},
+		{
+			name:           "stale base revision",
+			mockReturn:     errs.ErrRevisionConflict,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"item has been changed since the base revision"}` + "\n",
+			loginID:        validUUID.String(),
+			reqBody:        login,
+			expectCall:     true,
+		},
+		{
+			name:           "meta of another item",
+			mockReturn:     errs.ErrWrongOwnerOrNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"wrong owner or not found"}` + "\n",
+			loginID:        validUUID.String(),
+			reqBody:        login,
+			expectCall:     true,
+		},
 		{
 			name:           "invalid UUID in URL",
 			mockReturn:     nil,
@@ -108,19 +108,63 @@ func (mr *MockUseCaseMockRecorder) AddNote(arg0, arg1, arg2 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNote", reflect.TypeOf((*MockUseCase)(nil).AddNote), arg0, arg1, arg2)
 }
 
+// ChangePassword mocks base method.
+func (m *MockUseCase) ChangePassword(arg0 context.Context, arg1 *entity.User, arg2, arg3, arg4 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUseCaseMockRecorder) ChangePassword(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUseCase)(nil).ChangePassword), arg0, arg1, arg2, arg3, arg4)
+}
+
 // CheckAccessToken mocks base method.
-func (m *MockUseCase) CheckAccessToken(arg0 context.Context, arg1 string) (entity.User, error) {
+func (m *MockUseCase) CheckAccessToken(arg0 context.Context, arg1, arg2 string) (entity.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckAccessToken", arg0, arg1)
+	ret := m.ctrl.Call(m, "CheckAccessToken", arg0, arg1, arg2)
 	ret0, _ := ret[0].(entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CheckAccessToken indicates an expected call of CheckAccessToken.
-func (mr *MockUseCaseMockRecorder) CheckAccessToken(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUseCaseMockRecorder) CheckAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAccessToken", reflect.TypeOf((*MockUseCase)(nil).CheckAccessToken), arg0, arg1, arg2)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockUseCase) ConfirmTOTP(arg0 context.Context, arg1 entity.User, arg2 string) (entity.RecoveryCodes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.RecoveryCodes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockUseCaseMockRecorder) ConfirmTOTP(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockUseCase)(nil).ConfirmTOTP), arg0, arg1, arg2)
+}
+
+// CreatePersonalToken mocks base method.
+func (m *MockUseCase) CreatePersonalToken(arg0 context.Context, arg1 entity.User, arg2 entity.PersonalToken) (entity.PersonalToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePersonalToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.PersonalToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePersonalToken indicates an expected call of CreatePersonalToken.
+func (mr *MockUseCaseMockRecorder) CreatePersonalToken(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAccessToken", reflect.TypeOf((*MockUseCase)(nil).CheckAccessToken), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePersonalToken", reflect.TypeOf((*MockUseCase)(nil).CreatePersonalToken), arg0, arg1, arg2)
 }
 
 // DelCard mocks base method.
@@ -179,6 +223,49 @@ func (mr *MockUseCaseMockRecorder) DelUserBinary(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelUserBinary", reflect.TypeOf((*MockUseCase)(nil).DelUserBinary), arg0, arg1, arg2)
 }
 
+// DeleteUser mocks base method.
+func (m *MockUseCase) DeleteUser(arg0 context.Context, arg1 entity.User, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUseCaseMockRecorder) DeleteUser(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUseCase)(nil).DeleteUser), arg0, arg1, arg2, arg3)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockUseCase) DisableTOTP(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockUseCaseMockRecorder) DisableTOTP(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockUseCase)(nil).DisableTOTP), arg0, arg1, arg2, arg3)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockUseCase) EnrollTOTP(arg0 context.Context, arg1 entity.User) (entity.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", arg0, arg1)
+	ret0, _ := ret[0].(entity.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockUseCaseMockRecorder) EnrollTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockUseCase)(nil).EnrollTOTP), arg0, arg1)
+}
+
 // GetBinaries mocks base method.
 func (m *MockUseCase) GetBinaries(arg0 context.Context, arg1 entity.User) ([]entity.Binary, error) {
 	m.ctrl.T.Helper()
@@ -209,6 +296,51 @@ func (mr *MockUseCaseMockRecorder) GetCards(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCards", reflect.TypeOf((*MockUseCase)(nil).GetCards), arg0, arg1)
 }
 
+// GetChanges mocks base method.
+func (m *MockUseCase) GetChanges(arg0 context.Context, arg1 entity.User, arg2 int64) (entity.Changes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChanges", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.Changes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChanges indicates an expected call of GetChanges.
+func (mr *MockUseCaseMockRecorder) GetChanges(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChanges", reflect.TypeOf((*MockUseCase)(nil).GetChanges), arg0, arg1, arg2)
+}
+
+// GetDataKey mocks base method.
+func (m *MockUseCase) GetDataKey(arg0 context.Context, arg1 *entity.User) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataKey", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataKey indicates an expected call of GetDataKey.
+func (mr *MockUseCaseMockRecorder) GetDataKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataKey", reflect.TypeOf((*MockUseCase)(nil).GetDataKey), arg0, arg1)
+}
+
+// GetDevices mocks base method.
+func (m *MockUseCase) GetDevices(arg0 context.Context, arg1 entity.User) ([]entity.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDevices", arg0, arg1)
+	ret0, _ := ret[0].([]entity.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDevices indicates an expected call of GetDevices.
+func (mr *MockUseCaseMockRecorder) GetDevices(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDevices", reflect.TypeOf((*MockUseCase)(nil).GetDevices), arg0, arg1)
+}
+
 // GetDomainName mocks base method.
 func (m *MockUseCase) GetDomainName() string {
 	m.ctrl.T.Helper()
@@ -253,6 +385,36 @@ func (mr *MockUseCaseMockRecorder) GetNotes(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotes", reflect.TypeOf((*MockUseCase)(nil).GetNotes), arg0, arg1)
 }
 
+// GetPersonalTokens mocks base method.
+func (m *MockUseCase) GetPersonalTokens(arg0 context.Context, arg1 entity.User) ([]entity.PersonalToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalTokens", arg0, arg1)
+	ret0, _ := ret[0].([]entity.PersonalToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalTokens indicates an expected call of GetPersonalTokens.
+func (mr *MockUseCaseMockRecorder) GetPersonalTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalTokens", reflect.TypeOf((*MockUseCase)(nil).GetPersonalTokens), arg0, arg1)
+}
+
+// GetRecoveryKey mocks base method.
+func (m *MockUseCase) GetRecoveryKey(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecoveryKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecoveryKey indicates an expected call of GetRecoveryKey.
+func (mr *MockUseCaseMockRecorder) GetRecoveryKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecoveryKey", reflect.TypeOf((*MockUseCase)(nil).GetRecoveryKey), arg0, arg1, arg2)
+}
+
 // GetUserBinary mocks base method.
 func (m *MockUseCase) GetUserBinary(arg0 context.Context, arg1 *entity.User, arg2 uuid.UUID) (string, error) {
 	m.ctrl.T.Helper()
@@ -282,49 +444,262 @@ func (mr *MockUseCaseMockRecorder) HealthCheck() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthCheck", reflect.TypeOf((*MockUseCase)(nil).HealthCheck))
 }
 
+// JWKS mocks base method.
+func (m *MockUseCase) JWKS() entity.JWKS {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(entity.JWKS)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockUseCaseMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockUseCase)(nil).JWKS))
+}
+
+// LogoutUser mocks base method.
+func (m *MockUseCase) LogoutUser(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutUser indicates an expected call of LogoutUser.
+func (mr *MockUseCaseMockRecorder) LogoutUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutUser", reflect.TypeOf((*MockUseCase)(nil).LogoutUser), arg0, arg1, arg2)
+}
+
+// RecoverAccount mocks base method.
+func (m *MockUseCase) RecoverAccount(arg0 context.Context, arg1 entity.Recovery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecoverAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecoverAccount indicates an expected call of RecoverAccount.
+func (mr *MockUseCaseMockRecorder) RecoverAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverAccount", reflect.TypeOf((*MockUseCase)(nil).RecoverAccount), arg0, arg1)
+}
+
 // RefreshAccessToken mocks base method.
-func (m *MockUseCase) RefreshAccessToken(arg0 context.Context, arg1 string) (entity.JWT, error) {
+func (m *MockUseCase) RefreshAccessToken(arg0 context.Context, arg1, arg2 string) (entity.JWT, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RefreshAccessToken", arg0, arg1)
+	ret := m.ctrl.Call(m, "RefreshAccessToken", arg0, arg1, arg2)
 	ret0, _ := ret[0].(entity.JWT)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RefreshAccessToken indicates an expected call of RefreshAccessToken.
-func (mr *MockUseCaseMockRecorder) RefreshAccessToken(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUseCaseMockRecorder) RefreshAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshAccessToken", reflect.TypeOf((*MockUseCase)(nil).RefreshAccessToken), arg0, arg1, arg2)
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockUseCase) ReleaseIdempotencyKey(arg0 context.Context, arg1 uuid.UUID, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockUseCaseMockRecorder) ReleaseIdempotencyKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockUseCase)(nil).ReleaseIdempotencyKey), arg0, arg1, arg2)
+}
+
+// RenameDevice mocks base method.
+func (m *MockUseCase) RenameDevice(arg0 context.Context, arg1 entity.User, arg2 uuid.UUID, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameDevice", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameDevice indicates an expected call of RenameDevice.
+func (mr *MockUseCaseMockRecorder) RenameDevice(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameDevice", reflect.TypeOf((*MockUseCase)(nil).RenameDevice), arg0, arg1, arg2, arg3)
+}
+
+// ReserveIdempotencyKey mocks base method.
+func (m *MockUseCase) ReserveIdempotencyKey(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 string) (entity.IdempotentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveIdempotencyKey", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(entity.IdempotentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveIdempotencyKey indicates an expected call of ReserveIdempotencyKey.
+func (mr *MockUseCaseMockRecorder) ReserveIdempotencyKey(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshAccessToken", reflect.TypeOf((*MockUseCase)(nil).RefreshAccessToken), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIdempotencyKey", reflect.TypeOf((*MockUseCase)(nil).ReserveIdempotencyKey), arg0, arg1, arg2, arg3)
+}
+
+// RevokeDevice mocks base method.
+func (m *MockUseCase) RevokeDevice(arg0 context.Context, arg1 entity.User, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeDevice", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeDevice indicates an expected call of RevokeDevice.
+func (mr *MockUseCaseMockRecorder) RevokeDevice(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeDevice", reflect.TypeOf((*MockUseCase)(nil).RevokeDevice), arg0, arg1, arg2)
+}
+
+// RevokePersonalToken mocks base method.
+func (m *MockUseCase) RevokePersonalToken(arg0 context.Context, arg1 entity.User, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokePersonalToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokePersonalToken indicates an expected call of RevokePersonalToken.
+func (mr *MockUseCaseMockRecorder) RevokePersonalToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokePersonalToken", reflect.TypeOf((*MockUseCase)(nil).RevokePersonalToken), arg0, arg1, arg2)
+}
+
+// RotateDataKey mocks base method.
+func (m *MockUseCase) RotateDataKey(arg0 context.Context, arg1 *entity.User, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateDataKey", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateDataKey indicates an expected call of RotateDataKey.
+func (mr *MockUseCaseMockRecorder) RotateDataKey(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateDataKey", reflect.TypeOf((*MockUseCase)(nil).RotateDataKey), arg0, arg1, arg2, arg3)
+}
+
+// SaveIdempotentResponse mocks base method.
+func (m *MockUseCase) SaveIdempotentResponse(arg0 context.Context, arg1 uuid.UUID, arg2 string, arg3 entity.IdempotentResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIdempotentResponse", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIdempotentResponse indicates an expected call of SaveIdempotentResponse.
+func (mr *MockUseCaseMockRecorder) SaveIdempotentResponse(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdempotentResponse", reflect.TypeOf((*MockUseCase)(nil).SaveIdempotentResponse), arg0, arg1, arg2, arg3)
+}
+
+// SetRecoveryKey mocks base method.
+func (m *MockUseCase) SetRecoveryKey(arg0 context.Context, arg1 entity.User, arg2 entity.RecoveryKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRecoveryKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRecoveryKey indicates an expected call of SetRecoveryKey.
+func (mr *MockUseCaseMockRecorder) SetRecoveryKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRecoveryKey", reflect.TypeOf((*MockUseCase)(nil).SetRecoveryKey), arg0, arg1, arg2)
+}
+
+// SignInTOTP mocks base method.
+func (m *MockUseCase) SignInTOTP(arg0 context.Context, arg1, arg2, arg3 string) (entity.JWT, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInTOTP", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(entity.JWT)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignInTOTP indicates an expected call of SignInTOTP.
+func (mr *MockUseCaseMockRecorder) SignInTOTP(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInTOTP", reflect.TypeOf((*MockUseCase)(nil).SignInTOTP), arg0, arg1, arg2, arg3)
 }
 
 // SignInUser mocks base method.
-func (m *MockUseCase) SignInUser(arg0 context.Context, arg1, arg2 string) (entity.JWT, error) {
+func (m *MockUseCase) SignInUser(arg0 context.Context, arg1, arg2 string, arg3 entity.Device) (entity.JWT, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignInUser", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "SignInUser", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(entity.JWT)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignInUser indicates an expected call of SignInUser.
-func (mr *MockUseCaseMockRecorder) SignInUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockUseCaseMockRecorder) SignInUser(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInUser", reflect.TypeOf((*MockUseCase)(nil).SignInUser), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInUser", reflect.TypeOf((*MockUseCase)(nil).SignInUser), arg0, arg1, arg2, arg3)
 }
 
 // SignUpUser mocks base method.
-func (m *MockUseCase) SignUpUser(arg0 context.Context, arg1, arg2 string) (entity.User, error) {
+func (m *MockUseCase) SignUpUser(arg0 context.Context, arg1, arg2 string, arg3 entity.VaultKeys) (entity.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUpUser", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "SignUpUser", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignUpUser indicates an expected call of SignUpUser.
-func (mr *MockUseCaseMockRecorder) SignUpUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockUseCaseMockRecorder) SignUpUser(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUpUser", reflect.TypeOf((*MockUseCase)(nil).SignUpUser), arg0, arg1, arg2, arg3)
+}
+
+// StageRekey mocks base method.
+func (m *MockUseCase) StageRekey(arg0 context.Context, arg1 *entity.Rekey, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StageRekey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StageRekey indicates an expected call of StageRekey.
+func (mr *MockUseCaseMockRecorder) StageRekey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StageRekey", reflect.TypeOf((*MockUseCase)(nil).StageRekey), arg0, arg1, arg2)
+}
+
+// StageRekeyBinary mocks base method.
+func (m *MockUseCase) StageRekeyBinary(arg0 context.Context, arg1 *entity.User, arg2 uuid.UUID, arg3 *multipart.FileHeader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StageRekeyBinary", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StageRekeyBinary indicates an expected call of StageRekeyBinary.
+func (mr *MockUseCaseMockRecorder) StageRekeyBinary(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUpUser", reflect.TypeOf((*MockUseCase)(nil).SignUpUser), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StageRekeyBinary", reflect.TypeOf((*MockUseCase)(nil).StageRekeyBinary), arg0, arg1, arg2, arg3)
+}
+
+// SubscribeEvents mocks base method.
+func (m *MockUseCase) SubscribeEvents(arg0 context.Context, arg1 uuid.UUID, arg2 int64) ([]entity.Event, <-chan entity.Event) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeEvents", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entity.Event)
+	ret1, _ := ret[1].(<-chan entity.Event)
+	return ret0, ret1
+}
+
+// SubscribeEvents indicates an expected call of SubscribeEvents.
+func (mr *MockUseCaseMockRecorder) SubscribeEvents(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeEvents", reflect.TypeOf((*MockUseCase)(nil).SubscribeEvents), arg0, arg1, arg2)
 }
 
 // UpdateCard mocks base method.
@@ -368,3 +743,18 @@ func (mr *MockUseCaseMockRecorder) UpdateNote(arg0, arg1, arg2 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNote", reflect.TypeOf((*MockUseCase)(nil).UpdateNote), arg0, arg1, arg2)
 }
+
+// UpgradeUser mocks base method.
+func (m *MockUseCase) UpgradeUser(arg0 context.Context, arg1, arg2, arg3 string, arg4 entity.Device) (entity.JWT, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpgradeUser", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(entity.JWT)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpgradeUser indicates an expected call of UpgradeUser.
+func (mr *MockUseCaseMockRecorder) UpgradeUser(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradeUser", reflect.TypeOf((*MockUseCase)(nil).UpgradeUser), arg0, arg1, arg2, arg3, arg4)
+}
@@ -4,8 +4,14 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/server/mw/request"
+	"github.com/nextlag/keeper/internal/utils/errs"
 	"github.com/nextlag/keeper/pkg/logger/l"
 )
 
@@ -20,30 +26,28 @@ const (
 // The token can be passed either in the Authorization header or in the "access_token" cookie.
 // If the token is valid, information about the user is stored in the context of the request.
 // If the token is missing or invalid, the status 401 Unauthorized is returned.
+// A personal access token is accepted as well, a request out of its scope gets the status 403 Forbidden.
 func (c *Controller) MwAuth() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			var accessToken string
-			accessTokenFromCookie, err := r.Cookie("access_token")
-			authorizationHeader := strings.Fields(r.Header.Get("Authorization"))
-
-			if len(authorizationHeader) > 1 && authorizationHeader[0] == "Bearer" {
-				accessToken = authorizationHeader[1]
-			} else if err == nil {
-				accessToken = accessTokenFromCookie.Value
-			}
-
+			accessToken := accessTokenFromRequest(r)
 			if accessToken == "" {
 				http.Error(w, jsonError(errors.New("you are not logged in")), http.StatusUnauthorized)
 				return
 			}
 
-			user, err := c.uc.CheckAccessToken(r.Context(), accessToken)
+			user, err := c.uc.CheckAccessToken(r.Context(), accessToken, request.ClientIP(r.Context()))
 			if err != nil {
 				c.log.Error("error", l.ErrAttr(err))
 				http.Error(w, jsonError(err), http.StatusUnauthorized)
 				return
 			}
+			if user.Scope != nil {
+				if err = checkScope(*user.Scope, r); err != nil {
+					http.Error(w, jsonError(err), http.StatusForbidden)
+					return
+				}
+			}
 
 			ctx := context.WithValue(r.Context(), currentUserKey, user)
 			r = r.WithContext(ctx)
@@ -51,3 +55,53 @@ func (c *Controller) MwAuth() func(next http.Handler) http.Handler {
 		})
 	}
 }
+
+// accessTokenFromRequest returns the access token of the request,
+// taken from the Authorization header or else from the access_token cookie.
+func accessTokenFromRequest(r *http.Request) string {
+	authorizationHeader := strings.Fields(r.Header.Get("Authorization"))
+	if len(authorizationHeader) > 1 && authorizationHeader[0] == "Bearer" {
+		return authorizationHeader[1]
+	}
+	if accessTokenFromCookie, err := r.Cookie("access_token"); err == nil {
+		return accessTokenFromCookie.Value
+	}
+
+	return ""
+}
+
+// checkScope returns ErrOutOfScope unless the scope of a personal token grants the request.
+// Besides reading the current user, personal tokens only reach the item routes. With item IDs
+// in the scope, the ID of an item route has to be one of them and no items can be added.
+func checkScope(scope entity.TokenScope, r *http.Request) error {
+	_, route, _ := strings.Cut(r.URL.Path, "/user/")
+	segments := strings.Split(route, "/")
+	itemType := segments[0]
+
+	if itemType == "me" && r.Method == http.MethodGet {
+		return nil
+	}
+	if !slices.Contains(entity.ItemTypes, itemType) || !scope.AllowsType(itemType) {
+		return errs.ErrOutOfScope
+	}
+	if scope.ReadOnly && r.Method != http.MethodGet {
+		return errs.ErrOutOfScope
+	}
+	if len(scope.ItemIDs) == 0 {
+		return nil
+	}
+
+	if len(segments) < 2 || segments[1] == "" {
+		// Listing is filtered by the use case, adding is not allowed.
+		if r.Method != http.MethodGet {
+			return errs.ErrOutOfScope
+		}
+		return nil
+	}
+	itemID, err := uuid.Parse(segments[1])
+	if err != nil || !scope.AllowsItem(itemID) {
+		return errs.ErrOutOfScope
+	}
+
+	return nil
+}
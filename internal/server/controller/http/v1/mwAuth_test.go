@@ -43,7 +43,7 @@ func TestMwAuth(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.mockReturn != nil {
 				mockUseCase.EXPECT().
-					CheckAccessToken(gomock.Any(), tt.accessToken).
+					CheckAccessToken(gomock.Any(), tt.accessToken, "").
 					Return(tt.mockReturn, nil).
 					Times(1)
 			}
@@ -67,3 +67,128 @@ func TestMwAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestMwAuthPersonalTokenScope(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	token := "kpr_token"
+	itemID := uuid.New()
+	fullScope := entity.TokenScope{}
+	readOnlyLogins := entity.TokenScope{ReadOnly: true, ItemTypes: []string{entity.ItemLogins}}
+	oneItem := entity.TokenScope{ItemIDs: []uuid.UUID{itemID}}
+
+	tests := []struct {
+		name           string
+		scope          entity.TokenScope
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{
+			name:           "current user",
+			scope:          readOnlyLogins,
+			method:         http.MethodGet,
+			path:           userInfo,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "item of the scope type",
+			scope:          readOnlyLogins,
+			method:         http.MethodGet,
+			path:           userLogins,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "item of another type",
+			scope:          readOnlyLogins,
+			method:         http.MethodGet,
+			path:           userCards,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "change with a read only token",
+			scope:          readOnlyLogins,
+			method:         http.MethodPost,
+			path:           userLogins,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "account settings",
+			scope:          fullScope,
+			method:         http.MethodGet,
+			path:           userDevices,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "account deletion",
+			scope:          fullScope,
+			method:         http.MethodDelete,
+			path:           userInfo,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "change feed",
+			scope:          fullScope,
+			method:         http.MethodGet,
+			path:           userChanges,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "token management",
+			scope:          fullScope,
+			method:         http.MethodPost,
+			path:           userTokens,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "item of the scope",
+			scope:          oneItem,
+			method:         http.MethodPatch,
+			path:           userNotes + "/" + itemID.String(),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "item out of the scope",
+			scope:          oneItem,
+			method:         http.MethodGet,
+			path:           userBinary + "/" + uuid.NewString(),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "listing with item IDs",
+			scope:          oneItem,
+			method:         http.MethodGet,
+			path:           userNotes,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "adding with item IDs",
+			scope:          oneItem,
+			method:         http.MethodPost,
+			path:           userNotes,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := tt.scope
+			mockUseCase.EXPECT().
+				CheckAccessToken(gomock.Any(), token, "").
+				Return(entity.User{ID: uuid.New(), Scope: &scope}, nil)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+
+			handler := c.MwAuth()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// idempotencyKeyHeader is the header a client sets to make a retried request apply only once.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLen is the longest idempotency key accepted.
+const maxIdempotencyKeyLen = 100
+
+// maxBufferedBody is the largest request body kept in memory while its fingerprint is taken,
+// a larger one is spooled to a temporary file.
+const maxBufferedBody = 1 << 20
+
+// MwIdempotency returns middleware applying a change sent with the Idempotency-Key header only once.
+// The key is reserved for the user before the request runs and the response is stored with it,
+// a retried request gets the same status, headers and body without reaching the handler. A retry
+// arriving while the first request still runs gets the status 425 Too Early. The key is stored with
+// the fingerprint of the request, a request differing in method, URI or body sent with a used key
+// gets the status 422 Unprocessable Entity. Responses with a server error are not stored and release
+// the key, so such a request can be retried.
+// Requests without the header and GET requests are passed through.
+func (c *Controller) MwIdempotency() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" || r.Method == http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				http.Error(w, jsonError(errors.New("idempotency key is too long")), http.StatusBadRequest)
+				return
+			}
+
+			currentUser, err := c.getUserFromCtx(r.Context())
+			if err != nil {
+				c.log.Error("error", l.ErrAttr(err))
+				http.Error(w, jsonError(err), http.StatusInternalServerError)
+				return
+			}
+
+			fingerprint, release, err := requestFingerprint(r)
+			if err != nil {
+				http.Error(w, jsonError(err), http.StatusBadRequest)
+				return
+			}
+			defer release()
+
+			stored, err := c.uc.ReserveIdempotencyKey(r.Context(), currentUser.ID, key, fingerprint)
+			switch {
+			case err == nil:
+				for name, values := range stored.Header {
+					w.Header()[name] = values
+				}
+				w.WriteHeader(stored.Status)
+				_, _ = w.Write(stored.Body)
+				return
+			case errors.Is(err, errs.ErrNoStoredResponse):
+			case errors.Is(err, errs.ErrRequestInProgress):
+				http.Error(w, jsonError(err), http.StatusTooEarly)
+				return
+			case errors.Is(err, errs.ErrIdempotencyKeyReused):
+				http.Error(w, jsonError(err), http.StatusUnprocessableEntity)
+				return
+			default:
+				c.log.Error("error", l.ErrAttr(err))
+				http.Error(w, jsonError(err), http.StatusInternalServerError)
+				return
+			}
+
+			// The outcome is recorded even if the client has gone away in the meantime.
+			// A key of a request that has not completed, a panic included, is released.
+			ctx := context.WithoutCancel(r.Context())
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := c.uc.ReleaseIdempotencyKey(ctx, currentUser.ID, key); err != nil {
+					c.log.Error("error", l.ErrAttr(err))
+				}
+			}()
+
+			var body bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&body)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusInternalServerError {
+				return
+			}
+			// The change has been applied, so the key stays reserved even if the response is not stored.
+			completed = true
+			response := entity.IdempotentResponse{Status: status, Header: ww.Header().Clone(), Body: body.Bytes()}
+			if err = c.uc.SaveIdempotentResponse(ctx, currentUser.ID, key, response); err != nil {
+				c.log.Error("error", l.ErrAttr(err))
+			}
+		})
+	}
+}
+
+// requestFingerprint returns the hash of the method, URI and body of the request and replaces
+// the body, which has been read, with its copy. The returned function releases the copy.
+func requestFingerprint(r *http.Request) (string, func(), error) {
+	bodyHash := sha256.New()
+	var buffered bytes.Buffer
+	n, err := io.CopyN(io.MultiWriter(&buffered, bodyHash), r.Body, maxBufferedBody+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+
+	release := func() {}
+	body := io.Reader(&buffered)
+	if n > maxBufferedBody {
+		spool, err := os.CreateTemp("", "keeper-request-*")
+		if err != nil {
+			return "", nil, err
+		}
+		release = func() {
+			_ = spool.Close()
+			_ = os.Remove(spool.Name())
+		}
+		if _, err = buffered.WriteTo(spool); err == nil {
+			_, err = io.Copy(io.MultiWriter(spool, bodyHash), r.Body)
+		}
+		if err == nil {
+			_, err = spool.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			release()
+			return "", nil, err
+		}
+		body = spool
+	}
+	r.Body = io.NopCloser(body)
+
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s %s %x", r.Method, r.URL.RequestURI(), bodyHash.Sum(nil)))
+	return hex.EncodeToString(sum[:]), release, nil
+}
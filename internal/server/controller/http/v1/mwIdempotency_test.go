@@ -0,0 +1,219 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+)
+
+func TestMwIdempotency(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	user := entity.User{ID: uuid.New(), Email: "example@mail.ru"}
+	const key = "5f0c2b8e-9a4d-4e0a-8f43-3a3d2d7c4b11"
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+	tests := []struct {
+		name           string
+		method         string
+		key            string
+		handlerStatus  int
+		setupMock      func()
+		expectedStatus int
+		expectedBody   string
+		expectedCalls  int
+	}{
+		{
+			name:           "request without a key",
+			method:         http.MethodPost,
+			handlerStatus:  http.StatusAccepted,
+			setupMock:      func() {},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"id":"new"}`,
+			expectedCalls:  1,
+		},
+		{
+			name:           "GET request is passed through",
+			method:         http.MethodGet,
+			key:            key,
+			handlerStatus:  http.StatusOK,
+			setupMock:      func() {},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"new"}`,
+			expectedCalls:  1,
+		},
+		{
+			name:          "first request is stored",
+			method:        http.MethodPost,
+			key:           key,
+			handlerStatus: http.StatusAccepted,
+			setupMock: func() {
+				mockUseCase.EXPECT().ReserveIdempotencyKey(gomock.Any(), user.ID, key, gomock.Any()).
+					Return(entity.IdempotentResponse{}, errs.ErrNoStoredResponse)
+				mockUseCase.EXPECT().SaveIdempotentResponse(gomock.Any(), user.ID, key,
+					entity.IdempotentResponse{Status: http.StatusAccepted, Header: jsonHeader, Body: []byte(`{"id":"new"}`)}).
+					Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"id":"new"}`,
+			expectedCalls:  1,
+		},
+		{
+			name:          "retried request is replayed",
+			method:        http.MethodPost,
+			key:           key,
+			handlerStatus: http.StatusAccepted,
+			setupMock: func() {
+				mockUseCase.EXPECT().ReserveIdempotencyKey(gomock.Any(), user.ID, key, gomock.Any()).
+					Return(entity.IdempotentResponse{Status: http.StatusAccepted, Header: jsonHeader, Body: []byte(`{"id":"old"}`)}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"id":"old"}`,
+			expectedCalls:  0,
+		},
+		{
+			name:          "retry while the request is running",
+			method:        http.MethodPost,
+			key:           key,
+			handlerStatus: http.StatusAccepted,
+			setupMock: func() {
+				mockUseCase.EXPECT().ReserveIdempotencyKey(gomock.Any(), user.ID, key, gomock.Any()).
+					Return(entity.IdempotentResponse{}, errs.ErrRequestInProgress)
+			},
+			expectedStatus: http.StatusTooEarly,
+			expectedBody:   "{\"error\":\"request with the idempotency key is still in progress\"}\n",
+			expectedCalls:  0,
+		},
+		{
+			name:          "key reused for another request",
+			method:        http.MethodPost,
+			key:           key,
+			handlerStatus: http.StatusAccepted,
+			setupMock: func() {
+				mockUseCase.EXPECT().ReserveIdempotencyKey(gomock.Any(), user.ID, key, gomock.Any()).
+					Return(entity.IdempotentResponse{}, errs.ErrIdempotencyKeyReused)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "{\"error\":\"idempotency key has been used for another request\"}\n",
+			expectedCalls:  0,
+		},
+		{
+			name:          "server error releases the key",
+			method:        http.MethodDelete,
+			key:           key,
+			handlerStatus: http.StatusInternalServerError,
+			setupMock: func() {
+				mockUseCase.EXPECT().ReserveIdempotencyKey(gomock.Any(), user.ID, key, gomock.Any()).
+					Return(entity.IdempotentResponse{}, errs.ErrNoStoredResponse)
+				mockUseCase.EXPECT().ReleaseIdempotencyKey(gomock.Any(), user.ID, key).Return(nil)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"id":"new"}`,
+			expectedCalls:  1,
+		},
+		{
+			name:          "lookup error",
+			method:        http.MethodPost,
+			key:           key,
+			handlerStatus: http.StatusAccepted,
+			setupMock: func() {
+				mockUseCase.EXPECT().ReserveIdempotencyKey(gomock.Any(), user.ID, key, gomock.Any()).
+					Return(entity.IdempotentResponse{}, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "{\"error\":\"db error\"}\n",
+			expectedCalls:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			calls := 0
+			handler := c.MwIdempotency()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.handlerStatus)
+				_, _ = w.Write([]byte(`{"id":"new"}`))
+			}))
+
+			req := httptest.NewRequest(tt.method, userLogins, nil)
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, user))
+			if tt.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tt.key)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+			assert.Equal(t, tt.expectedCalls, calls)
+			if tt.expectedStatus < http.StatusBadRequest {
+				assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestMwIdempotencyPanic(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	user := entity.User{ID: uuid.New(), Email: "example@mail.ru"}
+	const key = "5f0c2b8e-9a4d-4e0a-8f43-3a3d2d7c4b11"
+
+	mockUseCase.EXPECT().ReserveIdempotencyKey(gomock.Any(), user.ID, key, gomock.Any()).
+		Return(entity.IdempotentResponse{}, errs.ErrNoStoredResponse)
+	mockUseCase.EXPECT().ReleaseIdempotencyKey(gomock.Any(), user.ID, key).Return(nil)
+
+	handler := c.MwIdempotency()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, userLogins, nil)
+	req = req.WithContext(context.WithValue(req.Context(), currentUserKey, user))
+	req.Header.Set(idempotencyKeyHeader, key)
+
+	assert.Panics(t, func() { handler.ServeHTTP(httptest.NewRecorder(), req) })
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	fingerprint := func(method, target string, body []byte) string {
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		value, release, err := requestFingerprint(req)
+		require.NoError(t, err)
+		defer release()
+
+		// The handler still reads the whole body.
+		read, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, body, read)
+		return value
+	}
+
+	body := []byte(`{"name":"mail"}`)
+	large := bytes.Repeat([]byte("a"), maxBufferedBody+10)
+
+	first := fingerprint(http.MethodPost, userLogins, body)
+	assert.Equal(t, first, fingerprint(http.MethodPost, userLogins, body))
+	assert.NotEqual(t, first, fingerprint(http.MethodPost, userLogins, []byte(`{"name":"bank"}`)))
+	assert.NotEqual(t, first, fingerprint(http.MethodPatch, userLogins, body))
+	assert.NotEqual(t, first, fingerprint(http.MethodPost, userLogins+"?id=1", body))
+	assert.Equal(t, fingerprint(http.MethodPost, userLogins, large), fingerprint(http.MethodPost, userLogins, large))
+	assert.NotEqual(t, fingerprint(http.MethodPost, userLogins, large), fingerprint(http.MethodPost, userLogins, large[1:]))
+}
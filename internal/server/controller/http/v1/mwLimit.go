@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/nextlag/keeper/internal/server/mw/request"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// MwLimitIP returns middleware limiting the number of requests of a client IP address.
+// Over the limit the status 429 Too Many Requests is returned with the Retry-After header.
+func (c *Controller) MwLimitIP() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if retryAfter := c.limiter.AllowIP(request.ClientIP(r.Context())); retryAfter > 0 {
+				c.tooManyRequests(w, retryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// maxAccountPayload is the largest payload the email of a throttled request is read from.
+const maxAccountPayload = 1 << 20
+
+// MwLimitAccount returns middleware throttling the sign ins of the account given by the email of the payload.
+// A response with the status 400 Bad Request or 401 Unauthorized counts as a failed sign in and
+// delays the next attempt, a successful one resets the count. The status 202 Accepted of a TOTP
+// challenge leaves the count as it is, the sign in is complete once the code is accepted.
+// While the account has to wait, the status 429 Too Many Requests is returned with the Retry-After header.
+func (c *Controller) MwLimitAccount() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			account, err := accountFromRequest(w, r)
+			var tooLarge *http.MaxBytesError
+			switch {
+			case err == nil:
+			case errors.As(err, &tooLarge):
+				http.Error(w, jsonError(err), http.StatusRequestEntityTooLarge)
+				return
+			default:
+				c.log.Error("error", l.ErrAttr(err))
+				http.Error(w, jsonError(err), http.StatusBadRequest)
+				return
+			}
+			if account == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if retryAfter := c.limiter.AccountRetryAfter(account); retryAfter > 0 {
+				c.tooManyRequests(w, retryAfter)
+				return
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			switch status := ww.Status(); {
+			case status == http.StatusBadRequest, status == http.StatusUnauthorized:
+				c.limiter.Failure(account)
+			case status == http.StatusAccepted:
+			case status >= http.StatusOK && status < http.StatusMultipleChoices:
+				c.limiter.Success(account)
+			}
+		})
+	}
+}
+
+// accountFromRequest returns the email of the JSON payload of the request and puts the read body back.
+// A payload over maxAccountPayload is rejected with *http.MaxBytesError.
+func accountFromRequest(w http.ResponseWriter, r *http.Request) (string, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAccountPayload))
+	if err != nil {
+		return "", err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload struct {
+		Email string `json:"email"`
+	}
+	// A malformed payload is left to the handler to reject.
+	_ = json.Unmarshal(body, &payload)
+
+	return payload.Email, nil
+}
+
+// tooManyRequests replies with the status 429 Too Many Requests and the number of seconds to wait.
+func (c *Controller) tooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	http.Error(w, jsonError(fmt.Errorf("%w, retry in %d seconds", errs.ErrTooManyAttempts, seconds)), http.StatusTooManyRequests)
+}
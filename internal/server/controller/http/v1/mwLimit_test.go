@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	config "github.com/nextlag/keeper/config/server"
+	"github.com/nextlag/keeper/internal/server/mw/limiter"
+)
+
+func TestMwLimitIP(t *testing.T) {
+	c, _, ctrl := loadTest(t)
+	defer ctrl.Finish()
+	c.limiter = limiter.New(&config.Security{LoginIPLimit: 2, LoginIPWindow: time.Minute})
+
+	handler := c.MwLimitIP()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	expected := []int{http.StatusCreated, http.StatusCreated, http.StatusTooManyRequests}
+	for _, status := range expected {
+		req := httptest.NewRequest(http.MethodPost, authRegister, nil)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, status, rr.Code)
+		if status == http.StatusTooManyRequests {
+			assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestMwLimitAccount(t *testing.T) {
+	c, _, ctrl := loadTest(t)
+	defer ctrl.Finish()
+	c.limiter = limiter.New(&config.Security{LoginFreeFailures: 1, LoginBackoff: time.Minute})
+
+	handler := c.MwLimitAccount()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		if strings.Contains(string(body), "wrong") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if strings.Contains(string(body), "challenge") {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "first failure is free",
+			body:           `{"email":"test@example.com","password":"wrong"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "success resets the failures",
+			body:           `{"email":"test@example.com","password":"password"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "free failure after the reset",
+			body:           `{"email":"test@example.com","password":"wrong"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "TOTP challenge keeps the failures",
+			body:           `{"email":"test@example.com","password":"challenge"}`,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "failure starting the backoff",
+			body:           `{"email":"test@example.com","password":"wrong"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "account has to wait",
+			body:           `{"email":"TEST@example.com","password":"password"}`,
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "other account is not limited",
+			body:           `{"email":"other@example.com","password":"password"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "malformed payload is passed on",
+			body:           `not json`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "payload is too large",
+			body:           `{"email":"other@example.com","password":"` + strings.Repeat("a", maxAccountPayload) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, authLogin, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -102,6 +103,8 @@ func (c *Controller) GetNotes(w http.ResponseWriter, r *http.Request) {
 // @Param note body entity.SecretNote true "Updated note data"
 // @Success 202 {string} string "Update accepted"
 // @Failure 400 {object} response
+// @Failure 404 {object} response
+// @Failure 409 {object} response
 // @Failure 500 {object} response
 // @Router /user/notes/{id} [patch]
 func (c *Controller) UpdateNote(w http.ResponseWriter, r *http.Request) {
@@ -129,7 +132,17 @@ func (c *Controller) UpdateNote(w http.ResponseWriter, r *http.Request) {
 
 	payloadNote.ID = noteUUID
 
-	if err = c.uc.UpdateNote(r.Context(), &payloadNote, currentUser.ID); err != nil {
+	err = c.uc.UpdateNote(r.Context(), &payloadNote, currentUser.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrRevisionConflict):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusConflict)
+		return
+	case errors.Is(err, errs.ErrWrongOwnerOrNotFound):
+		http.Error(w, jsonError(err), http.StatusNotFound)
+		return
+	default:
 		c.log.Error("error", l.ErrAttr(err))
 		http.Error(w, jsonError(err), http.StatusBadRequest)
 		return
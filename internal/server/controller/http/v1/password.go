@@ -0,0 +1,252 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+var (
+	errNewPasswordNotGiven = errors.New("new password has not given")
+	errDataKeyNotGiven     = errors.New("data key has not given")
+)
+
+// StageRekey godoc
+// @Summary Stage the re-encrypted vault
+// @Description Store the whole vault re-encrypted with a new data key until the key rotation commits it
+// @Tags password
+// @Accept json
+// @Produce json
+// @Param rekey body entity.Rekey true "Re-encrypted vault"
+// @Success 202 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Router /user/rekey [post]
+func (c *Controller) StageRekey(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	var rekey entity.Rekey
+	if err = json.NewDecoder(r.Body).Decode(&rekey); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+
+	if err = c.uc.StageRekey(r.Context(), &rekey, currentUser.ID); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if _, err = w.Write([]byte(jsonResponse("rekey staged"))); err != nil {
+		return
+	}
+}
+
+// StageRekeyBinary godoc
+// @Summary Stage a re-encrypted binary file
+// @Description Store the binary file re-encrypted with a new data key until the key rotation commits it
+// @Tags password
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Binary UUID"
+// @Param file formData file true "Re-encrypted binary file"
+// @Success 202 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Router /user/rekey/binary/{id} [post]
+func (c *Controller) StageRekeyBinary(w http.ResponseWriter, r *http.Request) {
+	binaryUUID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	_, file, err := r.FormFile("file")
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+
+	if err = c.uc.StageRekeyBinary(r.Context(), &currentUser, binaryUUID, file); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if _, err = w.Write([]byte(jsonResponse("rekey staged"))); err != nil {
+		return
+	}
+}
+
+// ChangePassword godoc
+// @Summary Change the master password
+// @Description Change the password together with the data key wrapped by the new password
+// @Tags password
+// @Accept json
+// @Produce json
+// @Param payload body entity.PasswordChange true "Old and new passwords with the re-wrapped data key"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Router /user/password [post]
+func (c *Controller) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	var payload entity.PasswordChange
+	if err = json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	if payload.NewPassword == "" {
+		http.Error(w, jsonError(errNewPasswordNotGiven), http.StatusBadRequest)
+		return
+	}
+	if payload.DataKey == "" {
+		http.Error(w, jsonError(errDataKeyNotGiven), http.StatusBadRequest)
+		return
+	}
+
+	err = c.uc.ChangePassword(r.Context(), &currentUser, payload.OldPassword, payload.NewPassword, payload.DataKey)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongCredentials), errors.Is(err, errs.ErrAuthUpgradeRequired):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write([]byte(jsonResponse("password changed"))); err != nil {
+		return
+	}
+}
+
+// GetDataKey godoc
+// @Summary Get the wrapped data key
+// @Description Retrieve the vault data key wrapped by the master password
+// @Tags password
+// @Produce json
+// @Success 200 {object} entity.DataKey
+// @Success 204 "No content"
+// @Failure 500 {object} response
+// @Router /user/key [get]
+func (c *Controller) GetDataKey(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	dataKey, err := c.uc.GetDataKey(r.Context(), &currentUser)
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	if dataKey == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(entity.DataKey{DataKey: dataKey}); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// RotateDataKey godoc
+// @Summary Rotate the data key
+// @Description Replace the vault with the staged copy re-encrypted with a new data key in one transaction
+// @Tags password
+// @Accept json
+// @Produce json
+// @Param payload body entity.DataKeyRotation true "Password and the new wrapped data key"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 409 {object} response
+// @Failure 500 {object} response
+// @Router /user/key [put]
+func (c *Controller) RotateDataKey(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	var payload entity.DataKeyRotation
+	if err = json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	if payload.DataKey == "" {
+		http.Error(w, jsonError(errDataKeyNotGiven), http.StatusBadRequest)
+		return
+	}
+
+	err = c.uc.RotateDataKey(r.Context(), &currentUser, payload.Password, payload.DataKey)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongCredentials), errors.Is(err, errs.ErrAuthUpgradeRequired):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	case errors.Is(err, errs.ErrRekeyIncomplete):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusConflict)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write([]byte(jsonResponse("data key rotated"))); err != nil {
+		return
+	}
+}
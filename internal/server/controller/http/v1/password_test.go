@@ -0,0 +1,239 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+)
+
+func TestStageRekey(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New()}
+
+	rekey := entity.Rekey{
+		Logins: []entity.Login{{ID: uuid.New(), Name: "login", Password: "$keeper$sealed"}},
+		Notes:  []entity.SecretNote{{ID: uuid.New(), Name: "note", Note: "$keeper$sealed"}},
+	}
+
+	tests := []struct {
+		name           string
+		mockReturn     error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "successful stage",
+			mockReturn:     nil,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"status":"rekey staged"}`,
+		},
+		{
+			name:           "error from use case",
+			mockReturn:     errors.New("failed to stage"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"failed to stage"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase.EXPECT().
+				StageRekey(gomock.Any(), &rekey, expectedUser.ID).
+				Return(tt.mockReturn).
+				Times(1)
+
+			reqBody, err := json.Marshal(rekey)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, userRekey, bytes.NewBuffer(reqBody))
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.StageRekey).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New(), Email: "user@example.com"}
+
+	tests := []struct {
+		name           string
+		payload        entity.PasswordChange
+		mockReturn     error
+		mockCalled     bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "successful password change",
+			payload:        entity.PasswordChange{OldPassword: "old", NewPassword: "new", DataKey: "$keeper$wrapped"},
+			mockCalled:     true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"password changed"}`,
+		},
+		{
+			name:           "new password not given",
+			payload:        entity.PasswordChange{OldPassword: "old", DataKey: "$keeper$wrapped"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"new password has not given"}` + "\n",
+		},
+		{
+			name:           "data key not given",
+			payload:        entity.PasswordChange{OldPassword: "old", NewPassword: "new"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"data key has not given"}` + "\n",
+		},
+		{
+			name:           "wrong old password",
+			payload:        entity.PasswordChange{OldPassword: "wrong", NewPassword: "new", DataKey: "$keeper$wrapped"},
+			mockReturn:     errs.ErrWrongCredentials,
+			mockCalled:     true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"wrong credentials have been given"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCalled {
+				mockUseCase.EXPECT().
+					ChangePassword(gomock.Any(), &expectedUser, tt.payload.OldPassword, tt.payload.NewPassword, tt.payload.DataKey).
+					Return(tt.mockReturn).
+					Times(1)
+			}
+
+			reqBody, err := json.Marshal(tt.payload)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, userPassword, bytes.NewBuffer(reqBody))
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.ChangePassword).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestGetDataKey(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New()}
+
+	tests := []struct {
+		name           string
+		dataKey        string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "wrapped key stored",
+			dataKey:        "$keeper$wrapped",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data_key":"$keeper$wrapped"}` + "\n",
+		},
+		{
+			name:           "no data key yet",
+			dataKey:        "",
+			expectedStatus: http.StatusNoContent,
+			expectedBody:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase.EXPECT().
+				GetDataKey(gomock.Any(), &expectedUser).
+				Return(tt.dataKey, nil).
+				Times(1)
+
+			req := httptest.NewRequest(http.MethodGet, userKey, nil)
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.GetDataKey).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestRotateDataKey(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New(), Email: "user@example.com"}
+	payload := entity.DataKeyRotation{Password: "password", DataKey: "$keeper$wrapped"}
+
+	tests := []struct {
+		name           string
+		mockReturn     error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "successful rotation",
+			mockReturn:     nil,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"data key rotated"}`,
+		},
+		{
+			name:           "wrong password",
+			mockReturn:     errs.ErrWrongCredentials,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"wrong credentials have been given"}` + "\n",
+		},
+		{
+			name:           "incomplete rekey",
+			mockReturn:     errs.ErrRekeyIncomplete,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"re-encrypted vault does not match the stored one"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase.EXPECT().
+				RotateDataKey(gomock.Any(), &expectedUser, payload.Password, payload.DataKey).
+				Return(tt.mockReturn).
+				Times(1)
+
+			reqBody, err := json.Marshal(payload)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPut, userKey, bytes.NewBuffer(reqBody))
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.RotateDataKey).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
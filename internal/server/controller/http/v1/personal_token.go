@@ -0,0 +1,173 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// maxTokenNameLength limits the name of a personal token.
+const maxTokenNameLength = 255
+
+var (
+	errTokenNameNotGiven = errors.New("token name has not given")
+	errTokenNameTooLong  = errors.New("token name is too long")
+	errTokenExpiry       = errors.New("token expiry has to be in the future")
+	errUnknownItemType   = errors.New("unknown item type in the token scope")
+)
+
+// personalTokenPayload holds the name, expiry and scope of a new personal token.
+type personalTokenPayload struct {
+	Name      string            `json:"name"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	Scope     entity.TokenScope `json:"scope"`
+}
+
+// validate checks the payload and returns the token it describes.
+func (payload personalTokenPayload) validate() (entity.PersonalToken, error) {
+	switch {
+	case payload.Name == "":
+		return entity.PersonalToken{}, errTokenNameNotGiven
+	case len(payload.Name) > maxTokenNameLength:
+		return entity.PersonalToken{}, errTokenNameTooLong
+	case !payload.ExpiresAt.After(time.Now()):
+		return entity.PersonalToken{}, errTokenExpiry
+	}
+	for _, itemType := range payload.Scope.ItemTypes {
+		if !slices.Contains(entity.ItemTypes, itemType) {
+			return entity.PersonalToken{}, errUnknownItemType
+		}
+	}
+
+	return entity.PersonalToken{Name: payload.Name, ExpiresAt: payload.ExpiresAt, Scope: payload.Scope}, nil
+}
+
+// GetPersonalTokens godoc
+// @Summary Get personal access tokens of the current user
+// @Description Retrieve the unexpired personal access tokens of the current user, without their secrets
+// @Tags tokens
+// @Produce json
+// @Success 200 {array} entity.PersonalToken
+// @Failure 500 {object} response
+// @Router /user/tokens [get]
+func (c *Controller) GetPersonalTokens(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	tokens, err := c.uc.GetPersonalTokens(r.Context(), currentUser)
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(tokens); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+	}
+}
+
+// CreatePersonalToken godoc
+// @Summary Create a personal access token
+// @Description Create a long-lived token for automation, limited to its scope: read only access,
+// @Description item types and item IDs. The secret of the token is only returned once.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param payload body personalTokenPayload true "Name, expiry and scope of the token"
+// @Success 201 {object} entity.PersonalToken
+// @Failure 400 {object} response
+// @Failure 500 {object} response
+// @Router /user/tokens [post]
+func (c *Controller) CreatePersonalToken(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	var payload personalTokenPayload
+	if err = json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	token, err := payload.validate()
+	if err != nil {
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+
+	token, err = c.uc.CreatePersonalToken(r.Context(), currentUser, token)
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(token); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+	}
+}
+
+// RevokePersonalToken godoc
+// @Summary Revoke a personal access token by UUID
+// @Description Delete a personal access token of the current user, it stops working at once
+// @Tags tokens
+// @Param id path string true "Token UUID"
+// @Success 202 {string} string "Delete accepted"
+// @Failure 400 {object} response
+// @Failure 404 {object} response
+// @Failure 500 {object} response
+// @Router /user/tokens/{id} [delete]
+func (c *Controller) RevokePersonalToken(w http.ResponseWriter, r *http.Request) {
+	tokenUUID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err), "tokenUUID", tokenUUID)
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	err = c.uc.RevokePersonalToken(r.Context(), currentUser, tokenUUID)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongOwnerOrNotFound):
+		http.Error(w, jsonError(err), http.StatusNotFound)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if _, err = w.Write([]byte(jsonResponse("delete accepted"))); err != nil {
+		return
+	}
+}
@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+)
+
+func TestGetPersonalTokens(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New()}
+	tokens := []entity.PersonalToken{
+		{ID: uuid.New(), Name: "ci", Scope: entity.TokenScope{ReadOnly: true, ItemTypes: []string{entity.ItemLogins}}},
+	}
+
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful get tokens",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "error from use case",
+			mockError:      errors.New("get failed"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase.EXPECT().GetPersonalTokens(gomock.Any(), expectedUser).Return(tokens, tt.mockError)
+
+			req := httptest.NewRequest(http.MethodGet, userTokens, nil)
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.GetPersonalTokens).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response []entity.PersonalToken
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, tokens, response)
+			}
+		})
+	}
+}
+
+func TestCreatePersonalToken(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New()}
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	scope := entity.TokenScope{ReadOnly: true, ItemTypes: []string{entity.ItemNotes}}
+	body := func(name string, expiresAt time.Time, itemType string) string {
+		return fmt.Sprintf(`{"name":%q,"expires_at":%q,"scope":{"read_only":true,"item_types":[%q]}}`,
+			name, expiresAt.Format(time.RFC3339), itemType)
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockCall       bool
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful create",
+			body:           body("ci", expiresAt, entity.ItemNotes),
+			mockCall:       true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "error from use case",
+			body:           body("ci", expiresAt, entity.ItemNotes),
+			mockCall:       true,
+			mockError:      errors.New("create failed"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "no name",
+			body:           body("", expiresAt, entity.ItemNotes),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "expired",
+			body:           body("ci", time.Now().Add(-time.Hour), entity.ItemNotes),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown item type",
+			body:           body("ci", expiresAt, "devices"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "malformed payload",
+			body:           `{"name":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				token := entity.PersonalToken{Name: "ci", ExpiresAt: expiresAt, Scope: scope}
+				created := token
+				created.ID = uuid.New()
+				created.Token = "kpr_secret"
+				mockUseCase.EXPECT().
+					CreatePersonalToken(gomock.Any(), expectedUser, token).
+					Return(created, tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, userTokens, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.CreatePersonalToken).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				assert.Contains(t, rr.Body.String(), "kpr_secret")
+			}
+		})
+	}
+}
+
+func TestRevokePersonalToken(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New()}
+	tokenID := uuid.New()
+
+	tests := []struct {
+		name           string
+		tokenID        string
+		mockReturn     error
+		expectCall     bool
+		expectedStatus int
+	}{
+		{
+			name:           "successful revoke",
+			tokenID:        tokenID.String(),
+			expectCall:     true,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "unknown token",
+			tokenID:        tokenID.String(),
+			mockReturn:     errs.ErrWrongOwnerOrNotFound,
+			expectCall:     true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid UUID in URL",
+			tokenID:        "123a45test",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectCall {
+				mockUseCase.EXPECT().
+					RevokePersonalToken(gomock.Any(), expectedUser, tokenID).
+					Return(tt.mockReturn).Times(1)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, userTokens+"/"+tt.tokenID, nil)
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.tokenID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.RevokePersonalToken).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+var errRecoveryKeyNotGiven = errors.New("recovery key has not given")
+
+// SetRecoveryKey godoc
+// @Summary Set a new recovery key
+// @Description Store the data key wrapped by a new recovery key, the previous recovery key stops working
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param payload body entity.RecoveryKey true "Password, recovery key secret and wrapped data key"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 409 {object} response
+// @Failure 500 {object} response
+// @Router /user/recovery [put]
+func (c *Controller) SetRecoveryKey(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	var recoveryKey entity.RecoveryKey
+	if err = json.NewDecoder(r.Body).Decode(&recoveryKey); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	if recoveryKey.RecoveryAuth == "" || recoveryKey.RecoveryKey == "" {
+		http.Error(w, jsonError(errRecoveryKeyNotGiven), http.StatusBadRequest)
+		return
+	}
+
+	err = c.uc.SetRecoveryKey(r.Context(), currentUser, recoveryKey)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongCredentials):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	case errors.Is(err, errs.ErrAuthUpgradeRequired):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusConflict)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write([]byte(jsonResponse("recovery key set"))); err != nil {
+		return
+	}
+}
+
+// GetRecoveryKey godoc
+// @Summary Get the data key wrapped by the recovery key
+// @Description Check the recovery key secret of the user and return the data key wrapped by the recovery key
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body entity.Recovery true "Email and recovery key secret"
+// @Success 200 {object} entity.RecoveryKey
+// @Failure 400 {object} response
+// @Failure 401 {object} response
+// @Failure 429 {object} response
+// @Failure 500 {object} response
+// @Router /auth/recover/key [post]
+func (c *Controller) GetRecoveryKey(w http.ResponseWriter, r *http.Request) {
+	var recovery entity.Recovery
+	if err := json.NewDecoder(r.Body).Decode(&recovery); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	if recovery.RecoveryAuth == "" {
+		http.Error(w, jsonError(errRecoveryKeyNotGiven), http.StatusBadRequest)
+		return
+	}
+
+	recoveryKey, err := c.uc.GetRecoveryKey(r.Context(), recovery.Email, recovery.RecoveryAuth)
+	if !c.recoveryError(w, err) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(entity.RecoveryKey{RecoveryKey: recoveryKey}); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+	}
+}
+
+// RecoverAccount godoc
+// @Summary Set a new password with the recovery key
+// @Description Check the recovery key secret of the user and set the new password with the data key wrapped by it,
+// @Description the user is signed out on every device
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body entity.Recovery true "Email, recovery key secret, new password and wrapped data key"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 401 {object} response
+// @Failure 429 {object} response
+// @Failure 500 {object} response
+// @Router /auth/recover [post]
+func (c *Controller) RecoverAccount(w http.ResponseWriter, r *http.Request) {
+	var recovery entity.Recovery
+	if err := json.NewDecoder(r.Body).Decode(&recovery); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case recovery.RecoveryAuth == "":
+		http.Error(w, jsonError(errRecoveryKeyNotGiven), http.StatusBadRequest)
+		return
+	case recovery.Password == "":
+		http.Error(w, jsonError(errNewPasswordNotGiven), http.StatusBadRequest)
+		return
+	case recovery.DataKey == "":
+		http.Error(w, jsonError(errDataKeyNotGiven), http.StatusBadRequest)
+		return
+	}
+
+	err := c.uc.RecoverAccount(r.Context(), recovery)
+	if !c.recoveryError(w, err) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write([]byte(jsonResponse("password set with the recovery key"))); err != nil {
+		return
+	}
+}
+
+// recoveryError writes the response of a failed recovery request, it reports whether the request has succeeded.
+func (c *Controller) recoveryError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errs.ErrWrongEmail):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+	case errors.Is(err, errs.ErrWrongRecoveryKey):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusUnauthorized)
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+	}
+
+	return false
+}
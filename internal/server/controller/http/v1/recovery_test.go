@@ -0,0 +1,198 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+)
+
+func TestSetRecoveryKey(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New(), Email: "test@example.com"}
+	recoveryKey := entity.RecoveryKey{Password: "password", RecoveryAuth: "auth", RecoveryKey: "wrapped"}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockCall       bool
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful set",
+			body:           `{"password":"password","recovery_auth":"auth","recovery_key":"wrapped"}`,
+			mockCall:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong password",
+			body:           `{"password":"password","recovery_auth":"auth","recovery_key":"wrapped"}`,
+			mockCall:       true,
+			mockError:      errs.ErrWrongCredentials,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "internal error",
+			body:           `{"password":"password","recovery_auth":"auth","recovery_key":"wrapped"}`,
+			mockCall:       true,
+			mockError:      errors.New("internal error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "no recovery key",
+			body:           `{"password":"password","recovery_auth":"auth"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				mockUseCase.EXPECT().SetRecoveryKey(gomock.Any(), expectedUser, recoveryKey).Return(tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, userRecovery, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.SetRecoveryKey).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestGetRecoveryKey(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockCall       bool
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful get",
+			body:           `{"email":"test@example.com","recovery_auth":"auth"}`,
+			mockCall:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong recovery key",
+			body:           `{"email":"test@example.com","recovery_auth":"auth"}`,
+			mockCall:       true,
+			mockError:      errs.ErrWrongRecoveryKey,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong email",
+			body:           `{"email":"test@example.com","recovery_auth":"auth"}`,
+			mockCall:       true,
+			mockError:      errs.ErrWrongEmail,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no recovery key",
+			body:           `{"email":"test@example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				mockUseCase.EXPECT().
+					GetRecoveryKey(gomock.Any(), "test@example.com", "auth").
+					Return("wrapped", tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, authRecoverKey, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.GetRecoveryKey).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response entity.RecoveryKey
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, "wrapped", response.RecoveryKey)
+			}
+		})
+	}
+}
+
+func TestRecoverAccount(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	recovery := entity.Recovery{Email: "test@example.com", RecoveryAuth: "auth", Password: "password", DataKey: "wrapped"}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockCall       bool
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful recovery",
+			body:           `{"email":"test@example.com","recovery_auth":"auth","password":"password","data_key":"wrapped"}`,
+			mockCall:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong recovery key",
+			body:           `{"email":"test@example.com","recovery_auth":"auth","password":"password","data_key":"wrapped"}`,
+			mockCall:       true,
+			mockError:      errs.ErrWrongRecoveryKey,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "internal error",
+			body:           `{"email":"test@example.com","recovery_auth":"auth","password":"password","data_key":"wrapped"}`,
+			mockCall:       true,
+			mockError:      errors.New("internal error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "no new password",
+			body:           `{"email":"test@example.com","recovery_auth":"auth","data_key":"wrapped"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no data key",
+			body:           `{"email":"test@example.com","recovery_auth":"auth","password":"password"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				mockUseCase.EXPECT().RecoverAccount(gomock.Any(), recovery).Return(tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, authRecover, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.RecoverAccount).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+var (
+	errTOTPCodeNotGiven     = errors.New("two-factor code has not given")
+	errRecoveryCodeNotGiven = errors.New("recovery code has not given")
+)
+
+// totpSignInPayload holds the challenge of a sign in, the email of the user and the TOTP code completing it.
+type totpSignInPayload struct {
+	Email     string `json:"email"`
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+// totpCodePayload holds a TOTP code.
+type totpCodePayload struct {
+	Code string `json:"code"`
+}
+
+// disableTOTPPayload holds the credentials and a recovery code of a user who has lost the authenticator.
+type disableTOTPPayload struct {
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// SignInTOTP godoc
+// @Summary Complete a sign in with a TOTP code
+// @Description Check the TOTP code of a sign in challenge and generate JWT tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body totpSignInPayload true "Email, challenge and TOTP code"
+// @Success 200 {object} entity.JWT
+// @Failure 400 {object} response
+// @Failure 401 {object} response
+// @Failure 429 {object} response
+// @Failure 500 {object} response
+// @Router /auth/login/totp [post]
+func (c *Controller) SignInTOTP(w http.ResponseWriter, r *http.Request) {
+	var payload totpSignInPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	if payload.Code == "" {
+		http.Error(w, jsonError(errTOTPCodeNotGiven), http.StatusBadRequest)
+		return
+	}
+
+	jwtToken, err := c.uc.SignInTOTP(r.Context(), payload.Email, payload.Challenge, payload.Code)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongTOTPCode), errors.Is(err, errs.ErrTOTPChallenge):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusUnauthorized)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	c.setLoginCookies(w, jwtToken)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(jwtToken); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DisableTOTP godoc
+// @Summary Disable two-factor authentication with a recovery code
+// @Description Turn the TOTP second factor off for a user who has lost the authenticator, the recovery codes stop working
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body disableTOTPPayload true "Credentials and recovery code"
+// @Success 200 {string} string "Two-factor authentication disabled"
+// @Failure 400 {object} response
+// @Failure 401 {object} response
+// @Failure 409 {object} response
+// @Failure 429 {object} response
+// @Failure 500 {object} response
+// @Router /auth/totp/disable [post]
+func (c *Controller) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	var payload disableTOTPPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	if payload.RecoveryCode == "" {
+		http.Error(w, jsonError(errRecoveryCodeNotGiven), http.StatusBadRequest)
+		return
+	}
+
+	err := c.uc.DisableTOTP(r.Context(), payload.Email, payload.Password, payload.RecoveryCode)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongEmail), errors.Is(err, errs.ErrWrongCredentials):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	case errors.Is(err, errs.ErrWrongRecoveryCode):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusUnauthorized)
+		return
+	case errors.Is(err, errs.ErrTOTPNotEnrolled), errors.Is(err, errs.ErrAuthUpgradeRequired):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusConflict)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write([]byte(jsonResponse("two-factor authentication disabled"))); err != nil {
+		return
+	}
+}
+
+// EnrollTOTP godoc
+// @Summary Enroll a TOTP second factor
+// @Description Generate the secret and the provisioning URI of a TOTP second factor, it is required at sign in once confirmed
+// @Tags user
+// @Produce json
+// @Success 200 {object} entity.TOTPEnrollment
+// @Failure 409 {object} response
+// @Failure 500 {object} response
+// @Router /user/totp [post]
+func (c *Controller) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	enrollment, err := c.uc.EnrollTOTP(r.Context(), currentUser)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrTOTPAlreadyEnabled):
+		http.Error(w, jsonError(err), http.StatusConflict)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(enrollment); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+	}
+}
+
+// ConfirmTOTP godoc
+// @Summary Confirm the enrolled TOTP second factor
+// @Description Turn the enrolled second factor on with a code of it and generate one-time recovery codes
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param payload body totpCodePayload true "TOTP code"
+// @Success 200 {object} entity.RecoveryCodes
+// @Failure 400 {object} response
+// @Failure 409 {object} response
+// @Failure 500 {object} response
+// @Router /user/totp/confirm [post]
+func (c *Controller) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	var payload totpCodePayload
+	if err = json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	if payload.Code == "" {
+		http.Error(w, jsonError(errTOTPCodeNotGiven), http.StatusBadRequest)
+		return
+	}
+
+	recoveryCodes, err := c.uc.ConfirmTOTP(r.Context(), currentUser, payload.Code)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongTOTPCode):
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	case errors.Is(err, errs.ErrTOTPAlreadyEnabled), errors.Is(err, errs.ErrTOTPNotEnrolled):
+		http.Error(w, jsonError(err), http.StatusConflict)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(recoveryCodes); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,261 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+)
+
+func TestSignInTOTP(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockCall       bool
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful signin",
+			body:           `{"email":"test@example.com","challenge":"challenge","code":"123456"}`,
+			mockCall:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong code",
+			body:           `{"email":"test@example.com","challenge":"challenge","code":"123456"}`,
+			mockCall:       true,
+			mockError:      errs.ErrWrongTOTPCode,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "expired challenge",
+			body:           `{"email":"test@example.com","challenge":"challenge","code":"123456"}`,
+			mockCall:       true,
+			mockError:      errs.ErrTOTPChallenge,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "internal error",
+			body:           `{"email":"test@example.com","challenge":"challenge","code":"123456"}`,
+			mockCall:       true,
+			mockError:      errors.New("internal error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "no code",
+			body:           `{"challenge":"challenge"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				mockUseCase.EXPECT().
+					SignInTOTP(gomock.Any(), "test@example.com", "challenge", "123456").
+					Return(entity.JWT{AccessToken: "access-token", RefreshToken: "refresh-token"}, tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, authTOTP, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.SignInTOTP).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Len(t, rr.Result().Cookies(), 3)
+				assert.Contains(t, rr.Body.String(), "access-token")
+			}
+		})
+	}
+}
+
+func TestDisableTOTP(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockCall       bool
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful disable",
+			body:           `{"email":"test@example.com","password":"password","recovery_code":"abcde-fghij"}`,
+			mockCall:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong credentials",
+			body:           `{"email":"test@example.com","password":"password","recovery_code":"abcde-fghij"}`,
+			mockCall:       true,
+			mockError:      errs.ErrWrongCredentials,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong recovery code",
+			body:           `{"email":"test@example.com","password":"password","recovery_code":"abcde-fghij"}`,
+			mockCall:       true,
+			mockError:      errs.ErrWrongRecoveryCode,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "not enrolled",
+			body:           `{"email":"test@example.com","password":"password","recovery_code":"abcde-fghij"}`,
+			mockCall:       true,
+			mockError:      errs.ErrTOTPNotEnrolled,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "no recovery code",
+			body:           `{"email":"test@example.com","password":"password"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				mockUseCase.EXPECT().
+					DisableTOTP(gomock.Any(), "test@example.com", "password", "abcde-fghij").
+					Return(tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, authTOTPOff, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.DisableTOTP).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New(), Email: "test@example.com"}
+	enrollment := entity.TOTPEnrollment{Secret: "SECRET", URI: "otpauth://totp/Keeper:test@example.com?secret=SECRET"}
+
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful enroll",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "already enabled",
+			mockError:      errs.ErrTOTPAlreadyEnabled,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "internal error",
+			mockError:      errors.New("internal error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase.EXPECT().EnrollTOTP(gomock.Any(), expectedUser).Return(enrollment, tt.mockError)
+
+			req := httptest.NewRequest(http.MethodPost, userTOTP, nil)
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.EnrollTOTP).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response entity.TOTPEnrollment
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, enrollment, response)
+			}
+		})
+	}
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New(), Email: "test@example.com"}
+	recoveryCodes := entity.RecoveryCodes{Codes: []string{"abcde-fghij", "klmno-pqrst"}}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockCall       bool
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful confirm",
+			body:           `{"code":"123456"}`,
+			mockCall:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong code",
+			body:           `{"code":"123456"}`,
+			mockCall:       true,
+			mockError:      errs.ErrWrongTOTPCode,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "not enrolled",
+			body:           `{"code":"123456"}`,
+			mockCall:       true,
+			mockError:      errs.ErrTOTPNotEnrolled,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "no code",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				mockUseCase.EXPECT().
+					ConfirmTOTP(gomock.Any(), expectedUser, "123456").
+					Return(recoveryCodes, tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, userTOTP+"/confirm", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.ConfirmTOTP).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response entity.RecoveryCodes
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, recoveryCodes, response)
+			}
+		})
+	}
+}
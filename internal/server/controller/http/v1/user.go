@@ -3,6 +3,7 @@ package v1
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/nextlag/keeper/internal/entity"
@@ -10,6 +11,14 @@ import (
 	"github.com/nextlag/keeper/pkg/logger/l"
 )
 
+var errPasswordNotGiven = errors.New("password has not given")
+
+// deleteUserPayload holds the credentials confirming the deletion of an account.
+type deleteUserPayload struct {
+	Password string `json:"password"`
+	Code     string `json:"code"` // TOTP code, required if two-factor authentication is enabled.
+}
+
 // getUserFromCtx - Retrieves the current user from the request context
 func (c *Controller) getUserFromCtx(ctx context.Context) (entity.User, error) {
 	currentUser, ok := ctx.Value(currentUserKey).(entity.User)
@@ -43,3 +52,64 @@ func (c *Controller) UserInfo(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, jsonError(err), http.StatusInternalServerError)
 	}
 }
+
+// DeleteUser godoc
+// @Summary Delete the current user
+// @Description Delete the account with all of its items, files, sessions and personal tokens.
+// @Description The password, and the TOTP code if two-factor authentication is enabled, are checked again
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param payload body deleteUserPayload true "Password and TOTP code"
+// @Success 200 {object} response
+// @Failure 400 {object} response
+// @Failure 401 {object} response
+// @Failure 409 {object} response
+// @Failure 500 {object} response
+// @Router /user/me [delete]
+func (c *Controller) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := c.getUserFromCtx(r.Context())
+	if err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(errs.ErrUnexpectedError), http.StatusInternalServerError)
+		return
+	}
+
+	var payload deleteUserPayload
+	if err = json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	if payload.Password == "" {
+		http.Error(w, jsonError(errPasswordNotGiven), http.StatusBadRequest)
+		return
+	}
+
+	err = c.uc.DeleteUser(r.Context(), currentUser, payload.Password, payload.Code)
+	switch {
+	case err == nil:
+	case errors.Is(err, errs.ErrWrongCredentials):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	case errors.Is(err, errs.ErrWrongTOTPCode):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusUnauthorized)
+		return
+	case errors.Is(err, errs.ErrAuthUpgradeRequired):
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusConflict)
+		return
+	default:
+		c.log.Error("error", l.ErrAttr(err))
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write([]byte(jsonResponse("account deleted"))); err != nil {
+		return
+	}
+}
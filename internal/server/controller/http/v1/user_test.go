@@ -3,10 +3,14 @@ package v1
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/nextlag/keeper/internal/entity"
@@ -69,3 +73,74 @@ func TestUserInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteUser(t *testing.T) {
+	c, mockUseCase, ctrl := loadTest(t)
+	defer ctrl.Finish()
+
+	expectedUser := entity.User{ID: uuid.New(), Email: "test@example.com"}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockCall       bool
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful deletion",
+			body:           `{"password":"password","code":"123456"}`,
+			mockCall:       true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong password",
+			body:           `{"password":"password","code":"123456"}`,
+			mockCall:       true,
+			mockError:      errs.ErrWrongCredentials,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong code",
+			body:           `{"password":"password","code":"123456"}`,
+			mockCall:       true,
+			mockError:      errs.ErrWrongTOTPCode,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "internal error",
+			body:           `{"password":"password","code":"123456"}`,
+			mockCall:       true,
+			mockError:      errors.New("internal error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "no password",
+			body:           `{"code":"123456"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid body",
+			body:           `invalid`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockCall {
+				mockUseCase.EXPECT().
+					DeleteUser(gomock.Any(), expectedUser, "password", "123456").
+					Return(tt.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, userInfo, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), currentUserKey, expectedUser))
+			rr := httptest.NewRecorder()
+
+			http.HandlerFunc(c.DeleteUser).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package limiter
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/nextlag/keeper/config/server"
+	"github.com/nextlag/keeper/pkg/cache"
+)
+
+const (
+	ipPrefix      = "ip:"
+	accountPrefix = "account:"
+
+	maxBackoff = 24 * time.Hour // Longest backoff if there is no lockout.
+)
+
+// Limiter throttles authentication attempts in memory. Requests are limited per IP address
+// within a fixed window, failed sign ins of an account delay the next attempt exponentially
+// and lock the account out for a while once there are too many of them.
+// Zero settings disable the corresponding limit.
+type Limiter struct {
+	mu    sync.Mutex
+	cache cache.Cacher
+	cfg   *config.Security
+	now   func() time.Time
+}
+
+// ipState counts the requests of an IP address within the current window.
+type ipState struct {
+	windowStart time.Time
+	requests    int
+}
+
+// accountState counts the consecutive failed sign ins of an account.
+type accountState struct {
+	failures     int
+	blockedUntil time.Time
+}
+
+// New creates a limiter with the settings of cfg. The state of an IP address or an account
+// is forgotten without attempts for the longest of the window and the longest block of an account,
+// so an account is never let in before its backoff or lockout is over.
+func New(cfg *config.Security) *Limiter {
+	cleanup := max(cfg.LoginIPWindow, time.Minute)
+	expiration := max(cleanup, longestBlock(cfg))
+
+	return &Limiter{
+		cache: cache.New(expiration, cleanup),
+		cfg:   cfg,
+		now:   time.Now,
+	}
+}
+
+// AllowIP counts a request of the IP address. It returns how long the address has to wait
+// before the next request, or zero if the request is allowed.
+func (lim *Limiter) AllowIP(ip string) time.Duration {
+	if lim.cfg.LoginIPLimit <= 0 || lim.cfg.LoginIPWindow <= 0 {
+		return 0
+	}
+
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+
+	now := lim.now()
+	state, _ := lim.cache.Get(ipPrefix + ip)
+	current, _ := state.(ipState)
+	if now.Sub(current.windowStart) >= lim.cfg.LoginIPWindow {
+		current = ipState{windowStart: now}
+	}
+	if current.requests >= lim.cfg.LoginIPLimit {
+		return current.windowStart.Add(lim.cfg.LoginIPWindow).Sub(now)
+	}
+
+	current.requests++
+	lim.cache.Set(ipPrefix+ip, current)
+
+	return 0
+}
+
+// AccountRetryAfter returns how long the account has to wait before the next sign in attempt,
+// or zero if it may try now.
+func (lim *Limiter) AccountRetryAfter(account string) time.Duration {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+
+	state, _ := lim.cache.Get(accountPrefix + normalize(account))
+	current, _ := state.(accountState)
+
+	return max(current.blockedUntil.Sub(lim.now()), 0)
+}
+
+// Failure records a failed sign in of the account. Every failure after LoginFreeFailures
+// doubles the delay before the next attempt, starting with LoginBackoff. After LoginMaxFailures
+// failures the account is locked out for LoginLockout and the count starts over.
+func (lim *Limiter) Failure(account string) {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+
+	key := accountPrefix + normalize(account)
+	state, _ := lim.cache.Get(key)
+	current, _ := state.(accountState)
+	current.failures++
+
+	now := lim.now()
+	switch {
+	case lim.cfg.LoginMaxFailures > 0 && current.failures >= lim.cfg.LoginMaxFailures:
+		current = accountState{blockedUntil: now.Add(lim.cfg.LoginLockout)}
+	case lim.cfg.LoginBackoff > 0 && current.failures > lim.cfg.LoginFreeFailures:
+		current.blockedUntil = now.Add(lim.backoff(current.failures - lim.cfg.LoginFreeFailures))
+	}
+
+	lim.cache.Set(key, current)
+}
+
+// Success forgets the failed sign ins of the account.
+func (lim *Limiter) Success(account string) {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+
+	lim.cache.Set(accountPrefix+normalize(account), accountState{})
+}
+
+// backoff returns the delay after the given number of failures past the free ones,
+// capped at the lockout if there is one.
+func (lim *Limiter) backoff(failures int) time.Duration {
+	limit := maxBackoff
+	if lim.cfg.LoginLockout > 0 {
+		limit = lim.cfg.LoginLockout
+	}
+
+	delay := lim.cfg.LoginBackoff
+	for i := 1; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+
+	return min(delay, limit)
+}
+
+// longestBlock returns the longest time an account can be blocked for: the lockout,
+// or the cap of the backoff if there is no lockout.
+func longestBlock(cfg *config.Security) time.Duration {
+	switch {
+	case cfg.LoginLockout > 0:
+		return cfg.LoginLockout
+	case cfg.LoginBackoff > 0:
+		return maxBackoff
+	default:
+		return 0
+	}
+}
+
+// normalize makes the accounts of the same email share one state.
+func normalize(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
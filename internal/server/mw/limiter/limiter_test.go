@@ -0,0 +1,121 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	config "github.com/nextlag/keeper/config/server"
+)
+
+func newTestLimiter(cfg *config.Security) (*Limiter, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lim := New(cfg)
+	lim.now = func() time.Time { return now }
+
+	return lim, &now
+}
+
+func TestAllowIP(t *testing.T) {
+	lim, now := newTestLimiter(&config.Security{LoginIPLimit: 2, LoginIPWindow: time.Minute})
+
+	assert.Zero(t, lim.AllowIP("10.0.0.1"))
+	assert.Zero(t, lim.AllowIP("10.0.0.1"))
+	assert.Equal(t, time.Minute, lim.AllowIP("10.0.0.1"))
+	assert.Zero(t, lim.AllowIP("10.0.0.2"), "other addresses are not limited")
+
+	*now = now.Add(40 * time.Second)
+	assert.Equal(t, 20*time.Second, lim.AllowIP("10.0.0.1"))
+
+	*now = now.Add(20 * time.Second)
+	assert.Zero(t, lim.AllowIP("10.0.0.1"), "a new window starts")
+}
+
+func TestAllowIPDisabled(t *testing.T) {
+	lim, _ := newTestLimiter(&config.Security{})
+
+	for range 100 {
+		assert.Zero(t, lim.AllowIP("10.0.0.1"))
+	}
+}
+
+func TestAccountBackoff(t *testing.T) {
+	lim, now := newTestLimiter(&config.Security{
+		LoginFreeFailures: 2,
+		LoginBackoff:      time.Second,
+		LoginMaxFailures:  6,
+		LoginLockout:      time.Hour,
+	})
+	account := "User@Example.com"
+
+	lim.Failure(account)
+	lim.Failure(account)
+	assert.Zero(t, lim.AccountRetryAfter(account), "free failures have no backoff")
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for _, delay := range expected {
+		lim.Failure(account)
+		assert.Equal(t, delay, lim.AccountRetryAfter(" user@example.com"))
+		*now = now.Add(delay)
+		assert.Zero(t, lim.AccountRetryAfter(account))
+	}
+
+	lim.Failure(account)
+	assert.Equal(t, time.Hour, lim.AccountRetryAfter(account), "too many failures lock the account out")
+
+	*now = now.Add(time.Hour)
+	assert.Zero(t, lim.AccountRetryAfter(account))
+	lim.Failure(account)
+	assert.Zero(t, lim.AccountRetryAfter(account), "the count starts over after the lockout")
+}
+
+func TestAccountSuccess(t *testing.T) {
+	lim, _ := newTestLimiter(&config.Security{LoginBackoff: time.Second})
+	account := "user@example.com"
+
+	lim.Failure(account)
+	assert.Equal(t, time.Second, lim.AccountRetryAfter(account))
+
+	lim.Success(account)
+	assert.Zero(t, lim.AccountRetryAfter(account))
+}
+
+func TestBackoffCap(t *testing.T) {
+	lim, _ := newTestLimiter(&config.Security{LoginBackoff: time.Second, LoginLockout: 10 * time.Second})
+
+	assert.Equal(t, 8*time.Second, lim.backoff(4))
+	assert.Equal(t, 10*time.Second, lim.backoff(5))
+	assert.Equal(t, 10*time.Second, lim.backoff(1000))
+
+	lim.cfg.LoginLockout = 0
+	assert.Equal(t, maxBackoff, lim.backoff(1000))
+}
+
+func TestLongestBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.Security
+		expected time.Duration
+	}{
+		{
+			name:     "lockout",
+			cfg:      config.Security{LoginBackoff: time.Second, LoginLockout: 15 * time.Minute},
+			expected: 15 * time.Minute,
+		},
+		{
+			name:     "backoff without a lockout",
+			cfg:      config.Security{LoginBackoff: time.Second},
+			expected: maxBackoff,
+		},
+		{
+			name: "no account limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, longestBlock(&tt.cfg))
+		})
+	}
+}
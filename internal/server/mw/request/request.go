@@ -1,6 +1,8 @@
 package request
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"time"
 
@@ -23,7 +25,12 @@ type Request struct {
 	Compress    string `json:"compress,omitempty"`
 }
 
+type contextKey string
+
+const clientIPKey contextKey = "clientIP"
+
 // MwRequest creates middleware for logging HTTP requests.
+// It also stores the IP address of the client in the context of the request, see ClientIP.
 func MwRequest(log *l.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
@@ -52,8 +59,26 @@ func MwRequest(log *l.Logger) func(next http.Handler) http.Handler {
 				}
 			}()
 
-			next.ServeHTTP(ww, r)
+			ctx := context.WithValue(r.Context(), clientIPKey, remoteIP(r.RemoteAddr))
+			next.ServeHTTP(ww, r.WithContext(ctx))
 		}
 		return http.HandlerFunc(fn)
 	}
 }
+
+// ClientIP returns the IP address of the client stored in the context by MwRequest,
+// or an empty string outside of it.
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// remoteIP strips the port from the remote address of the request.
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+
+	return host
+}
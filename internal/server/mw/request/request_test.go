@@ -46,6 +46,7 @@ func TestLoggerMiddleware(t *testing.T) {
 			handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				assert.Equal(t, tc.method, r.Method)
 				assert.Equal(t, tc.path, r.URL.Path)
+				assert.Equal(t, "127.0.0.1", request.ClientIP(r.Context()))
 
 				w.WriteHeader(http.StatusOK)
 				_, err = w.Write([]byte("OK"))
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"os"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// deletedUserPrefix prefixes the cache keys marking deleted users.
+const deletedUserPrefix = "deleted-user:"
+
+// DeleteUser deletes the account of the user with everything stored for it: the vault items, the files,
+// the sessions and the personal tokens. The user signs in again for it with the password,
+// and with a TOTP code if the second factor is enabled. Every token of the user stops working at once,
+// including the ones cached before, and its event streams end. Returns ErrWrongCredentials
+// if the password does not match and ErrWrongTOTPCode for a wrong code.
+func (uc *UseCase) DeleteUser(ctx context.Context, currentUser entity.User, password, code string) error {
+	user, err := uc.repo.GetUserByEmail(ctx, currentUser.Email, password)
+	if err != nil {
+		return l.WrapErr(err)
+	}
+	if user.TOTPEnabled {
+		if err = uc.repo.VerifyTOTP(ctx, user.ID, code); err != nil {
+			return l.WrapErr(err)
+		}
+	}
+
+	if err = uc.repo.DeleteUser(ctx, user.ID); err != nil {
+		return l.WrapErr(err)
+	}
+	uc.markDeleted(user.ID)
+	uc.events.disconnect(user.ID)
+
+	// The account is gone already, files left behind are only logged.
+	if err = os.RemoveAll(uc.userDirectory(user.ID)); err != nil {
+		uc.log.Error("error", l.ErrAttr(err))
+	}
+
+	return nil
+}
+
+// markDeleted evicts the user from the token cache: the cached tokens of the user are rejected
+// until they expire, the mark expires with the same default expiration, so it outlives them.
+func (uc *UseCase) markDeleted(userID uuid.UUID) {
+	uc.cache.Set(deletedUserPrefix+userID.String(), true)
+}
+
+// userDeleted reports whether the user has been marked deleted in the cache.
+func (uc *UseCase) userDeleted(userID uuid.UUID) bool {
+	_, deleted := uc.cache.Get(deletedUserPrefix + userID.String())
+	return deleted
+}
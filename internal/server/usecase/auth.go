@@ -2,8 +2,11 @@ package usecase
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/nextlag/keeper/internal/entity"
 	"github.com/nextlag/keeper/internal/utils"
@@ -15,31 +18,33 @@ const minutesPerHour = 60
 
 // SignUpUser registers a new user with the provided email and hashed password.
 // It validates the email format and hashes the password before storing it.
-func (uc *UseCase) SignUpUser(ctx context.Context, email, password string) (user entity.User, err error) {
+// The keys of the vault are stored with the user, the recovery key is checked with the hash of its secret.
+func (uc *UseCase) SignUpUser(ctx context.Context, email, password string, keys entity.VaultKeys) (user entity.User, err error) {
 	if _, err = mail.ParseAddress(email); err != nil {
 		err = errs.ErrWrongEmail
 		return user, err
 	}
 
-	hashedPassword, err := utils.HashPassword(password)
+	hashedPassword, err := utils.HashPasswordWithParams(password, uc.passwordParams())
 	if err != nil {
 		return user, l.WrapErr(err)
 	}
 
-	return uc.repo.AddUser(ctx, email, hashedPassword)
+	var recoveryHash string
+	if keys.RecoveryAuth != "" {
+		recoveryHash = utils.HashRecoveryAuth(keys.RecoveryAuth)
+	}
+
+	return uc.repo.AddUser(ctx, email, hashedPassword, keys.DataKey, recoveryHash, keys.RecoveryKey)
 }
 
-// SignInUser authenticates a user with the provided email and password.
-// It first validates the email format using mail.ParseAddress from the net/mail package.
-// If the email format is incorrect, it returns ErrWrongEmail.
-// It then retrieves the user from the repository using GetUserByEmail method.
-// If an error occurs during user retrieval, it propagates the error.
-// Upon successful authentication, it generates JWT tokens (access token and refresh token) for the user.
-// The access token and refresh token are created using utils.CreateToken function with configured expiration times and private keys.
-// It sets the max age, domain, and other properties for the JWT tokens based on the configuration.
-// If any error occurs during token generation, it returns the error.
-// Finally, it returns the generated JWT tokens and a nil error if the operation is successful.
-func (uc *UseCase) SignInUser(ctx context.Context, email, password string) (token entity.JWT, err error) {
+// SignInUser authenticates the user with the email and auth hash and starts a session on the device.
+// A user with a second factor gets a TOTP challenge instead of the tokens, see SignInTOTP.
+func (uc *UseCase) SignInUser(
+	ctx context.Context,
+	email, password string,
+	device entity.Device,
+) (token entity.JWT, err error) {
 	if _, err = mail.ParseAddress(email); err != nil {
 		err = errs.ErrWrongEmail
 		return token, err
@@ -50,19 +55,79 @@ func (uc *UseCase) SignInUser(ctx context.Context, email, password string) (toke
 		return token, l.WrapErr(err)
 	}
 
-	token.AccessToken, err = utils.CreateToken(
-		uc.cfg.Security.AccessTokenExpiresIn,
-		user.ID,
-		uc.cfg.Security.AccessTokenPrivateKey)
+	// A hash of the bcrypt scheme or of outdated parameters is replaced while the password is at hand.
+	if err = uc.repo.RehashPassword(ctx, user.ID, password, uc.passwordParams()); err != nil {
+		uc.log.Error("error", l.ErrAttr(err))
+	}
+
+	return uc.signIn(ctx, user, device)
+}
+
+// UpgradeUser moves a user registered before the auth hash to it and signs them in.
+// The master password is sent to the server this one time to check the stored hash,
+// afterwards only the hash of the auth hash is kept.
+func (uc *UseCase) UpgradeUser(
+	ctx context.Context,
+	email, password, authHash string,
+	device entity.Device,
+) (token entity.JWT, err error) {
+	if _, err = mail.ParseAddress(email); err != nil {
+		err = errs.ErrWrongEmail
+		return token, err
+	}
+
+	hashedAuthHash, err := utils.HashPasswordWithParams(authHash, uc.passwordParams())
+	if err != nil {
+		return token, l.WrapErr(err)
+	}
+
+	user, err := uc.repo.UpgradeAuth(ctx, email, password, hashedAuthHash)
+	if err != nil {
+		return token, l.WrapErr(err)
+	}
+
+	return uc.signIn(ctx, user, device)
+}
+
+// passwordParams returns the Argon2id parameters of password hashes from the config,
+// the recommended ones for those not set.
+func (uc *UseCase) passwordParams() utils.PasswordParams {
+	params := utils.DefaultPasswordParams()
+	if uc.cfg.Security.PasswordTime > 0 {
+		params.Time = uc.cfg.Security.PasswordTime
+	}
+	if uc.cfg.Security.PasswordMemory > 0 {
+		params.Memory = uc.cfg.Security.PasswordMemory
+	}
+	if uc.cfg.Security.PasswordThreads > 0 {
+		params.Threads = uc.cfg.Security.PasswordThreads
+	}
+
+	return params
+}
+
+// issueTokens starts a session of the signed in user on the device and generates its access and refresh tokens.
+func (uc *UseCase) issueTokens(ctx context.Context, user entity.User, device entity.Device) (token entity.JWT, err error) {
+	refreshTokenID := uuid.New()
+	session, err := uc.repo.CreateSession(ctx, user.ID, refreshTokenID, device, uc.refreshTokenExpiry())
 	if err != nil {
 		return token, l.WrapErr(err)
 	}
 
-	token.RefreshToken, err = utils.CreateToken(
-		uc.cfg.Security.RefreshTokenExpiresIn,
-		user.ID,
-		uc.cfg.Security.RefreshTokenPrivateKey)
+	return uc.sessionTokens(session, refreshTokenID)
+}
 
+// sessionTokens generates the access token of the session and the refresh token with the given ID.
+// Both tokens carry the session ID, so they stop working once the session is revoked.
+func (uc *UseCase) sessionTokens(session entity.Session, refreshTokenID uuid.UUID) (token entity.JWT, err error) {
+	claims := utils.SessionClaims{UserID: session.UserID, SessionID: session.ID}
+	token.AccessToken, err = uc.accessKeys.CreateSessionToken(uc.cfg.Security.AccessTokenExpiresIn, claims)
+	if err != nil {
+		return token, l.WrapErr(err)
+	}
+
+	claims.TokenID = refreshTokenID
+	token.RefreshToken, err = uc.refreshKeys.CreateSessionToken(uc.cfg.Security.RefreshTokenExpiresIn, claims)
 	if err != nil {
 		return token, l.WrapErr(err)
 	}
@@ -74,65 +139,85 @@ func (uc *UseCase) SignInUser(ctx context.Context, email, password string) (toke
 	return
 }
 
-// CheckAccessToken verifies the validity of the provided access token.
-// If the token is valid, it retrieves and returns the associated user details.
-// It first checks a local cache for the user corresponding to the access token.
-// If not found in cache, it validates the token using a public key and retrieves
-// the user details from the repository using the extracted userID from the token's subject.
-// Upon successful validation, it caches the user details for future requests with the same token.
-func (uc *UseCase) CheckAccessToken(ctx context.Context, accessToken string) (user entity.User, err error) {
+// refreshTokenExpiry returns the expiry of a refresh token issued now.
+func (uc *UseCase) refreshTokenExpiry() time.Time {
+	return time.Now().UTC().Add(uc.cfg.Security.RefreshTokenExpiresIn)
+}
+
+// CheckAccessToken returns the user of a valid access token or personal token, carrying the ID of the session
+// or the scope of the token. Checked tokens are cached, a cached token of a revoked session or a deleted user
+// is rejected with ErrSessionRevoked. The device of the session is marked as seen from the given IP address.
+func (uc *UseCase) CheckAccessToken(ctx context.Context, accessToken, ip string) (user entity.User, err error) {
+	if utils.IsPersonalToken(accessToken) {
+		return uc.checkPersonalToken(ctx, accessToken)
+	}
+
 	if userFromCache, found := uc.cache.Get(accessToken); found {
-		checkedUser, ok := userFromCache.(entity.User)
+		cachedUser, ok := userFromCache.(entity.User)
 		if ok {
-			return checkedUser, nil
+			if uc.sessionRevoked(cachedUser.SessionID) || uc.userDeleted(cachedUser.ID) {
+				return user, errs.ErrSessionRevoked
+			}
+			return cachedUser, nil
 		}
 	}
 
-	sub, err := utils.ValidToken(accessToken, uc.cfg.Security.AccessTokenPublicKey)
+	claims, err := uc.accessKeys.ValidSessionToken(accessToken)
 	if err != nil {
 		err = errs.ErrTokenValidation
 		return user, err
 	}
 
-	userID := fmt.Sprint(sub)
-	user, err = uc.repo.GetUserByID(ctx, userID)
+	session, err := uc.repo.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		return user, l.WrapErr(err)
+	}
+	if session.UserID != claims.UserID || !session.Active(time.Now()) {
+		return user, errs.ErrSessionRevoked
+	}
+
+	user, err = uc.repo.GetUserByID(ctx, claims.UserID.String())
 	if err != nil {
 		err = errs.ErrTokenValidation
 		return user, err
 	}
 
+	if err = uc.repo.TouchSession(ctx, session.ID, ip); err != nil {
+		return user, l.WrapErr(err)
+	}
+
+	user.SessionID = session.ID
 	uc.cache.Set(accessToken, user)
 	return
 }
 
-// RefreshAccessToken validates the provided refresh token, retrieves the corresponding user,
-// and generates a new access token.
-func (uc *UseCase) RefreshAccessToken(ctx context.Context, refreshToken string) (token entity.JWT, err error) {
-
-	userID, err := utils.ValidToken(refreshToken, uc.cfg.Security.RefreshTokenPublicKey)
-	if err != nil {
+// RefreshAccessToken validates the provided refresh token and rotates it: the session gets
+// a new refresh token and the given one stops working. A refresh token that has already been
+// rotated revokes the whole session, it has been used by someone else, and ErrTokenReused is returned.
+// The device of the session is marked as seen from the given IP address.
+func (uc *UseCase) RefreshAccessToken(ctx context.Context, refreshToken, ip string) (token entity.JWT, err error) {
+	claims, err := uc.refreshKeys.ValidSessionToken(refreshToken)
+	if err != nil || claims.TokenID == uuid.Nil {
 		err = errs.ErrTokenValidation
 		return token, err
 	}
 
-	user, err := uc.repo.GetUserByID(ctx, fmt.Sprint(userID))
-	if err != nil {
-		err = errs.ErrTokenValidation
-		return token, err
+	newRefreshTokenID := uuid.New()
+	session, err := uc.repo.RotateSession(
+		ctx,
+		claims.SessionID,
+		claims.TokenID,
+		newRefreshTokenID,
+		ip,
+		uc.refreshTokenExpiry())
+	if errors.Is(err, errs.ErrTokenReused) {
+		uc.log.Warn("refresh token reused, session revoked", "session", claims.SessionID.String())
+		uc.markRevoked(claims.SessionID)
+		uc.events.publish(claims.UserID, entity.EventSessionRevoked, entity.SessionEvent{ID: claims.SessionID})
 	}
-
-	token.RefreshToken = refreshToken
-	token.AccessToken, err = utils.CreateToken(
-		uc.cfg.Security.AccessTokenExpiresIn,
-		user.ID,
-		uc.cfg.Security.AccessTokenPrivateKey)
-
 	if err != nil {
 		return token, l.WrapErr(err)
 	}
 
-	token.AccessTokenMaxAge = uc.cfg.Security.AccessTokenMaxAge * minutesPerHour
-	token.RefreshTokenMaxAge = uc.cfg.Security.RefreshTokenMaxAge * minutesPerHour
-	token.Domain = uc.cfg.Security.Domain
-	return
+	return uc.sessionTokens(session, newRefreshTokenID)
 }
@@ -14,8 +14,10 @@ import (
 )
 
 // GetBinaries retrieves all binaries associated with the given user.
+// With a personal token only the items in its scope are returned.
 func (uc *UseCase) GetBinaries(ctx context.Context, user entity.User) ([]entity.Binary, error) {
-	return uc.repo.GetBinaries(ctx, user)
+	binaries, err := uc.repo.GetBinaries(ctx, user)
+	return inScope(user, binaries, func(binary entity.Binary) uuid.UUID { return binary.ID }), err
 }
 
 // AddBinary adds a new binary file to the storage and database.
@@ -26,7 +28,7 @@ func (uc *UseCase) AddBinary(
 	userID uuid.UUID,
 ) error {
 
-	userDirectory := uc.cfg.FilesStorage.Location + "/" + userID.String()
+	userDirectory := uc.userDirectory(userID)
 	if err := uc.repo.AddBinary(ctx, binary, userID); err != nil {
 		return l.WrapErr(err)
 	}
@@ -34,6 +36,7 @@ func (uc *UseCase) AddBinary(
 		uc.log.Debug("error", l.ErrAttr(err))
 		return l.WrapErr(err)
 	}
+	uc.itemChanged(userID, entity.ItemBinary, binary.ID, binary.Revision)
 	return nil
 }
 
@@ -52,7 +55,7 @@ func (uc *UseCase) GetUserBinary(
 		"%s/%s/%s",
 		uc.cfg.FilesStorage.Location,
 		currentUser.ID.String(),
-		binary.ID), nil
+		storedName(binary)), nil
 }
 
 // DelUserBinary deletes a binary file from the storage and database.
@@ -62,16 +65,21 @@ func (uc *UseCase) DelUserBinary(
 	binaryUUID uuid.UUID,
 ) error {
 
-	err := uc.repo.DelUserBinary(ctx, currentUser, binaryUUID)
+	binary, err := uc.repo.GetBinary(ctx, binaryUUID, currentUser.ID)
 	if err != nil {
 		return l.WrapErr(err)
 	}
 
+	if err = uc.repo.DelUserBinary(ctx, currentUser, binaryUUID); err != nil {
+		return l.WrapErr(err)
+	}
+	uc.itemDeleted(currentUser.ID, entity.ItemBinary, binaryUUID)
+
 	filePath := fmt.Sprintf(
 		"%s/%s/%s",
 		uc.cfg.FilesStorage.Location,
 		currentUser.ID.String(),
-		binaryUUID.String())
+		storedName(binary))
 
 	return os.Remove(filePath)
 }
@@ -83,10 +91,25 @@ func (uc *UseCase) AddBinaryMeta(
 	binaryUUID uuid.UUID,
 	meta []entity.Meta,
 ) (*entity.Binary, error) {
-	return uc.repo.AddBinaryMeta(
+	binary, err := uc.repo.AddBinaryMeta(
 		ctx,
 		currentUser,
 		binaryUUID,
 		meta,
 	)
+	if err != nil {
+		return nil, err
+	}
+	uc.itemChanged(currentUser.ID, entity.ItemBinary, binary.ID, binary.Revision)
+
+	return binary, nil
+}
+
+// storedName returns the name of the binary file in the user directory.
+// Files uploaded before any password change are stored under the binary ID.
+func storedName(binary *entity.Binary) string {
+	if binary.StoredName != "" {
+		return binary.StoredName
+	}
+	return binary.ID.String()
 }
@@ -9,21 +9,38 @@ import (
 )
 
 // GetCards retrieves all cards for a given user.
+// With a personal token only the items in its scope are returned.
 func (uc *UseCase) GetCards(ctx context.Context, user entity.User) ([]entity.Card, error) {
-	return uc.repo.GetCards(ctx, user)
+	cards, err := uc.repo.GetCards(ctx, user)
+	return inScope(user, cards, func(card entity.Card) uuid.UUID { return card.ID }), err
 }
 
 // AddCard adds a new card for a specific user.
 func (uc *UseCase) AddCard(ctx context.Context, card *entity.Card, userID uuid.UUID) error {
-	return uc.repo.AddCard(ctx, card, userID)
+	if err := uc.repo.AddCard(ctx, card, userID); err != nil {
+		return err
+	}
+	uc.itemChanged(userID, entity.ItemCards, card.ID, card.Revision)
+
+	return nil
 }
 
 // DelCard deletes a card for a specific user based on card UUID.
 func (uc *UseCase) DelCard(ctx context.Context, cardUUID, userID uuid.UUID) error {
-	return uc.repo.DelCard(ctx, cardUUID, userID)
+	if err := uc.repo.DelCard(ctx, cardUUID, userID); err != nil {
+		return err
+	}
+	uc.itemDeleted(userID, entity.ItemCards, cardUUID)
+
+	return nil
 }
 
 // UpdateCard updates an existing card for a specific user.
 func (uc *UseCase) UpdateCard(ctx context.Context, card *entity.Card, userID uuid.UUID) error {
-	return uc.repo.UpdateCard(ctx, card, userID)
+	if err := uc.repo.UpdateCard(ctx, card, userID); err != nil {
+		return err
+	}
+	uc.itemChanged(userID, entity.ItemCards, card.ID, card.Revision)
+
+	return nil
 }
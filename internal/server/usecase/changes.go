@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// GetChanges returns the items of the user created, updated or deleted after the since revision.
+// A zero or unknown revision returns the whole vault, marked as full.
+func (uc *UseCase) GetChanges(ctx context.Context, currentUser entity.User, since int64) (entity.Changes, error) {
+	return uc.repo.GetChanges(ctx, currentUser.ID, since)
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+const (
+	eventHistory = 1000 // Latest events of all users kept for resuming streams.
+	eventBuffer  = 32   // Events waiting for a slow subscriber before it is dropped.
+)
+
+// eventHub fans the events of a user out to every connected device and keeps the latest events,
+// so a device that has reconnected gets the ones it has missed.
+type eventHub struct {
+	mu          sync.Mutex
+	lastID      int64                                        // ID of the latest event.
+	horizon     int64                                        // Events after this ID are all in the history.
+	history     []entity.Event                               // Latest events, oldest first.
+	historyUser []uuid.UUID                                  // Owners of the events in the history.
+	subscribers map[uuid.UUID]map[chan entity.Event]struct{} // Channels of the connected devices by user.
+	closed      bool
+}
+
+// newEventHub creates a hub. Event IDs start from the current time, so the IDs of a restarted
+// server follow the ones streamed before and the devices resuming with them are asked to resync.
+func newEventHub() *eventHub {
+	start := time.Now().UnixMicro()
+	return &eventHub{
+		lastID:      start,
+		horizon:     start,
+		subscribers: make(map[uuid.UUID]map[chan entity.Event]struct{}),
+	}
+}
+
+// subscribe connects a device of the user until the context is done. The events after lastEventID
+// are returned first, with a single EventResync instead when some of them are no longer kept;
+// zero lastEventID starts with the new events. The channel is closed once the device is
+// disconnected, for falling behind as well, so it resumes with a new subscription.
+func (h *eventHub) subscribe(ctx context.Context, userID uuid.UUID, lastEventID int64) ([]entity.Event, <-chan entity.Event) {
+	events := make(chan entity.Event, eventBuffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(events)
+		return nil, events
+	}
+
+	var missed []entity.Event
+	switch {
+	case lastEventID == 0:
+	case lastEventID < h.horizon || lastEventID > h.lastID:
+		missed = []entity.Event{{ID: h.lastID, Type: entity.EventResync}}
+	default:
+		for index := range h.history {
+			if h.historyUser[index] == userID && h.history[index].ID > lastEventID {
+				missed = append(missed, h.history[index])
+			}
+		}
+	}
+
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan entity.Event]struct{})
+	}
+	h.subscribers[userID][events] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.drop(userID, events)
+	}()
+
+	return missed, events
+}
+
+// publish sends the event to every connected device of the user and keeps it in the history.
+func (h *eventHub) publish(userID uuid.UUID, eventType string, data any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	event := entity.Event{ID: h.lastID, Type: eventType, Data: data}
+
+	if len(h.history) == eventHistory {
+		h.horizon = h.history[0].ID
+		h.history, h.historyUser = h.history[1:], h.historyUser[1:]
+	}
+	h.history = append(h.history, event)
+	h.historyUser = append(h.historyUser, userID)
+
+	for events := range h.subscribers[userID] {
+		select {
+		case events <- event:
+		default:
+			h.drop(userID, events)
+		}
+	}
+}
+
+// disconnect drops every connected device of the user.
+func (h *eventHub) disconnect(userID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for events := range h.subscribers[userID] {
+		h.drop(userID, events)
+	}
+}
+
+// close drops every connected device, new subscriptions end at once.
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for userID, subscribers := range h.subscribers {
+		for events := range subscribers {
+			h.drop(userID, events)
+		}
+	}
+}
+
+// drop closes the channel of the subscriber unless it has been dropped before. The caller holds the lock.
+func (h *eventHub) drop(userID uuid.UUID, events chan entity.Event) {
+	subscribers, ok := h.subscribers[userID]
+	if !ok {
+		return
+	}
+	if _, ok = subscribers[events]; !ok {
+		return
+	}
+
+	delete(subscribers, events)
+	if len(subscribers) == 0 {
+		delete(h.subscribers, userID)
+	}
+	close(events)
+}
+
+// SubscribeEvents streams the events of the user to a device until the context is done, see eventHub.subscribe.
+func (uc *UseCase) SubscribeEvents(ctx context.Context, userID uuid.UUID, lastEventID int64) ([]entity.Event, <-chan entity.Event) {
+	return uc.events.subscribe(ctx, userID, lastEventID)
+}
+
+// CloseEvents ends every event stream, so the server does not wait for them on shutdown.
+func (uc *UseCase) CloseEvents() {
+	uc.events.close()
+}
+
+// itemChanged notifies the devices of the user that the item has been created or updated.
+func (uc *UseCase) itemChanged(userID uuid.UUID, itemType string, itemID uuid.UUID, revision int64) {
+	uc.events.publish(userID, entity.EventItemChanged, entity.ItemEvent{ID: itemID, Type: itemType, Revision: revision})
+}
+
+// itemDeleted notifies the devices of the user that the item has been deleted.
+func (uc *UseCase) itemDeleted(userID uuid.UUID, itemType string, itemID uuid.UUID) {
+	uc.events.publish(userID, entity.EventItemDeleted, entity.ItemEvent{ID: itemID, Type: itemType})
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// ReserveIdempotencyKey reserves the idempotency key of the user for a request about to run,
+// or returns the response to the request with the same fingerprint the user has already sent with it.
+func (uc *UseCase) ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key, fingerprint string) (entity.IdempotentResponse, error) {
+	return uc.repo.ReserveIdempotencyKey(ctx, userID, key, fingerprint)
+}
+
+// SaveIdempotentResponse stores the response to the request of the user with the idempotency key.
+func (uc *UseCase) SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, response entity.IdempotentResponse) error {
+	return uc.repo.SaveIdempotentResponse(ctx, userID, key, response)
+}
+
+// ReleaseIdempotencyKey drops the reservation of the idempotency key, so the request can be retried.
+func (uc *UseCase) ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error {
+	return uc.repo.ReleaseIdempotencyKey(ctx, userID, key)
+}
@@ -10,20 +10,37 @@ import (
 
 // AddLogin adds a new login entry for a specific user.
 func (uc *UseCase) AddLogin(ctx context.Context, login *entity.Login, userID uuid.UUID) error {
-	return uc.repo.AddLogin(ctx, login, userID)
+	if err := uc.repo.AddLogin(ctx, login, userID); err != nil {
+		return err
+	}
+	uc.itemChanged(userID, entity.ItemLogins, login.ID, login.Revision)
+
+	return nil
 }
 
 // GetLogins retrieves all login entries for a given user.
+// With a personal token only the items in its scope are returned.
 func (uc *UseCase) GetLogins(ctx context.Context, user entity.User) ([]entity.Login, error) {
-	return uc.repo.GetLogins(ctx, user)
+	logins, err := uc.repo.GetLogins(ctx, user)
+	return inScope(user, logins, func(login entity.Login) uuid.UUID { return login.ID }), err
 }
 
 // DelLogin deletes a login entry for a specific user based on login ID.
 func (uc *UseCase) DelLogin(ctx context.Context, loginID, userID uuid.UUID) error {
-	return uc.repo.DelLogin(ctx, loginID, userID)
+	if err := uc.repo.DelLogin(ctx, loginID, userID); err != nil {
+		return err
+	}
+	uc.itemDeleted(userID, entity.ItemLogins, loginID)
+
+	return nil
 }
 
 // UpdateLogin updates an existing login entry for a specific user.
 func (uc *UseCase) UpdateLogin(ctx context.Context, login *entity.Login, userID uuid.UUID) error {
-	return uc.repo.UpdateLogin(ctx, login, userID)
+	if err := uc.repo.UpdateLogin(ctx, login, userID); err != nil {
+		return err
+	}
+	uc.itemChanged(userID, entity.ItemLogins, login.ID, login.Revision)
+
+	return nil
 }
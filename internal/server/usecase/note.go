@@ -9,21 +9,38 @@ import (
 )
 
 // GetNotes retrieves all secret notes for a specific user.
+// With a personal token only the items in its scope are returned.
 func (uc *UseCase) GetNotes(ctx context.Context, user entity.User) ([]entity.SecretNote, error) {
-	return uc.repo.GetNotes(ctx, user)
+	notes, err := uc.repo.GetNotes(ctx, user)
+	return inScope(user, notes, func(note entity.SecretNote) uuid.UUID { return note.ID }), err
 }
 
 // AddNote adds a new secret note for a specific user.
 func (uc *UseCase) AddNote(ctx context.Context, note *entity.SecretNote, userID uuid.UUID) error {
-	return uc.repo.AddNote(ctx, note, userID)
+	if err := uc.repo.AddNote(ctx, note, userID); err != nil {
+		return err
+	}
+	uc.itemChanged(userID, entity.ItemNotes, note.ID, note.Revision)
+
+	return nil
 }
 
 // DelNote deletes a secret note for a specific user based on note ID.
 func (uc *UseCase) DelNote(ctx context.Context, noteID, userID uuid.UUID) error {
-	return uc.repo.DelNote(ctx, noteID, userID)
+	if err := uc.repo.DelNote(ctx, noteID, userID); err != nil {
+		return err
+	}
+	uc.itemDeleted(userID, entity.ItemNotes, noteID)
+
+	return nil
 }
 
 // UpdateNote updates an existing secret note for a specific user.
 func (uc *UseCase) UpdateNote(ctx context.Context, note *entity.SecretNote, userID uuid.UUID) error {
-	return uc.repo.UpdateNote(ctx, note, userID)
+	if err := uc.repo.UpdateNote(ctx, note, userID); err != nil {
+		return err
+	}
+	uc.itemChanged(userID, entity.ItemNotes, note.ID, note.Revision)
+
+	return nil
 }
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// rekeySuffix marks a re-encrypted binary file waiting for the data key rotation.
+const rekeySuffix = ".rekey"
+
+// StageRekey stores the re-encrypted vault until RotateDataKey commits it.
+func (uc *UseCase) StageRekey(ctx context.Context, rekey *entity.Rekey, userID uuid.UUID) error {
+	return uc.repo.StageRekey(ctx, rekey, userID)
+}
+
+// StageRekeyBinary stores the re-encrypted file of the binary next to the current one.
+func (uc *UseCase) StageRekeyBinary(
+	ctx context.Context,
+	currentUser *entity.User,
+	binaryUUID uuid.UUID,
+	file *multipart.FileHeader,
+) error {
+	binary, err := uc.repo.GetBinary(ctx, binaryUUID, currentUser.ID)
+	if err != nil {
+		return l.WrapErr(err)
+	}
+
+	return utils.SaveUploadedFile(file, binary.ID.String()+rekeySuffix, uc.userDirectory(currentUser.ID))
+}
+
+// GetDataKey retrieves the wrapped data key of the user, empty if the vault has none yet.
+func (uc *UseCase) GetDataKey(ctx context.Context, currentUser *entity.User) (string, error) {
+	return uc.repo.GetDataKey(ctx, currentUser.ID)
+}
+
+// ChangePassword changes the password together with the data key wrapped by it.
+// The vault items stay untouched, they are sealed with the data key.
+func (uc *UseCase) ChangePassword(
+	ctx context.Context,
+	currentUser *entity.User,
+	oldPassword, newPassword, dataKey string,
+) error {
+	hashedPassword, err := utils.HashPasswordWithParams(newPassword, uc.passwordParams())
+	if err != nil {
+		return l.WrapErr(err)
+	}
+
+	return uc.repo.ChangePassword(ctx, currentUser.ID, oldPassword, hashedPassword, dataKey)
+}
+
+// RotateDataKey switches the vault to the staged copy re-encrypted with a new data key.
+// The items and the wrapped key change in one transaction, re-encrypted files get new names
+// beforehand, so the vault is never partly re-encrypted. Old files are removed after the commit.
+func (uc *UseCase) RotateDataKey(ctx context.Context, currentUser *entity.User, password, dataKey string) error {
+	if _, err := uc.repo.GetUserByEmail(ctx, currentUser.Email, password); err != nil {
+		return err
+	}
+
+	binaries, err := uc.repo.GetBinaries(ctx, *currentUser)
+	if err != nil {
+		return l.WrapErr(err)
+	}
+
+	userDirectory := uc.userDirectory(currentUser.ID)
+	storedNames := make(map[uuid.UUID]string, len(binaries))
+	for index := range binaries {
+		stagedPath := filepath.Join(userDirectory, binaries[index].ID.String()+rekeySuffix)
+		newName := uuid.NewString()
+		if err = os.Rename(stagedPath, filepath.Join(userDirectory, newName)); err != nil {
+			uc.unstageBinaries(userDirectory, storedNames)
+			if errors.Is(err, os.ErrNotExist) {
+				return errs.ErrRekeyIncomplete
+			}
+			return l.WrapErr(err)
+		}
+		storedNames[binaries[index].ID] = newName
+	}
+
+	if err = uc.repo.RotateDataKey(ctx, currentUser.ID, password, dataKey, storedNames); err != nil {
+		uc.unstageBinaries(userDirectory, storedNames)
+		return err
+	}
+	// Every item has been re-encrypted, the devices fetch the whole vault again.
+	uc.events.publish(currentUser.ID, entity.EventResync, nil)
+
+	for index := range binaries {
+		if err = os.Remove(filepath.Join(userDirectory, storedName(&binaries[index]))); err != nil {
+			uc.log.Error("error", l.ErrAttr(err))
+		}
+	}
+
+	return nil
+}
+
+// unstageBinaries moves the re-encrypted files back to their staged names after a failed rotation.
+func (uc *UseCase) unstageBinaries(userDirectory string, storedNames map[uuid.UUID]string) {
+	for binaryID, name := range storedNames {
+		err := os.Rename(filepath.Join(userDirectory, name), filepath.Join(userDirectory, binaryID.String()+rekeySuffix))
+		if err != nil {
+			uc.log.Error("error", l.ErrAttr(err))
+		}
+	}
+}
+
+// userDirectory returns the directory with the binary files of the user.
+func (uc *UseCase) userDirectory(userID uuid.UUID) string {
+	return uc.cfg.FilesStorage.Location + "/" + userID.String()
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// revokedTokenPrefix prefixes the cache keys marking revoked personal tokens.
+const revokedTokenPrefix = "revoked-token:"
+
+// personalTokenOwner is the cached result of checking a personal token.
+type personalTokenOwner struct {
+	user      entity.User // Owner of the token with its scope.
+	tokenID   uuid.UUID
+	expiresAt time.Time
+}
+
+// CreatePersonalToken creates a personal token of the user with the name, scope and expiry of the given one.
+// The returned token holds its secret, which is not stored and cannot be read again.
+func (uc *UseCase) CreatePersonalToken(
+	ctx context.Context,
+	currentUser entity.User,
+	token entity.PersonalToken,
+) (entity.PersonalToken, error) {
+	secret, err := utils.GeneratePersonalToken()
+	if err != nil {
+		return entity.PersonalToken{}, l.WrapErr(err)
+	}
+
+	token.UserID = currentUser.ID
+	created, err := uc.repo.CreatePersonalToken(ctx, token, utils.HashPersonalToken(secret))
+	if err != nil {
+		return entity.PersonalToken{}, l.WrapErr(err)
+	}
+	created.Token = secret
+
+	return created, nil
+}
+
+// GetPersonalTokens returns the unexpired personal tokens of the user without their secrets.
+func (uc *UseCase) GetPersonalTokens(ctx context.Context, currentUser entity.User) ([]entity.PersonalToken, error) {
+	return uc.repo.GetPersonalTokens(ctx, currentUser.ID)
+}
+
+// RevokePersonalToken deletes the personal token of the user, it stops working at once.
+// Returns ErrWrongOwnerOrNotFound if the user has no such token.
+func (uc *UseCase) RevokePersonalToken(ctx context.Context, currentUser entity.User, tokenID uuid.UUID) error {
+	if err := uc.repo.DelPersonalToken(ctx, tokenID, currentUser.ID); err != nil {
+		return err
+	}
+	uc.cache.Set(revokedTokenPrefix+tokenID.String(), true)
+
+	return nil
+}
+
+// checkPersonalToken returns the owner of the personal token with the scope of the token.
+// The result is cached like the one of an access token; a revoked token or a token of a deleted user
+// is rejected at once, an expired one when it expires.
+// Returns ErrTokenValidation if the token is unknown or has expired.
+func (uc *UseCase) checkPersonalToken(ctx context.Context, token string) (entity.User, error) {
+	if cached, found := uc.cache.Get(token); found {
+		owner, ok := cached.(personalTokenOwner)
+		if ok {
+			_, revoked := uc.cache.Get(revokedTokenPrefix + owner.tokenID.String())
+			if revoked || uc.userDeleted(owner.user.ID) || !time.Now().Before(owner.expiresAt) {
+				return entity.User{}, errs.ErrTokenValidation
+			}
+			return owner.user, nil
+		}
+	}
+
+	personalToken, err := uc.repo.GetPersonalToken(ctx, utils.HashPersonalToken(token))
+	if err != nil {
+		if errors.Is(err, errs.ErrTokenValidation) {
+			return entity.User{}, err
+		}
+		return entity.User{}, l.WrapErr(err)
+	}
+
+	user, err := uc.repo.GetUserByID(ctx, personalToken.UserID.String())
+	if err != nil {
+		return entity.User{}, errs.ErrTokenValidation
+	}
+
+	if err = uc.repo.TouchPersonalToken(ctx, personalToken.ID); err != nil {
+		return entity.User{}, l.WrapErr(err)
+	}
+
+	user.Scope = &personalToken.Scope
+	uc.cache.Set(token, personalTokenOwner{user: user, tokenID: personalToken.ID, expiresAt: personalToken.ExpiresAt})
+	return user, nil
+}
+
+// inScope keeps the items the user may access with the personal token of the request,
+// all of them for a session.
+func inScope[T any](user entity.User, items []T, itemID func(T) uuid.UUID) []T {
+	if user.Scope == nil {
+		return items
+	}
+
+	return slices.DeleteFunc(items, func(item T) bool {
+		return !user.Scope.AllowsItem(itemID(item))
+	})
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// RunPurge purges the expired tombstones and idempotency keys at once and then every purge interval
+// until the context is done. A zero interval disables the job.
+func (uc *UseCase) RunPurge(ctx context.Context) {
+	if uc.cfg.Sync == nil || uc.cfg.Sync.PurgeInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(uc.cfg.Sync.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		uc.purge(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge deletes the tombstones and the idempotency keys that have outlived their retention windows.
+func (uc *UseCase) purge(ctx context.Context, now time.Time) {
+	if retention := uc.cfg.Sync.TombstoneRetention; retention > 0 {
+		purged, err := uc.repo.PurgeTombstones(ctx, now.Add(-retention))
+		if err != nil {
+			uc.log.Error("error", l.ErrAttr(err))
+		} else if purged > 0 {
+			uc.log.Info("tombstones purged", "count", purged)
+		}
+	}
+
+	if retention := uc.cfg.Sync.IdempotencyRetention; retention > 0 {
+		purged, err := uc.repo.PurgeIdempotencyKeys(ctx, now.Add(-retention))
+		if err != nil {
+			uc.log.Error("error", l.ErrAttr(err))
+		} else if purged > 0 {
+			uc.log.Info("idempotency keys purged", "count", purged)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"net/mail"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// SetRecoveryKey sets a new recovery key of the user checked with the password:
+// the data key wrapped by it and the secret it is checked with. The previous recovery key stops working.
+func (uc *UseCase) SetRecoveryKey(ctx context.Context, currentUser entity.User, recoveryKey entity.RecoveryKey) error {
+	if _, err := uc.repo.GetUserByEmail(ctx, currentUser.Email, recoveryKey.Password); err != nil {
+		return l.WrapErr(err)
+	}
+
+	return uc.repo.SetRecoveryKey(ctx, currentUser.ID, utils.HashRecoveryAuth(recoveryKey.RecoveryAuth), recoveryKey.RecoveryKey)
+}
+
+// GetRecoveryKey returns the data key wrapped by the recovery key of the user,
+// the first step of setting a new password with it.
+// Returns ErrWrongRecoveryKey if the secret does not match or the user has no recovery key.
+func (uc *UseCase) GetRecoveryKey(ctx context.Context, email, recoveryAuth string) (string, error) {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return "", errs.ErrWrongEmail
+	}
+
+	return uc.repo.GetRecoveryKey(ctx, email, utils.HashRecoveryAuth(recoveryAuth))
+}
+
+// RecoverAccount sets the new password of the user checked with the recovery key,
+// together with the data key wrapped by the new password. The user is signed out on every device.
+// Returns ErrWrongRecoveryKey if the secret does not match or the user has no recovery key.
+func (uc *UseCase) RecoverAccount(ctx context.Context, recovery entity.Recovery) error {
+	if _, err := mail.ParseAddress(recovery.Email); err != nil {
+		return errs.ErrWrongEmail
+	}
+
+	hashedPassword, err := utils.HashPasswordWithParams(recovery.Password, uc.passwordParams())
+	if err != nil {
+		return l.WrapErr(err)
+	}
+
+	userID, err := uc.repo.RecoverAccount(
+		ctx,
+		recovery.Email,
+		utils.HashRecoveryAuth(recovery.RecoveryAuth),
+		hashedPassword,
+		recovery.DataKey,
+	)
+	if err != nil {
+		return l.WrapErr(err)
+	}
+
+	sessions, err := uc.repo.GetSessions(ctx, userID)
+	if err != nil {
+		return l.WrapErr(err)
+	}
+	for index := range sessions {
+		if err = uc.revokeSession(ctx, sessions[index].ID, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"github.com/nextlag/keeper/internal/entity"
 	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
@@ -32,36 +33,52 @@ func (r *Repo) GetBinaries(ctx context.Context, user entity.User) (binaries []en
 	binaries = make([]entity.Binary, len(binariesFromDB))
 
 	for index := range binariesFromDB {
-		binaries[index].ID = binariesFromDB[index].ID
-		binaries[index].Name = binariesFromDB[index].Name
-		binaries[index].FileName = binariesFromDB[index].FileName
-		for metaIndex := range binariesFromDB[index].Meta {
-			binaries[index].Meta = append(binaries[index].Meta, entity.Meta{
-				ID:    binariesFromDB[index].Meta[metaIndex].ID,
-				Name:  binariesFromDB[index].Meta[metaIndex].Name,
-				Value: binariesFromDB[index].Meta[metaIndex].Value,
-			})
-		}
+		binaries[index] = binaryFromDB(&binariesFromDB[index])
 	}
 
 	return
 }
 
+// binaryFromDB converts the stored binary and its metadata to the entity.
+func binaryFromDB(binaryFromDB *models.Binary) entity.Binary {
+	binary := entity.Binary{
+		ID:         binaryFromDB.ID,
+		Name:       binaryFromDB.Name,
+		FileName:   binaryFromDB.FileName,
+		StoredName: binaryFromDB.StoredName,
+		Revision:   binaryFromDB.Revision,
+	}
+	for metaIndex := range binaryFromDB.Meta {
+		binary.Meta = append(binary.Meta, entity.Meta{
+			ID:    binaryFromDB.Meta[metaIndex].ID,
+			Name:  binaryFromDB.Meta[metaIndex].Name,
+			Value: binaryFromDB.Meta[metaIndex].Value,
+		})
+	}
+
+	return binary
+}
+
 // AddBinary inserts a new binary record into the database.
+// Keeps the ID chosen by the client, a nil ID gets a new one.
 // Sets the ID of the binary after successful insertion.
 func (r *Repo) AddBinary(ctx context.Context, binary *entity.Binary, userID uuid.UUID) error {
 	newBinaryToDB := models.Binary{
+		ID:       binary.ID,
 		Name:     binary.Name,
 		FileName: binary.FileName,
 		UserID:   userID,
 	}
 
-	if err := r.db.WithContext(ctx).Create(&newBinaryToDB).Error; err != nil {
-		return l.WrapErr(err)
-	}
-	binary.ID = newBinaryToDB.ID
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		if err = tx.Create(&newBinaryToDB).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		binary.ID = newBinaryToDB.ID
 
-	return nil
+		binary.Revision, err = stampRevision(tx, &models.Binary{}, newBinaryToDB.ID, userID)
+		return err
+	})
 }
 
 // GetBinary retrieves a single binary by its ID and ensures it belongs to the specified user.
@@ -91,9 +108,10 @@ func (r *Repo) GetBinary(ctx context.Context, binaryID, userID uuid.UUID) (binar
 	}
 
 	return &entity.Binary{
-		ID:       binaryFromDB.ID,
-		FileName: binaryFromDB.FileName,
-		Meta:     meta,
+		ID:         binaryFromDB.ID,
+		FileName:   binaryFromDB.FileName,
+		StoredName: binaryFromDB.StoredName,
+		Meta:       meta,
 	}, nil
 }
 
@@ -106,10 +124,16 @@ func (r *Repo) DelUserBinary(ctx context.Context, currentUser *entity.User, bina
 		err = errWrongBinaryOwner
 		return l.WrapErr(err)
 	}
-	return l.WrapErr(r.db.Delete(&binaryFromDB).Error)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := stampRevision(tx, &models.Binary{}, binaryFromDB.ID, currentUser.ID); err != nil {
+			return err
+		}
+
+		return l.WrapErr(tx.Delete(&binaryFromDB).Error)
+	})
 }
 
-// AddBinaryMeta adds metadata to a binary record.
+// AddBinaryMeta adds metadata to a binary record owned by the current user, see saveMeta.
 // Retrieves the updated binary after saving the metadata.
 func (r *Repo) AddBinaryMeta(
 	ctx context.Context,
@@ -117,15 +141,32 @@ func (r *Repo) AddBinaryMeta(
 	binaryUUID uuid.UUID,
 	meta []entity.Meta,
 ) (*entity.Binary, error) {
-	metaForDB := make([]models.MetaBinary, len(meta))
-	for index := range meta {
-		metaForDB[index].BinaryID = binaryUUID
-		metaForDB[index].Name = meta[index].Name
-		metaForDB[index].Value = meta[index].Value
+	var binaryFromDB models.Binary
+	if err := r.db.WithContext(ctx).Find(&binaryFromDB, binaryUUID).Error; err != nil {
+		return nil, l.WrapErr(err)
+	}
+	if binaryFromDB.UserID != currentUser.ID {
+		return nil, l.WrapErr(errWrongBinaryOwner)
 	}
 
-	if err := r.db.WithContext(ctx).Save(&metaForDB).Error; err != nil {
-		return nil, l.WrapErr(err)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for index := range meta {
+			metaForDB := models.MetaBinary{
+				ID:       meta[index].ID,
+				Name:     meta[index].Name,
+				Value:    meta[index].Value,
+				BinaryID: binaryUUID,
+			}
+			if err := saveMeta(tx, &metaForDB, "binary_id", binaryUUID, meta[index]); err != nil {
+				return err
+			}
+		}
+
+		_, err := stampRevision(tx, &models.Binary{}, binaryUUID, currentUser.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return r.GetBinary(ctx, binaryUUID, currentUser.ID)
@@ -33,26 +33,44 @@ func (r *Repo) GetCards(ctx context.Context, user entity.User) (cards []entity.C
 	cards = make([]entity.Card, len(cardsFromDB))
 
 	for index := range cardsFromDB {
-		cards[index].ID = cardsFromDB[index].ID
-		cards[index].Brand = cardsFromDB[index].Brand
-		cards[index].CardHolderName = cardsFromDB[index].CardHolderName
-		cards[index].ExpirationMonth = cardsFromDB[index].ExpirationMonth
-		cards[index].ExpirationYear = cardsFromDB[index].ExpirationYear
-		cards[index].Name = cardsFromDB[index].Name
-		cards[index].Number = cardsFromDB[index].Number
-		cards[index].SecurityCode = cardsFromDB[index].SecurityCode
+		cards[index] = cardFromDB(&cardsFromDB[index])
 	}
 	return
 }
 
+// cardFromDB converts the stored card and its metadata to the entity.
+func cardFromDB(cardFromDB *models.Card) entity.Card {
+	card := entity.Card{
+		ID:              cardFromDB.ID,
+		Brand:           cardFromDB.Brand,
+		CardHolderName:  cardFromDB.CardHolderName,
+		ExpirationMonth: cardFromDB.ExpirationMonth,
+		ExpirationYear:  cardFromDB.ExpirationYear,
+		Name:            cardFromDB.Name,
+		Number:          cardFromDB.Number,
+		SecurityCode:    cardFromDB.SecurityCode,
+		Revision:        cardFromDB.Revision,
+	}
+	for metaIndex := range cardFromDB.Meta {
+		card.Meta = append(card.Meta, entity.Meta{
+			ID:    cardFromDB.Meta[metaIndex].ID,
+			Name:  cardFromDB.Meta[metaIndex].Name,
+			Value: cardFromDB.Meta[metaIndex].Value,
+		})
+	}
+
+	return card
+}
+
 // AddCard adds a new card to the database for the specified user.
 // It creates a new card entry and associated meta information within a database transaction.
-// If the card is successfully added, it updates the provided card entity with the new card ID.
+// The IDs chosen by the client are kept, the ciphertexts are bound to them; a nil ID gets a new one.
+// If the card is successfully added, it updates the provided card entity with the card ID.
 // Returns an error if any occurred during the operation.
 func (r *Repo) AddCard(ctx context.Context, card *entity.Card, userID uuid.UUID) (err error) {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		cardToDB := models.Card{
-			ID:              uuid.New(),
+			ID:              card.ID,
 			UserID:          userID,
 			Name:            card.Name,
 			Brand:           card.Brand,
@@ -69,16 +87,19 @@ func (r *Repo) AddCard(ctx context.Context, card *entity.Card, userID uuid.UUID)
 		card.ID = cardToDB.ID
 		for index, meta := range card.Meta {
 			metaForCard := models.MetaCard{
+				ID:     meta.ID,
 				Name:   meta.Name,
 				Value:  meta.Value,
 				CardID: cardToDB.ID,
 			}
-			if err = tx.WithContext(ctx).Create(&metaForCard).Error; err != nil {
-				return l.WrapErr(err)
+			if err = saveMeta(tx.WithContext(ctx), &metaForCard, "card_id", cardToDB.ID, meta); err != nil {
+				return err
 			}
 			card.Meta[index].ID = metaForCard.ID
 		}
-		return nil
+
+		card.Revision, err = stampRevision(tx, &models.Card{}, cardToDB.ID, userID)
+		return err
 	})
 }
 
@@ -98,7 +119,13 @@ func (r *Repo) DelCard(ctx context.Context, cardUUID, userID uuid.UUID) (err err
 		err = errs.ErrWrongOwnerOrNotFound
 		return err
 	}
-	return r.db.WithContext(ctx).Delete(&models.Card{}, cardUUID).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := stampRevision(tx, &models.Card{}, cardUUID, userID); err != nil {
+			return err
+		}
+
+		return l.WrapErr(tx.Delete(&models.Card{}, cardUUID).Error)
+	})
 }
 
 // UpdateCard updates the details of the specified card in the database if the user is the owner.
@@ -111,6 +138,10 @@ func (r *Repo) UpdateCard(ctx context.Context, card *entity.Card, userID uuid.UU
 	}
 
 	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkBaseRevision(tx.WithContext(ctx), &models.Card{}, card.ID, userID, card.BaseRevision); err != nil {
+			return err
+		}
+
 		cardToDB := models.Card{
 			ID:              card.ID,
 			UserID:          userID,
@@ -125,18 +156,23 @@ func (r *Repo) UpdateCard(ctx context.Context, card *entity.Card, userID uuid.UU
 		if err = tx.WithContext(ctx).Save(&cardToDB).Error; err != nil {
 			return l.WrapErr(err)
 		}
-		for _, meta := range card.Meta {
+		for index, meta := range card.Meta {
 			metaForCard := models.MetaCard{
 				Name:   meta.Name,
 				Value:  meta.Value,
 				CardID: cardToDB.ID,
 				ID:     meta.ID,
 			}
-			if err = tx.WithContext(ctx).Create(&metaForCard).Error; err != nil {
-				return l.WrapErr(err)
+			if err = saveMeta(tx.WithContext(ctx), &metaForCard, "card_id", cardToDB.ID, meta); err != nil {
+				return err
 			}
+			card.Meta[index].ID = metaForCard.ID
 		}
 
-		return nil
+		if err := pruneMeta(tx.WithContext(ctx), &models.MetaCard{}, "card_id", cardToDB.ID, card.Meta); err != nil {
+			return err
+		}
+		card.Revision, err = stampRevision(tx, &models.Card{}, cardToDB.ID, userID)
+		return err
 	})
 }
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// GetChanges returns the items of the user changed after the since revision, deleted ones as tombstones.
+// A zero or unknown revision, or one older than the purged tombstones, returns the whole vault instead,
+// marked as full. The changes are read from one snapshot and end at the cursor, the latest revision
+// of the user; the row of the user is locked by every change until it commits, so no change up to
+// the cursor can show up later.
+func (r *Repo) GetChanges(ctx context.Context, userID uuid.UUID, since int64) (changes entity.Changes, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Select("revision", "purged_revision").First(&user, "id = ?", userID).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		changes.Cursor = user.Revision
+		if since <= 0 || since > changes.Cursor || since < user.PurgedRevision {
+			changes.Full, since = true, 0
+		}
+
+		query := func() *gorm.DB {
+			// Unscoped below reaches the preload as well, so the deleted metadata is left out explicitly.
+			query := tx.Preload("Meta", activeMeta).Where("user_id = ? AND revision <= ?", userID, changes.Cursor)
+			if changes.Full {
+				return query
+			}
+			return query.Unscoped().Where("revision > ?", since)
+		}
+
+		var logins []models.Login
+		if err := query().Find(&logins).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		for index := range logins {
+			if logins[index].DeletedAt.Valid {
+				changes.Deleted = append(changes.Deleted, tombstone(logins[index].ID, entity.ItemLogins, logins[index].Revision))
+				continue
+			}
+			changes.Logins = append(changes.Logins, loginFromDB(&logins[index]))
+		}
+
+		var cards []models.Card
+		if err := query().Find(&cards).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		for index := range cards {
+			if cards[index].DeletedAt.Valid {
+				changes.Deleted = append(changes.Deleted, tombstone(cards[index].ID, entity.ItemCards, cards[index].Revision))
+				continue
+			}
+			changes.Cards = append(changes.Cards, cardFromDB(&cards[index]))
+		}
+
+		var notes []models.Note
+		if err := query().Find(&notes).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		for index := range notes {
+			if notes[index].DeletedAt.Valid {
+				changes.Deleted = append(changes.Deleted, tombstone(notes[index].ID, entity.ItemNotes, notes[index].Revision))
+				continue
+			}
+			changes.Notes = append(changes.Notes, noteFromDB(&notes[index]))
+		}
+
+		var binaries []models.Binary
+		if err := query().Find(&binaries).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		for index := range binaries {
+			if binaries[index].DeletedAt.Valid {
+				changes.Deleted = append(changes.Deleted, tombstone(binaries[index].ID, entity.ItemBinary, binaries[index].Revision))
+				continue
+			}
+			changes.Binaries = append(changes.Binaries, binaryFromDB(&binaries[index]))
+		}
+
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+
+	return changes, err
+}
+
+// activeMeta scopes a preload of metadata to the rows that have not been deleted.
+func activeMeta(db *gorm.DB) *gorm.DB {
+	return db.Where("deleted_at IS NULL")
+}
+
+// nextRevision increments the revision of the user and returns it. The row of the user stays locked
+// until the transaction ends, so the changes of the user commit in the order of their revisions.
+func nextRevision(tx *gorm.DB, userID uuid.UUID) (int64, error) {
+	var user models.User
+	if err := tx.Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+		Where("id = ?", userID).
+		UpdateColumn("revision", gorm.Expr("revision + ?", 1)).Error; err != nil {
+		return 0, l.WrapErr(err)
+	}
+
+	return user.Revision, nil
+}
+
+// stampRevision sets the next revision of the user on the item, deleted or not, and returns it.
+func stampRevision(tx *gorm.DB, model any, itemID, userID uuid.UUID) (int64, error) {
+	revision, err := nextRevision(tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err = tx.Unscoped().Model(model).
+		Where("id = ? AND user_id = ?", itemID, userID).
+		UpdateColumn("revision", revision).Error; err != nil {
+		return 0, l.WrapErr(err)
+	}
+
+	return revision, nil
+}
+
+// checkBaseRevision locks the item of the user for the update and rejects the update with errs.ErrRevisionConflict
+// when the item has been changed since the base revision, or with errs.ErrWrongOwnerOrNotFound when the user
+// has no such item. A zero base revision skips the check.
+func checkBaseRevision(tx *gorm.DB, model any, itemID, userID uuid.UUID, base int64) error {
+	if base == 0 {
+		return nil
+	}
+
+	var revision int64
+	result := tx.Model(model).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", itemID, userID).
+		Select("revision").Scan(&revision)
+	if result.Error != nil {
+		return l.WrapErr(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errs.ErrWrongOwnerOrNotFound
+	}
+	if revision != base {
+		return errs.ErrRevisionConflict
+	}
+
+	return nil
+}
+
+// saveMeta saves a metadata row of the item. The IDs of metadata are chosen by the client, so a row
+// is only updated when it belongs to the item, a row pruned before is restored; an ID taken by the
+// metadata of another item is rejected with ErrWrongOwnerOrNotFound, and a new ID is inserted.
+func saveMeta(tx *gorm.DB, row any, column string, itemID uuid.UUID, meta entity.Meta) error {
+	if meta.ID != uuid.Nil {
+		result := tx.Unscoped().Model(row).
+			Where("id = ? AND "+column+" = ?", meta.ID, itemID).
+			Updates(map[string]any{"name": meta.Name, "value": meta.Value, "deleted_at": nil})
+		if result.Error != nil {
+			return l.WrapErr(result.Error)
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+
+		var count int64
+		if err := tx.Unscoped().Model(row).Where("id = ?", meta.ID).Count(&count).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		if count > 0 {
+			return errs.ErrWrongOwnerOrNotFound
+		}
+	}
+
+	return l.WrapErr(tx.Create(row).Error)
+}
+
+// pruneMeta deletes the metadata of the item left out of the update,
+// so an update replaces the metadata of the item instead of adding to it.
+func pruneMeta(tx *gorm.DB, model any, column string, itemID uuid.UUID, meta []entity.Meta) error {
+	query := tx.Where(column+" = ?", itemID)
+	if len(meta) > 0 {
+		ids := make([]uuid.UUID, len(meta))
+		for index := range meta {
+			ids[index] = meta[index].ID
+		}
+		query = query.Where("id NOT IN ?", ids)
+	}
+
+	return l.WrapErr(query.Delete(model).Error)
+}
+
+// stampAllRevisions sets the next revision of the user on every item of the user.
+func stampAllRevisions(tx *gorm.DB, userID uuid.UUID) error {
+	revision, err := nextRevision(tx, userID)
+	if err != nil {
+		return err
+	}
+	for _, model := range []any{&models.Login{}, &models.Card{}, &models.Note{}, &models.Binary{}} {
+		if err = tx.Model(model).
+			Where("user_id = ?", userID).
+			UpdateColumn("revision", revision).Error; err != nil {
+			return l.WrapErr(err)
+		}
+	}
+
+	return nil
+}
+
+// tombstone returns the deleted item of the type.
+func tombstone(itemID uuid.UUID, itemType string, revision int64) entity.DeletedItem {
+	return entity.DeletedItem{ID: itemID, Type: itemType, Revision: revision}
+}
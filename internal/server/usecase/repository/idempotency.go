@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// idempotencyLease is how long a reserved key waits for the response of its request.
+// A key reserved longer ago is taken over by a retry, its request is taken as lost.
+const idempotencyLease = 10 * time.Minute
+
+// ReserveIdempotencyKey reserves the idempotency key of the user for a request about to run.
+// Returns the stored response if a request has been completed with the key and ErrRequestInProgress
+// while another request with the key is running. ErrNoStoredResponse is returned once the key
+// has been reserved; its response is then stored by SaveIdempotentResponse or the key is released.
+// A key reserved with another fingerprint is reported with ErrIdempotencyKeyReused.
+func (r *Repo) ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key, fingerprint string) (entity.IdempotentResponse, error) {
+	db := r.db.WithContext(ctx)
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.IdempotencyKey{UserID: userID, Key: key, Fingerprint: fingerprint})
+	if result.Error != nil {
+		return entity.IdempotentResponse{}, l.WrapErr(result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return entity.IdempotentResponse{}, errs.ErrNoStoredResponse
+	}
+
+	var keys []models.IdempotencyKey
+	if err := db.Where("user_id = ? AND key = ?", userID, key).Limit(1).Find(&keys).Error; err != nil {
+		return entity.IdempotentResponse{}, l.WrapErr(err)
+	}
+	if len(keys) == 0 {
+		// The key has just been released by the request that reserved it.
+		return entity.IdempotentResponse{}, errs.ErrRequestInProgress
+	}
+	// Keys stored before fingerprints were recorded have none and match any retry.
+	if keys[0].Fingerprint != "" && keys[0].Fingerprint != fingerprint {
+		return entity.IdempotentResponse{}, errs.ErrIdempotencyKeyReused
+	}
+	if keys[0].Status != 0 {
+		return entity.IdempotentResponse{Status: keys[0].Status, Header: keys[0].Header, Body: keys[0].Body}, nil
+	}
+
+	now := time.Now()
+	result = db.Model(&models.IdempotencyKey{}).
+		Where("user_id = ? AND key = ? AND status = 0 AND created_at < ?", userID, key, now.Add(-idempotencyLease)).
+		Update("created_at", now)
+	if result.Error != nil {
+		return entity.IdempotentResponse{}, l.WrapErr(result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return entity.IdempotentResponse{}, errs.ErrNoStoredResponse
+	}
+
+	return entity.IdempotentResponse{}, errs.ErrRequestInProgress
+}
+
+// SaveIdempotentResponse stores the response to the request that has reserved the idempotency key of the user.
+func (r *Repo) SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, response entity.IdempotentResponse) error {
+	return l.WrapErr(r.db.WithContext(ctx).
+		Where("user_id = ? AND key = ? AND status = 0", userID, key).
+		Updates(&models.IdempotencyKey{
+			Status: response.Status,
+			Header: response.Header,
+			Body:   response.Body,
+		}).Error)
+}
+
+// ReleaseIdempotencyKey drops the reservation of the idempotency key of the user
+// made by a request that has not been completed, so the request can be retried.
+func (r *Repo) ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error {
+	return l.WrapErr(r.db.WithContext(ctx).
+		Where("user_id = ? AND key = ? AND status = 0", userID, key).
+		Delete(&models.IdempotencyKey{}).Error)
+}
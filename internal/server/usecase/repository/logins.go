@@ -16,12 +16,13 @@ import (
 // It wraps the database operation in a transaction to ensure atomicity.
 // Creates a new Login record with the provided entity.Login data
 // and associates it with the user identified by userID.
+// The IDs chosen by the client are kept, the ciphertexts are bound to them; a nil ID gets a new one.
 // Also creates MetaLogin records for any metadata associated with the login entry.
 // Returns an error if the operation fails.
 func (r *Repo) AddLogin(ctx context.Context, login *entity.Login, userID uuid.UUID) (err error) {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		loginToDB := models.Login{
-			ID:       uuid.New(),
+			ID:       login.ID,
 			UserID:   userID,
 			Name:     login.Name,
 			Password: login.Password,
@@ -36,17 +37,19 @@ func (r *Repo) AddLogin(ctx context.Context, login *entity.Login, userID uuid.UU
 		login.ID = loginToDB.ID
 		for index, meta := range login.Meta {
 			metaForLogin := models.MetaLogin{
+				ID:      meta.ID,
 				Name:    meta.Name,
 				Value:   meta.Value,
 				LoginID: loginToDB.ID,
 			}
-			if err = tx.WithContext(ctx).Create(&metaForLogin).Error; err != nil {
-				return l.WrapErr(err)
+			if err = saveMeta(tx.WithContext(ctx), &metaForLogin, "login_id", loginToDB.ID, meta); err != nil {
+				return err
 			}
 			login.Meta[index].ID = metaForLogin.ID
 		}
 
-		return nil
+		login.Revision, err = stampRevision(tx, &models.Login{}, loginToDB.ID, userID)
+		return err
 	})
 }
 
@@ -70,23 +73,33 @@ func (r *Repo) GetLogins(ctx context.Context, user entity.User) (logins []entity
 	logins = make([]entity.Login, len(loginsFromDB))
 
 	for index := range loginsFromDB {
-		logins[index].ID = loginsFromDB[index].ID
-		logins[index].Name = loginsFromDB[index].Name
-		logins[index].Password = loginsFromDB[index].Password
-		logins[index].URI = loginsFromDB[index].URI
-		logins[index].Login = loginsFromDB[index].Login
-		for metaIndex := range loginsFromDB[index].Meta {
-			logins[index].Meta = append(logins[index].Meta, entity.Meta{
-				ID:    loginsFromDB[index].Meta[metaIndex].ID,
-				Name:  loginsFromDB[index].Meta[metaIndex].Name,
-				Value: loginsFromDB[index].Meta[metaIndex].Value,
-			})
-		}
+		logins[index] = loginFromDB(&loginsFromDB[index])
 	}
 
 	return
 }
 
+// loginFromDB converts the stored login and its metadata to the entity.
+func loginFromDB(loginFromDB *models.Login) entity.Login {
+	login := entity.Login{
+		ID:       loginFromDB.ID,
+		Name:     loginFromDB.Name,
+		Password: loginFromDB.Password,
+		URI:      loginFromDB.URI,
+		Login:    loginFromDB.Login,
+		Revision: loginFromDB.Revision,
+	}
+	for metaIndex := range loginFromDB.Meta {
+		login.Meta = append(login.Meta, entity.Meta{
+			ID:    loginFromDB.Meta[metaIndex].ID,
+			Name:  loginFromDB.Meta[metaIndex].Name,
+			Value: loginFromDB.Meta[metaIndex].Value,
+		})
+	}
+
+	return login
+}
+
 // IsLoginOwner checks if a specific user is the owner of a login entry.
 // Returns true if the user is the owner, false otherwise.
 func (r *Repo) IsLoginOwner(ctx context.Context, loginID, userID uuid.UUID) bool {
@@ -102,7 +115,13 @@ func (r *Repo) DelLogin(ctx context.Context, loginID, userID uuid.UUID) (err err
 		return errs.ErrWrongOwnerOrNotFound
 	}
 
-	return l.WrapErr(r.db.WithContext(ctx).Delete(&models.Login{}, loginID).Error)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := stampRevision(tx, &models.Login{}, loginID, userID); err != nil {
+			return err
+		}
+
+		return l.WrapErr(tx.Delete(&models.Login{}, loginID).Error)
+	})
 }
 
 // UpdateLogin updates an existing login entry if the user is the owner of the login.
@@ -114,6 +133,10 @@ func (r *Repo) UpdateLogin(ctx context.Context, login *entity.Login, userID uuid
 	}
 
 	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkBaseRevision(tx.WithContext(ctx), &models.Login{}, login.ID, userID, login.BaseRevision); err != nil {
+			return err
+		}
+
 		loginToDB := models.Login{
 			ID:       login.ID,
 			Name:     login.Name,
@@ -127,17 +150,24 @@ func (r *Repo) UpdateLogin(ctx context.Context, login *entity.Login, userID uuid
 			return l.WrapErr(err)
 		}
 		login.ID = loginToDB.ID
-		for _, meta := range login.Meta {
+		for index, meta := range login.Meta {
 			metaForLogin := models.MetaLogin{
 				Name:    meta.Name,
 				Value:   meta.Value,
 				LoginID: loginToDB.ID,
 				ID:      meta.ID,
 			}
-			if err := tx.WithContext(ctx).Create(&metaForLogin).Error; err != nil {
-				return l.WrapErr(err)
+			if err := saveMeta(tx.WithContext(ctx), &metaForLogin, "login_id", loginToDB.ID, meta); err != nil {
+				return err
 			}
+			login.Meta[index].ID = metaForLogin.ID
+		}
+
+		if err := pruneMeta(tx.WithContext(ctx), &models.MetaLogin{}, "login_id", loginToDB.ID, login.Meta); err != nil {
+			return err
 		}
-		return nil
+		revision, err := stampRevision(tx, &models.Login{}, loginToDB.ID, userID)
+		login.Revision = revision
+		return err
 	})
 }
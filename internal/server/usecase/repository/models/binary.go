@@ -17,9 +17,12 @@ type MetaBinary struct {
 // Binary represents a binary entity stored in the database.
 type Binary struct {
 	gorm.Model
-	ID       uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
-	Name     string       // Name of the binary data
-	FileName string       // File name associated with the binary data
-	UserID   uuid.UUID    // Foreign key reference to User ID
-	Meta     []MetaBinary // Metadata associated with the binary data
+	ID         uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	Name       string       // Name of the binary data
+	FileName   string       // File name associated with the binary data
+	StoredName string       // Name of the stored file, the ID when empty
+	UserID     uuid.UUID    // Foreign key reference to User ID
+	Meta       []MetaBinary // Metadata associated with the binary data
+
+	Revision int64 `gorm:"not null;default:0;index"` // Revision of the latest change, deletion included
 }
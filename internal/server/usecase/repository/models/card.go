@@ -27,4 +27,6 @@ type Card struct {
 	SecurityCode    string     // Security code (CVV) of the card
 	UserID          uuid.UUID  // Foreign key reference to User ID
 	Meta            []MetaCard `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // Metadata associated with the card
+
+	Revision int64 `gorm:"not null;default:0;index"` // Revision of the latest change, deletion included
 }
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey holds the response to a request sent with an idempotency key,
+// replayed when the request is retried with the same key. A zero status marks a key
+// reserved by a request that is still running. The fingerprint tells a retry from
+// another request sent with the same key.
+type IdempotencyKey struct {
+	UserID      uuid.UUID   `gorm:"type:uuid;primaryKey"` // Foreign key reference to User ID
+	Key         string      `gorm:"size:100;primaryKey"`  // Idempotency key chosen by the client
+	Fingerprint string      `gorm:"size:64"`              // Hash of the method, URI and body of the request
+	Status      int         // Status code of the response
+	Header      http.Header `gorm:"serializer:json"` // Headers of the response
+	Body        []byte      // Body of the response
+	CreatedAt   time.Time   `gorm:"index"` // Timestamp of the first request
+}
@@ -24,4 +24,6 @@ type Login struct {
 	Password string      // Login password
 	UserID   uuid.UUID   // Foreign key reference to User ID
 	Meta     []MetaLogin `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // Metadata associated with the login
+
+	Revision int64 `gorm:"not null;default:0;index"` // Revision of the latest change, deletion included
 }
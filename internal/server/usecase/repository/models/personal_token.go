@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalToken represents a personal access token of a user.
+type PersonalToken struct {
+	ID         uuid.UUID   `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	UserID     uuid.UUID   `gorm:"type:uuid;index;not null"` // Foreign key reference to User ID
+	Name       string      `gorm:"not null"`                 // Name given by the user
+	TokenHash  string      `gorm:"uniqueIndex;not null"`     // SHA-256 hash of the secret of the token
+	ReadOnly   bool        `gorm:"not null;default:false"`   // Only items may be read
+	ItemTypes  []string    `gorm:"serializer:json"`          // Item types the token may access, all if empty
+	ItemIDs    []uuid.UUID `gorm:"serializer:json"`          // Items the token may access, all if empty
+	CreatedAt  time.Time   // Timestamp when the token was created
+	ExpiresAt  time.Time   `gorm:"not null"` // Timestamp the token stops working
+	LastUsedAt *time.Time  // Timestamp the token was last used, nil if never
+}
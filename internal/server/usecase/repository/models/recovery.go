@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode represents a one-time code that disables the TOTP second factor of a user.
+type RecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	UserID    uuid.UUID `gorm:"type:uuid;index;not null"` // Foreign key reference to User ID
+	CodeHash  string    `gorm:"not null"`                 // SHA-256 of the normalized code
+	CreatedAt time.Time // Timestamp the code was generated
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rekey represents a re-encrypted copy of the user vault waiting for the password change.
+type Rekey struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primary_key"` // Owner of the vault
+	Payload   string    `gorm:"not null"`              // JSON encoded re-encrypted vault
+	CreatedAt time.Time // Timestamp when the copy was staged
+	UpdatedAt time.Time // Timestamp when the copy was last replaced
+}
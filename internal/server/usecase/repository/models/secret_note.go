@@ -22,4 +22,6 @@ type Note struct {
 	Note   string     // Content of the note
 	UserID uuid.UUID  // Foreign key reference to User ID
 	Meta   []MetaNote `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // Metadata associated with the note
+
+	Revision int64 `gorm:"not null;default:0;index"` // Revision of the latest change, deletion included
 }
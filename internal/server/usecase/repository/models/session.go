@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session represents a signed in client of a user.
+type Session struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	UserID         uuid.UUID  `gorm:"type:uuid;index;not null"` // Foreign key reference to User ID
+	RefreshTokenID uuid.UUID  `gorm:"type:uuid;not null"`       // ID of the only refresh token of the session that may be used
+	DeviceName     string     // Name of the device the session has been started from
+	Platform       string     // Operating system and architecture of the device
+	LastIP         string     // IP address the device was last seen from
+	CreatedAt      time.Time  // Timestamp of the sign in
+	LastUsedAt     time.Time  // Timestamp the device was last seen
+	ExpiresAt      time.Time  `gorm:"not null"` // Timestamp the current refresh token expires
+	RevokedAt      *time.Time // Timestamp the session was revoked, nil while it is active
+}
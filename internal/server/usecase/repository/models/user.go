@@ -12,12 +12,29 @@ type User struct {
 	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
 	Email     string    `gorm:"unique;uniqueIndex;not null"` // Email address of the user
 	Password  string    `gorm:"not null"`                    // Password hash of the user
+	AuthHash  bool      `gorm:"not null;default:false"`      // Password holds the hash of the client auth hash, not of the master password
+	DataKey   string    // Vault data key wrapped by the master password, opaque to the server
 	CreatedAt time.Time // Timestamp when the user was created
 	UpdatedAt time.Time // Timestamp when the user was last updated
 	Cards     []Card    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // List of cards associated with the user
 	Logins    []Login   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // List of logins associated with the user
 	Notes     []Note    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // List of notes associated with the user
 	Binary    []Binary  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // List of binary data associated with the user
+	Sessions  []Session `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // List of signed in clients of the user
+
+	TOTPSecret      string         // Secret of the TOTP second factor, set on enrollment before it is confirmed
+	TOTPEnabled     bool           `gorm:"not null;default:false"` // Sign in requires a TOTP code
+	TOTPLastCounter int64          // Time step of the last accepted TOTP code, older codes are rejected
+	RecoveryCodes   []RecoveryCode `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // One-time codes disabling the second factor
+
+	PersonalTokens []PersonalToken `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // Personal access tokens of the user
+
+	RecoveryKey  string // Vault data key wrapped by the recovery key, opaque to the server
+	RecoveryHash string // Hash of the secret the recovery key is checked with, empty without a recovery key
+
+	Revision int64 `gorm:"not null;default:0"` // Revision of the latest change of the user items
+
+	PurgedRevision int64 `gorm:"not null;default:0"` // Revision of the latest purged tombstone, older cursors get the whole vault
 }
 
 // ToString returns a formatted string representation of the user.
@@ -30,24 +30,42 @@ func (r *Repo) GetNotes(ctx context.Context, user entity.User) (notes []entity.S
 	notes = make([]entity.SecretNote, len(notesFromDB))
 
 	for index := range notesFromDB {
-		notes[index].ID = notesFromDB[index].ID
-		notes[index].Name = notesFromDB[index].Name
-		notes[index].Note = notesFromDB[index].Note
+		notes[index] = noteFromDB(&notesFromDB[index])
 	}
 	return
 }
 
+// noteFromDB converts the stored note and its metadata to the entity.
+func noteFromDB(noteFromDB *models.Note) entity.SecretNote {
+	note := entity.SecretNote{
+		ID:       noteFromDB.ID,
+		Name:     noteFromDB.Name,
+		Note:     noteFromDB.Note,
+		Revision: noteFromDB.Revision,
+	}
+	for metaIndex := range noteFromDB.Meta {
+		note.Meta = append(note.Meta, entity.Meta{
+			ID:    noteFromDB.Meta[metaIndex].ID,
+			Name:  noteFromDB.Meta[metaIndex].Name,
+			Value: noteFromDB.Meta[metaIndex].Value,
+		})
+	}
+
+	return note
+}
+
 // AddNote adds a new secret note for a specific user. It also adds associated meta data.
+// The IDs chosen by the client are kept, the ciphertexts are bound to them; a nil ID gets a new one.
 func (r *Repo) AddNote(ctx context.Context, note *entity.SecretNote, userID uuid.UUID) (err error) {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		noteToDB := models.Note{
-			ID:     uuid.New(),
+			ID:     note.ID,
 			UserID: userID,
 			Name:   note.Name,
 			Note:   note.Note,
 		}
 
-		if err = r.db.WithContext(ctx).Create(&noteToDB).Error; err != nil {
+		if err = tx.WithContext(ctx).Create(&noteToDB).Error; err != nil {
 			return l.WrapErr(err)
 		}
 
@@ -59,13 +77,14 @@ func (r *Repo) AddNote(ctx context.Context, note *entity.SecretNote, userID uuid
 				NoteID: noteToDB.ID,
 				ID:     meta.ID,
 			}
-			if err = tx.WithContext(ctx).Create(&metaForNote).Error; err != nil {
-				return l.WrapErr(err)
+			if err = saveMeta(tx.WithContext(ctx), &metaForNote, "note_id", noteToDB.ID, meta); err != nil {
+				return err
 			}
 			note.Meta[index].ID = metaForNote.ID
 		}
 
-		return nil
+		note.Revision, err = stampRevision(tx, &models.Note{}, noteToDB.ID, userID)
+		return err
 	})
 }
 
@@ -81,7 +100,13 @@ func (r *Repo) DelNote(ctx context.Context, noteID, userID uuid.UUID) (err error
 	if !r.IsNoteOwner(ctx, noteID, userID) {
 		return errs.ErrWrongOwnerOrNotFound
 	}
-	return r.db.WithContext(ctx).Delete(&models.Note{}, noteID).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := stampRevision(tx, &models.Note{}, noteID, userID); err != nil {
+			return err
+		}
+
+		return l.WrapErr(tx.Delete(&models.Note{}, noteID).Error)
+	})
 }
 
 // UpdateNote updates an existing secret note if the user is the owner of the note.
@@ -91,6 +116,10 @@ func (r *Repo) UpdateNote(ctx context.Context, note *entity.SecretNote, userID u
 	}
 
 	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkBaseRevision(tx.WithContext(ctx), &models.Note{}, note.ID, userID, note.BaseRevision); err != nil {
+			return err
+		}
+
 		noteToDB := models.Note{
 			ID:     note.ID,
 			UserID: userID,
@@ -98,10 +127,26 @@ func (r *Repo) UpdateNote(ctx context.Context, note *entity.SecretNote, userID u
 			Note:   note.Note,
 		}
 
-		if err = r.db.WithContext(ctx).Save(&noteToDB).Error; err != nil {
+		if err = tx.WithContext(ctx).Save(&noteToDB).Error; err != nil {
 			return l.WrapErr(err)
 		}
+		for index, meta := range note.Meta {
+			metaForNote := models.MetaNote{
+				Name:   meta.Name,
+				Value:  meta.Value,
+				NoteID: noteToDB.ID,
+				ID:     meta.ID,
+			}
+			if err = saveMeta(tx.WithContext(ctx), &metaForNote, "note_id", noteToDB.ID, meta); err != nil {
+				return err
+			}
+			note.Meta[index].ID = metaForNote.ID
+		}
 
-		return nil
+		if err := pruneMeta(tx.WithContext(ctx), &models.MetaNote{}, "note_id", noteToDB.ID, note.Meta); err != nil {
+			return err
+		}
+		note.Revision, err = stampRevision(tx, &models.Note{}, noteToDB.ID, userID)
+		return err
 	})
 }
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// CreatePersonalToken stores a personal token of its user under the hash of its secret.
+func (r *Repo) CreatePersonalToken(
+	ctx context.Context,
+	token entity.PersonalToken,
+	tokenHash string,
+) (entity.PersonalToken, error) {
+	tokenToDB := models.PersonalToken{
+		UserID:    token.UserID,
+		Name:      token.Name,
+		TokenHash: tokenHash,
+		ReadOnly:  token.Scope.ReadOnly,
+		ItemTypes: token.Scope.ItemTypes,
+		ItemIDs:   token.Scope.ItemIDs,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: token.ExpiresAt,
+	}
+
+	if err := r.db.WithContext(ctx).Create(&tokenToDB).Error; err != nil {
+		return entity.PersonalToken{}, l.WrapErr(err)
+	}
+
+	return personalTokenToEntity(tokenToDB), nil
+}
+
+// GetPersonalToken returns the unexpired personal token with the hash of the secret.
+// Returns ErrTokenValidation if there is no such token.
+func (r *Repo) GetPersonalToken(ctx context.Context, tokenHash string) (entity.PersonalToken, error) {
+	var tokenFromDB models.PersonalToken
+	if err := r.db.WithContext(ctx).
+		First(&tokenFromDB, "token_hash = ? AND expires_at > ?", tokenHash, time.Now().UTC()).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.PersonalToken{}, errs.ErrTokenValidation
+		}
+		return entity.PersonalToken{}, l.WrapErr(err)
+	}
+
+	return personalTokenToEntity(tokenFromDB), nil
+}
+
+// GetPersonalTokens returns the unexpired personal tokens of the user, the newest first.
+func (r *Repo) GetPersonalTokens(ctx context.Context, userID uuid.UUID) (tokens []entity.PersonalToken, err error) {
+	var tokensFromDB []models.PersonalToken
+	if err = r.db.WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now().UTC()).
+		Order("created_at desc").
+		Find(&tokensFromDB).Error; err != nil {
+		return nil, l.WrapErr(err)
+	}
+
+	tokens = make([]entity.PersonalToken, len(tokensFromDB))
+	for index := range tokensFromDB {
+		tokens[index] = personalTokenToEntity(tokensFromDB[index])
+	}
+
+	return tokens, nil
+}
+
+// TouchPersonalToken records that the personal token has been used now.
+func (r *Repo) TouchPersonalToken(ctx context.Context, tokenID uuid.UUID) error {
+	return l.WrapErr(r.db.WithContext(ctx).
+		Model(&models.PersonalToken{}).
+		Where("id = ?", tokenID).
+		Update("last_used_at", time.Now().UTC()).Error)
+}
+
+// DelPersonalToken deletes the personal token of the user.
+// Returns ErrWrongOwnerOrNotFound if the user has no such token.
+func (r *Repo) DelPersonalToken(ctx context.Context, tokenID, userID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", tokenID, userID).
+		Delete(&models.PersonalToken{})
+	if result.Error != nil {
+		return l.WrapErr(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errs.ErrWrongOwnerOrNotFound
+	}
+
+	return nil
+}
+
+// personalTokenToEntity converts the personal token model to the entity, without its secret.
+func personalTokenToEntity(tokenFromDB models.PersonalToken) entity.PersonalToken {
+	return entity.PersonalToken{
+		ID:     tokenFromDB.ID,
+		UserID: tokenFromDB.UserID,
+		Name:   tokenFromDB.Name,
+		Scope: entity.TokenScope{
+			ReadOnly:  tokenFromDB.ReadOnly,
+			ItemTypes: tokenFromDB.ItemTypes,
+			ItemIDs:   tokenFromDB.ItemIDs,
+		},
+		CreatedAt:  tokenFromDB.CreatedAt,
+		ExpiresAt:  tokenFromDB.ExpiresAt,
+		LastUsedAt: tokenFromDB.LastUsedAt,
+	}
+}
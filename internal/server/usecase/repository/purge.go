@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// tombstoneTables are the tables of the items together with the tables of their metadata.
+var tombstoneTables = []struct {
+	model, meta any
+	column      string // Column of the metadata referencing the item.
+}{
+	{&models.Login{}, &models.MetaLogin{}, "login_id"},
+	{&models.Card{}, &models.MetaCard{}, "card_id"},
+	{&models.Note{}, &models.MetaNote{}, "note_id"},
+	{&models.Binary{}, &models.MetaBinary{}, "binary_id"},
+}
+
+// PurgeTombstones deletes the items deleted before the given time for good, together with their metadata,
+// and returns the number of purged items. Every user keeps the revision of the latest purged tombstone,
+// so a device with an older cursor gets the whole vault and cannot miss a deletion. The row of the user
+// is locked before the items, in the same order as by the changes.
+func (r *Repo) PurgeTombstones(ctx context.Context, before time.Time) (purged int64, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range tombstoneTables {
+			latest := tx.Unscoped().Model(table.model).
+				Select("user_id, MAX(revision) AS revision").
+				Where("deleted_at < ?", before).
+				Group("user_id")
+			if err := tx.Exec(`UPDATE users SET purged_revision = purged.revision FROM (?) AS purged
+				WHERE users.id = purged.user_id AND users.purged_revision < purged.revision`, latest).Error; err != nil {
+				return err
+			}
+
+			tombstones := tx.Unscoped().Model(table.model).Select("id").Where("deleted_at < ?", before)
+			if err := tx.Unscoped().Where(table.column+" IN (?)", tombstones).Delete(table.meta).Error; err != nil {
+				return err
+			}
+			result := tx.Unscoped().Where("deleted_at < ?", before).Delete(table.model)
+			if result.Error != nil {
+				return result.Error
+			}
+			purged += result.RowsAffected
+		}
+
+		return nil
+	})
+
+	return purged, l.WrapErr(err)
+}
+
+// PurgeIdempotencyKeys deletes the responses stored for the idempotency keys of requests
+// sent before the given time and returns their number.
+func (r *Repo) PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&models.IdempotencyKey{})
+
+	return result.RowsAffected, l.WrapErr(result.Error)
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// SetRecoveryKey sets the data key wrapped by the recovery key of the user and the hash of the secret
+// the recovery key is checked with. The previous recovery key stops working.
+func (r *Repo) SetRecoveryKey(ctx context.Context, userID uuid.UUID, recoveryHash, recoveryKey string) error {
+	return l.WrapErr(r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"recovery_key":  recoveryKey,
+			"recovery_hash": recoveryHash,
+		}).Error)
+}
+
+// GetRecoveryKey returns the data key wrapped by the recovery key of the user with the email.
+// Returns ErrWrongRecoveryKey if there is no such user, the user has no recovery key
+// or the hash does not match.
+func (r *Repo) GetRecoveryKey(ctx context.Context, email, recoveryHash string) (string, error) {
+	var userFromDB models.User
+	if err := r.db.WithContext(ctx).
+		Select("id", "recovery_key", "recovery_hash").
+		First(&userFromDB, "email = ?", email).Error; err != nil {
+		return "", errs.ErrWrongRecoveryKey
+	}
+	if !recoveryMatches(userFromDB, recoveryHash) {
+		return "", errs.ErrWrongRecoveryKey
+	}
+
+	return userFromDB.RecoveryKey, nil
+}
+
+// RecoverAccount sets the new password hash and the data key wrapped by the new password of the user
+// with the email, checked with the recovery key. A staged rekey is dropped, it has been made for the old password.
+// Returns the ID of the user, or ErrWrongRecoveryKey like GetRecoveryKey.
+func (r *Repo) RecoverAccount(ctx context.Context, email, recoveryHash, hashedPassword, dataKey string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var userFromDB models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&userFromDB, "email = ?", email).Error; err != nil {
+			return errs.ErrWrongRecoveryKey
+		}
+		if !recoveryMatches(userFromDB, recoveryHash) {
+			return errs.ErrWrongRecoveryKey
+		}
+
+		if err := tx.Model(&userFromDB).Updates(map[string]any{
+			"password":  hashedPassword,
+			"auth_hash": true,
+			"data_key":  dataKey,
+		}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		userID = userFromDB.ID
+
+		return l.WrapErr(tx.Delete(&models.Rekey{}, "user_id = ?", userFromDB.ID).Error)
+	})
+
+	return userID, err
+}
+
+// recoveryMatches reports whether the user has a recovery key checked with the hash.
+func recoveryMatches(userFromDB models.User, recoveryHash string) bool {
+	return userFromDB.RecoveryHash != "" &&
+		subtle.ConstantTimeCompare([]byte(userFromDB.RecoveryHash), []byte(recoveryHash)) == 1
+}
@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
+	"github.com/nextlag/keeper/internal/utils"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// StageRekey stores the re-encrypted vault of the user, replacing a previously staged one.
+// The stored vault stays untouched until RotateDataKey commits the staged copy.
+func (r *Repo) StageRekey(ctx context.Context, rekey *entity.Rekey, userID uuid.UUID) error {
+	payload, err := json.Marshal(rekey)
+	if err != nil {
+		return l.WrapErr(err)
+	}
+
+	return l.WrapErr(r.db.WithContext(ctx).Save(&models.Rekey{
+		UserID:  userID,
+		Payload: string(payload),
+	}).Error)
+}
+
+// ChangePassword sets the new password hash and the data key wrapped by the new password
+// in a single transaction. Returns ErrWrongCredentials if the old password does not match.
+func (r *Repo) ChangePassword(ctx context.Context, userID uuid.UUID, oldPassword, newPasswordHash, dataKey string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockUser(tx, userID, oldPassword); err != nil {
+			return err
+		}
+
+		return l.WrapErr(tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]any{
+				"password": newPasswordHash,
+				"data_key": dataKey,
+			}).Error)
+	})
+}
+
+// RotateDataKey replaces every item of the user with the staged re-encrypted copy
+// and sets the new wrapped data key in a single transaction. The recovery key of the user is cleared.
+// storedNames maps every binary of the user to the name of its re-encrypted file.
+// Returns ErrWrongCredentials if the password does not match and ErrRekeyIncomplete
+// if the staged copy does not cover exactly the items of the stored vault at their current revisions,
+// so an item changed after the staging is never overwritten and the vault has to be staged again.
+func (r *Repo) RotateDataKey(
+	ctx context.Context,
+	userID uuid.UUID,
+	password, dataKey string,
+	storedNames map[uuid.UUID]string,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockUser(tx, userID, password); err != nil {
+			return err
+		}
+
+		var staged models.Rekey
+		if err := tx.First(&staged, "user_id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrRekeyIncomplete
+			}
+			return l.WrapErr(err)
+		}
+
+		var rekey entity.Rekey
+		if err := json.Unmarshal([]byte(staged.Payload), &rekey); err != nil {
+			return l.WrapErr(err)
+		}
+
+		if err := rekeyLogins(tx, rekey.Logins, userID); err != nil {
+			return err
+		}
+		if err := rekeyCards(tx, rekey.Cards, userID); err != nil {
+			return err
+		}
+		if err := rekeyNotes(tx, rekey.Notes, userID); err != nil {
+			return err
+		}
+		if err := rekeyBinaries(tx, rekey.Binaries, userID, storedNames); err != nil {
+			return err
+		}
+
+		// Every item has changed, other devices pick the re-encrypted copies up on their next sync.
+		if err := stampAllRevisions(tx, userID); err != nil {
+			return err
+		}
+
+		// The recovery key wraps the old data key, it stops working with it.
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]any{
+				"data_key":      dataKey,
+				"recovery_key":  "",
+				"recovery_hash": "",
+			}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+
+		return l.WrapErr(tx.Delete(&staged).Error)
+	})
+}
+
+// lockUser locks the user row for the rest of the transaction and checks the password.
+func lockUser(tx *gorm.DB, userID uuid.UUID, password string) error {
+	var userFromDB models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&userFromDB, "id = ?", userID).Error; err != nil {
+		return errs.ErrWrongCredentials
+	}
+	if err := utils.VerifyPassword(userFromDB.Password, password); err != nil {
+		return errs.ErrWrongCredentials
+	}
+	if !userFromDB.AuthHash {
+		return errs.ErrAuthUpgradeRequired
+	}
+
+	return nil
+}
+
+// checkCoverage ensures the staged items are exactly the user items stored in the table of the model,
+// each at the revision it was staged from. revisions maps the ID of every staged item to its revision.
+func checkCoverage(tx *gorm.DB, model any, userID uuid.UUID, revisions map[uuid.UUID]int64) error {
+	var stored []struct {
+		ID       uuid.UUID
+		Revision int64
+	}
+	if err := tx.Model(model).Select("id", "revision").Where("user_id = ?", userID).Scan(&stored).Error; err != nil {
+		return l.WrapErr(err)
+	}
+
+	if len(stored) != len(revisions) {
+		return errs.ErrRekeyIncomplete
+	}
+	for _, item := range stored {
+		if revision, ok := revisions[item.ID]; !ok || revision != item.Revision {
+			return errs.ErrRekeyIncomplete
+		}
+	}
+
+	return nil
+}
+
+// rekeyLogins replaces the logins of the user and their metadata with the re-encrypted ones.
+func rekeyLogins(tx *gorm.DB, logins []entity.Login, userID uuid.UUID) error {
+	revisions := make(map[uuid.UUID]int64, len(logins))
+	for index := range logins {
+		revisions[logins[index].ID] = logins[index].Revision
+	}
+	if err := checkCoverage(tx, &models.Login{}, userID, revisions); err != nil {
+		return err
+	}
+
+	for _, login := range logins {
+		if err := tx.Model(&models.Login{}).Where("id = ?", login.ID).Updates(map[string]any{
+			"name":     login.Name,
+			"login":    login.Login,
+			"password": login.Password,
+			"uri":      login.URI,
+		}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+
+		if err := tx.Unscoped().Where("login_id = ?", login.ID).Delete(&models.MetaLogin{}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		for _, meta := range login.Meta {
+			if err := tx.Create(&models.MetaLogin{
+				ID:      meta.ID,
+				Name:    meta.Name,
+				Value:   meta.Value,
+				LoginID: login.ID,
+			}).Error; err != nil {
+				return l.WrapErr(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rekeyCards replaces the cards of the user and their metadata with the re-encrypted ones.
+func rekeyCards(tx *gorm.DB, cards []entity.Card, userID uuid.UUID) error {
+	revisions := make(map[uuid.UUID]int64, len(cards))
+	for index := range cards {
+		revisions[cards[index].ID] = cards[index].Revision
+	}
+	if err := checkCoverage(tx, &models.Card{}, userID, revisions); err != nil {
+		return err
+	}
+
+	for _, card := range cards {
+		if err := tx.Model(&models.Card{}).Where("id = ?", card.ID).Updates(map[string]any{
+			"name":             card.Name,
+			"card_holder_name": card.CardHolderName,
+			"number":           card.Number,
+			"brand":            card.Brand,
+			"expiration_month": card.ExpirationMonth,
+			"expiration_year":  card.ExpirationYear,
+			"security_code":    card.SecurityCode,
+		}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+
+		if err := tx.Unscoped().Where("card_id = ?", card.ID).Delete(&models.MetaCard{}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		for _, meta := range card.Meta {
+			if err := tx.Create(&models.MetaCard{
+				ID:     meta.ID,
+				Name:   meta.Name,
+				Value:  meta.Value,
+				CardID: card.ID,
+			}).Error; err != nil {
+				return l.WrapErr(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rekeyNotes replaces the notes of the user and their metadata with the re-encrypted ones.
+func rekeyNotes(tx *gorm.DB, notes []entity.SecretNote, userID uuid.UUID) error {
+	revisions := make(map[uuid.UUID]int64, len(notes))
+	for index := range notes {
+		revisions[notes[index].ID] = notes[index].Revision
+	}
+	if err := checkCoverage(tx, &models.Note{}, userID, revisions); err != nil {
+		return err
+	}
+
+	for _, note := range notes {
+		if err := tx.Model(&models.Note{}).Where("id = ?", note.ID).Updates(map[string]any{
+			"name": note.Name,
+			"note": note.Note,
+		}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+
+		if err := tx.Unscoped().Where("note_id = ?", note.ID).Delete(&models.MetaNote{}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		for _, meta := range note.Meta {
+			if err := tx.Create(&models.MetaNote{
+				ID:     meta.ID,
+				Name:   meta.Name,
+				Value:  meta.Value,
+				NoteID: note.ID,
+			}).Error; err != nil {
+				return l.WrapErr(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rekeyBinaries replaces the metadata of the user binaries with the re-encrypted one
+// and switches every binary to its re-encrypted file.
+func rekeyBinaries(tx *gorm.DB, binaries []entity.Binary, userID uuid.UUID, storedNames map[uuid.UUID]string) error {
+	if len(binaries) != len(storedNames) {
+		return errs.ErrRekeyIncomplete
+	}
+	revisions := make(map[uuid.UUID]int64, len(binaries))
+	for index := range binaries {
+		revisions[binaries[index].ID] = binaries[index].Revision
+		if storedNames[binaries[index].ID] == "" {
+			return errs.ErrRekeyIncomplete
+		}
+	}
+	if err := checkCoverage(tx, &models.Binary{}, userID, revisions); err != nil {
+		return err
+	}
+
+	for _, binary := range binaries {
+		if err := tx.Model(&models.Binary{}).
+			Where("id = ?", binary.ID).
+			Update("stored_name", storedNames[binary.ID]).Error; err != nil {
+			return l.WrapErr(err)
+		}
+
+		if err := tx.Unscoped().Where("binary_id = ?", binary.ID).Delete(&models.MetaBinary{}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		for _, meta := range binary.Meta {
+			if err := tx.Create(&models.MetaBinary{
+				ID:       meta.ID,
+				Name:     meta.Name,
+				Value:    meta.Value,
+				BinaryID: binary.ID,
+			}).Error; err != nil {
+				return l.WrapErr(err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -11,15 +11,43 @@ import (
 
 	"github.com/nextlag/keeper/internal/entity"
 	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
+	"github.com/nextlag/keeper/internal/utils"
 	"github.com/nextlag/keeper/pkg/logger/l"
 )
 
 // Repository interface defines methods for interacting with the database.
 type Repository interface {
 	DBHealthCheck() error
-	AddUser(ctx context.Context, email, hashedPassword string) (entity.User, error)
+	AddUser(ctx context.Context, email, hashedPassword, dataKey, recoveryHash, recoveryKey string) (entity.User, error)
 	GetUserByEmail(ctx context.Context, email, hashedPassword string) (entity.User, error)
+	UpgradeAuth(ctx context.Context, email, password, hashedAuthHash string) (entity.User, error)
+	RehashPassword(ctx context.Context, userID uuid.UUID, password string, params utils.PasswordParams) error
 	GetUserByID(ctx context.Context, id string) (entity.User, error)
+	GetDataKey(ctx context.Context, userID uuid.UUID) (string, error)
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+
+	SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	EnableTOTP(ctx context.Context, userID uuid.UUID, code string, recoveryCodeHashes []string) error
+	VerifyTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	DisableTOTP(ctx context.Context, userID uuid.UUID, recoveryCodeHash string) error
+
+	SetRecoveryKey(ctx context.Context, userID uuid.UUID, recoveryHash, recoveryKey string) error
+	GetRecoveryKey(ctx context.Context, email, recoveryHash string) (string, error)
+	RecoverAccount(ctx context.Context, email, recoveryHash, hashedPassword, dataKey string) (uuid.UUID, error)
+
+	CreateSession(ctx context.Context, userID, refreshTokenID uuid.UUID, device entity.Device, expiresAt time.Time) (entity.Session, error)
+	GetSession(ctx context.Context, sessionID uuid.UUID) (entity.Session, error)
+	GetSessions(ctx context.Context, userID uuid.UUID) ([]entity.Session, error)
+	RotateSession(ctx context.Context, sessionID, refreshTokenID, newRefreshTokenID uuid.UUID, ip string, expiresAt time.Time) (entity.Session, error)
+	TouchSession(ctx context.Context, sessionID uuid.UUID, ip string) error
+	RenameSession(ctx context.Context, sessionID, userID uuid.UUID, name string) error
+	RevokeSession(ctx context.Context, sessionID, userID uuid.UUID) error
+
+	CreatePersonalToken(ctx context.Context, token entity.PersonalToken, tokenHash string) (entity.PersonalToken, error)
+	GetPersonalToken(ctx context.Context, tokenHash string) (entity.PersonalToken, error)
+	GetPersonalTokens(ctx context.Context, userID uuid.UUID) ([]entity.PersonalToken, error)
+	TouchPersonalToken(ctx context.Context, tokenID uuid.UUID) error
+	DelPersonalToken(ctx context.Context, tokenID, userID uuid.UUID) error
 
 	GetLogins(ctx context.Context, user entity.User) ([]entity.Login, error)
 	AddLogin(ctx context.Context, login *entity.Login, userID uuid.UUID) error
@@ -44,6 +72,17 @@ type Repository interface {
 	GetBinary(ctx context.Context, binaryID, userID uuid.UUID) (*entity.Binary, error)
 	DelUserBinary(ctx context.Context, currentUser *entity.User, binaryUUID uuid.UUID) error
 	AddBinaryMeta(ctx context.Context, currentUser *entity.User, binaryUUID uuid.UUID, meta []entity.Meta) (*entity.Binary, error)
+
+	GetChanges(ctx context.Context, userID uuid.UUID, since int64) (entity.Changes, error)
+	ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key, fingerprint string) (entity.IdempotentResponse, error)
+	SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, response entity.IdempotentResponse) error
+	ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error
+	PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+	PurgeTombstones(ctx context.Context, before time.Time) (int64, error)
+
+	StageRekey(ctx context.Context, rekey *entity.Rekey, userID uuid.UUID) error
+	ChangePassword(ctx context.Context, userID uuid.UUID, oldPassword, newPasswordHash, dataKey string) error
+	RotateDataKey(ctx context.Context, userID uuid.UUID, password, dataKey string, storedNames map[uuid.UUID]string) error
 }
 
 // Repo implements the Repository interface and provides methods for database operations.
@@ -87,6 +126,11 @@ func (r *Repo) Migrate() {
 		&models.MetaNote{},
 		&models.Binary{},
 		&models.MetaBinary{},
+		&models.Rekey{},
+		&models.Session{},
+		&models.RecoveryCode{},
+		&models.PersonalToken{},
+		&models.IdempotencyKey{},
 	}
 
 	if err := r.db.AutoMigrate(tables...); err != nil {
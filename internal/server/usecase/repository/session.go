@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// CreateSession starts a session of the user on the device whose refresh token has the given ID.
+func (r *Repo) CreateSession(
+	ctx context.Context,
+	userID, refreshTokenID uuid.UUID,
+	device entity.Device,
+	expiresAt time.Time,
+) (entity.Session, error) {
+	now := time.Now().UTC()
+	sessionToDB := models.Session{
+		UserID:         userID,
+		RefreshTokenID: refreshTokenID,
+		DeviceName:     device.Name,
+		Platform:       device.Platform,
+		LastIP:         device.LastIP,
+		CreatedAt:      now,
+		LastUsedAt:     now,
+		ExpiresAt:      expiresAt,
+	}
+
+	if err := r.db.WithContext(ctx).Create(&sessionToDB).Error; err != nil {
+		return entity.Session{}, l.WrapErr(err)
+	}
+
+	return sessionToEntity(sessionToDB), nil
+}
+
+// GetSession returns the session by its ID. Returns ErrSessionRevoked if there is no such session.
+func (r *Repo) GetSession(ctx context.Context, sessionID uuid.UUID) (entity.Session, error) {
+	var sessionFromDB models.Session
+	if err := r.db.WithContext(ctx).First(&sessionFromDB, "id = ?", sessionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.Session{}, errs.ErrSessionRevoked
+		}
+		return entity.Session{}, l.WrapErr(err)
+	}
+
+	return sessionToEntity(sessionFromDB), nil
+}
+
+// GetSessions returns the active sessions of the user, the device seen most recently first.
+func (r *Repo) GetSessions(ctx context.Context, userID uuid.UUID) (sessions []entity.Session, err error) {
+	var sessionsFromDB []models.Session
+	if err = r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now().UTC()).
+		Order("last_used_at desc").
+		Find(&sessionsFromDB).Error; err != nil {
+		return nil, l.WrapErr(err)
+	}
+
+	sessions = make([]entity.Session, len(sessionsFromDB))
+	for index := range sessionsFromDB {
+		sessions[index] = sessionToEntity(sessionsFromDB[index])
+	}
+
+	return sessions, nil
+}
+
+// RotateSession replaces the refresh token of the session in a single transaction.
+// Only the current refresh token of the session may be rotated: presenting one rotated before
+// means it has leaked, so the whole session is revoked and ErrTokenReused is returned.
+// Returns ErrSessionRevoked if the session is revoked or has expired.
+func (r *Repo) RotateSession(
+	ctx context.Context,
+	sessionID, refreshTokenID, newRefreshTokenID uuid.UUID,
+	ip string,
+	expiresAt time.Time,
+) (session entity.Session, err error) {
+	var reused bool
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var sessionFromDB models.Session
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&sessionFromDB, "id = ?", sessionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrSessionRevoked
+			}
+			return l.WrapErr(err)
+		}
+
+		now := time.Now().UTC()
+		if !sessionToEntity(sessionFromDB).Active(now) {
+			return errs.ErrSessionRevoked
+		}
+
+		if sessionFromDB.RefreshTokenID != refreshTokenID {
+			reused = true
+			return l.WrapErr(tx.Model(&sessionFromDB).Update("revoked_at", now).Error)
+		}
+
+		sessionFromDB.RefreshTokenID = newRefreshTokenID
+		sessionFromDB.LastUsedAt = now
+		sessionFromDB.LastIP = ip
+		sessionFromDB.ExpiresAt = expiresAt
+		if err := tx.Save(&sessionFromDB).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		session = sessionToEntity(sessionFromDB)
+
+		return nil
+	})
+	if err == nil && reused {
+		err = errs.ErrTokenReused
+	}
+
+	return session, err
+}
+
+// TouchSession records that the device of the session has been seen now from the given IP address.
+func (r *Repo) TouchSession(ctx context.Context, sessionID uuid.UUID, ip string) error {
+	return l.WrapErr(r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id = ?", sessionID).
+		Updates(map[string]any{"last_used_at": time.Now().UTC(), "last_ip": ip}).Error)
+}
+
+// RenameSession renames the device of an active session of the user.
+// Returns ErrWrongOwnerOrNotFound if the user has no such active session.
+func (r *Repo) RenameSession(ctx context.Context, sessionID, userID uuid.UUID, name string) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("device_name", name)
+	if result.Error != nil {
+		return l.WrapErr(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errs.ErrWrongOwnerOrNotFound
+	}
+
+	return nil
+}
+
+// RevokeSession revokes the session of the user. Revoking a revoked session is not an error.
+func (r *Repo) RevokeSession(ctx context.Context, sessionID, userID uuid.UUID) error {
+	return l.WrapErr(r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now().UTC()).Error)
+}
+
+// sessionToEntity converts the session model to the entity.
+func sessionToEntity(sessionFromDB models.Session) entity.Session {
+	return entity.Session{
+		ID:     sessionFromDB.ID,
+		UserID: sessionFromDB.UserID,
+		Device: entity.Device{
+			Name:     sessionFromDB.DeviceName,
+			Platform: sessionFromDB.Platform,
+			LastIP:   sessionFromDB.LastIP,
+		},
+		CreatedAt:  sessionFromDB.CreatedAt,
+		LastUsedAt: sessionFromDB.LastUsedAt,
+		ExpiresAt:  sessionFromDB.ExpiresAt,
+		RevokedAt:  sessionFromDB.RevokedAt,
+	}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
+	"github.com/nextlag/keeper/internal/utils"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// SetTOTPSecret stores the secret of a TOTP second factor being enrolled; it is not required at sign in
+// until EnableTOTP confirms it. Returns ErrTOTPAlreadyEnabled if the user already has a second factor.
+func (r *Repo) SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userFromDB, err := lockTOTPUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if userFromDB.TOTPEnabled {
+			return errs.ErrTOTPAlreadyEnabled
+		}
+
+		return l.WrapErr(tx.Model(&userFromDB).Updates(map[string]any{
+			"totp_secret":       secret,
+			"totp_last_counter": 0,
+		}).Error)
+	})
+}
+
+// EnableTOTP confirms the enrolled secret with a code of it, requires it at sign in from now on
+// and replaces the recovery codes of the user with the given hashes.
+// Returns ErrTOTPNotEnrolled if there is no secret to confirm and ErrWrongTOTPCode if the code does not match.
+func (r *Repo) EnableTOTP(ctx context.Context, userID uuid.UUID, code string, recoveryCodeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userFromDB, err := lockTOTPUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if userFromDB.TOTPEnabled {
+			return errs.ErrTOTPAlreadyEnabled
+		}
+		if userFromDB.TOTPSecret == "" {
+			return errs.ErrTOTPNotEnrolled
+		}
+
+		counter, ok := utils.ValidTOTP(userFromDB.TOTPSecret, code, time.Now(), userFromDB.TOTPLastCounter)
+		if !ok {
+			return errs.ErrWrongTOTPCode
+		}
+		if err = tx.Model(&userFromDB).Updates(map[string]any{
+			"totp_enabled":      true,
+			"totp_last_counter": counter,
+		}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+
+		if err = tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		recoveryCodes := make([]models.RecoveryCode, len(recoveryCodeHashes))
+		for index, codeHash := range recoveryCodeHashes {
+			recoveryCodes[index] = models.RecoveryCode{UserID: userID, CodeHash: codeHash}
+		}
+
+		return l.WrapErr(tx.Create(&recoveryCodes).Error)
+	})
+}
+
+// VerifyTOTP checks a TOTP code of the user. An accepted code and the ones before it cannot be used again.
+// Returns ErrTOTPNotEnrolled if the user has no second factor and ErrWrongTOTPCode if the code does not match.
+func (r *Repo) VerifyTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userFromDB, err := lockTOTPUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if !userFromDB.TOTPEnabled {
+			return errs.ErrTOTPNotEnrolled
+		}
+
+		counter, ok := utils.ValidTOTP(userFromDB.TOTPSecret, code, time.Now(), userFromDB.TOTPLastCounter)
+		if !ok {
+			return errs.ErrWrongTOTPCode
+		}
+
+		return l.WrapErr(tx.Model(&userFromDB).Update("totp_last_counter", counter).Error)
+	})
+}
+
+// DisableTOTP turns the second factor of the user off with one of the recovery codes.
+// The secret and all recovery codes are removed. Returns ErrTOTPNotEnrolled if the user has
+// no second factor and ErrWrongRecoveryCode if no recovery code has the given hash.
+func (r *Repo) DisableTOTP(ctx context.Context, userID uuid.UUID, recoveryCodeHash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userFromDB, err := lockTOTPUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if !userFromDB.TOTPEnabled {
+			return errs.ErrTOTPNotEnrolled
+		}
+
+		var recoveryCode models.RecoveryCode
+		if err = tx.First(&recoveryCode, "user_id = ? AND code_hash = ?", userID, recoveryCodeHash).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.ErrWrongRecoveryCode
+			}
+			return l.WrapErr(err)
+		}
+
+		if err = tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return l.WrapErr(err)
+		}
+
+		return l.WrapErr(tx.Model(&userFromDB).Updates(map[string]any{
+			"totp_secret":       "",
+			"totp_enabled":      false,
+			"totp_last_counter": 0,
+		}).Error)
+	})
+}
+
+// lockTOTPUser locks the user row for the rest of the transaction and returns it.
+func lockTOTPUser(tx *gorm.DB, userID uuid.UUID) (userFromDB models.User, err error) {
+	if err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&userFromDB, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return userFromDB, errs.ErrWrongCredentials
+		}
+		return userFromDB, l.WrapErr(err)
+	}
+
+	return userFromDB, nil
+}
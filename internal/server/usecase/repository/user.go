@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/nextlag/keeper/internal/entity"
 	"github.com/nextlag/keeper/internal/server/usecase/repository/models"
@@ -13,11 +15,19 @@ import (
 	"github.com/nextlag/keeper/pkg/logger/l"
 )
 
-// AddUser inserts a new user into the database.
-func (r *Repo) AddUser(ctx context.Context, email, hashedPassword string) (user entity.User, err error) {
+// AddUser inserts a new user into the database together with the wrapped data key of the vault
+// and the recovery key, both empty if the vault is created later.
+func (r *Repo) AddUser(
+	ctx context.Context,
+	email, hashedPassword, dataKey, recoveryHash, recoveryKey string,
+) (user entity.User, err error) {
 	newUser := models.User{
-		Email:    email,
-		Password: hashedPassword,
+		Email:        email,
+		Password:     hashedPassword,
+		AuthHash:     true,
+		DataKey:      dataKey,
+		RecoveryKey:  recoveryKey,
+		RecoveryHash: recoveryHash,
 	}
 
 	result := r.db.WithContext(ctx).Create(&newUser)
@@ -50,15 +60,13 @@ func (r *Repo) GetUserByID(ctx context.Context, id string) (user entity.User, er
 
 	user.ID = userFromDB.ID
 	user.Email = userFromDB.Email
+	user.TOTPEnabled = userFromDB.TOTPEnabled
 	return
 }
 
-// GetUserByEmail retrieves a user from the database by their email address.
-// It uses the provided email to query the database and fetch the corresponding user record.
-// If no user is found with the given email, it returns ErrWrongCredentials.
-// It then verifies the provided hashed password against the stored password hash in the database.
-// If the passwords do not match, it also returns ErrWrongCredentials.
-// Upon successful retrieval and password verification, it populates and returns the user entity with ID and email.
+// GetUserByEmail retrieves the user with the email checked with the password hash.
+// Returns ErrWrongCredentials if there is no such user or the password does not match, and
+// ErrAuthUpgradeRequired if it matches a master password stored before the auth hash.
 func (r *Repo) GetUserByEmail(ctx context.Context, email, hashedPassword string) (user entity.User, err error) {
 	var userFromDB models.User
 	r.db.WithContext(ctx).Where("email = ?", email).First(&userFromDB)
@@ -73,7 +81,100 @@ func (r *Repo) GetUserByEmail(ctx context.Context, email, hashedPassword string)
 		return
 	}
 
+	if !userFromDB.AuthHash {
+		err = errs.ErrAuthUpgradeRequired
+		return
+	}
+
 	user.ID = userFromDB.ID
 	user.Email = userFromDB.Email
+	user.TOTPEnabled = userFromDB.TOTPEnabled
 	return
 }
+
+// RehashPassword replaces the password hash of the user with a hash of the same password with the given
+// parameters if it is a bcrypt hash or has other parameters. The password is checked against the stored hash
+// again under the row lock, so a password changed in the meantime is not overwritten.
+// Returns ErrWrongCredentials if the password does not match.
+func (r *Repo) RehashPassword(ctx context.Context, userID uuid.UUID, password string, params utils.PasswordParams) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var userFromDB models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "password").First(&userFromDB, "id = ?", userID).Error; err != nil {
+			return l.WrapErr(err)
+		}
+		if !utils.PasswordNeedsRehash(userFromDB.Password, params) {
+			return nil
+		}
+		if err := utils.VerifyPassword(userFromDB.Password, password); err != nil {
+			return errs.ErrWrongCredentials
+		}
+
+		hashedPassword, err := utils.HashPasswordWithParams(password, params)
+		if err != nil {
+			return l.WrapErr(err)
+		}
+		return l.WrapErr(tx.Model(&userFromDB).Update("password", hashedPassword).Error)
+	})
+}
+
+// UpgradeAuth replaces the hash of the master password with the hash of the client auth hash.
+// The master password is checked against the stored hash one last time. Returns ErrWrongCredentials
+// if the password does not match or the user has already been upgraded.
+func (r *Repo) UpgradeAuth(ctx context.Context, email, password, hashedAuthHash string) (user entity.User, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var userFromDB models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&userFromDB, "email = ?", email).Error; err != nil {
+			return errs.ErrWrongCredentials
+		}
+		if userFromDB.AuthHash {
+			return errs.ErrWrongCredentials
+		}
+		if err := utils.VerifyPassword(userFromDB.Password, password); err != nil {
+			return errs.ErrWrongCredentials
+		}
+
+		user.ID = userFromDB.ID
+		user.Email = userFromDB.Email
+		user.TOTPEnabled = userFromDB.TOTPEnabled
+		return l.WrapErr(tx.Model(&userFromDB).Updates(map[string]any{
+			"password":  hashedAuthHash,
+			"auth_hash": true,
+		}).Error)
+	})
+
+	return user, err
+}
+
+// GetDataKey retrieves the wrapped data key of the user.
+// An empty key means the vault has no data key yet.
+func (r *Repo) GetDataKey(ctx context.Context, userID uuid.UUID) (string, error) {
+	var userFromDB models.User
+	if err := r.db.WithContext(ctx).Select("data_key").First(&userFromDB, "id = ?", userID).Error; err != nil {
+		return "", l.WrapErr(err)
+	}
+
+	return userFromDB.DataKey, nil
+}
+
+// DeleteUser deletes the user with everything stored for them. The vault items, sessions, recovery codes
+// and personal tokens go with the user row by their cascading foreign keys, the staged rekey has none.
+// Returns ErrWrongOwnerOrNotFound if there is no such user.
+func (r *Repo) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Rekey{}, "user_id = ?", userID).Error; err != nil {
+			return l.WrapErr(err)
+		}
+
+		result := tx.Delete(&models.User{}, "id = ?", userID)
+		if result.Error != nil {
+			return l.WrapErr(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errs.ErrWrongOwnerOrNotFound
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+// revokedSessionPrefix prefixes the cache keys marking revoked sessions.
+const revokedSessionPrefix = "revoked-session:"
+
+// LogoutUser revokes the session the tokens belong to. The refresh token is tried first,
+// the access token is enough when the refresh token is not given.
+// Returns ErrTokenValidation if neither token is valid.
+func (uc *UseCase) LogoutUser(ctx context.Context, refreshToken, accessToken string) error {
+	claims, err := uc.refreshKeys.ValidSessionToken(refreshToken)
+	if err != nil {
+		claims, err = uc.accessKeys.ValidSessionToken(accessToken)
+	}
+	if err != nil {
+		return errs.ErrTokenValidation
+	}
+
+	return uc.revokeSession(ctx, claims.SessionID, claims.UserID)
+}
+
+// GetDevices returns the devices the user is signed in on, the one of the current session is marked.
+func (uc *UseCase) GetDevices(ctx context.Context, currentUser entity.User) ([]entity.Session, error) {
+	devices, err := uc.repo.GetSessions(ctx, currentUser.ID)
+	if err != nil {
+		return nil, l.WrapErr(err)
+	}
+	for index := range devices {
+		devices[index].Current = devices[index].ID == currentUser.SessionID
+	}
+
+	return devices, nil
+}
+
+// RenameDevice renames the device of the user.
+// Returns ErrWrongOwnerOrNotFound if the user is not signed in on such a device.
+func (uc *UseCase) RenameDevice(ctx context.Context, currentUser entity.User, deviceID uuid.UUID, name string) error {
+	return uc.repo.RenameSession(ctx, deviceID, currentUser.ID, name)
+}
+
+// RevokeDevice signs the user out on the device: the refresh and access tokens of its session
+// stop working at once, including the access tokens cached before.
+// Returns ErrWrongOwnerOrNotFound if the user is not signed in on such a device.
+func (uc *UseCase) RevokeDevice(ctx context.Context, currentUser entity.User, deviceID uuid.UUID) error {
+	session, err := uc.repo.GetSession(ctx, deviceID)
+	if errors.Is(err, errs.ErrSessionRevoked) {
+		return errs.ErrWrongOwnerOrNotFound
+	}
+	if err != nil {
+		return l.WrapErr(err)
+	}
+	if session.UserID != currentUser.ID || !session.Active(time.Now()) {
+		return errs.ErrWrongOwnerOrNotFound
+	}
+
+	return uc.revokeSession(ctx, session.ID, currentUser.ID)
+}
+
+// revokeSession revokes the session of the user on the server and marks it revoked in the cache,
+// so access tokens of the session cached before are rejected as well.
+func (uc *UseCase) revokeSession(ctx context.Context, sessionID, userID uuid.UUID) error {
+	if err := uc.repo.RevokeSession(ctx, sessionID, userID); err != nil {
+		return l.WrapErr(err)
+	}
+	uc.markRevoked(sessionID)
+	uc.events.publish(userID, entity.EventSessionRevoked, entity.SessionEvent{ID: sessionID})
+
+	return nil
+}
+
+// markRevoked marks the session revoked in the cache. The mark is set after every cached token
+// of the session and expires with the same default expiration, so it outlives them.
+func (uc *UseCase) markRevoked(sessionID uuid.UUID) {
+	uc.cache.Set(revokedSessionPrefix+sessionID.String(), true)
+}
+
+// sessionRevoked reports whether the session has been marked revoked in the cache.
+func (uc *UseCase) sessionRevoked(sessionID uuid.UUID) bool {
+	_, revoked := uc.cache.Get(revokedSessionPrefix + sessionID.String())
+	return revoked
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlag/keeper/internal/entity"
+	"github.com/nextlag/keeper/internal/utils"
+	"github.com/nextlag/keeper/internal/utils/errs"
+	"github.com/nextlag/keeper/pkg/logger/l"
+)
+
+const (
+	totpIssuer          = "Keeper"          // Issuer shown by authenticator apps.
+	recoveryCodeCount   = 10                // Number of recovery codes generated on enrollment.
+	totpChallengePrefix = "totp-challenge:" // Prefixes the cache keys of sign in challenges.
+	maxTOTPAttempts     = 5                 // Number of codes a challenge is checked with.
+)
+
+// totpChallenge is a sign in waiting for a TOTP code, kept in the cache until it expires.
+type totpChallenge struct {
+	user     entity.User
+	device   entity.Device
+	attempts int
+	used     bool
+}
+
+// signIn issues the tokens of a user whose password has been checked. A user with a second factor
+// gets a TOTP challenge instead, the tokens are issued by SignInTOTP.
+func (uc *UseCase) signIn(ctx context.Context, user entity.User, device entity.Device) (entity.JWT, error) {
+	if !user.TOTPEnabled {
+		return uc.issueTokens(ctx, user, device)
+	}
+
+	challenge := uuid.NewString()
+	uc.cache.Set(totpChallengePrefix+challenge, totpChallenge{user: user, device: device})
+
+	return entity.JWT{TOTPChallenge: challenge}, nil
+}
+
+// SignInTOTP completes the sign in of the challenge with a TOTP code and issues the tokens.
+// A challenge expires with the cache, is used once and survives a few wrong codes; it is only
+// accepted with the email of the user signing in, which the sign ins of the account are throttled by.
+// ErrTOTPChallenge is returned once it is gone and ErrWrongTOTPCode for a wrong code.
+func (uc *UseCase) SignInTOTP(ctx context.Context, email, challenge, code string) (token entity.JWT, err error) {
+	key := totpChallengePrefix + challenge
+	pending, err := uc.reserveTOTPAttempt(key, email)
+	if err != nil {
+		return token, err
+	}
+
+	if err = uc.repo.VerifyTOTP(ctx, pending.user.ID, code); err != nil {
+		if errors.Is(err, errs.ErrWrongTOTPCode) {
+			return token, err
+		}
+		return token, l.WrapErr(err)
+	}
+	if err = uc.useTOTPChallenge(key); err != nil {
+		return token, err
+	}
+
+	return uc.issueTokens(ctx, pending.user, pending.device)
+}
+
+// reserveTOTPAttempt counts an attempt of the challenge before its code is checked,
+// so concurrent attempts cannot get past the limit.
+func (uc *UseCase) reserveTOTPAttempt(key, email string) (totpChallenge, error) {
+	uc.totpMu.Lock()
+	defer uc.totpMu.Unlock()
+
+	value, found := uc.cache.Get(key)
+	pending, ok := value.(totpChallenge)
+	if !found || !ok || pending.used || pending.attempts >= maxTOTPAttempts ||
+		!strings.EqualFold(strings.TrimSpace(email), pending.user.Email) {
+		return pending, errs.ErrTOTPChallenge
+	}
+
+	pending.attempts++
+	uc.cache.Set(key, pending)
+
+	return pending, nil
+}
+
+// useTOTPChallenge marks the challenge used once its code has been accepted.
+// Only the first of concurrent attempts with a right code gets the tokens.
+func (uc *UseCase) useTOTPChallenge(key string) error {
+	uc.totpMu.Lock()
+	defer uc.totpMu.Unlock()
+
+	value, found := uc.cache.Get(key)
+	pending, ok := value.(totpChallenge)
+	if !found || !ok || pending.used {
+		return errs.ErrTOTPChallenge
+	}
+
+	pending.used = true
+	uc.cache.Set(key, pending)
+
+	return nil
+}
+
+// EnrollTOTP generates the secret of a new TOTP second factor of the user.
+// The second factor is not required at sign in until ConfirmTOTP confirms it with a code.
+func (uc *UseCase) EnrollTOTP(ctx context.Context, currentUser entity.User) (enrollment entity.TOTPEnrollment, err error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return enrollment, l.WrapErr(err)
+	}
+	if err = uc.repo.SetTOTPSecret(ctx, currentUser.ID, secret); err != nil {
+		return enrollment, l.WrapErr(err)
+	}
+
+	return entity.TOTPEnrollment{Secret: secret, URI: utils.TOTPURI(totpIssuer, currentUser.Email, secret)}, nil
+}
+
+// ConfirmTOTP turns the enrolled second factor on with a code of it and returns new recovery codes.
+// The codes are shown this one time, the server keeps only their hashes.
+func (uc *UseCase) ConfirmTOTP(ctx context.Context, currentUser entity.User, code string) (entity.RecoveryCodes, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return entity.RecoveryCodes{}, l.WrapErr(err)
+	}
+	hashes := make([]string, len(codes))
+	for index, recoveryCode := range codes {
+		hashes[index] = utils.HashRecoveryCode(recoveryCode)
+	}
+
+	if err = uc.repo.EnableTOTP(ctx, currentUser.ID, code, hashes); err != nil {
+		return entity.RecoveryCodes{}, l.WrapErr(err)
+	}
+
+	return entity.RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTOTP turns the second factor of the user off with the password and a recovery code,
+// for a user who has lost the authenticator and cannot sign in.
+func (uc *UseCase) DisableTOTP(ctx context.Context, email, password, recoveryCode string) error {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return errs.ErrWrongEmail
+	}
+
+	user, err := uc.repo.GetUserByEmail(ctx, email, password)
+	if err != nil {
+		return l.WrapErr(err)
+	}
+
+	return l.WrapErr(uc.repo.DisableTOTP(ctx, user.ID, utils.HashRecoveryCode(recoveryCode)))
+}
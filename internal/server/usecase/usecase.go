@@ -1,8 +1,13 @@
 package usecase
 
 import (
+	"fmt"
+	"sync"
+
 	config "github.com/nextlag/keeper/config/server"
+	"github.com/nextlag/keeper/internal/entity"
 	"github.com/nextlag/keeper/internal/server/usecase/repository"
+	"github.com/nextlag/keeper/internal/utils"
 	c "github.com/nextlag/keeper/pkg/cache"
 	"github.com/nextlag/keeper/pkg/logger/l"
 )
@@ -13,16 +18,48 @@ type UseCase struct {
 	cfg   *config.Config
 	cache c.Cacher // Cache interface for caching operations
 	log   *l.Logger
+
+	accessKeys  *utils.KeyRing // Keys access tokens are signed and verified with.
+	refreshKeys *utils.KeyRing // Keys refresh tokens are signed and verified with.
+
+	events *eventHub // Hub the events of the users are streamed to their devices through.
+
+	totpMu sync.Mutex // Serializes the updates of the TOTP challenges in the cache.
 }
 
 // New creates a new instance of UseCase with provided dependencies.
-func New(r repository.Repository, cfg *config.Config, cache c.Cacher, log *l.Logger) *UseCase {
+// It returns an error if the token keys of the configuration cannot be loaded.
+func New(r repository.Repository, cfg *config.Config, cache c.Cacher, log *l.Logger) (*UseCase, error) {
+	accessKeys, err := newKeyRing(cfg.Security.AccessTokenPrivateKey, cfg.Security.AccessTokenPublicKey,
+		cfg.Security.AccessTokenPreviousKeys)
+	if err != nil {
+		return nil, fmt.Errorf("access token keys: %w", err)
+	}
+	refreshKeys, err := newKeyRing(cfg.Security.RefreshTokenPrivateKey, cfg.Security.RefreshTokenPublicKey,
+		cfg.Security.RefreshTokenPreviousKeys)
+	if err != nil {
+		return nil, fmt.Errorf("refresh token keys: %w", err)
+	}
+
 	return &UseCase{
-		repo:  r,
-		cfg:   cfg,
-		cache: cache,
-		log:   log,
+		repo:        r,
+		cfg:         cfg,
+		cache:       cache,
+		log:         log,
+		accessKeys:  accessKeys,
+		refreshKeys: refreshKeys,
+		events:      newEventHub(),
+	}, nil
+}
+
+// newKeyRing creates the key ring of the current key pair and the previous keys of the configuration.
+func newKeyRing(privateKey, publicKey string, previousKeys []config.PreviousKey) (*utils.KeyRing, error) {
+	previous := make([]utils.VerificationKey, len(previousKeys))
+	for index, key := range previousKeys {
+		previous[index] = utils.VerificationKey{PublicKey: key.PublicKey, RetiresAt: key.RetiresAt}
 	}
+
+	return utils.NewKeyRing(privateKey, publicKey, previous...)
 }
 
 // HealthCheck performs a health check on the repository/database.
@@ -35,3 +72,9 @@ func (uc *UseCase) HealthCheck() error {
 func (uc *UseCase) GetDomainName() string {
 	return uc.cfg.Security.Domain
 }
+
+// JWKS returns the public keys access tokens are currently accepted with,
+// so other services can verify the tokens themselves.
+func (uc *UseCase) JWKS() entity.JWKS {
+	return uc.accessKeys.JWKS()
+}
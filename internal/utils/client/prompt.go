@@ -0,0 +1,47 @@
+package client
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	errEmptyPassword = errors.New("empty password")
+	errNotConfirmed  = errors.New("not confirmed")
+)
+
+// PromptPassword writes the prompt to out and reads a password line from in.
+func PromptPassword(in io.Reader, out io.Writer, prompt string) (string, error) {
+	fmt.Fprint(out, prompt)
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("PromptPassword - ReadString - %w", err)
+	}
+
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errEmptyPassword
+	}
+
+	return password, nil
+}
+
+// Confirm writes the prompt to out and reads a line from in, the answer confirms the action if it matches.
+func Confirm(in io.Reader, out io.Writer, prompt, answer string) error {
+	fmt.Fprint(out, prompt)
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("Confirm - ReadString - %w", err)
+	}
+
+	if strings.TrimSpace(line) != answer {
+		return errNotConfirmed
+	}
+
+	return nil
+}
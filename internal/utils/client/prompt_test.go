@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromptPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "unix line",
+			input:    "secret\n",
+			expected: "secret",
+		},
+		{
+			name:     "windows line",
+			input:    "secret\r\n",
+			expected: "secret",
+		},
+		{
+			name:     "no newline",
+			input:    "secret",
+			expected: "secret",
+		},
+		{
+			name:    "empty input",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			password, err := PromptPassword(strings.NewReader(tt.input), &out, "Password: ")
+			assert.Equal(t, "Password: ", out.String())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, password)
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			name:  "answer given",
+			input: "delete\n",
+		},
+		{
+			name:  "answer with spaces",
+			input: " delete \r\n",
+		},
+		{
+			name:    "other answer",
+			input:   "yes\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty input",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := Confirm(strings.NewReader(tt.input), &out, "Confirm: ", "delete")
+			assert.Equal(t, "Confirm: ", out.String())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
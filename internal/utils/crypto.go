@@ -1,199 +1,536 @@
 package utils
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
-	"log"
-	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
 )
 
-const keyLength = 32
+const (
+	keyLength = 32
 
-var errEmptyFile = errors.New("empty file has been given")
+	// envelopePrefix marks values produced by the versioned format.
+	// '$' is not part of the base64 URL alphabet, so a legacy headerless blob can never start with it.
+	envelopePrefix  = "$keeper$"
+	envelopeVersion = 1
+	// boundEnvelopeVersion marks an envelope sealed with associated data. The header and the
+	// associated data are authenticated along with the ciphertext, so the envelope opens
+	// only for the same data.
+	boundEnvelopeVersion = 2
 
-// getKeyFromPass generates a 32-byte key from a provided string.
-// If the input string is shorter than 32 bytes, it is padded.
-// If it is longer, it is truncated to 32 bytes.
-func getKeyFromPass(keyString string) []byte {
-	key := []byte(keyString)
+	kdfArgon2id = 1
+	// kdfDataKey marks data sealed with a random data key; the header names the key by its ID.
+	kdfDataKey = 2
 
-	if len(key) < keyLength {
-		for {
-			key = append(key, key[0])
-			if len(key) == keyLength {
-				break
+	dataKeyIDLength = 8
+
+	// legacyMinLength is the length of the shortest legacy blob: the nonce and the tag of AES-GCM.
+	legacyMinLength = 12 + 16
+
+	// Argon2id defaults follow the second recommended option of RFC 9106.
+	defaultKDFTime    = 3
+	defaultKDFMemory  = 64 * 1024
+	defaultKDFThreads = 4
+
+	// Limits of the Argon2id parameters read from an envelope header. The header comes with the data,
+	// so parameters outside of them are rejected instead of being run or crashing the derivation.
+	maxKDFTime   = 64
+	maxKDFMemory = 1024 * 1024
+)
+
+// Errors returned by the Cipher. Every error of a failed decryption matches one of
+// ErrAuthFailed, ErrMalformed or ErrUnsupportedVersion with errors.Is.
+var (
+	// ErrAuthFailed means the ciphertext did not authenticate: it is sealed with another key
+	// or has been modified.
+	ErrAuthFailed = errors.New("ciphertext authentication failed: wrong key or corrupted data")
+	// ErrMalformed means the value is not a well-formed ciphertext.
+	ErrMalformed = errors.New("malformed ciphertext")
+	// ErrUnsupportedVersion means the ciphertext is written by a format or with a key derivation
+	// function this version does not know.
+	ErrUnsupportedVersion = errors.New("unsupported ciphertext version")
+	// ErrTampered means a bound envelope does not open for the associated data it is read with:
+	// it belongs to another item or field, or it has been modified. It also matches ErrAuthFailed.
+	ErrTampered = errors.New("ciphertext does not belong to this field or has been tampered with")
+
+	errEmptyFile         = errors.New("empty file has been given")
+	errMalformedEnvelope = fmt.Errorf("%w: invalid envelope", ErrMalformed)
+	errShortHeader       = fmt.Errorf("%w: incomplete envelope header", ErrMalformed)
+	errMalformedDataKey  = fmt.Errorf("%w: invalid wrapped data key", ErrMalformed)
+	errMalformedKDF      = fmt.Errorf("%w: key derivation parameters out of range", ErrMalformed)
+	errUnsupportedKDF    = fmt.Errorf("%w: unknown key derivation function", ErrUnsupportedVersion)
+	errKeyUnavailable    = fmt.Errorf("%w: no key for the ciphertext parameters", ErrAuthFailed)
+)
+
+// KDFParams holds the Argon2id parameters used to derive an encryption key from the master password.
+type KDFParams struct {
+	Time    uint32 // Number of passes over the memory.
+	Memory  uint32 // Memory size in KiB.
+	Threads uint8  // Degree of parallelism.
+	Salt    []byte // Per-user salt.
+}
+
+// DefaultKDFParams returns the recommended Argon2id parameters with the given salt.
+func DefaultKDFParams(salt []byte) KDFParams {
+	return KDFParams{
+		Time:    defaultKDFTime,
+		Memory:  defaultKDFMemory,
+		Threads: defaultKDFThreads,
+		Salt:    salt,
+	}
+}
+
+// UserSalt returns the per-user salt for the given account email.
+// The salt is derived from the email, so every device of the same owner
+// derives the same key from the same master password.
+func UserSalt(email string) []byte {
+	sum := sha256.Sum256([]byte("keeper:" + strings.ToLower(strings.TrimSpace(email))))
+	return sum[:16]
+}
+
+// DeriveKey derives a 32-byte key from the password using Argon2id.
+func DeriveKey(password string, params KDFParams) []byte {
+	return argon2.IDKey([]byte(password), params.Salt, params.Time, params.Memory, params.Threads, keyLength)
+}
+
+// valid reports whether the parameters can be run by Argon2id within the limits: at least one pass
+// and one thread, at least 8 KiB of memory per thread, and neither passes nor memory above the caps.
+func (p KDFParams) valid() bool {
+	return p.Time >= 1 && p.Time <= maxKDFTime &&
+		p.Threads >= 1 &&
+		p.Memory >= 8*uint32(p.Threads) && p.Memory <= maxKDFMemory
+}
+
+// keyRef names the key an envelope is sealed with: a key derived from the master password
+// with the KDF parameters, or a data key with the ID. The serialized header identifies the key.
+type keyRef struct {
+	header []byte     // Envelope header: version, key kind and the kind-specific fields.
+	params *KDFParams // Parameters to derive the key from the password, nil for a data key.
+}
+
+// ref returns the reference to the key derived with the parameters.
+// The header holds the version, the KDF id, the parameters and the salt.
+func (p KDFParams) ref() keyRef {
+	buf := make([]byte, 0, 12+len(p.Salt))
+	buf = append(buf, envelopeVersion, kdfArgon2id)
+	buf = binary.BigEndian.AppendUint32(buf, p.Time)
+	buf = binary.BigEndian.AppendUint32(buf, p.Memory)
+	buf = append(buf, p.Threads, byte(len(p.Salt)))
+	buf = append(buf, p.Salt...)
+
+	return keyRef{header: buf, params: &p}
+}
+
+// cacheKey identifies the key of the reference. The version byte is left out,
+// so bound and unbound envelopes sealed with the same key share it.
+func (r keyRef) cacheKey() string {
+	return string(r.header[1:])
+}
+
+// bound returns the header of an envelope sealed with the key and associated data.
+func (r keyRef) bound() []byte {
+	header := bytes.Clone(r.header)
+	header[0] = boundEnvelopeVersion
+
+	return header
+}
+
+// parseHeader reads the envelope header and returns the key reference and the remaining payload.
+func parseHeader(data []byte) (ref keyRef, payload []byte, err error) {
+	length, err := headerLength(data)
+	if err != nil {
+		return ref, nil, err
+	}
+	if len(data) < length {
+		return ref, nil, errMalformedEnvelope
+	}
+	ref.header = bytes.Clone(data[:length])
+
+	if data[1] == kdfArgon2id {
+		saltLength := int(data[11])
+		ref.params = &KDFParams{
+			Time:    binary.BigEndian.Uint32(data[2:6]),
+			Memory:  binary.BigEndian.Uint32(data[6:10]),
+			Threads: data[10],
+			Salt:    bytes.Clone(data[12 : 12+saltLength]),
+		}
+		if !ref.params.valid() {
+			return ref, nil, errMalformedKDF
+		}
+	}
+
+	return ref, data[length:], nil
+}
+
+// headerLength returns the length of the envelope header at the start of the data.
+// It needs the fixed part of the header only and reports errShortHeader if that part is incomplete.
+func headerLength(data []byte) (int, error) {
+	if len(data) < 2 {
+		return 0, errShortHeader
+	}
+	if data[0] != envelopeVersion && data[0] != boundEnvelopeVersion {
+		return 0, fmt.Errorf("%w: %d", ErrUnsupportedVersion, data[0])
+	}
+
+	switch data[1] {
+	case kdfArgon2id:
+		if len(data) < 12 {
+			return 0, errShortHeader
+		}
+		return 12 + int(data[11]), nil
+	case kdfDataKey:
+		if len(data) < 3 {
+			return 0, errShortHeader
+		}
+		return 3 + int(data[2]), nil
+	default:
+		return 0, fmt.Errorf("%w: %d", errUnsupportedKDF, data[1])
+	}
+}
+
+// readHeader reads an envelope header from the stream.
+func readHeader(src io.Reader) ([]byte, error) {
+	header := make([]byte, 0, 64)
+	for {
+		length, err := headerLength(header)
+		if err == nil {
+			rest := make([]byte, length-len(header))
+			if _, err = io.ReadFull(src, rest); err != nil {
+				return nil, errMalformedEnvelope
 			}
+			return append(header, rest...), nil
 		}
-	} else if len(key) > keyLength {
-		key = key[:keyLength]
+		if !errors.Is(err, errShortHeader) {
+			return nil, err
+		}
+
+		next := make([]byte, 1)
+		if _, err = io.ReadFull(src, next); err != nil {
+			return nil, errMalformedEnvelope
+		}
+		header = append(header, next...)
 	}
+}
 
-	return key
+// IsEnvelope reports whether the value was produced by the versioned format.
+func IsEnvelope(encryptedString string) bool {
+	return strings.HasPrefix(encryptedString, envelopePrefix)
 }
 
-// Encrypt encrypts a string using AES-GCM with a key derived from keyString.
-// The encrypted string is returned in base64 URL encoding format.
-// If the input string is empty, it is returned as-is.
-func Encrypt(keyString, stringToEncrypt string) string {
-	if stringToEncrypt == "" {
-		return stringToEncrypt
+// IsLegacyCiphertext reports whether the value is well-formed in the legacy headerless format:
+// padded URL base64 long enough to hold the nonce and the tag. It does not tell whether the value opens.
+func IsLegacyCiphertext(encryptedString string) bool {
+	if IsEnvelope(encryptedString) {
+		return false
 	}
-	cipherBlock, err := aes.NewCipher(getKeyFromPass(keyString))
-	if err != nil {
-		log.Printf("Encrypt - aes.NewCipher - %v", err)
-		return ""
+	data, err := base64.URLEncoding.DecodeString(encryptedString)
+
+	return err == nil && len(data) >= legacyMinLength
+}
+
+// Cipher encrypts and decrypts vault data with a key derived from the master password
+// or with a data key. Derived keys are cached per parameter set, so the expensive derivation
+// runs once per process instead of once per field.
+type Cipher struct {
+	password string
+	sealRef  keyRef // Key that seals new data.
+
+	mu   sync.Mutex
+	keys map[string][]byte
+}
+
+// NewCipher creates a Cipher that seals new data with the given KDF parameters.
+func NewCipher(password string, params KDFParams) *Cipher {
+	return &Cipher{
+		password: password,
+		sealRef:  params.ref(),
+		keys:     make(map[string][]byte),
 	}
+}
 
-	aead, err := cipher.NewGCM(cipherBlock)
-	if err != nil {
-		log.Printf("Encrypt - cipher.NewGCM - %v", err)
-		return ""
+// NewKeyCipher creates a Cipher from a key already derived with the given KDF parameters.
+// Without the password it can only open data sealed with the same parameters.
+func NewKeyCipher(key []byte, params KDFParams) *Cipher {
+	c := NewCipher("", params)
+	c.keys[c.sealRef.cacheKey()] = bytes.Clone(key)
+	return c
+}
+
+// NewDataKeyCipher creates a Cipher that seals and opens data with the data key only.
+func NewDataKeyCipher(dataKey *DataKey) *Cipher {
+	return (&Cipher{keys: make(map[string][]byte)}).WithDataKey(dataKey)
+}
+
+// WithDataKey returns a Cipher that seals new data with the data key.
+// It still opens everything c opens, so data sealed before the switch stays readable.
+func (c *Cipher) WithDataKey(dataKey *DataKey) *Cipher {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	withKey := &Cipher{
+		password: c.password,
+		sealRef:  dataKey.ref(),
+		keys:     make(map[string][]byte, len(c.keys)+1),
 	}
+	for cacheKey, key := range c.keys {
+		withKey.keys[cacheKey] = key
+	}
+	withKey.keys[withKey.sealRef.cacheKey()] = bytes.Clone(dataKey.Key)
 
-	nonce := make([]byte, aead.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		log.Printf("Encrypt - io.ReadFull(rand.Reader, nonce) - %v", err)
-		return ""
+	return withKey
+}
+
+// key returns the key the reference names, deriving a password key on first use.
+func (c *Cipher) key(ref keyRef) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cacheKey := ref.cacheKey()
+	if key, ok := c.keys[cacheKey]; ok {
+		return key, nil
+	}
+	if c.password == "" || ref.params == nil {
+		return nil, errKeyUnavailable
 	}
+	key := DeriveKey(c.password, *ref.params)
+	c.keys[cacheKey] = key
 
-	return base64.URLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(stringToEncrypt), nil))
+	return key, nil
 }
 
-// Decrypt decrypts a base64 URL encoded AES-GCM encrypted string.
-// The decrypted string is returned. If the input string is empty, it is returned as-is.
-func Decrypt(keyString, encryptedString string) (decryptedString string) {
+// NeedsUpgrade reports whether the value is stored in the legacy format
+// or sealed with a key other than the one that seals new data.
+func (c *Cipher) NeedsUpgrade(encryptedString string) bool {
+	return c.needsUpgrade(encryptedString, c.sealRef.header)
+}
+
+// NeedsUpgradeBound reports whether the value is stored in the legacy format, is not bound
+// to associated data or is sealed with a key other than the one that seals new data.
+func (c *Cipher) NeedsUpgradeBound(encryptedString string) bool {
+	return c.needsUpgrade(encryptedString, c.sealRef.bound())
+}
+
+// needsUpgrade reports whether the header of the value differs from the expected one.
+func (c *Cipher) needsUpgrade(encryptedString string, header []byte) bool {
 	if encryptedString == "" {
-		return encryptedString
+		return false
+	}
+	if !IsEnvelope(encryptedString) {
+		return true
 	}
-	encryptData, err := base64.URLEncoding.DecodeString(encryptedString)
+	data, err := base64.URLEncoding.DecodeString(strings.TrimPrefix(encryptedString, envelopePrefix))
 	if err != nil {
-		log.Println(err)
-		return
+		return true
+	}
+	ref, _, err := parseHeader(data)
+	if err != nil {
+		return true
 	}
 
-	cipherBlock, err := aes.NewCipher(getKeyFromPass(keyString))
+	return !bytes.Equal(ref.header, header)
+}
+
+// seal encrypts the data and returns the envelope bytes.
+// With associated data the envelope is bound to it, see boundEnvelopeVersion.
+func (c *Cipher) seal(plainData, associatedData []byte) ([]byte, error) {
+	key, err := c.key(c.sealRef)
 	if err != nil {
-		log.Printf("Decrypt - aes.NewCipher - %v", err)
-		return ""
+		return nil, err
 	}
 
-	aead, err := cipher.NewGCM(cipherBlock)
+	aead, err := newAEAD(key)
 	if err != nil {
-		log.Printf("Decrypt - cipher.NewGCM - %v", err)
-		return ""
+		return nil, err
 	}
 
-	nonceSize := aead.NonceSize()
-	if len(encryptData) < nonceSize {
-		log.Printf("Decrypt - aead.NonceSize - %v", err)
-		return ""
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("io.ReadFull(rand.Reader, nonce) - %w", err)
 	}
 
-	nonce, cipherText := encryptData[:nonceSize], encryptData[nonceSize:]
-	plainData, err := aead.Open(nil, nonce, cipherText, nil)
-	if err != nil {
-		log.Printf("Decrypt - aead.Open - %v", err)
-		return ""
+	header, additionalData := c.sealRef.header, []byte(nil)
+	if associatedData != nil {
+		header = c.sealRef.bound()
+		additionalData = append(bytes.Clone(header), associatedData...)
 	}
+	envelope := bytes.Clone(header)
+	envelope = append(envelope, nonce...)
 
-	return string(plainData)
+	return aead.Seal(envelope, nonce, plainData, additionalData), nil
 }
 
-// EncryptFile encrypts the contents of a file using AES-GCM and writes the result
-// to a new file. The key for encryption is derived from keyString.
-// If the input file is empty, an error is returned.
-func EncryptFile(keyString, inputFilePath, outputFilePath string) error {
-	fi, err := os.Stat(inputFilePath)
+// open decrypts envelope bytes. A bound envelope opens only with the associated data
+// it was sealed with; an unbound one written before binding opens with any.
+func (c *Cipher) open(envelope, associatedData []byte) ([]byte, error) {
+	ref, payload, err := parseHeader(envelope)
 	if err != nil {
-		return fmt.Errorf("EncryptFile - os.Stat - %w", err)
-	}
-	if size := fi.Size(); size == 0 {
-		return fmt.Errorf("EncryptFile - fi.Size - %w", errEmptyFile)
+		return nil, err
 	}
-	cipherBlock, err := aes.NewCipher(getKeyFromPass(keyString))
+
+	key, err := c.key(ref)
 	if err != nil {
-		return fmt.Errorf("EncryptFile - aes.NewCipher - %w", err)
+		return nil, err
 	}
 
-	aead, err := cipher.NewGCM(cipherBlock)
+	aead, err := newAEAD(key)
 	if err != nil {
-		return fmt.Errorf("EncryptFile - cipher.NewGCM - %w", err)
+		return nil, err
 	}
 
-	nonce := make([]byte, aead.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return fmt.Errorf("EncryptFile - io.ReadFull(rand.Reader, nonce) - %w", err)
+	if ref.header[0] != boundEnvelopeVersion {
+		return openAEAD(aead, payload, nil)
+	}
+	plainData, err := openAEAD(aead, payload, append(bytes.Clone(ref.header), associatedData...))
+	if errors.Is(err, ErrAuthFailed) {
+		return nil, fmt.Errorf("%w: %w", ErrTampered, err)
 	}
-	inputData, err := os.ReadFile(inputFilePath)
 	if err != nil {
-		return fmt.Errorf("EncryptFile - os.ReadFile - %w", err)
+		return nil, err
 	}
 
-	outputFile, err := os.Create(outputFilePath)
+	return plainData, nil
+}
+
+// openLegacy decrypts a headerless blob sealed with the password-padding key of the first format.
+func (c *Cipher) openLegacy(encryptData []byte) ([]byte, error) {
+	if c.password == "" {
+		return nil, errKeyUnavailable
+	}
+
+	aead, err := newAEAD(getKeyFromPass(c.password))
 	if err != nil {
-		return fmt.Errorf("EncryptFile - os.Create - %w", err)
+		return nil, err
 	}
 
-	defer outputFile.Close()
+	return openAEAD(aead, encryptData, nil)
+}
 
-	_, err = outputFile.WriteString(
-		base64.URLEncoding.EncodeToString(aead.Seal(nonce, nonce, inputData, nil)))
+// Encrypt encrypts a string using AES-GCM and returns it as a versioned envelope.
+// If the input string is empty, it is returned as-is.
+func (c *Cipher) Encrypt(stringToEncrypt string) (string, error) {
+	return c.EncryptBound(stringToEncrypt, nil)
+}
+
+// EncryptBound encrypts a string like Encrypt and binds the envelope to the associated data,
+// so it decrypts only with the same data. A nil associated data leaves the envelope unbound.
+func (c *Cipher) EncryptBound(stringToEncrypt string, associatedData []byte) (string, error) {
+	if stringToEncrypt == "" {
+		return stringToEncrypt, nil
+	}
+
+	envelope, err := c.seal([]byte(stringToEncrypt), associatedData)
 	if err != nil {
-		return fmt.Errorf("EncryptFile - base64.URLEncoding.EncodeToString - %w", err)
+		return "", fmt.Errorf("Encrypt - seal - %w", err)
 	}
 
-	return nil
+	return envelopePrefix + base64.URLEncoding.EncodeToString(envelope), nil
+}
+
+// Decrypt decrypts a versioned envelope or a legacy headerless base64 blob.
+// A value that fails to decrypt is an error matching ErrAuthFailed, ErrMalformed
+// or ErrUnsupportedVersion, never an empty string.
+// If the input string is empty, it is returned as-is.
+func (c *Cipher) Decrypt(encryptedString string) (string, error) {
+	return c.DecryptBound(encryptedString, nil)
 }
 
-// DecryptFile decrypts a base64 URL encoded AES-GCM encrypted file and writes
-// the result to a new file. The key for decryption is derived from keyString.
-func DecryptFile(keyString, encryptedPath, decryptedFilePath string) error {
-	encryptedData, err := os.ReadFile(encryptedPath)
+// DecryptBound decrypts a value encrypted by EncryptBound with the same associated data.
+// A bound envelope read with other associated data fails with ErrTampered. Values written
+// before binding, unbound envelopes and legacy blobs, still decrypt.
+// If the input string is empty, it is returned as-is.
+func (c *Cipher) DecryptBound(encryptedString string, associatedData []byte) (string, error) {
+	if encryptedString == "" {
+		return encryptedString, nil
+	}
+
+	plainData, err := c.decrypt([]byte(encryptedString), associatedData)
 	if err != nil {
-		return fmt.Errorf("DecryptFile - os.ReadFile - %w", err)
+		return "", err
+	}
+
+	return string(plainData), nil
+}
+
+// decrypt detects the format of the encoded data and decrypts it.
+func (c *Cipher) decrypt(encoded, associatedData []byte) ([]byte, error) {
+	isEnvelope := bytes.HasPrefix(encoded, []byte(envelopePrefix))
+	if isEnvelope {
+		encoded = encoded[len(envelopePrefix):]
 	}
 
-	encryptData, err := base64.URLEncoding.DecodeString(string(encryptedData))
+	encryptData, err := base64.URLEncoding.DecodeString(string(encoded))
 	if err != nil {
-		return fmt.Errorf("DecryptFile - base64.URLEncoding.DecodeString - %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
 	}
 
-	cipherBlock, err := aes.NewCipher(getKeyFromPass(keyString))
+	if isEnvelope {
+		return c.open(encryptData, associatedData)
+	}
+	return c.openLegacy(encryptData)
+}
+
+// newAEAD creates an AES-GCM AEAD for the key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	cipherBlock, err := aes.NewCipher(key)
 	if err != nil {
-		return fmt.Errorf("DecryptFile - aes.NewCipher - %w", err)
+		return nil, fmt.Errorf("aes.NewCipher - %w", err)
 	}
 
 	aead, err := cipher.NewGCM(cipherBlock)
 	if err != nil {
-		return fmt.Errorf("DecryptFile - cipher.NewGCM - %w", err)
+		return nil, fmt.Errorf("cipher.NewGCM - %w", err)
 	}
 
+	return aead, nil
+}
+
+// openAEAD splits the nonce from the ciphertext and opens it with the additional data.
+func openAEAD(aead cipher.AEAD, encryptData, additionalData []byte) ([]byte, error) {
 	nonceSize := aead.NonceSize()
 	if len(encryptData) < nonceSize {
-		return fmt.Errorf("DecryptFile - aead.NonceSize - %w", err)
+		return nil, errMalformedEnvelope
 	}
 
 	nonce, cipherText := encryptData[:nonceSize], encryptData[nonceSize:]
-	plainData, err := aead.Open(nil, nonce, cipherText, nil)
+	plainData, err := aead.Open(nil, nonce, cipherText, additionalData)
 	if err != nil {
-		return fmt.Errorf("DecryptFile - aead.Open - %w", err)
+		return nil, ErrAuthFailed
 	}
-	outputFile, err := os.Create(decryptedFilePath)
-	if err != nil {
-		return fmt.Errorf("DecryptFile - os.Create - %w", err)
+
+	return plainData, nil
+}
+
+// getKeyFromPass generates a 32-byte key from a provided string.
+// It is the key schedule of the legacy headerless format and is only used to read old data:
+// if the input string is shorter than 32 bytes, it is padded,
+// if it is longer, it is truncated to 32 bytes.
+func getKeyFromPass(keyString string) []byte {
+	key := []byte(keyString)
+	if len(key) == 0 {
+		return make([]byte, keyLength)
 	}
 
-	defer outputFile.Close()
-	_, err = outputFile.WriteString(
-		string(plainData))
-	if err != nil {
-		return fmt.Errorf("DecryptFile - outputFile.WriteString - %w", err)
+	if len(key) < keyLength {
+		for {
+			key = append(key, key[0])
+			if len(key) == keyLength {
+				break
+			}
+		}
+	} else if len(key) > keyLength {
+		key = key[:keyLength]
 	}
 
-	return nil
+	return key
 }
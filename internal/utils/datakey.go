@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// DataKey is the random key that encrypts the vault.
+// It is kept wrapped by the key derived from the master password, so a password change
+// only re-wraps the data key instead of re-encrypting every item.
+type DataKey struct {
+	ID  []byte // Identifier written into the header of every envelope sealed with the key.
+	Key []byte // Key material.
+}
+
+// NewDataKey generates a random data key with a random ID.
+func NewDataKey() (*DataKey, error) {
+	dataKey := &DataKey{
+		ID:  make([]byte, dataKeyIDLength),
+		Key: make([]byte, keyLength),
+	}
+	if _, err := io.ReadFull(rand.Reader, dataKey.ID); err != nil {
+		return nil, fmt.Errorf("NewDataKey - io.ReadFull - %w", err)
+	}
+	if _, err := io.ReadFull(rand.Reader, dataKey.Key); err != nil {
+		return nil, fmt.Errorf("NewDataKey - io.ReadFull - %w", err)
+	}
+
+	return dataKey, nil
+}
+
+// ref returns the reference to the data key.
+// The header holds the version, the data key kind and the key ID.
+func (k *DataKey) ref() keyRef {
+	header := make([]byte, 0, 3+len(k.ID))
+	header = append(header, envelopeVersion, kdfDataKey, byte(len(k.ID)))
+	header = append(header, k.ID...)
+
+	return keyRef{header: header}
+}
+
+// Wrap seals the data key with the master cipher and returns it as an envelope string.
+func (k *DataKey) Wrap(master *Cipher) (string, error) {
+	plainData := make([]byte, 0, 1+len(k.ID)+len(k.Key))
+	plainData = append(plainData, byte(len(k.ID)))
+	plainData = append(plainData, k.ID...)
+	plainData = append(plainData, k.Key...)
+
+	envelope, err := master.seal(plainData, nil)
+	if err != nil {
+		return "", fmt.Errorf("DataKey - Wrap - %w", err)
+	}
+
+	return envelopePrefix + base64.URLEncoding.EncodeToString(envelope), nil
+}
+
+// UnwrapDataKey opens a data key wrapped by Wrap with the master cipher.
+func UnwrapDataKey(wrapped string, master *Cipher) (*DataKey, error) {
+	if !IsEnvelope(wrapped) {
+		return nil, errMalformedDataKey
+	}
+
+	plainData, err := master.decrypt([]byte(wrapped), nil)
+	if err != nil {
+		return nil, fmt.Errorf("UnwrapDataKey - %w", err)
+	}
+	if len(plainData) < 1 || len(plainData) != 1+int(plainData[0])+keyLength {
+		return nil, errMalformedDataKey
+	}
+	idLength := int(plainData[0])
+
+	return &DataKey{
+		ID:  bytes.Clone(plainData[1 : 1+idLength]),
+		Key: bytes.Clone(plainData[1+idLength:]),
+	}, nil
+}
+
+// SubKey derives a key for a separate purpose from the key the cipher seals with.
+// The info string names the purpose; the sealing key cannot be recovered from the derived one.
+// The ID of the derived key is a hash of the key, so the same key always gets the same ID.
+func (c *Cipher) SubKey(info string) (*DataKey, error) {
+	key, err := c.key(c.sealRef)
+	if err != nil {
+		return nil, fmt.Errorf("SubKey - %w", err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(info))
+	subKey := mac.Sum(nil)
+	keyID := sha256.Sum256(subKey)
+
+	return &DataKey{ID: keyID[:dataKeyIDLength], Key: subKey}, nil
+}
@@ -0,0 +1,93 @@
+package utils_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+func TestDataKeyWrap(t *testing.T) {
+	dataKey, err := utils.NewDataKey()
+	require.NoError(t, err)
+
+	master := utils.NewCipher("secretKey", testKDFParams("user@example.com"))
+	wrapped, err := dataKey.Wrap(master)
+	require.NoError(t, err)
+
+	unwrapped, err := utils.UnwrapDataKey(wrapped, utils.NewCipher("secretKey", testKDFParams("user@example.com")))
+	require.NoError(t, err)
+	require.Equal(t, dataKey, unwrapped)
+
+	_, err = utils.UnwrapDataKey(wrapped, utils.NewCipher("wrongKey", testKDFParams("user@example.com")))
+	require.Error(t, err)
+	_, err = utils.UnwrapDataKey(encrypt(t, master, phrase), master)
+	require.Error(t, err)
+}
+
+func TestDataKeyCipher(t *testing.T) {
+	dataKey, err := utils.NewDataKey()
+	require.NoError(t, err)
+
+	master := utils.NewCipher("secretKey", testKDFParams("user@example.com"))
+	sealedWithPassword := encrypt(t, master, phrase)
+
+	c := master.WithDataKey(dataKey)
+	encryptedString := encrypt(t, c, phrase)
+	require.False(t, c.NeedsUpgrade(encryptedString))
+	require.True(t, c.NeedsUpgrade(sealedWithPassword))
+	require.Equal(t, phrase, decrypt(t, c, sealedWithPassword))
+	require.Equal(t, phrase, decrypt(t, c, legacyPhrase))
+
+	dataKeyOnly := utils.NewDataKeyCipher(dataKey)
+	require.Equal(t, phrase, decrypt(t, dataKeyOnly, encryptedString))
+	_, err = dataKeyOnly.Decrypt(sealedWithPassword)
+	require.ErrorIs(t, err, utils.ErrAuthFailed)
+
+	otherKey, err := utils.NewDataKey()
+	require.NoError(t, err)
+	_, err = utils.NewDataKeyCipher(otherKey).Decrypt(encryptedString)
+	require.ErrorIs(t, err, utils.ErrAuthFailed)
+}
+
+func TestDataKeyStream(t *testing.T) {
+	dataKey, err := utils.NewDataKey()
+	require.NoError(t, err)
+	c := utils.NewDataKeyCipher(dataKey)
+
+	plainData := bytes.Repeat([]byte(phrase), 10000)
+	encrypted, err := io.ReadAll(c.EncryptReader(bytes.NewReader(plainData)))
+	require.NoError(t, err)
+
+	decrypted, err := c.DecryptStream(bytes.NewReader(encrypted))
+	require.NoError(t, err)
+	result, err := io.ReadAll(decrypted)
+	require.NoError(t, err)
+	require.Equal(t, plainData, result)
+}
+
+func TestSubKey(t *testing.T) {
+	dataKey, err := utils.NewDataKey()
+	require.NoError(t, err)
+	vault := utils.NewDataKeyCipher(dataKey)
+
+	subKey, err := vault.SubKey("cache")
+	require.NoError(t, err)
+	require.NotEqual(t, dataKey.Key, subKey.Key)
+
+	again, err := utils.NewDataKeyCipher(dataKey).SubKey("cache")
+	require.NoError(t, err)
+	require.Equal(t, subKey, again)
+
+	other, err := vault.SubKey("other")
+	require.NoError(t, err)
+	require.NotEqual(t, subKey.ID, other.ID)
+
+	encrypted := encrypt(t, utils.NewDataKeyCipher(subKey), phrase)
+	_, err = vault.Decrypt(encrypted)
+	require.Error(t, err)
+	require.Equal(t, phrase, decrypt(t, utils.NewDataKeyCipher(again), encrypted))
+}
@@ -16,6 +16,23 @@ var (
 	ErrTokenValidation      = errors.New("token validation error")
 	ErrUnexpectedError      = errors.New("some unexpected error")
 	ErrWrongOwnerOrNotFound = errors.New("wrong owner or not found")
+	ErrRekeyIncomplete      = errors.New("re-encrypted vault does not match the stored one")
+	ErrAuthUpgradeRequired  = errors.New("account has to upgrade the authentication scheme")
+	ErrSessionRevoked       = errors.New("session has been revoked or has expired")
+	ErrTokenReused          = errors.New("refresh token has already been used, the session has been revoked")
+	ErrWrongTOTPCode        = errors.New("wrong two-factor code")
+	ErrWrongRecoveryCode    = errors.New("wrong recovery code")
+	ErrTOTPChallenge        = errors.New("two-factor challenge has expired or is unknown")
+	ErrTOTPAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnrolled      = errors.New("two-factor authentication has not been enrolled")
+	ErrTooManyAttempts      = errors.New("too many attempts")
+	ErrOutOfScope           = errors.New("request is out of the scope of the personal token")
+	ErrWrongRecoveryKey     = errors.New("wrong recovery key or no recovery key has been set")
+	ErrRevisionConflict     = errors.New("item has been changed since the base revision")
+	ErrNoStoredResponse     = errors.New("no request has been completed with the idempotency key")
+	ErrRequestInProgress    = errors.New("request with the idempotency key is still in progress")
+	ErrIdempotencyKeyReused = errors.New("idempotency key has been used for another request")
+	ErrOperationRejected    = errors.New("queued operation has been rejected by the server")
 )
 
 // GormErr represents an error structure typically returned by GORM.
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/nextlag/keeper/internal/entity"
+)
+
+// VerificationKey is a previous public key a key ring still accepts tokens of until RetiresAt.
+// A zero RetiresAt never retires the key.
+type VerificationKey struct {
+	PublicKey string    // Base64 encoded PEM RSA public key.
+	RetiresAt time.Time // Time tokens of the key stop being accepted.
+}
+
+// KeyRing signs tokens with its current RSA key and verifies them with any of its keys that has not retired,
+// so the signing key can be rotated without invalidating the tokens issued before.
+// Tokens carry the ID of their key in the kid header, the ID is the RFC 7638 thumbprint of the public key.
+type KeyRing struct {
+	signingKey   *rsa.PrivateKey
+	signingKeyID string
+	keys         map[string]ringKey // Verification keys by ID, the current one included.
+	now          func() time.Time
+}
+
+// ringKey is a verification key of a key ring.
+type ringKey struct {
+	public    *rsa.PublicKey
+	retiresAt time.Time
+}
+
+// NewKeyRing creates a key ring signing with the current base64 encoded PEM key pair
+// and accepting the tokens of the previous keys as well.
+func NewKeyRing(privateKey, publicKey string, previous ...VerificationKey) (*KeyRing, error) {
+	signingKey, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	currentKey, err := parsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !signingKey.PublicKey.Equal(currentKey) {
+		return nil, fmt.Errorf("%w: the public key does not match the private key", errToken)
+	}
+
+	ring := &KeyRing{
+		signingKey:   signingKey,
+		signingKeyID: keyID(currentKey),
+		keys:         map[string]ringKey{keyID(currentKey): {public: currentKey}},
+		now:          time.Now,
+	}
+	for _, key := range previous {
+		previousKey, err := parsePublicKey(key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ring.keys[keyID(previousKey)]; ok {
+			continue
+		}
+		ring.keys[keyID(previousKey)] = ringKey{public: previousKey, retiresAt: key.RetiresAt}
+	}
+
+	return ring, nil
+}
+
+// CreateSessionToken creates a token of the session like the CreateSessionToken function does,
+// signed with the current key and carrying its ID.
+func (kr *KeyRing) CreateSessionToken(ttl time.Duration, sessionClaims SessionClaims) (string, error) {
+	return signTokenWithKey(ttl, sessionTokenClaims(sessionClaims), kr.signingKey, kr.signingKeyID)
+}
+
+// ValidSessionToken checks the validity of a session token and returns its claims.
+// The token is verified with the key named by its kid header, which must not have retired.
+// A token without the header, issued before the key ring, is tried with every key that has not retired.
+func (kr *KeyRing) ValidSessionToken(token string) (SessionClaims, error) {
+	claims, err := kr.parseToken(token)
+	if err != nil {
+		return SessionClaims{}, err
+	}
+
+	return sessionClaimsOf(claims)
+}
+
+// JWKS returns the keys tokens are currently accepted with as a JSON Web Key Set,
+// the current key first and the previous ones ordered by ID.
+func (kr *KeyRing) JWKS() entity.JWKS {
+	jwks := entity.JWKS{Keys: make([]entity.JWK, 0, len(kr.keys))}
+	for id, key := range kr.keys {
+		if kr.retired(key) {
+			continue
+		}
+		jwk := jwkOf(key.public)
+		jwk.Kid = id
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	slices.SortFunc(jwks.Keys, func(a, b entity.JWK) int {
+		switch {
+		case a.Kid == kr.signingKeyID:
+			return -1
+		case b.Kid == kr.signingKeyID:
+			return 1
+		default:
+			return strings.Compare(a.Kid, b.Kid)
+		}
+	})
+
+	return jwks
+}
+
+// keyID returns the ID of the public key, its RFC 7638 JWK thumbprint.
+func keyID(key *rsa.PublicKey) string {
+	jwk := jwkOf(key)
+	// The members required for an RSA key in lexicographic order, as the thumbprint is defined.
+	thumbprintInput, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{E: jwk.E, Kty: jwk.Kty, N: jwk.N})
+	sum := sha256.Sum256(thumbprintInput)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// parseToken verifies the token with the key of its kid header, or with every key if it has none.
+func (kr *KeyRing) parseToken(token string) (jwt.MapClaims, error) {
+	unverified, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+
+	if keyID, ok := unverified.Header["kid"].(string); ok {
+		key, found := kr.keys[keyID]
+		if !found || kr.retired(key) {
+			return nil, fmt.Errorf("%w: validate: unknown or retired key %q", errToken, keyID)
+		}
+		return parseTokenWithKey(token, key.public)
+	}
+
+	err = fmt.Errorf("%w: validate: no key", errToken)
+	for _, key := range kr.keys {
+		if kr.retired(key) {
+			continue
+		}
+		var claims jwt.MapClaims
+		if claims, err = parseTokenWithKey(token, key.public); err == nil {
+			return claims, nil
+		}
+	}
+
+	return nil, err
+}
+
+// retired reports whether tokens of the key are no longer accepted.
+func (kr *KeyRing) retired(key ringKey) bool {
+	return !key.retiresAt.IsZero() && !kr.now().Before(key.retiresAt)
+}
+
+// jwkOf returns the public key as a JSON Web Key without its ID.
+func jwkOf(key *rsa.PublicKey) entity.JWK {
+	return entity.JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: jwt.SigningMethodRS256.Alg(),
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
@@ -0,0 +1,116 @@
+package utils_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+// newKeyPair returns a new RSA key pair encoded like the keys of the configuration.
+func newKeyPair(t *testing.T) (privateKey, publicKey string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	return base64.StdEncoding.EncodeToString(privatePEM), base64.StdEncoding.EncodeToString(publicPEM)
+}
+
+// tokenKeyID returns the kid header of the token.
+func tokenKeyID(t *testing.T, token string) string {
+	t.Helper()
+
+	header, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	require.NoError(t, err)
+	var fields struct {
+		Kid string `json:"kid"`
+	}
+	require.NoError(t, json.Unmarshal(header, &fields))
+
+	return fields.Kid
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	oldPrivate, oldPublic := newKeyPair(t)
+	newPrivate, newPublic := newKeyPair(t)
+	claims := utils.SessionClaims{UserID: uuid.New(), SessionID: uuid.New(), TokenID: uuid.New()}
+
+	oldRing, err := utils.NewKeyRing(oldPrivate, oldPublic)
+	require.NoError(t, err)
+	oldToken, err := oldRing.CreateSessionToken(time.Hour, claims)
+	require.NoError(t, err)
+	legacyToken, err := utils.CreateSessionToken(time.Hour, claims, oldPrivate)
+	require.NoError(t, err)
+	require.Empty(t, tokenKeyID(t, legacyToken))
+
+	ring, err := utils.NewKeyRing(newPrivate, newPublic,
+		utils.VerificationKey{PublicKey: oldPublic, RetiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	newToken, err := ring.CreateSessionToken(time.Hour, claims)
+	require.NoError(t, err)
+	require.NotEqual(t, tokenKeyID(t, oldToken), tokenKeyID(t, newToken))
+
+	for _, token := range []string{newToken, oldToken, legacyToken} {
+		validClaims, err := ring.ValidSessionToken(token)
+		require.NoError(t, err)
+		require.Equal(t, claims, validClaims)
+	}
+
+	jwks := ring.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	require.Equal(t, tokenKeyID(t, newToken), jwks.Keys[0].Kid, "the current key comes first")
+	require.Equal(t, tokenKeyID(t, oldToken), jwks.Keys[1].Kid)
+	require.Equal(t, "RS256", jwks.Keys[0].Alg)
+	require.Equal(t, "AQAB", jwks.Keys[0].E)
+
+	_, err = oldRing.ValidSessionToken(newToken)
+	require.Error(t, err, "a ring does not know keys added after it")
+}
+
+func TestKeyRingRetiredKey(t *testing.T) {
+	oldPrivate, oldPublic := newKeyPair(t)
+	newPrivate, newPublic := newKeyPair(t)
+	claims := utils.SessionClaims{UserID: uuid.New(), SessionID: uuid.New()}
+
+	oldRing, err := utils.NewKeyRing(oldPrivate, oldPublic)
+	require.NoError(t, err)
+	oldToken, err := oldRing.CreateSessionToken(time.Hour, claims)
+	require.NoError(t, err)
+	legacyToken, err := utils.CreateSessionToken(time.Hour, claims, oldPrivate)
+	require.NoError(t, err)
+
+	ring, err := utils.NewKeyRing(newPrivate, newPublic,
+		utils.VerificationKey{PublicKey: oldPublic, RetiresAt: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+
+	_, err = ring.ValidSessionToken(oldToken)
+	require.Error(t, err)
+	_, err = ring.ValidSessionToken(legacyToken)
+	require.Error(t, err)
+	require.Len(t, ring.JWKS().Keys, 1)
+}
+
+func TestKeyRingMismatchedPair(t *testing.T) {
+	private, _ := newKeyPair(t)
+	_, public := newKeyPair(t)
+
+	_, err := utils.NewKeyRing(private, public)
+	require.Error(t, err)
+}
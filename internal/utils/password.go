@@ -1,22 +1,139 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword generates a bcrypt hash of the given password.
+// authHashInfo separates the auth hash from the vault keys derived from the same master key.
+const authHashInfo = "keeper auth hash"
+
+const (
+	// passwordHashID names Argon2id in the PHC string format of password hashes.
+	passwordHashID     = "argon2id"
+	passwordSaltLength = 16
+	passwordHashLength = 32
+)
+
+var (
+	// ErrPasswordMismatch means the password does not match the hash.
+	ErrPasswordMismatch = errors.New("password does not match")
+
+	errUnknownPasswordHash = errors.New("unknown password hash format")
+)
+
+// PasswordParams holds the Argon2id parameters of password hashes.
+type PasswordParams struct {
+	Time    uint32 // Number of passes over the memory.
+	Memory  uint32 // Memory size in KiB.
+	Threads uint8  // Degree of parallelism.
+}
+
+// DefaultPasswordParams returns the recommended Argon2id parameters of password hashes,
+// the same as the ones of the vault keys.
+func DefaultPasswordParams() PasswordParams {
+	return PasswordParams{Time: defaultKDFTime, Memory: defaultKDFMemory, Threads: defaultKDFThreads}
+}
+
+// HashPassword generates an Argon2id hash of the given password with the default parameters.
 func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
+	return HashPasswordWithParams(password, DefaultPasswordParams())
+}
+
+// HashPasswordWithParams generates an Argon2id hash of the given password with a random salt.
+// The hash is in the PHC string format, $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>,
+// so it carries everything needed to verify it.
+func HashPasswordWithParams(password string, params PasswordParams) (string, error) {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", fmt.Errorf("could not hash password: %w", err)
 	}
+	hash := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, passwordHashLength)
 
-	return string(hashedPassword), nil
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		passwordHashID, argon2.Version, params.Memory, params.Time, params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash)), nil
 }
 
-// VerifyPassword - password verification
+// VerifyPassword checks the password against the hash. The algorithm is detected from the hash:
+// Argon2id hashes in the PHC string format and bcrypt hashes are accepted.
+// Returns ErrPasswordMismatch if the password does not match.
 func VerifyPassword(hashedPassword, candidatePassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(candidatePassword))
+	if isBcryptHash(hashedPassword) {
+		err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(candidatePassword))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrPasswordMismatch
+		}
+		return err
+	}
+
+	params, salt, hash, err := parsePasswordHash(hashedPassword)
+	if err != nil {
+		return err
+	}
+	candidate := argon2.IDKey([]byte(candidatePassword), salt, params.Time, params.Memory, params.Threads, uint32(len(hash)))
+	if subtle.ConstantTimeCompare(candidate, hash) != 1 {
+		return ErrPasswordMismatch
+	}
+
+	return nil
+}
+
+// PasswordNeedsRehash reports whether the hash has to be replaced by a hash with the given parameters:
+// it is a bcrypt hash or an Argon2id hash with other parameters.
+func PasswordNeedsRehash(hashedPassword string, params PasswordParams) bool {
+	current, _, hash, err := parsePasswordHash(hashedPassword)
+	return err != nil || current != params || len(hash) != passwordHashLength
+}
+
+// parsePasswordHash splits an Argon2id hash in the PHC string format into its parameters, salt and hash.
+func parsePasswordHash(hashedPassword string) (params PasswordParams, salt, hash []byte, err error) {
+	fields := strings.Split(hashedPassword, "$")
+	if len(fields) != 6 || fields[0] != "" || fields[1] != passwordHashID {
+		return params, nil, nil, errUnknownPasswordHash
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(fields[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errUnknownPasswordHash
+	}
+	_, err = fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads)
+	if err != nil || params.Time == 0 || params.Threads == 0 {
+		return params, nil, nil, errUnknownPasswordHash
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(fields[4]); err != nil {
+		return params, nil, nil, errUnknownPasswordHash
+	}
+	if hash, err = base64.RawStdEncoding.DecodeString(fields[5]); err != nil || len(hash) == 0 {
+		return params, nil, nil, errUnknownPasswordHash
+	}
+
+	return params, salt, hash, nil
+}
+
+// isBcryptHash reports whether the hash is a bcrypt hash.
+func isBcryptHash(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$2a$") ||
+		strings.HasPrefix(hashedPassword, "$2b$") ||
+		strings.HasPrefix(hashedPassword, "$2y$")
+}
+
+// AuthHash derives the secret the client authenticates with instead of the master password.
+// It is a one-way function of the key derived from the password with the default parameters,
+// so the server never learns anything that opens the vault. The default parameters keep
+// the hash stable whatever KDF settings the client is configured with.
+func AuthHash(password, email string) string {
+	mac := hmac.New(sha256.New, DeriveKey(password, DefaultKDFParams(UserSalt(email))))
+	mac.Write([]byte(authHashInfo))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
 }
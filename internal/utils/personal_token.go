@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// PersonalTokenPrefix starts every personal access token, telling them apart from JWTs.
+const PersonalTokenPrefix = "kpr_"
+
+const personalTokenLength = 32 // Number of random bytes of a personal access token.
+
+// GeneratePersonalToken returns the secret of a new personal access token.
+func GeneratePersonalToken() (string, error) {
+	raw := make([]byte, personalTokenLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("GeneratePersonalToken - rand.Read - %w", err)
+	}
+
+	return PersonalTokenPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// IsPersonalToken reports whether the token is a personal access token rather than a JWT.
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
+
+// HashPersonalToken returns the hash a personal access token is stored under.
+// The tokens are random, so a fast hash is enough to keep them from being read back.
+func HashPersonalToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	recoveryKeyLength    = 32 // Random bytes of a recovery key.
+	recoveryKeyGroupSize = 4  // Characters between the hyphens of a formatted recovery key.
+
+	recoveryWrapInfo = "keeper recovery wrap" // Separates the key wrapping the data key from the auth secret.
+	recoveryAuthInfo = "keeper recovery auth" // Separates the auth secret from the key wrapping the data key.
+)
+
+var errMalformedRecoveryKey = errors.New("malformed recovery key")
+
+// RecoveryKey is a random key wrapping a copy of the vault data key, the way back into the vault
+// when the master password is forgotten. Like the master password it never leaves the device:
+// the server gets the data key wrapped by it and a secret derived from it to check it with.
+type RecoveryKey []byte
+
+// NewRecoveryKey generates a random recovery key.
+func NewRecoveryKey() (RecoveryKey, error) {
+	key := make(RecoveryKey, recoveryKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("NewRecoveryKey - rand.Read - %w", err)
+	}
+
+	return key, nil
+}
+
+// ParseRecoveryKey parses a recovery key formatted by String. Case, spaces and hyphens are ignored.
+func ParseRecoveryKey(formatted string) (RecoveryKey, error) {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(formatted))
+	key, err := totpEncoding.DecodeString(normalized)
+	if err != nil || len(key) != recoveryKeyLength {
+		return nil, errMalformedRecoveryKey
+	}
+
+	return key, nil
+}
+
+// String formats the recovery key in base32 with hyphens between groups of characters.
+func (k RecoveryKey) String() string {
+	encoded := totpEncoding.EncodeToString(k)
+	groups := make([]string, 0, len(encoded)/recoveryKeyGroupSize+1)
+	for len(encoded) > recoveryKeyGroupSize {
+		groups = append(groups, encoded[:recoveryKeyGroupSize])
+		encoded = encoded[recoveryKeyGroupSize:]
+	}
+
+	return strings.Join(append(groups, encoded), "-")
+}
+
+// Cipher returns the cipher wrapping the data key with the recovery key.
+func (k RecoveryKey) Cipher() *Cipher {
+	key := k.derive(recoveryWrapInfo)
+	keyID := sha256.Sum256(key)
+
+	return NewDataKeyCipher(&DataKey{ID: keyID[:dataKeyIDLength], Key: key})
+}
+
+// Auth returns the secret the recovery key is checked with on the server.
+// It is a one-way function of the key, so the server cannot unwrap the data key with it.
+func (k RecoveryKey) Auth() string {
+	return base64.RawURLEncoding.EncodeToString(k.derive(recoveryAuthInfo))
+}
+
+// derive derives a key for the purpose named by info from the recovery key.
+func (k RecoveryKey) derive(info string) []byte {
+	mac := hmac.New(sha256.New, k)
+	mac.Write([]byte(info))
+
+	return mac.Sum(nil)
+}
+
+// HashRecoveryAuth returns the hash the auth secret of a recovery key is stored under.
+// The secret is derived from a random key, so a fast hash is enough to keep it from being read back.
+func HashRecoveryAuth(auth string) string {
+	sum := sha256.Sum256([]byte(auth))
+
+	return hex.EncodeToString(sum[:])
+}
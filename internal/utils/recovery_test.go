@@ -0,0 +1,62 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+func TestRecoveryKeyFormat(t *testing.T) {
+	key, err := utils.NewRecoveryKey()
+	require.NoError(t, err)
+
+	formatted := key.String()
+	require.Len(t, strings.Split(formatted, "-"), 13)
+
+	parsed, err := utils.ParseRecoveryKey(formatted)
+	require.NoError(t, err)
+	require.Equal(t, key, parsed)
+
+	parsed, err = utils.ParseRecoveryKey(" " + strings.ToLower(strings.ReplaceAll(formatted, "-", " ")) + " ")
+	require.NoError(t, err)
+	require.Equal(t, key, parsed)
+
+	_, err = utils.ParseRecoveryKey(formatted[:len(formatted)-5])
+	require.Error(t, err)
+	_, err = utils.ParseRecoveryKey("not a recovery key")
+	require.Error(t, err)
+}
+
+func TestRecoveryKeyWrapsDataKey(t *testing.T) {
+	key, err := utils.NewRecoveryKey()
+	require.NoError(t, err)
+	otherKey, err := utils.NewRecoveryKey()
+	require.NoError(t, err)
+	dataKey, err := utils.NewDataKey()
+	require.NoError(t, err)
+
+	wrapped, err := dataKey.Wrap(key.Cipher())
+	require.NoError(t, err)
+
+	unwrapped, err := utils.UnwrapDataKey(wrapped, key.Cipher())
+	require.NoError(t, err)
+	require.Equal(t, dataKey, unwrapped)
+
+	_, err = utils.UnwrapDataKey(wrapped, otherKey.Cipher())
+	require.ErrorIs(t, err, utils.ErrAuthFailed)
+}
+
+func TestRecoveryKeyAuth(t *testing.T) {
+	key, err := utils.NewRecoveryKey()
+	require.NoError(t, err)
+	otherKey, err := utils.NewRecoveryKey()
+	require.NoError(t, err)
+
+	require.Equal(t, key.Auth(), key.Auth())
+	require.NotEqual(t, key.Auth(), otherKey.Auth())
+	require.Equal(t, utils.HashRecoveryAuth(key.Auth()), utils.HashRecoveryAuth(key.Auth()))
+	require.NotEqual(t, key.Auth(), utils.HashRecoveryAuth(key.Auth()))
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	shareHeaderLength   = 2 // Threshold and index bytes in front of the value of an encoded share.
+	shareChecksumLength = 2 // Bytes of the SHA-256 checksum behind the value of an encoded share.
+	shareWordPrefix     = 4 // Letters that name a share word unambiguously.
+)
+
+var (
+	errShareChecksum   = errors.New("share checksum mismatch, a word has been mistyped")
+	errShareParams     = errors.New("the threshold must be at least 2 and at most the number of shares, up to 255")
+	errMalformedShare  = errors.New("malformed share")
+	errShareWord       = errors.New("unknown share word")
+	errNotEnoughShares = errors.New("not enough shares")
+	errSharesMismatch  = errors.New("the shares do not belong to one secret")
+)
+
+// Share is one of the shares a secret is split into with Shamir's secret sharing over GF(256).
+// Any Threshold shares of a secret recover it, fewer tell nothing about it.
+type Share struct {
+	Threshold byte   // Number of shares recovering the secret.
+	Index     byte   // Point the share polynomials are evaluated at, never zero.
+	Value     []byte // Values of the share polynomials, one per byte of the secret.
+}
+
+// SplitSecret splits the secret into the given number of shares, any threshold of them recover it.
+// Every byte of the secret is the constant term of its own random polynomial of degree threshold-1,
+// the share with index x holds the values of the polynomials at x.
+func SplitSecret(secret []byte, shares, threshold int) ([]Share, error) {
+	if threshold < 2 || threshold > shares || shares > 255 {
+		return nil, errShareParams
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("SplitSecret - %w: empty secret", errMalformedShare)
+	}
+
+	result := make([]Share, shares)
+	for index := range result {
+		result[index] = Share{
+			Threshold: byte(threshold),
+			Index:     byte(index + 1),
+			Value:     make([]byte, len(secret)),
+		}
+	}
+
+	coefficients := make([]byte, threshold)
+	for position, secretByte := range secret {
+		coefficients[0] = secretByte
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, fmt.Errorf("SplitSecret - rand.Read - %w", err)
+		}
+		for index := range result {
+			result[index].Value[position] = gfEvaluate(coefficients, result[index].Index)
+		}
+	}
+
+	return result, nil
+}
+
+// CombineShares recovers the secret from at least the threshold number of its shares.
+// The secret is the value of the polynomials at zero, found by Lagrange interpolation.
+func CombineShares(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errNotEnoughShares
+	}
+
+	threshold, length := shares[0].Threshold, len(shares[0].Value)
+	seen := make(map[byte]bool, len(shares))
+	for _, share := range shares {
+		if share.Threshold != threshold || len(share.Value) != length || share.Index == 0 || seen[share.Index] {
+			return nil, errSharesMismatch
+		}
+		seen[share.Index] = true
+	}
+	if len(shares) < int(threshold) {
+		return nil, fmt.Errorf("%w: %d of %d", errNotEnoughShares, len(shares), threshold)
+	}
+	shares = shares[:threshold]
+
+	// The Lagrange basis polynomials at zero: product of x_j / (x_j - x_i), subtraction is XOR in GF(256).
+	basis := make([]byte, len(shares))
+	for i := range shares {
+		basis[i] = 1
+		for j := range shares {
+			if i == j {
+				continue
+			}
+			basis[i] = gfMul(basis[i], gfDiv(shares[j].Index, shares[j].Index^shares[i].Index))
+		}
+	}
+
+	secret := make([]byte, length)
+	for position := range secret {
+		for i := range shares {
+			secret[position] ^= gfMul(basis[i], shares[i].Value[position])
+		}
+	}
+
+	return secret, nil
+}
+
+// String encodes the share as a list of words, one per byte of the threshold, the index,
+// the value and a checksum catching mistyped words.
+func (s Share) String() string {
+	data := append([]byte{s.Threshold, s.Index}, s.Value...)
+	sum := sha256.Sum256(data)
+	data = append(data, sum[:shareChecksumLength]...)
+
+	words := make([]string, len(data))
+	for index, b := range data {
+		words[index] = shareWords[b]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// ParseShare parses a share encoded by String. Case and extra spaces are ignored,
+// and a word may be shortened to its first four letters.
+func ParseShare(encoded string) (Share, error) {
+	words := strings.Fields(strings.ToLower(encoded))
+	if len(words) <= shareHeaderLength+shareChecksumLength {
+		return Share{}, errMalformedShare
+	}
+
+	data := make([]byte, len(words))
+	for index, word := range words {
+		b, ok := shareWordBytes()[shareWordKey(word)]
+		if !ok || !strings.HasPrefix(shareWords[b], word) {
+			return Share{}, fmt.Errorf("%w %q", errShareWord, word)
+		}
+		data[index] = b
+	}
+
+	payload, checksum := data[:len(data)-shareChecksumLength], data[len(data)-shareChecksumLength:]
+	sum := sha256.Sum256(payload)
+	if subtle.ConstantTimeCompare(sum[:shareChecksumLength], checksum) != 1 {
+		return Share{}, errShareChecksum
+	}
+
+	share := Share{Threshold: payload[0], Index: payload[1], Value: payload[shareHeaderLength:]}
+	if share.Threshold < 2 || share.Index == 0 {
+		return Share{}, errMalformedShare
+	}
+
+	return share, nil
+}
+
+// shareWordKey returns the prefix a share word is looked up by.
+func shareWordKey(word string) string {
+	if len(word) > shareWordPrefix {
+		return word[:shareWordPrefix]
+	}
+
+	return word
+}
+
+// gfEvaluate evaluates the polynomial with the coefficients, the constant term first, at x in GF(256).
+func gfEvaluate(coefficients []byte, x byte) byte {
+	var result byte
+	for index := len(coefficients) - 1; index >= 0; index-- {
+		result = gfMul(result, x) ^ coefficients[index]
+	}
+
+	return result
+}
+
+// gfMul multiplies in GF(256) with the AES polynomial x^8 + x^4 + x^3 + x + 1.
+// It runs in constant time, the operands are secret.
+func gfMul(a, b byte) byte {
+	var result byte
+	for range 8 {
+		result ^= -(b & 1) & a
+		a = (a << 1) ^ (-(a >> 7) & 0x1b)
+		b >>= 1
+	}
+
+	return result
+}
+
+// gfDiv divides in GF(256), b must not be zero. The inverse of b is b^254.
+func gfDiv(a, b byte) byte {
+	inverse := byte(1)
+	for range 254 {
+		inverse = gfMul(inverse, b)
+	}
+
+	return gfMul(a, inverse)
+}
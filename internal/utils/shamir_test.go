@@ -0,0 +1,98 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+func TestSplitCombineSecret(t *testing.T) {
+	key, err := utils.NewRecoveryKey()
+	require.NoError(t, err)
+
+	shares, err := utils.SplitSecret(key, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	// Every subset of three shares recovers the secret, whatever the order.
+	for i := range shares {
+		for j := i + 1; j < len(shares); j++ {
+			for k := j + 1; k < len(shares); k++ {
+				secret, err := utils.CombineShares([]utils.Share{shares[k], shares[i], shares[j]})
+				require.NoError(t, err)
+				require.Equal(t, []byte(key), secret)
+			}
+		}
+	}
+
+	secret, err := utils.CombineShares(shares)
+	require.NoError(t, err)
+	require.Equal(t, []byte(key), secret)
+
+	_, err = utils.CombineShares(shares[:2])
+	require.Error(t, err)
+	_, err = utils.CombineShares([]utils.Share{shares[0], shares[0], shares[1]})
+	require.Error(t, err)
+
+	otherShares, err := utils.SplitSecret(key, 5, 2)
+	require.NoError(t, err)
+	_, err = utils.CombineShares([]utils.Share{shares[0], shares[1], otherShares[2]})
+	require.Error(t, err)
+}
+
+func TestSplitSecretParams(t *testing.T) {
+	secret := []byte("secret")
+
+	for _, params := range [][2]int{{5, 1}, {2, 3}, {256, 3}, {0, 0}} {
+		_, err := utils.SplitSecret(secret, params[0], params[1])
+		require.Error(t, err, "shares %d, threshold %d", params[0], params[1])
+	}
+
+	shares, err := utils.SplitSecret(secret, 255, 255)
+	require.NoError(t, err)
+	combined, err := utils.CombineShares(shares)
+	require.NoError(t, err)
+	require.Equal(t, secret, combined)
+}
+
+func TestShareWords(t *testing.T) {
+	key, err := utils.NewRecoveryKey()
+	require.NoError(t, err)
+	shares, err := utils.SplitSecret(key, 3, 2)
+	require.NoError(t, err)
+
+	encoded := shares[1].String()
+	words := strings.Fields(encoded)
+	require.Len(t, words, len(key)+4)
+
+	parsed, err := utils.ParseShare(encoded)
+	require.NoError(t, err)
+	require.Equal(t, shares[1], parsed)
+
+	// Words may be typed in any case and shortened to four letters.
+	shortened := make([]string, len(words))
+	for index, word := range words {
+		shortened[index] = strings.ToUpper(word[:min(len(word), 4)])
+	}
+	parsed, err = utils.ParseShare("  " + strings.Join(shortened, "   ") + "\n")
+	require.NoError(t, err)
+	require.Equal(t, shares[1], parsed)
+
+	// A swapped word breaks the checksum.
+	swapped := append([]string(nil), words...)
+	swapped[2], swapped[3] = swapped[3], swapped[2]
+	if swapped[2] != swapped[3] {
+		_, err = utils.ParseShare(strings.Join(swapped, " "))
+		require.Error(t, err)
+	}
+
+	_, err = utils.ParseShare(strings.Join(words[:len(words)-1], " "))
+	require.Error(t, err)
+	_, err = utils.ParseShare(strings.Replace(encoded, words[0], "notaword", 1))
+	require.Error(t, err)
+	_, err = utils.ParseShare("")
+	require.Error(t, err)
+}
@@ -0,0 +1,426 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+const (
+	// streamMagic starts a chunked stream. It differs from envelopePrefix,
+	// so streams, whole-file envelopes and legacy blobs can be told apart by the first bytes.
+	streamMagic = "$keeper-stream$"
+
+	// streamChunkSize is the plaintext size of every segment but the last one.
+	streamChunkSize = 64 * 1024
+
+	// streamNoncePrefixSize is the random part of the segment nonce,
+	// followed by a 4-byte segment counter and a 1-byte final segment flag.
+	streamNoncePrefixSize = 7
+
+	streamFinalSegment = 1
+)
+
+var (
+	errStreamTooLong   = errors.New("stream is too long")
+	errStreamTruncated = fmt.Errorf("%w: stream is truncated", ErrMalformed)
+	errStreamClosed    = errors.New("write to closed stream")
+)
+
+// The stream layout is STREAM construction over AES-GCM:
+//
+//	magic | key header | nonce prefix | segment 0 | ... | segment N
+//
+// Every segment seals up to streamChunkSize bytes with the nonce
+// nonce prefix | counter | final flag, and the whole stream header as associated data.
+// Reordered, dropped or appended segments fail authentication,
+// and so does a stream cut at a segment boundary because its last segment is not flagged final.
+
+// streamNonce builds the nonce of the segment.
+func streamNonce(nonce, prefix []byte, counter uint32, final bool) []byte {
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefixSize:], counter)
+	nonce[len(nonce)-1] = 0
+	if final {
+		nonce[len(nonce)-1] = streamFinalSegment
+	}
+
+	return nonce
+}
+
+// streamWriter encrypts written data segment by segment.
+type streamWriter struct {
+	dst     io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	nonce   []byte
+	counter uint32
+	buf     []byte
+	out     []byte
+	closed  bool
+}
+
+// EncryptStream returns a writer that encrypts everything written to it into dst as a chunked stream.
+// Memory use does not depend on the stream length. Close must be called to write the final segment;
+// it does not close dst.
+func (c *Cipher) EncryptStream(dst io.Writer) (io.WriteCloser, error) {
+	key, err := c.key(c.sealRef)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, streamNoncePrefixSize)
+	if _, err = io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, fmt.Errorf("io.ReadFull(rand.Reader, prefix) - %w", err)
+	}
+
+	header := append([]byte(streamMagic), c.sealRef.header...)
+	header = append(header, prefix...)
+	if _, err = dst.Write(header); err != nil {
+		return nil, fmt.Errorf("EncryptStream - dst.Write - %w", err)
+	}
+
+	return &streamWriter{
+		dst:    dst,
+		aead:   aead,
+		header: header,
+		prefix: prefix,
+		nonce:  make([]byte, aead.NonceSize()),
+		buf:    make([]byte, 0, streamChunkSize),
+		out:    make([]byte, 0, streamChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Write buffers the data and seals every full segment once more data follows it,
+// so the final segment is never empty unless the whole stream is.
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errStreamClosed
+	}
+
+	var written int
+	for len(p) > 0 {
+		if len(w.buf) == streamChunkSize {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(w.buf[len(w.buf):streamChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+// Close seals the final segment.
+func (w *streamWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	return w.flush(true)
+}
+
+// flush seals the buffered data as the next segment.
+func (w *streamWriter) flush(final bool) error {
+	if w.counter == math.MaxUint32 {
+		return errStreamTooLong
+	}
+
+	nonce := streamNonce(w.nonce, w.prefix, w.counter, final)
+	w.out = w.aead.Seal(w.out[:0], nonce, w.buf, w.header)
+	if _, err := w.dst.Write(w.out); err != nil {
+		return fmt.Errorf("streamWriter - dst.Write - %w", err)
+	}
+
+	w.buf = w.buf[:0]
+	w.counter++
+
+	return nil
+}
+
+// streamReader decrypts a chunked stream segment by segment.
+type streamReader struct {
+	src     io.Reader
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	nonce   []byte
+	counter uint32
+	in      []byte
+	pending int
+	out     []byte
+	outPos  int
+	done    bool
+	err     error
+}
+
+// DecryptStream returns a reader of the plaintext of src.
+// Chunked streams are decrypted with constant memory; data in the whole-file envelope
+// or the legacy headerless format is read and decrypted at once.
+// A stream that fails authentication returns an error from Read after the last authentic segment,
+// so the consumer must discard the output on error.
+func (c *Cipher) DecryptStream(src io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(src)
+	head, err := buffered.Peek(len(streamMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("DecryptStream - Peek - %w", err)
+	}
+
+	if string(head) != streamMagic {
+		encoded, err := io.ReadAll(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("DecryptStream - io.ReadAll - %w", err)
+		}
+		plainData, err := c.decrypt(encoded, nil)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(plainData), nil
+	}
+
+	header, ref, err := readStreamHeader(buffered)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := c.key(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamReader{
+		src:    buffered,
+		aead:   aead,
+		header: header,
+		prefix: header[len(header)-streamNoncePrefixSize:],
+		nonce:  make([]byte, aead.NonceSize()),
+		in:     make([]byte, streamChunkSize+aead.Overhead()+1),
+		out:    make([]byte, 0, streamChunkSize),
+	}, nil
+}
+
+// readStreamHeader reads the magic, the key header and the nonce prefix of a chunked stream.
+func readStreamHeader(src io.Reader) (header []byte, ref keyRef, err error) {
+	header = make([]byte, len(streamMagic))
+	if _, err = io.ReadFull(src, header); err != nil {
+		return nil, ref, errMalformedEnvelope
+	}
+
+	keyHeader, err := readHeader(src)
+	if err != nil {
+		return nil, ref, err
+	}
+	header = append(header, keyHeader...)
+
+	prefix := make([]byte, streamNoncePrefixSize)
+	if _, err = io.ReadFull(src, prefix); err != nil {
+		return nil, ref, errMalformedEnvelope
+	}
+	header = append(header, prefix...)
+
+	ref, _, err = parseHeader(keyHeader)
+	if err != nil {
+		return nil, ref, err
+	}
+
+	return header, ref, nil
+}
+
+// Read returns decrypted data, opening the next segment when the current one is consumed.
+func (r *streamReader) Read(p []byte) (int, error) {
+	for r.outPos == len(r.out) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+
+	n := copy(p, r.out[r.outPos:])
+	r.outPos += n
+
+	return n, nil
+}
+
+// next reads and opens the next segment. One byte past the segment is read ahead
+// to learn whether the segment is the final one.
+func (r *streamReader) next() error {
+	r.out, r.outPos = r.out[:0], 0
+	if r.counter == math.MaxUint32 {
+		return errStreamTooLong
+	}
+	segmentSize := streamChunkSize + r.aead.Overhead()
+
+	n, err := io.ReadFull(r.src, r.in[r.pending:])
+	total := r.pending + n
+	final := false
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		return fmt.Errorf("streamReader - io.ReadFull - %w", err)
+	}
+	if final && total == 0 {
+		return errStreamTruncated
+	}
+
+	segment := r.in[:total]
+	if !final {
+		segment = r.in[:segmentSize]
+	}
+
+	nonce := streamNonce(r.nonce, r.prefix, r.counter, final)
+	plainData, err := r.aead.Open(r.out[:0], nonce, segment, r.header)
+	if err != nil {
+		return fmt.Errorf("segment %d - %w", r.counter, ErrAuthFailed)
+	}
+	r.out, r.outPos = plainData, 0
+
+	if !final {
+		r.in[0] = r.in[segmentSize]
+		r.pending = 1
+	}
+	r.counter++
+	r.done = final
+
+	return nil
+}
+
+// EncryptReader returns a reader of the chunked stream of src.
+// The encryption runs as the result is read, closing it stops the encryption.
+func (c *Cipher) EncryptReader(src io.Reader) io.ReadCloser {
+	encrypted, encryptedWriter := io.Pipe()
+
+	go func() {
+		stream, err := c.EncryptStream(encryptedWriter)
+		if err == nil {
+			_, err = io.Copy(stream, src)
+		}
+		if err == nil {
+			err = stream.Close()
+		}
+		encryptedWriter.CloseWithError(err)
+	}()
+
+	return encrypted
+}
+
+// EncryptFile encrypts the contents of a file into a chunked stream written to a new file.
+// If the input file is empty, an error is returned.
+func (c *Cipher) EncryptFile(inputFilePath, outputFilePath string) error {
+	inputFile, err := os.Open(inputFilePath)
+	if err != nil {
+		return fmt.Errorf("EncryptFile - os.Open - %w", err)
+	}
+	defer inputFile.Close()
+
+	fi, err := inputFile.Stat()
+	if err != nil {
+		return fmt.Errorf("EncryptFile - Stat - %w", err)
+	}
+	if size := fi.Size(); size == 0 {
+		return fmt.Errorf("EncryptFile - fi.Size - %w", errEmptyFile)
+	}
+
+	outputFile, err := os.Create(outputFilePath)
+	if err != nil {
+		return fmt.Errorf("EncryptFile - os.Create - %w", err)
+	}
+	defer outputFile.Close()
+
+	stream, err := c.EncryptStream(outputFile)
+	if err != nil {
+		return fmt.Errorf("EncryptFile - %w", err)
+	}
+	if _, err = io.Copy(stream, inputFile); err != nil {
+		return fmt.Errorf("EncryptFile - io.Copy - %w", err)
+	}
+	if err = stream.Close(); err != nil {
+		return fmt.Errorf("EncryptFile - %w", err)
+	}
+
+	return outputFile.Close()
+}
+
+// DecryptFile decrypts a file written by EncryptFile, or by the whole-file envelope
+// and legacy headerless formats, and writes the result to a new file.
+// The output file is removed if the input fails authentication.
+func (c *Cipher) DecryptFile(encryptedPath, decryptedFilePath string) error {
+	encryptedFile, err := os.Open(encryptedPath)
+	if err != nil {
+		return fmt.Errorf("DecryptFile - os.Open - %w", err)
+	}
+	defer encryptedFile.Close()
+
+	plain, err := c.DecryptStream(encryptedFile)
+	if err != nil {
+		return fmt.Errorf("DecryptFile - %w", err)
+	}
+
+	outputFile, err := os.Create(decryptedFilePath)
+	if err != nil {
+		return fmt.Errorf("DecryptFile - os.Create - %w", err)
+	}
+
+	if _, err = io.Copy(outputFile, plain); err != nil {
+		outputFile.Close()
+		os.Remove(decryptedFilePath)
+		return fmt.Errorf("DecryptFile - io.Copy - %w", err)
+	}
+
+	return outputFile.Close()
+}
+
+// FileNeedsUpgrade reports whether an encrypted file is stored in a format older than the chunked stream
+// or sealed with a key other than the one that seals new data.
+func (c *Cipher) FileNeedsUpgrade(encryptedPath string) (bool, error) {
+	file, err := os.Open(encryptedPath)
+	if err != nil {
+		return false, fmt.Errorf("FileNeedsUpgrade - os.Open - %w", err)
+	}
+	defer file.Close()
+
+	head := make([]byte, len(streamMagic))
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("FileNeedsUpgrade - io.ReadFull - %w", err)
+	}
+	if string(head[:n]) != streamMagic {
+		return true, nil
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return false, fmt.Errorf("FileNeedsUpgrade - Seek - %w", err)
+	}
+	_, ref, err := readStreamHeader(file)
+	if err != nil {
+		return true, nil
+	}
+
+	return !bytes.Equal(ref.header, c.sealRef.header), nil
+}
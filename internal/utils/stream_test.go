@@ -0,0 +1,99 @@
+package utils_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+const testChunkSize = 64 * 1024
+
+func encryptStream(t *testing.T, c *utils.Cipher, plainData []byte) []byte {
+	t.Helper()
+
+	var encrypted bytes.Buffer
+	stream, err := c.EncryptStream(&encrypted)
+	require.NoError(t, err)
+	_, err = stream.Write(plainData)
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+
+	return encrypted.Bytes()
+}
+
+func decryptStream(c *utils.Cipher, encrypted []byte) ([]byte, error) {
+	plain, err := c.DecryptStream(bytes.NewReader(encrypted))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(plain)
+}
+
+func TestStream(t *testing.T) {
+	c := utils.NewCipher("secretKey", testKDFParams("user@example.com"))
+
+	for _, size := range []int{0, 1, testChunkSize - 1, testChunkSize, testChunkSize + 1, 3*testChunkSize + 7} {
+		plainData := make([]byte, size)
+		_, err := rand.Read(plainData)
+		require.NoError(t, err)
+
+		decrypted, err := decryptStream(c, encryptStream(t, c, plainData))
+		require.NoError(t, err, size)
+		require.Equal(t, plainData, decrypted, size)
+	}
+}
+
+func TestStreamTampered(t *testing.T) {
+	c := utils.NewCipher("secretKey", testKDFParams("user@example.com"))
+	plainData := bytes.Repeat([]byte("keeper"), testChunkSize)
+	encrypted := encryptStream(t, c, plainData)
+
+	tampered := bytes.Clone(encrypted)
+	tampered[len(tampered)/2] ^= 1
+	_, err := decryptStream(c, tampered)
+	require.Error(t, err)
+
+	// A stream cut at a segment boundary has no final segment.
+	headerSize := len(encrypted) - len(plainData) - 16*((len(plainData)+testChunkSize-1)/testChunkSize)
+	_, err = decryptStream(c, encrypted[:headerSize+testChunkSize+16])
+	require.Error(t, err)
+
+	_, err = decryptStream(c, encrypted[:len(encrypted)-1])
+	require.Error(t, err)
+
+	_, err = decryptStream(utils.NewCipher("wrongKey", testKDFParams("user@example.com")), encrypted)
+	require.Error(t, err)
+}
+
+func TestStreamLegacy(t *testing.T) {
+	c := utils.NewCipher("secretKey", testKDFParams("user@example.com"))
+
+	decrypted, err := decryptStream(c, []byte(legacyPhrase))
+	require.NoError(t, err)
+	require.Equal(t, phrase, string(decrypted))
+
+	decrypted, err = decryptStream(c, []byte(encrypt(t, c, phrase)))
+	require.NoError(t, err)
+	require.Equal(t, phrase, string(decrypted))
+}
+
+func TestEncryptReader(t *testing.T) {
+	c := utils.NewCipher("secretKey", testKDFParams("user@example.com"))
+	plainData := strings.Repeat(phrase, testChunkSize/len(phrase)+1)
+
+	encrypted := c.EncryptReader(strings.NewReader(plainData))
+	defer encrypted.Close()
+
+	plain, err := c.DecryptStream(encrypted)
+	require.NoError(t, err)
+	decrypted, err := io.ReadAll(plain)
+	require.NoError(t, err)
+	require.Equal(t, plainData, string(decrypted))
+}
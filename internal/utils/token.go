@@ -1,12 +1,14 @@
 package utils
 
 import (
+	"crypto/rsa"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 // errToken error indicating problems with the token
@@ -16,25 +18,62 @@ var errToken = errors.New("tokenError")
 // It encodes the payload into the token and signs it using the RSA private key provided as base64 encoded `privateKey`.
 // Returns the signed JWT token as a string or an error if any operation fails.
 func CreateToken(ttl time.Duration, payload any, privateKey string) (string, error) {
-	decodedPrivateKey, err := base64.StdEncoding.DecodeString(privateKey)
-	if err != nil {
-		return "", fmt.Errorf("could not decode key: %w", err)
+	claims := make(jwt.MapClaims)
+	claims["sub"] = payload
+
+	return signToken(ttl, claims, privateKey)
+}
+
+// SessionClaims holds the claims of a token issued for a session.
+type SessionClaims struct {
+	UserID    uuid.UUID // Subject of the token.
+	SessionID uuid.UUID // Session the token belongs to.
+	TokenID   uuid.UUID // ID of the token, nil for access tokens.
+}
+
+// CreateSessionToken creates a JWT token of the session like CreateToken does.
+// Besides the user ID in the subject it carries the session ID, and the token ID when it is set.
+func CreateSessionToken(ttl time.Duration, sessionClaims SessionClaims, privateKey string) (string, error) {
+	return signToken(ttl, sessionTokenClaims(sessionClaims), privateKey)
+}
+
+// sessionTokenClaims returns the claims of a token of the session.
+func sessionTokenClaims(sessionClaims SessionClaims) jwt.MapClaims {
+	claims := make(jwt.MapClaims)
+	claims["sub"] = sessionClaims.UserID.String()
+	claims["sid"] = sessionClaims.SessionID.String()
+	if sessionClaims.TokenID != uuid.Nil {
+		claims["jti"] = sessionClaims.TokenID.String()
 	}
 
-	key, err := jwt.ParseRSAPrivateKeyFromPEM(decodedPrivateKey)
+	return claims
+}
+
+// signToken sets the time claims and signs the token with the RSA private key.
+func signToken(ttl time.Duration, claims jwt.MapClaims, privateKey string) (string, error) {
+	key, err := parsePrivateKey(privateKey)
 	if err != nil {
-		return "", fmt.Errorf("create: parse key: %w", err)
+		return "", err
 	}
 
+	return signTokenWithKey(ttl, claims, key, "")
+}
+
+// signTokenWithKey sets the time claims and signs the token with the key.
+// The key ID is put in the kid header when it is given.
+func signTokenWithKey(ttl time.Duration, claims jwt.MapClaims, key *rsa.PrivateKey, keyID string) (string, error) {
 	now := time.Now().UTC()
 
-	claims := make(jwt.MapClaims)
-	claims["sub"] = payload
 	claims["exp"] = now.Add(ttl).Unix()
 	claims["iat"] = now.Unix()
 	claims["nbf"] = now.Unix()
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if keyID != "" {
+		unsigned.Header["kid"] = keyID
+	}
+
+	token, err := unsigned.SignedString(key)
 	if err != nil {
 		return "", fmt.Errorf("create: sign token: %w", err)
 	}
@@ -44,16 +83,68 @@ func CreateToken(ttl time.Duration, payload any, privateKey string) (string, err
 
 // ValidToken checks the validity of the token and returns the subject of the token if it is valid.
 func ValidToken(token, publicKey string) (any, error) {
-	decodedPublicKey, err := base64.StdEncoding.DecodeString(publicKey)
+	claims, err := parseToken(token, publicKey)
 	if err != nil {
-		return nil, fmt.Errorf("could not decode: %w", err)
+		return nil, err
 	}
+	return claims["sub"], nil
+}
 
-	key, err := jwt.ParseRSAPublicKeyFromPEM(decodedPublicKey)
+// ValidSessionToken checks the validity of a token created by CreateSessionToken and returns its claims.
+// A valid token without the session ID, issued before sessions, is an error.
+func ValidSessionToken(token, publicKey string) (sessionClaims SessionClaims, err error) {
+	claims, err := parseToken(token, publicKey)
 	if err != nil {
-		return "", fmt.Errorf("validate: parse key: %w", err)
+		return sessionClaims, err
 	}
 
+	return sessionClaimsOf(claims)
+}
+
+// sessionClaimsOf returns the session claims of a verified token.
+func sessionClaimsOf(claims jwt.MapClaims) (sessionClaims SessionClaims, err error) {
+	if sessionClaims.UserID, err = uuidClaim(claims, "sub"); err != nil {
+		return sessionClaims, err
+	}
+	if sessionClaims.SessionID, err = uuidClaim(claims, "sid"); err != nil {
+		return sessionClaims, err
+	}
+	if _, ok := claims["jti"]; ok {
+		if sessionClaims.TokenID, err = uuidClaim(claims, "jti"); err != nil {
+			return sessionClaims, err
+		}
+	}
+
+	return sessionClaims, nil
+}
+
+// uuidClaim returns the UUID held by the claim.
+func uuidClaim(claims jwt.MapClaims, claim string) (uuid.UUID, error) {
+	value, ok := claims[claim].(string)
+	if !ok {
+		return uuid.Nil, fmt.Errorf("%w: validate: no %s claim", errToken, claim)
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: validate: %s claim: %w", errToken, claim, err)
+	}
+
+	return id, nil
+}
+
+// parseToken checks the signature and the time claims of the token and returns its claims.
+func parseToken(token, publicKey string) (jwt.MapClaims, error) {
+	key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTokenWithKey(token, key)
+}
+
+// parseTokenWithKey checks the signature of the token with the key and its time claims and returns its claims.
+func parseTokenWithKey(token string, key *rsa.PublicKey) (jwt.MapClaims, error) {
 	parsedToken, err := jwt.Parse(token, func(t *jwt.Token) (any, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("%w unexpected method: %s", errToken, t.Header["alg"])
@@ -68,5 +159,35 @@ func ValidToken(token, publicKey string) (any, error) {
 	if !ok || !parsedToken.Valid {
 		return nil, fmt.Errorf("%w: %s", errToken, "validate: invalid token")
 	}
-	return claims["sub"], nil
+	return claims, nil
+}
+
+// parsePrivateKey decodes a base64 encoded PEM RSA private key.
+func parsePrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	decodedPrivateKey, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode key: %w", err)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(decodedPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("create: parse key: %w", err)
+	}
+
+	return key, nil
+}
+
+// parsePublicKey decodes a base64 encoded PEM RSA public key.
+func parsePublicKey(publicKey string) (*rsa.PublicKey, error) {
+	decodedPublicKey, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode: %w", err)
+	}
+
+	key, err := jwt.ParseRSAPublicKeyFromPEM(decodedPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("validate: parse key: %w", err)
+	}
+
+	return key, nil
 }
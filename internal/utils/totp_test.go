@@ -0,0 +1,85 @@
+package utils_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/keeper/internal/utils"
+)
+
+// rfcSecret is the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890", in base32.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// The last six digits of the RFC 6238 appendix B test vectors.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := utils.TOTPCode(rfcSecret, utils.TOTPCounter(time.Unix(tt.unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, tt.code, code)
+	}
+
+	_, err := utils.TOTPCode("not base32!", 1)
+	require.ErrorIs(t, err, utils.ErrTOTPSecret)
+}
+
+func TestValidTOTP(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	counter := utils.TOTPCounter(now)
+	code, err := utils.TOTPCode(secret, counter)
+	require.NoError(t, err)
+
+	matched, ok := utils.ValidTOTP(secret, code, now, 0)
+	require.True(t, ok)
+	require.Equal(t, counter, matched)
+
+	_, ok = utils.ValidTOTP(secret, code, now.Add(30*time.Second), 0)
+	require.True(t, ok, "one step of clock skew is allowed")
+	_, ok = utils.ValidTOTP(secret, code, now.Add(2*time.Minute), 0)
+	require.False(t, ok)
+	_, ok = utils.ValidTOTP(secret, code, now, matched)
+	require.False(t, ok, "a used code is rejected")
+	_, ok = utils.ValidTOTP(secret, "000000", now.Add(10*time.Minute), 0)
+	require.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := utils.TOTPURI("Keeper", "user@example.com", "SECRET")
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/Keeper:user@example.com?"))
+	require.Contains(t, uri, "secret=SECRET")
+	require.Contains(t, uri, "issuer=Keeper")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := utils.GenerateRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		require.Len(t, code, 11)
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+
+	require.Equal(t, utils.HashRecoveryCode(codes[0]), utils.HashRecoveryCode(" "+strings.ToUpper(codes[0])))
+	require.Equal(t, utils.HashRecoveryCode(codes[0]), utils.HashRecoveryCode(strings.ReplaceAll(codes[0], "-", "")))
+	require.NotEqual(t, utils.HashRecoveryCode(codes[0]), utils.HashRecoveryCode(codes[1]))
+}
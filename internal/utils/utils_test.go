@@ -1,29 +1,191 @@
 package utils_test
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
 	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 
 	config "github.com/nextlag/keeper/config/server"
 	"github.com/nextlag/keeper/internal/utils"
 )
 
-const phrase = "This is top secret"
+const (
+	phrase = "This is top secret"
+
+	// legacyPhrase is phrase sealed with "secretKey" by the headerless format.
+	legacyPhrase = "nM5dT55UrtBeMq8CSyGEjMIzrrp8NwQTUpxIJCk5Ozs-1k5e_yUcXZ_MKUoa5g=="
+)
+
+// testKDFParams keeps key derivation cheap in tests.
+func testKDFParams(email string) utils.KDFParams {
+	return utils.KDFParams{Time: 1, Memory: 1024, Threads: 1, Salt: utils.UserSalt(email)}
+}
+
+// encrypt encrypts the string with the cipher and fails the test on error.
+func encrypt(t *testing.T, c *utils.Cipher, plainString string) string {
+	t.Helper()
+
+	encryptedString, err := c.Encrypt(plainString)
+	require.NoError(t, err)
+
+	return encryptedString
+}
+
+// decrypt decrypts the string with the cipher and fails the test on error.
+func decrypt(t *testing.T, c *utils.Cipher, encryptedString string) string {
+	t.Helper()
+
+	plainString, err := c.Decrypt(encryptedString)
+	require.NoError(t, err)
+
+	return plainString
+}
 
 func TestCrypto(t *testing.T) {
 	secretKey := "secretKey"
-	encryptedString := utils.Encrypt(secretKey, phrase)
-	decryptedString := utils.Decrypt(secretKey, encryptedString)
+	c := utils.NewCipher(secretKey, testKDFParams("user@example.com"))
+	encryptedString := encrypt(t, c, phrase)
+	decryptedString := decrypt(t, c, encryptedString)
 
 	if phrase != decryptedString {
 		t.Errorf("got %q, wanted %q", decryptedString, phrase)
 	}
+	require.True(t, utils.IsEnvelope(encryptedString))
+	require.False(t, c.NeedsUpgrade(encryptedString))
+}
+
+func TestCryptoLegacy(t *testing.T) {
+	c := utils.NewCipher("secretKey", testKDFParams("user@example.com"))
+
+	require.Equal(t, phrase, decrypt(t, c, legacyPhrase))
+	require.True(t, c.NeedsUpgrade(legacyPhrase))
+}
+
+func TestCryptoParams(t *testing.T) {
+	secretKey := "secretKey"
+	encryptedString := encrypt(t, utils.NewCipher(secretKey, testKDFParams("first@example.com")), phrase)
+
+	c := utils.NewCipher(secretKey, testKDFParams("second@example.com"))
+	require.True(t, c.NeedsUpgrade(encryptedString))
+	require.Equal(t, phrase, decrypt(t, c, encryptedString))
+
+	_, err := utils.NewCipher("wrongKey", testKDFParams("first@example.com")).Decrypt(encryptedString)
+	require.ErrorIs(t, err, utils.ErrAuthFailed)
+}
+
+func TestDecryptErrors(t *testing.T) {
+	c := utils.NewCipher("secretKey", testKDFParams("user@example.com"))
+	encryptedString := encrypt(t, c, phrase)
+
+	_, err := c.Decrypt("plain value")
+	require.ErrorIs(t, err, utils.ErrMalformed)
+	_, err = c.Decrypt(encryptedString[:len(encryptedString)-8])
+	require.Error(t, err)
+	_, err = c.Decrypt("$keeper$" + base64.URLEncoding.EncodeToString([]byte{9, 1}))
+	require.ErrorIs(t, err, utils.ErrUnsupportedVersion)
+	_, err = c.Decrypt("$keeper$" + base64.URLEncoding.EncodeToString([]byte{1}))
+	require.ErrorIs(t, err, utils.ErrMalformed)
+
+	envelope, err := base64.URLEncoding.DecodeString(strings.TrimPrefix(encryptedString, "$keeper$"))
+	require.NoError(t, err)
+	envelope[len(envelope)-1] ^= 1
+	_, err = c.Decrypt("$keeper$" + base64.URLEncoding.EncodeToString(envelope))
+	require.ErrorIs(t, err, utils.ErrAuthFailed)
+}
+
+func TestDecryptKDFParams(t *testing.T) {
+	c := utils.NewCipher("secretKey", testKDFParams("user@example.com"))
+	envelope, err := base64.URLEncoding.DecodeString(strings.TrimPrefix(encrypt(t, c, phrase), "$keeper$"))
+	require.NoError(t, err)
+	streamed := encryptStream(t, c, []byte(phrase))
+	headerAt := bytes.Index(streamed, envelope[:12])
+	require.Positive(t, headerAt)
+
+	tests := []struct {
+		name    string
+		time    uint32
+		memory  uint32
+		threads uint8
+	}{
+		{name: "no passes", time: 0, memory: 1024, threads: 1},
+		{name: "too many passes", time: 1 << 20, memory: 1024, threads: 1},
+		{name: "no threads", time: 1, memory: 1024, threads: 0},
+		{name: "too little memory per thread", time: 1, memory: 8*4 - 1, threads: 4},
+		{name: "too much memory", time: 1, memory: 1<<32 - 1, threads: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setParams writes the parameters to the key header at the offset.
+			setParams := func(data []byte, at int) []byte {
+				data = bytes.Clone(data)
+				binary.BigEndian.PutUint32(data[at+2:], tt.time)
+				binary.BigEndian.PutUint32(data[at+6:], tt.memory)
+				data[at+10] = tt.threads
+				return data
+			}
+
+			_, err := c.Decrypt("$keeper$" + base64.URLEncoding.EncodeToString(setParams(envelope, 0)))
+			require.ErrorIs(t, err, utils.ErrMalformed)
+			_, err = decryptStream(c, setParams(streamed, headerAt))
+			require.ErrorIs(t, err, utils.ErrMalformed)
+		})
+	}
+}
+
+func TestCryptoBound(t *testing.T) {
+	c := utils.NewCipher("secretKey", testKDFParams("user@example.com"))
+	unbound := encrypt(t, c, phrase)
+	encryptedString, err := c.EncryptBound(phrase, []byte("login:1:password"))
+	require.NoError(t, err)
+
+	plainString, err := c.DecryptBound(encryptedString, []byte("login:1:password"))
+	require.NoError(t, err)
+	require.Equal(t, phrase, plainString)
+	require.False(t, c.NeedsUpgradeBound(encryptedString))
+
+	_, err = c.DecryptBound(encryptedString, []byte("login:2:password"))
+	require.ErrorIs(t, err, utils.ErrTampered)
+	require.ErrorIs(t, err, utils.ErrAuthFailed)
+	_, err = c.DecryptBound(encryptedString, []byte("login:1:login"))
+	require.ErrorIs(t, err, utils.ErrTampered)
+	_, err = c.Decrypt(encryptedString)
+	require.ErrorIs(t, err, utils.ErrTampered)
+
+	plainString, err = c.DecryptBound(unbound, []byte("login:1:password"))
+	require.NoError(t, err)
+	require.Equal(t, phrase, plainString)
+	require.True(t, c.NeedsUpgradeBound(unbound))
+	require.True(t, c.NeedsUpgradeBound(legacyPhrase))
+}
+
+func TestKeyCipher(t *testing.T) {
+	params := testKDFParams("user@example.com")
+	encryptedString := encrypt(t, utils.NewCipher("secretKey", params), phrase)
+
+	c := utils.NewKeyCipher(utils.DeriveKey("secretKey", params), params)
+	require.Equal(t, phrase, decrypt(t, c, encryptedString))
+	require.Equal(t, phrase, decrypt(t, c, encrypt(t, c, phrase)))
+
+	_, err := c.Decrypt(legacyPhrase)
+	require.ErrorIs(t, err, utils.ErrAuthFailed)
+	_, err = c.Decrypt(encrypt(t, utils.NewCipher("secretKey", testKDFParams("other@example.com")), phrase))
+	require.ErrorIs(t, err, utils.ErrAuthFailed)
+}
+
+func TestUserSalt(t *testing.T) {
+	require.Equal(t, utils.UserSalt("User@Example.com "), utils.UserSalt("user@example.com"))
+	require.NotEqual(t, utils.UserSalt("first@example.com"), utils.UserSalt("second@example.com"))
 }
 
 func TestHash(t *testing.T) {
@@ -56,15 +218,44 @@ func TestToken(t *testing.T) {
 	}
 }
 
+func TestSessionToken(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	claims := utils.SessionClaims{UserID: uuid.New(), SessionID: uuid.New(), TokenID: uuid.New()}
+	token, err := utils.CreateSessionToken(time.Hour, claims, cfg.Security.AccessTokenPrivateKey)
+	require.NoError(t, err)
+	validClaims, err := utils.ValidSessionToken(token, cfg.Security.AccessTokenPublicKey)
+	require.NoError(t, err)
+	require.Equal(t, claims, validClaims)
+
+	claims.TokenID = uuid.Nil
+	token, err = utils.CreateSessionToken(time.Hour, claims, cfg.Security.AccessTokenPrivateKey)
+	require.NoError(t, err)
+	validClaims, err = utils.ValidSessionToken(token, cfg.Security.AccessTokenPublicKey)
+	require.NoError(t, err)
+	require.Equal(t, claims, validClaims)
+
+	token, err = utils.CreateToken(time.Hour, claims.UserID, cfg.Security.AccessTokenPrivateKey)
+	require.NoError(t, err)
+	_, err = utils.ValidSessionToken(token, cfg.Security.AccessTokenPublicKey)
+	require.Error(t, err)
+}
+
 func TestCryptoFile(t *testing.T) {
 	inputFilePath := "../../README.md"
 	outputEncryptedFilePath := "../../encrypted_README.md"
 	outputDecryptedFilePath := "../../decrypted_README.md"
-	err := utils.EncryptFile(phrase, inputFilePath, outputEncryptedFilePath)
+	c := utils.NewCipher(phrase, testKDFParams("user@example.com"))
+	err := c.EncryptFile(inputFilePath, outputEncryptedFilePath)
 	if err != nil {
 		t.Errorf("got %v error", err)
 	}
-	err = utils.DecryptFile(phrase, outputEncryptedFilePath, outputDecryptedFilePath)
+	needsUpgrade, err := c.FileNeedsUpgrade(outputEncryptedFilePath)
+	require.NoError(t, err)
+	require.False(t, needsUpgrade)
+
+	err = c.DecryptFile(outputEncryptedFilePath, outputDecryptedFilePath)
 	if err != nil {
 		t.Errorf("got %v error", err)
 	}
@@ -102,3 +293,85 @@ func getFileHash(filePath string) (string, error) {
 
 	return string(hash.Sum(nil)), nil
 }
+
+func TestAuthHash(t *testing.T) {
+	hash := utils.AuthHash("secretKey", "user@example.com")
+	require.NotEmpty(t, hash)
+	require.NotContains(t, hash, "secretKey")
+	require.Equal(t, hash, utils.AuthHash("secretKey", " User@Example.com"))
+	require.NotEqual(t, hash, utils.AuthHash("otherKey", "user@example.com"))
+	require.NotEqual(t, hash, utils.AuthHash("secretKey", "other@example.com"))
+}
+
+func TestVerifyPassword(t *testing.T) {
+	params := utils.PasswordParams{Time: 1, Memory: 1024, Threads: 1}
+	argon2Hash, err := utils.HashPasswordWithParams("TestPassword", params)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(argon2Hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("TestPassword"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		wantErr  error
+	}{
+		{
+			name:     "argon2id hash",
+			hash:     argon2Hash,
+			password: "TestPassword",
+		},
+		{
+			name:     "argon2id hash with a wrong password",
+			hash:     argon2Hash,
+			password: "WrongPassword",
+			wantErr:  utils.ErrPasswordMismatch,
+		},
+		{
+			name:     "bcrypt hash",
+			hash:     string(bcryptHash),
+			password: "TestPassword",
+		},
+		{
+			name:     "bcrypt hash with a wrong password",
+			hash:     string(bcryptHash),
+			password: "WrongPassword",
+			wantErr:  utils.ErrPasswordMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := utils.VerifyPassword(tt.hash, tt.password)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	require.Error(t, utils.VerifyPassword("$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$aGFzaA", "TestPassword"))
+	require.Error(t, utils.VerifyPassword("plain", "plain"))
+}
+
+func TestVerifyPasswordLongPassword(t *testing.T) {
+	password := strings.Repeat("a", 72)
+	hashedPassword, err := utils.HashPasswordWithParams(password, utils.PasswordParams{Time: 1, Memory: 1024, Threads: 1})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, utils.VerifyPassword(hashedPassword, password+"b"), utils.ErrPasswordMismatch)
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	params := utils.PasswordParams{Time: 1, Memory: 1024, Threads: 1}
+	argon2Hash, err := utils.HashPasswordWithParams("TestPassword", params)
+	require.NoError(t, err)
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("TestPassword"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	require.False(t, utils.PasswordNeedsRehash(argon2Hash, params))
+	require.True(t, utils.PasswordNeedsRehash(argon2Hash, utils.PasswordParams{Time: 2, Memory: 1024, Threads: 1}))
+	require.True(t, utils.PasswordNeedsRehash(string(bcryptHash), params))
+}
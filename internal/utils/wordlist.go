@@ -0,0 +1,44 @@
+package utils
+
+import "sync"
+
+// shareWords encodes the bytes of a share, the word at an index stands for that byte.
+// The words are short, common and differ in their first four letters.
+var shareWords = [256]string{
+	"acid", "acorn", "actor", "adult", "agent", "alarm", "album", "alert", "alley", "alpha", "amber",
+	"anchor", "angle", "ankle", "apple", "apron", "arena", "armor", "arrow", "atlas", "attic", "audio",
+	"autumn", "bacon", "badge", "bagel", "baker", "bamboo", "banana", "banjo", "barrel", "basin",
+	"basket", "beach", "beaver", "berry", "bicycle", "bison", "blanket", "bonus", "border", "bottle",
+	"bracket", "branch", "bread", "brick", "bridge", "broom", "bubble", "bucket", "bundle", "burger",
+	"button", "cabin", "cactus", "camel", "candle", "canoe", "canvas", "canyon", "carpet", "carrot",
+	"castle", "cement", "cherry", "chess", "cider", "circus", "citrus", "clover", "cobalt", "cocoa",
+	"coffee", "comet", "copper", "coral", "cotton", "cradle", "crayon", "curtain", "cushion", "dahlia",
+	"daisy", "dancer", "denim", "desert", "diamond", "dinner", "dolphin", "donkey", "dragon", "drawer",
+	"dream", "drum", "eagle", "easel", "echo", "eclipse", "elbow", "ember", "engine", "fabric",
+	"falcon", "feather", "fence", "ferry", "fiddle", "flute", "forest", "fossil", "fox", "frost",
+	"galaxy", "garden", "garlic", "geyser", "ginger", "giraffe", "glacier", "globe", "goblet", "gopher",
+	"granite", "grape", "gravel", "guitar", "hammer", "harbor", "harvest", "hazel", "helmet", "hermit",
+	"honey", "horizon", "hornet", "husky", "igloo", "iguana", "island", "ivory", "jacket", "jaguar",
+	"jelly", "jigsaw", "jungle", "kayak", "kernel", "kettle", "kitten", "koala", "ladder", "lagoon",
+	"lantern", "laptop", "lava", "lemon", "lentil", "letter", "lilac", "lobster", "locket", "lotus",
+	"lumber", "magnet", "mango", "maple", "marble", "meadow", "melon", "meteor", "mirror", "mitten",
+	"monkey", "mosaic", "muffin", "museum", "napkin", "nectar", "needle", "nickel", "noodle", "nutmeg",
+	"oasis", "olive", "onion", "orange", "orbit", "orchid", "otter", "oyster", "paddle", "palace",
+	"panda", "papaya", "parrot", "peanut", "pebble", "pencil", "pepper", "piano", "pillow", "pirate",
+	"planet", "pocket", "pony", "poppy", "potato", "puzzle", "quartz", "quilt", "rabbit", "radar",
+	"radish", "raven", "ribbon", "riddle", "river", "robin", "rocket", "saddle", "salmon", "sandal",
+	"satin", "scarf", "shadow", "silver", "sketch", "sparrow", "spider", "sponge", "squid", "statue",
+	"sugar", "summit", "sunset", "teapot", "temple", "tiger", "tomato", "tulip", "turtle", "unicorn",
+	"valley", "velvet", "violin", "volcano", "waffle", "wagon", "walnut", "walrus", "window", "winter",
+	"wizard", "yogurt", "zebra", "zipper",
+}
+
+// shareWordBytes returns the bytes of the share words by their first four letters.
+var shareWordBytes = sync.OnceValue(func() map[string]byte {
+	bytes := make(map[string]byte, len(shareWords))
+	for index, word := range shareWords {
+		bytes[shareWordKey(word)] = byte(index)
+	}
+
+	return bytes
+})
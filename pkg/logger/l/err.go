@@ -5,6 +5,10 @@ import (
 )
 
 // WrapErr wraps the provided error with the package and function name of the caller.
+// A nil error stays nil, so the result of a call can be wrapped and returned as is.
 func WrapErr(err error) error {
+	if err == nil {
+		return nil
+	}
 	return fmt.Errorf("%w", err)
 }